@@ -0,0 +1,217 @@
+package stuber
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// SearchResponse is the body answered by the search endpoint of the admin
+// API.
+type SearchResponse struct {
+	Found    *Stub   `json:"found,omitempty"`    // The matched stub, if any.
+	Similars []*Stub `json:"similars,omitempty"` // The most similar stubs, in descending rank.
+}
+
+// BatchSearchResponse is the answer to one query of a batch search of the
+// admin API.
+type BatchSearchResponse struct {
+	SearchResponse
+
+	Error *ErrorResponse `json:"error,omitempty"` // The failure of the query, if any.
+}
+
+// Codes of the errors answered by the admin API, telling the kind of the
+// error without parsing its message.
+const (
+	ErrorCodeInvalidImport   = "invalid_import"    // The body is not a valid document of stubs.
+	ErrorCodeInvalidQuery    = "invalid_query"     // The body is not a valid query.
+	ErrorCodeServiceNotFound = "service_not_found" // No stub has the service of the query.
+	ErrorCodeMethodNotFound  = "method_not_found"  // No stub has the method of the query.
+	ErrorCodeStubNotFound    = "stub_not_found"    // No stub answers the query, or has the ID.
+	ErrorCodeInternal        = "internal"          // Any other failure.
+)
+
+// ErrorResponse is the body answered by the admin API on failure.
+type ErrorResponse struct {
+	Error string `json:"error"` // The message of the error.
+	Code  string `json:"code"`  // The kind of the error, such as ErrorCodeStubNotFound.
+}
+
+// NewAdminHandler returns the HTTP handler of the admin API of the
+// Budgerigar, which stuberclient speaks:
+//
+//   - GET /api/stubs lists all the stubs, POST adds the stubs of the body,
+//     decoded like Import, and DELETE clears them.
+//   - GET /api/stubs/used and GET /api/stubs/unused list the used and the
+//     unused stubs.
+//   - GET /api/stubs/{id} returns a stub and DELETE deletes it.
+//   - POST /api/stubs/batchDelete deletes the stubs of the body, a list of
+//     IDs, and answers their number.
+//   - POST /api/stubs/search answers the SearchResponse of the query of the
+//     body, honoring the headers of NewQuery. A body holding an array of
+//     queries is searched with FindByQueries, and answered with an array of
+//     BatchSearchResponse, in the order of the queries.
+//
+// Failures are answered with an ErrorResponse: 400 for invalid documents and
+// queries, 404 for unknown services, methods and stubs.
+//
+// Parameters:
+// - b: The Budgerigar to administer.
+//
+// Returns:
+// - http.Handler: The handler to mount at the root of a server.
+func NewAdminHandler(b *Budgerigar) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/stubs", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, b.All())
+	})
+	mux.HandleFunc("GET /api/stubs/used", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, b.Used())
+	})
+	mux.HandleFunc("GET /api/stubs/unused", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, b.Unused())
+	})
+	mux.HandleFunc("POST /api/stubs", func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, fmt.Errorf("%w: %w", ErrInvalidImport, err))
+
+			return
+		}
+
+		ids, err := b.Import(body)
+		if err != nil {
+			writeError(w, err)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, ids)
+	})
+	mux.HandleFunc("DELETE /api/stubs", func(w http.ResponseWriter, _ *http.Request) {
+		b.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/stubs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeError(w, ErrStubNotFound)
+
+			return
+		}
+
+		stub := b.FindByID(id)
+		if stub == nil {
+			writeError(w, ErrStubNotFound)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, stub)
+	})
+	mux.HandleFunc("DELETE /api/stubs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil || b.DeleteByID(id) == 0 {
+			writeError(w, ErrStubNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/stubs/batchDelete", func(w http.ResponseWriter, r *http.Request) {
+		var ids []uuid.UUID
+		if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+			writeError(w, fmt.Errorf("%w: %w", ErrInvalidImport, err))
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, b.DeleteByID(ids...))
+	})
+	mux.HandleFunc("POST /api/stubs/search", func(w http.ResponseWriter, r *http.Request) {
+		queries, batch, err := decodeQueries(r, b.toggles)
+		if err != nil && !errors.Is(err, ErrInvalidQuery) {
+			err = fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		if err != nil {
+			writeError(w, err)
+
+			return
+		}
+
+		if batch {
+			responses := make([]BatchSearchResponse, 0, len(queries))
+
+			for _, outcome := range b.FindByQueries(queries...) {
+				var response BatchSearchResponse
+
+				if outcome.Err != nil {
+					_, body := errorResponse(outcome.Err)
+					response.Error = &body
+				} else {
+					response.SearchResponse = SearchResponse{Found: outcome.Result.Found(), Similars: outcome.Result.Similars()}
+				}
+
+				responses = append(responses, response)
+			}
+
+			writeJSON(w, http.StatusOK, responses)
+
+			return
+		}
+
+		result, err := b.FindByQuery(queries[0])
+		if err != nil {
+			writeError(w, err)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, SearchResponse{Found: result.Found(), Similars: result.Similars()})
+	})
+
+	return mux
+}
+
+// writeJSON writes the JSON encoding of the value with the given status.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes the ErrorResponse of the error, with the status of its
+// kind.
+func writeError(w http.ResponseWriter, err error) {
+	status, body := errorResponse(err)
+
+	writeJSON(w, status, body)
+}
+
+// errorResponse returns the status and the ErrorResponse of the error.
+func errorResponse(err error) (int, ErrorResponse) {
+	status, code := http.StatusInternalServerError, ErrorCodeInternal
+
+	switch {
+	case errors.Is(err, ErrInvalidImport):
+		status, code = http.StatusBadRequest, ErrorCodeInvalidImport
+	case errors.Is(err, ErrInvalidQuery):
+		status, code = http.StatusBadRequest, ErrorCodeInvalidQuery
+	case errors.Is(err, ErrServiceNotFound):
+		status, code = http.StatusNotFound, ErrorCodeServiceNotFound
+	case errors.Is(err, ErrMethodNotFound):
+		status, code = http.StatusNotFound, ErrorCodeMethodNotFound
+	case errors.Is(err, ErrStubNotFound):
+		status, code = http.StatusNotFound, ErrorCodeStubNotFound
+	}
+
+	return status, ErrorResponse{Error: err.Error(), Code: code}
+}
@@ -0,0 +1,94 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestNewAdminHandler_Errors(t *testing.T) {
+	handler := stuber.NewAdminHandler(stuber.New())
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/api/stubs/search", `{"service": "Greeter"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/stubs/search", `{`, http.StatusBadRequest},
+		{http.MethodPost, "/api/stubs/search", `{"service": "Greeter", "method": "SayHello", "data": {}}`, http.StatusNotFound},
+		{http.MethodPost, "/api/stubs", `{"service": 1}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/stubs/not-a-uuid", ``, http.StatusNotFound},
+		{http.MethodDelete, "/api/stubs/00000000-0000-0000-0000-000000000001", ``, http.StatusNotFound},
+		{http.MethodPut, "/api/stubs", ``, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+		require.Equal(t, tt.status, rec.Code, "%s %s %s", tt.method, tt.path, tt.body)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(`{"service": "Greeter", "method": "SayHello", "data": {}}`)))
+
+	var body stuber.ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Equal(t, stuber.ErrorCodeServiceNotFound, body.Code)
+}
+
+func TestNewAdminHandler_BatchSearch(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	s.PutMany(stub)
+
+	handler := stuber.NewAdminHandler(s)
+
+	search := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(body)))
+
+		return rec
+	}
+
+	rec := search(` [
+		{"service": "Greeter", "method": "SayHello", "data": {"name": "Bob"}},
+		{"service": "Greeter", "method": "SayGoodbye", "data": {}}
+	]`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var responses []stuber.BatchSearchResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&responses))
+	require.Len(t, responses, 2)
+	require.Equal(t, stub.ID, responses[0].Found.ID)
+	require.Nil(t, responses[0].Error)
+	require.Nil(t, responses[1].Found)
+	require.Equal(t, stuber.ErrorCodeMethodNotFound, responses[1].Error.Code)
+
+	// An invalid query fails the batch.
+	rec = search(`[{"service": "Greeter", "method": "SayHello"}, {"service": "Greeter"}]`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// A single query is answered alone.
+	rec = search(`{"service": "Greeter", "method": "SayHello", "data": {"name": "Bob"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response stuber.SearchResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.Equal(t, stub.ID, response.Found.ID)
+}
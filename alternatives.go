@@ -0,0 +1,82 @@
+package stuber
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// WeightedOutput is an alternative output of a stub, selected in proportion
+// to its weight.
+type WeightedOutput struct {
+	Weight int    `json:"weight"` // The weight of the output, never selected unless positive.
+	Output Output `json:"output"` // The output returned when selected.
+}
+
+// outputPicker selects the alternative outputs of the matched stubs.
+//
+// With a seed, of the output or of the Budgerigar, each stub draws from its
+// own generator seeded by it and the ID of the stub, so the same sequence of
+// matches selects the same outputs across runs.
+type outputPicker struct {
+	mu     sync.Mutex
+	seeded map[uuid.UUID]*rand.Rand
+}
+
+// newOutputPicker creates a new outputPicker.
+func newOutputPicker() *outputPicker {
+	return &outputPicker{seeded: make(map[uuid.UUID]*rand.Rand)}
+}
+
+// reset restarts the seeded generators.
+func (p *outputPicker) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seeded = make(map[uuid.UUID]*rand.Rand)
+}
+
+// pick returns the stub unchanged if it has no alternative outputs,
+// otherwise a copy of the stub with one of them, selected in proportion to
+// their weights.
+func (p *outputPicker) pick(stub *Stub, seed *uint64) *Stub {
+	total := 0
+
+	for _, alternative := range stub.Output.Alternatives {
+		total += max(alternative.Weight, 0)
+	}
+
+	if total == 0 {
+		return stub
+	}
+
+	n := p.intN(stub, seed, total)
+
+	for _, alternative := range stub.Output.Alternatives {
+		if n -= max(alternative.Weight, 0); n < 0 {
+			return stub.withOutput(alternative.Output)
+		}
+	}
+
+	return stub
+}
+
+// intN returns a random number in [0, n) from the generator of the stub.
+func (p *outputPicker) intN(stub *Stub, seed *uint64, n int) int {
+	if seed == nil {
+		return rand.IntN(n) //nolint:gosec
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rng, ok := p.seeded[stub.ID]
+	if !ok {
+		rng = rand.New(rand.NewPCG(*seed, binary.BigEndian.Uint64(stub.ID[8:]))) //nolint:gosec
+		p.seeded[stub.ID] = rng
+	}
+
+	return rng.IntN(n)
+}
@@ -0,0 +1,112 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func alternativesStub() *stuber.Stub {
+	return &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Service: "Search",
+		Method:  "Query",
+		Output: stuber.Output{
+			Data: map[string]interface{}{"status": "base"},
+			Alternatives: []stuber.WeightedOutput{
+				{Weight: 3, Output: stuber.Output{Data: map[string]interface{}{"status": "ok"}}},
+				{Weight: 1, Output: stuber.Output{Error: "unavailable"}},
+				{Weight: 0, Output: stuber.Output{Error: "never"}},
+			},
+		},
+	}
+}
+
+func TestBudgerigar_Alternatives(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(alternativesStub())
+
+	counts := make(map[string]int)
+
+	for range 4000 {
+		result, err := s.FindByQuery(stuber.Query{Service: "Search", Method: "Query"})
+		require.NoError(t, err)
+
+		output := result.Found().Output
+		require.Empty(t, output.Alternatives)
+
+		if output.Error != "" {
+			counts[output.Error]++
+		} else {
+			counts[output.Data["status"].(string)]++
+		}
+	}
+
+	require.Zero(t, counts["base"])
+	require.Zero(t, counts["never"])
+	require.InDelta(t, 3000, counts["ok"], 300)
+	require.InDelta(t, 1000, counts["unavailable"], 300)
+}
+
+func TestBudgerigar_Alternatives_Seed(t *testing.T) {
+	run := func() []string {
+		s := stuber.New(stuber.WithTemplateSeed(42))
+		s.PutMany(alternativesStub())
+
+		var errs []string
+
+		for range 20 {
+			result, err := s.FindByQuery(stuber.Query{Service: "Search", Method: "Query"})
+			require.NoError(t, err)
+
+			errs = append(errs, result.Found().Output.Error)
+		}
+
+		return errs
+	}
+
+	first := run()
+	require.Equal(t, first, run())
+	require.Contains(t, first, "")
+	require.Contains(t, first, "unavailable")
+}
+
+func TestBudgerigar_Alternatives_Base(t *testing.T) {
+	s := stuber.New()
+
+	base := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Search",
+		Method:   "Query",
+		Abstract: true,
+		Output:   stuber.Output{Headers: map[string]string{"x-source": "base"}},
+	}
+
+	derived := alternativesStub()
+	derived.Base = &base.ID
+	s.PutMany(base, derived)
+
+	counts := make(map[string]int)
+
+	for range 400 {
+		result, err := s.FindByQuery(stuber.Query{Service: "Search", Method: "Query"})
+		require.NoError(t, err)
+
+		output := result.Found().Output
+		require.Empty(t, output.Alternatives)
+
+		if output.Error != "" {
+			counts[output.Error]++
+		} else {
+			counts[output.Data["status"].(string)]++
+		}
+	}
+
+	// The alternatives of the derived stub are selected, not its own output.
+	require.Zero(t, counts["base"])
+	require.Positive(t, counts["ok"])
+	require.Positive(t, counts["unavailable"])
+}
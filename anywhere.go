@@ -0,0 +1,77 @@
+package stuber
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+)
+
+// ErrScanLimitReached is returned by FindAnywhere when there are more Stub
+// values than it is allowed to evaluate.
+var ErrScanLimitReached = errors.New("scan limit reached")
+
+// DefaultScanLimit is the default number of Stub values evaluated by
+// FindAnywhere.
+const DefaultScanLimit = 10_000
+
+// anywhere returns the Stub values of all the services and methods that match
+// the data and the headers of the query, merged with their base stubs, by
+// decreasing rank and then in their canonical order.
+//
+// At most the scan limit of Stub values are evaluated, in their canonical
+// order, in which case the matches among them are returned along with
+// ErrScanLimitReached.
+func (s *searcher) anywhere(query Query) ([]*Stub, error) {
+	stubs := s.all()
+	SortStubs(stubs)
+
+	var err error
+
+	if s.scanLimit > 0 && len(stubs) > s.scanLimit {
+		stubs, err = stubs[:s.scanLimit], ErrScanLimitReached
+	}
+
+	query = normalizeQuery(query)
+
+	var matched []candidate
+
+	for _, stub := range stubs {
+		if current := s.evaluate(query, stub); current.valid && current.matched {
+			matched = append(matched, current)
+		}
+	}
+
+	slices.SortStableFunc(matched, func(a, b candidate) int {
+		return cmp.Compare(b.rank, a.rank)
+	})
+
+	result := make([]*Stub, len(matched))
+	for i, current := range matched {
+		result[i] = current.stub
+	}
+
+	return result, err
+}
+
+// FindAnywhere returns the Stub values of any service and method that match
+// the data and the headers of the query, answering "does any stub match this
+// payload?" for exploratory tools not knowing the target method.
+//
+// The service and the method of the query are ignored. Like
+// FindStubsByExample, the priority and the usage of the Stub values are
+// ignored, as are their ordered groups and dependencies, and the Stub values
+// are not marked as used.
+//
+// Parameters:
+// - query: The Query whose data and headers are matched.
+//
+// Returns:
+// - []*Stub: The matching Stub values merged with their base stubs, by
+// decreasing rank.
+// - error: ErrScanLimitReached, along with the matches found so far, if there
+// are more Stub values than the limit set by WithScanLimit.
+func (b *Budgerigar) FindAnywhere(query Query) ([]*Stub, error) {
+	query.Service, query.Method = "", ""
+
+	return b.searcher.anywhere(b.canonicalQuery(query))
+}
@@ -0,0 +1,50 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_FindAnywhere(t *testing.T) {
+	hello := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	goodbye := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Farewell",
+		Method:  "SayGoodbye",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+	}
+	other := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+	}
+
+	s := stuber.New()
+	s.PutMany(hello, goodbye, other)
+
+	query := stuber.Query{Data: map[string]interface{}{"name": "Bob"}}
+
+	stubs, err := s.FindAnywhere(query)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{hello.ID, goodbye.ID}, []uuid.UUID{stubs[0].ID, stubs[1].ID})
+	require.Empty(t, s.Used())
+
+	limited := stuber.New(stuber.WithScanLimit(1))
+	limited.PutMany(hello, goodbye, other)
+
+	// Farewell comes first in the canonical order.
+	stubs, err = limited.FindAnywhere(query)
+	require.ErrorIs(t, err, stuber.ErrScanLimitReached)
+	require.Len(t, stubs, 1)
+	require.Equal(t, goodbye.ID, stubs[0].ID)
+}
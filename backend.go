@@ -0,0 +1,155 @@
+package stuber
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Backend persists the Stub values of a Budgerigar attached to it with
+// AttachBackend, which writes its changes through.
+//
+// Each call is a transaction: either all of its changes are persisted, or
+// none is. A Backend must be safe for concurrent use.
+type Backend interface {
+	// Load returns the persisted stubs.
+	Load() ([]*Stub, error)
+	// Put persists the given stubs, replacing the ones with the same IDs.
+	Put(stubs []*Stub) error
+	// Delete deletes the stubs with the given IDs, ignoring the unknown ones.
+	Delete(ids []uuid.UUID) error
+	// Clear deletes all the stubs.
+	Clear() error
+}
+
+// persistence writes the changes of a searcher through to its backend.
+//
+// Its mutex is held by the changes of the searcher, so the backend receives
+// them in the order they are applied.
+type persistence struct {
+	sync.Mutex
+
+	backend Backend
+}
+
+// write applies the given write to the backend, if any, and logs its
+// failure: the change is already applied and the backend is behind.
+//
+// The mutex must be held.
+func (s *searcher) write(op string, write func(Backend) error) {
+	if s.persistence.backend == nil {
+		return
+	}
+
+	if err := write(s.persistence.backend); err != nil {
+		s.logger.Error("stuber: backend write failed", "op", op, "error", err)
+	}
+}
+
+// stubIDs returns the IDs of the given stubs.
+func stubIDs(stubs []*Stub) []uuid.UUID {
+	ids := make([]uuid.UUID, len(stubs))
+	for i, stub := range stubs {
+		ids[i] = stub.ID
+	}
+
+	return ids
+}
+
+// AttachBackend inserts the Stub values persisted by the backend, writes
+// the Stub values stored before to it, and then writes the changes of the Stub
+// values through to it: insertions, patches, deletions and clears.
+//
+// The Stub values stored before are written before the persisted ones are
+// inserted, so if the backend cannot be written, the Budgerigar is left
+// unchanged.
+//
+// A failed write doesn't fail the change, which is already applied, and is
+// logged with the logger of WithLogger.
+//
+// Parameters:
+// - backend: The backend to attach, or nil to detach the current one.
+//
+// Returns:
+// - []uuid.UUID: The keys of the Stub values loaded from the backend.
+// - error: An error if the backend cannot be loaded or written, in which case
+// it is not attached and no Stub value is inserted.
+func (b *Budgerigar) AttachBackend(backend Backend) ([]uuid.UUID, error) {
+	s := b.searcher
+
+	if backend == nil {
+		s.persistence.Lock()
+		defer s.persistence.Unlock()
+
+		s.persistence.backend = nil
+
+		return nil, nil
+	}
+
+	stubs, err := backend.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	b.prepare(stubs)
+
+	loaded := make(map[uuid.UUID]struct{}, len(stubs))
+	for _, stub := range stubs {
+		loaded[stub.ID] = struct{}{}
+	}
+
+	s.persistence.Lock()
+
+	// Write the Stub values stored before, which the backend doesn't have.
+	var missing []*Stub
+
+	for _, stub := range s.all() {
+		if _, ok := loaded[stub.ID]; !ok {
+			missing = append(missing, stub)
+		}
+	}
+
+	if err := backend.Put(missing); err != nil {
+		s.persistence.Unlock()
+
+		return nil, err
+	}
+
+	ids := s.insert(stubs)
+	s.persistence.backend = backend
+
+	s.persistence.Unlock()
+
+	s.cache.invalidate()
+	s.events.publish(EventPut, stubs...)
+	b.templateCache.invalidate(ids...)
+
+	return ids, nil
+}
+
+// MigrateToBackend writes the Stub values of the JSON or YAML document, or
+// directory of documents, at the given path to the backend, such as to move
+// from SaveToFile to a Backend.
+//
+// Parameters:
+// - path: The path of the file or the directory, as read by LoadFromFile.
+// - backend: The backend to write the Stub values to.
+//
+// Returns:
+// - int: The number of migrated Stub values.
+// - error: An error if the documents cannot be loaded, or the Stub values
+// written.
+func MigrateToBackend(path string, backend Backend) (int, error) {
+	b := New()
+
+	ids, err := b.LoadFromFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := backend.Put(b.All()); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
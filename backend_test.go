@@ -0,0 +1,120 @@
+package stuber_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+var errBackendDown = errors.New("backend down") //nolint:gochecknoglobals
+
+// failingBackend is a Backend whose writes fail.
+type failingBackend struct {
+	stubs []*stuber.Stub
+	puts  int
+}
+
+func (b *failingBackend) Load() ([]*stuber.Stub, error) { return b.stubs, nil }
+
+func (b *failingBackend) Put([]*stuber.Stub) error {
+	b.puts++
+
+	return errBackendDown
+}
+
+func (b *failingBackend) Delete([]uuid.UUID) error { return errBackendDown }
+
+func (b *failingBackend) Clear() error { return errBackendDown }
+
+func TestBudgerigar_AttachBackend_PutError(t *testing.T) {
+	local := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"}
+	persisted := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayBye"}
+
+	s := stuber.New()
+	s.PutMany(local)
+
+	backend := &failingBackend{stubs: []*stuber.Stub{persisted}}
+
+	ids, err := s.AttachBackend(backend)
+	require.ErrorIs(t, err, errBackendDown)
+	require.Nil(t, ids)
+	require.Equal(t, 1, backend.puts)
+
+	// Nothing was applied: the persisted stubs are not inserted.
+	require.Len(t, s.All(), 1)
+	require.Nil(t, s.FindByID(persisted.ID))
+
+	// And the backend is not attached.
+	s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHi"})
+	require.Equal(t, 1, backend.puts)
+}
+
+// memoryBackend is a Backend keeping the Stub values in memory.
+type memoryBackend struct {
+	stubs map[uuid.UUID]*stuber.Stub
+}
+
+func (b *memoryBackend) Load() ([]*stuber.Stub, error) {
+	stubs := make([]*stuber.Stub, 0, len(b.stubs))
+	for _, stub := range b.stubs {
+		stubs = append(stubs, stub)
+	}
+
+	return stubs, nil
+}
+
+func (b *memoryBackend) Put(stubs []*stuber.Stub) error {
+	for _, stub := range stubs {
+		b.stubs[stub.ID] = stub
+	}
+
+	return nil
+}
+
+func (b *memoryBackend) Delete(ids []uuid.UUID) error {
+	for _, id := range ids {
+		delete(b.stubs, id)
+	}
+
+	return nil
+}
+
+func (b *memoryBackend) Clear() error {
+	clear(b.stubs)
+
+	return nil
+}
+
+func TestNew_WithBackend(t *testing.T) {
+	persisted := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayBye"}
+	backend := &memoryBackend{stubs: map[uuid.UUID]*stuber.Stub{persisted.ID: persisted}}
+
+	// The options after WithBackend apply to the loaded stubs too.
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := stuber.New(stuber.WithBackend(backend), stuber.WithClock(func() time.Time { return now }))
+
+	require.Equal(t, now, *s.FindByID(persisted.ID).CreatedAt)
+
+	stub := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"}
+	s.PutMany(stub)
+	require.Contains(t, backend.stubs, stub.ID)
+
+	// A backend failing to attach is logged and left detached.
+	var logs bytes.Buffer
+
+	failing := &failingBackend{stubs: []*stuber.Stub{persisted}}
+	s = stuber.New(
+		stuber.WithBackend(failing),
+		stuber.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	require.Empty(t, s.All())
+	require.Contains(t, logs.String(), errBackendDown.Error())
+}
@@ -0,0 +1,41 @@
+package stuber
+
+import "net/http"
+
+// BatchResult is the outcome of one query of a batch search.
+type BatchResult struct {
+	Result *Result // The Result of the query, or nil.
+	Err    error   // The error of the query, or nil.
+}
+
+// FindByQueries searches the given queries in order, as FindByQuery does,
+// so a verification phase can check many calls at once.
+//
+// Parameters:
+// - queries: The queries to search.
+//
+// Returns:
+// - []BatchResult: The outcome of each query, in the order of the queries.
+func (b *Budgerigar) FindByQueries(queries ...Query) []BatchResult {
+	results := make([]BatchResult, len(queries))
+
+	for i, query := range queries {
+		results[i].Result, results[i].Err = b.FindByQuery(query)
+	}
+
+	return results
+}
+
+// NewQueries decodes the queries of the request, whose body is either a
+// single query or an array of queries, honoring the StrictQuery feature flag
+// of the Budgerigar.
+//
+// Parameters:
+// - r: The HTTP request whose body holds the queries.
+//
+// Returns:
+// - []Query: The decoded queries, in order.
+// - error: An error if the body cannot be decoded or a query is not valid.
+func (b *Budgerigar) NewQueries(r *http.Request) ([]Query, error) {
+	return NewQueriesFlags(r, b.toggles)
+}
@@ -0,0 +1,54 @@
+package stuber_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_FindByQueries(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+
+	s.PutMany(stub)
+
+	payload := ` [
+		{"service": "Greeter", "method": "SayHello", "data": {"name": "Bob"}},
+		{"service": "Unknown", "method": "SayHello", "data": {}}
+	]`
+
+	queries, err := s.NewQueries(httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(payload)))
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+
+	results := s.FindByQueries(queries...)
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, stub.ID, results[0].Result.Found().ID)
+	require.ErrorIs(t, results[1].Err, stuber.ErrServiceNotFound)
+
+	single := `{"service": "Greeter", "method": "SayHello", "data": {"name": "Bob"}}`
+
+	queries, err = stuber.NewQueries(httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(single)))
+	require.NoError(t, err)
+	require.Len(t, queries, 1)
+	require.Equal(t, "Bob", queries[0].Data["name"])
+
+	invalid := `[{"service": "Greeter", "method": "SayHello"}, {"service": "Greeter"}]`
+
+	_, err = stuber.NewQueries(httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(invalid)))
+	require.ErrorIs(t, err, stuber.ErrInvalidQuery)
+	require.ErrorContains(t, err, "query 1:")
+}
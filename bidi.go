@@ -0,0 +1,122 @@
+package stuber
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBidiInvalidated is returned by a BidiResult whose Budgerigar was cleared
+// since the stream started, as its stubs may no longer exist.
+var ErrBidiInvalidated = errors.New("bidi stream invalidated by clear")
+
+// BidiResult matches the messages of a bidirectional stream one by one, each
+// message being answered by the stub it matches.
+//
+// Clearing the Budgerigar invalidates the streams started before: their
+// next messages, including the ones being matched during the clear, fail
+// with ErrBidiInvalidated instead of being answered by stale stubs, without
+// marking stubs as used or moving scenarios.
+type BidiResult struct {
+	b     *Budgerigar
+	query Query
+
+	mu       sync.Mutex
+	messages int
+}
+
+// FindByQueryBidi starts matching the messages of a bidirectional stream of
+// the service and the method of the query, with its headers.
+//
+// Parameters:
+// - query: The Query of the stream, whose data is ignored.
+//
+// Returns:
+// - *BidiResult: The stream, matching its messages with Next.
+// - error: ErrServiceNotFound or ErrMethodNotFound if there is no Stub value
+// for the service or the method of the query.
+func (b *Budgerigar) FindByQueryBidi(query Query) (*BidiResult, error) {
+	query = b.canonicalQuery(query)
+
+	generation := b.searcher.currentGeneration()
+
+	if _, err := b.searcher.findBy(query.Service, query.Method); err != nil {
+		return nil, err
+	}
+
+	query.Data = nil
+	query.generation = &generation
+
+	return &BidiResult{b: b, query: query}, nil
+}
+
+// Next returns the Stub value answering the next message of the stream.
+//
+// Parameters:
+// - data: The data of the message.
+//
+// Returns:
+// - *Stub: The Stub value matching the message.
+// - error: ErrStubNotFound if no Stub value matches the message, or
+// ErrBidiInvalidated if the Budgerigar was cleared since the stream started.
+func (r *BidiResult) Next(data map[string]interface{}) (*Stub, error) {
+	query := r.query
+	query.Data = data
+	query.MessageCount = r.Messages() + 1
+
+	// The search fails with ErrBidiInvalidated before its side effects if
+	// the stubs were cleared since the stream started.
+	result, err := r.b.FindByQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// The message is answered with the returned Stub value, so its call is
+	// over for the concurrency limits.
+	result.Done()
+
+	if result.Found() == nil {
+		return nil, ErrStubNotFound
+	}
+
+	r.mu.Lock()
+	r.messages++
+	r.mu.Unlock()
+
+	return result.Found(), nil
+}
+
+// Messages returns the number of messages of the stream answered so far.
+//
+// Returns:
+// - int: The number of answered messages.
+func (r *BidiResult) Messages() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.messages
+}
+
+// currentGeneration returns the number of clears of the searcher.
+func (s *searcher) currentGeneration() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.generation
+}
+
+// stale checks if the query is of a bidi stream whose stubs were cleared
+// since it started.
+//
+// The mutex must be held.
+func (s *searcher) stale(query Query) bool {
+	return query.generation != nil && *query.generation != s.generation
+}
+
+// invalidated checks if the query is of a bidi stream whose stubs were
+// cleared since it started.
+func (s *searcher) invalidated(query Query) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.stale(query)
+}
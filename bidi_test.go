@@ -0,0 +1,176 @@
+package stuber_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func bidiStubs() []*stuber.Stub {
+	return []*stuber.Stub{
+		{
+			ID:      uuid.New(),
+			Service: "Chat",
+			Method:  "Talk",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"text": "hello"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"text": "hi"}},
+		},
+		{
+			ID:      uuid.New(),
+			Service: "Chat",
+			Method:  "Talk",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"text": "bye"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"text": "see you"}},
+		},
+	}
+}
+
+func TestBudgerigar_FindByQueryBidi(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(bidiStubs()...)
+
+	_, err := s.FindByQueryBidi(stuber.Query{Service: "Chat", Method: "Shout"})
+	require.ErrorIs(t, err, stuber.ErrMethodNotFound)
+
+	stream, err := s.FindByQueryBidi(stuber.Query{Service: "Chat", Method: "Talk"})
+	require.NoError(t, err)
+
+	stub, err := stream.Next(map[string]interface{}{"text": "hello"})
+	require.NoError(t, err)
+	require.Equal(t, "hi", stub.Output.Data["text"])
+
+	_, err = stream.Next(map[string]interface{}{"text": "what?"})
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+
+	stub, err = stream.Next(map[string]interface{}{"text": "bye"})
+	require.NoError(t, err)
+	require.Equal(t, "see you", stub.Output.Data["text"])
+	require.Equal(t, 2, stream.Messages())
+}
+
+func TestBudgerigar_FindByQueryBidi_Clear(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(bidiStubs()...)
+
+	stream, err := s.FindByQueryBidi(stuber.Query{Service: "Chat", Method: "Talk"})
+	require.NoError(t, err)
+
+	s.Clear()
+	s.PutMany(bidiStubs()...)
+
+	_, err = stream.Next(map[string]interface{}{"text": "hello"})
+	require.ErrorIs(t, err, stuber.ErrBidiInvalidated)
+
+	// Streams started after the clear are not affected.
+	stream, err = s.FindByQueryBidi(stuber.Query{Service: "Chat", Method: "Talk"})
+	require.NoError(t, err)
+
+	_, err = stream.Next(map[string]interface{}{"text": "hello"})
+	require.NoError(t, err)
+}
+
+func TestBudgerigar_FindByQueryBidi_ClearDuringStream(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(bidiStubs()...)
+
+	stream, err := s.FindByQueryBidi(stuber.Query{Service: "Chat", Method: "Talk"})
+	require.NoError(t, err)
+
+	var (
+		wg          sync.WaitGroup
+		invalidated bool
+		unexpected  error
+	)
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for range 1000 {
+			_, err := stream.Next(map[string]interface{}{"text": "hello"})
+			if errors.Is(err, stuber.ErrBidiInvalidated) {
+				invalidated = true
+
+				return
+			}
+
+			// Before the clear, the messages are answered.
+			if err != nil {
+				unexpected = err
+
+				return
+			}
+		}
+	}()
+
+	s.Clear()
+	wg.Wait()
+
+	require.NoError(t, unexpected)
+
+	// The stream is invalidated, whether or not it noticed before the end.
+	_, err = stream.Next(map[string]interface{}{"text": "hello"})
+	require.ErrorIs(t, err, stuber.ErrBidiInvalidated)
+
+	if invalidated {
+		require.Less(t, stream.Messages(), 1000)
+	}
+}
+
+func TestBudgerigar_FindByQueryBidi_ClearDuringSearch(t *testing.T) {
+	stub := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Chat",
+		Method:   "Talk",
+		Scenario: "chat",
+		NewState: "talking",
+		Input:    stuber.InputData{Equals: map[string]interface{}{"text": "hello"}},
+		Output:   stuber.Output{Data: map[string]interface{}{"text": "hi"}},
+	}
+
+	var (
+		s       *stuber.Budgerigar
+		cleared bool
+	)
+
+	// Clear the stubs while the message is being matched, putting the same
+	// stub back so its usage is observable.
+	s = stuber.New(stuber.WithRanker(func(query stuber.Query, candidate *stuber.Stub) float64 {
+		if !cleared {
+			cleared = true
+
+			s.Clear()
+			s.PutMany(stub)
+		}
+
+		return stuber.DefaultRank(query, candidate)
+	}))
+	s.PutMany(stub)
+
+	var matches int
+
+	s.Listen(stuber.EventListenerFunc(func(event stuber.StubEvent) {
+		if _, ok := event.(stuber.StubMatched); ok {
+			matches++
+		}
+	}))
+
+	stream, err := s.FindByQueryBidi(stuber.Query{Service: "Chat", Method: "Talk"})
+	require.NoError(t, err)
+
+	_, err = stream.Next(map[string]interface{}{"text": "hello"})
+	require.ErrorIs(t, err, stuber.ErrBidiInvalidated)
+	require.True(t, cleared)
+
+	require.Zero(t, s.HitsByID(stub.ID))
+	require.Empty(t, s.Used())
+	require.Equal(t, map[string]string{"chat": "Started"}, s.ScenarioStates())
+	require.Zero(t, matches)
+	require.Zero(t, stream.Messages())
+}
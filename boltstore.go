@@ -0,0 +1,272 @@
+package stuber
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrCorruptDatabase is returned when a stub of a BoltStore cannot be
+// decoded.
+var ErrCorruptDatabase = errors.New("corrupt stub database")
+
+// boltOpenTimeout is the time OpenBoltStore waits for the lock of a database
+// opened by another process.
+const boltOpenTimeout = time.Second
+
+// boltStubs is the bucket of the stubs of a BoltStore, keyed by ID.
+var boltStubs = []byte("stubs") //nolint:gochecknoglobals
+
+// BoltStore is an embedded Backend keeping the stubs in a bbolt database,
+// for large sets of stubs.
+//
+// Each Put, Delete or Clear is a transaction of the database, synced to the
+// disk before returning and applied entirely or not at all. Unlike the log
+// of a LogStore, replayed by each Load and growing until compacted, the
+// database only holds the live stubs, so Load reads each stub once.
+type BoltStore struct {
+	db   *bolt.DB
+	keys KeyProvider // The keys encrypting the stubs, if any.
+}
+
+// BoltStoreOption configures a BoltStore opened with OpenBoltStore.
+type BoltStoreOption func(*BoltStore)
+
+// WithBoltEncryption encrypts the stubs of the BoltStore with AES-GCM, using
+// the keys of the provider.
+//
+// The stubs written without encryption are still read, so a database is
+// encrypted progressively, as the stubs are written again.
+func WithBoltEncryption(keys KeyProvider) BoltStoreOption {
+	return func(s *BoltStore) {
+		s.keys = keys
+	}
+}
+
+// OpenBoltStore opens the database at the given path, creating it if
+// needed.
+//
+// A database is opened by a single process at a time: OpenBoltStore fails
+// if another one does not release it within a second.
+//
+// Parameters:
+// - path: The path of the database file.
+// - opts: The options to apply.
+//
+// Returns:
+// - *BoltStore: The opened store, to be closed with Close.
+// - error: An error if the database cannot be opened.
+func OpenBoltStore(path string, opts ...BoltStoreOption) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltStubs)
+
+		return err
+	})
+	if err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	s := &BoltStore{db: db}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// Load returns the stubs of the database.
+//
+// Returns:
+// - []*Stub: The stubs, in the order of their IDs.
+// - error: An error if the database cannot be read, one matching
+// ErrCorruptDatabase if a stub cannot be decoded, or one matching
+// ErrDecryption if it cannot be decrypted.
+func (s *BoltStore) Load() ([]*Stub, error) {
+	var stubs []*Stub
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// The keys and values are valid until the end of the transaction.
+		var keys, values [][]byte
+
+		err := tx.Bucket(boltStubs).ForEach(func(key, value []byte) error {
+			keys = append(keys, key)
+			values = append(values, value)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		stubs, err = s.decodeAll(keys, values)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stubs, nil
+}
+
+// decodeAll decodes the given stubs on all the CPUs, decoding taking most of
+// the time of a Load.
+func (s *BoltStore) decodeAll(keys, values [][]byte) ([]*Stub, error) {
+	var (
+		stubs = make([]*Stub, len(values))
+		errs  = make([]error, len(values))
+		next  atomic.Int64
+		wg    sync.WaitGroup
+	)
+
+	for range min(runtime.GOMAXPROCS(0), len(values)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := int(next.Add(1) - 1); i < len(values); i = int(next.Add(1) - 1) {
+				stubs[i], errs[i] = s.decode(values[i])
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("stub %x: %w", keys[i], err)
+		}
+	}
+
+	return stubs, nil
+}
+
+// Put inserts or replaces the given stubs in a single transaction.
+//
+// Parameters:
+// - stubs: The stubs to persist.
+//
+// Returns:
+// - error: An error if the transaction fails.
+func (s *BoltStore) Put(stubs []*Stub) error {
+	if len(stubs) == 0 {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltStubs)
+
+		for _, stub := range stubs {
+			value, err := s.encode(stub)
+			if err != nil {
+				return err
+			}
+
+			if err := bucket.Put(stub.ID[:], value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete deletes the stubs with the given IDs in a single transaction.
+//
+// Parameters:
+// - ids: The IDs of the stubs to delete.
+//
+// Returns:
+// - error: An error if the transaction fails.
+func (s *BoltStore) Delete(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltStubs)
+
+		for _, id := range ids {
+			if err := bucket.Delete(id[:]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Clear deletes all the stubs in a single transaction.
+//
+// Returns:
+// - error: An error if the transaction fails.
+func (s *BoltStore) Clear() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(boltStubs); err != nil {
+			return err
+		}
+
+		_, err := tx.CreateBucket(boltStubs)
+
+		return err
+	})
+}
+
+// encode encodes the stub, stamped with the current version so loading it
+// doesn't upgrade it, and encrypted if the store has keys.
+func (s *BoltStore) encode(stub *Stub) ([]byte, error) {
+	stamped := *stub
+	stamped.SchemaVersion = SchemaVersion
+
+	value, err := json.Marshal(&stamped)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.keys != nil {
+		return seal(s.keys, value)
+	}
+
+	return value, nil
+}
+
+// decode decodes the stub, decrypting it if needed.
+func (s *BoltStore) decode(value []byte) (*Stub, error) {
+	if sealed(value) {
+		var err error
+		if value, err = unseal(s.keys, value); err != nil {
+			return nil, err
+		}
+	}
+
+	var stub Stub
+	if err := json.Unmarshal(value, &stub); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptDatabase, err)
+	}
+
+	return &stub, nil
+}
+
+// Close closes the database.
+//
+// Returns:
+// - error: An error if the database cannot be closed.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,123 @@
+package stuber_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBoltStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stubs.db")
+
+	store, err := stuber.OpenBoltStore(path)
+	require.NoError(t, err)
+
+	s := stuber.New()
+
+	ids, err := s.AttachBackend(store)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	hello := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	bye := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayGoodbye"}
+	s.PutMany(hello, bye)
+	s.DeleteByID(bye.ID)
+	require.NoError(t, s.PatchByID(hello.ID, func(stub *stuber.Stub) error {
+		stub.Priority = 5
+
+		return nil
+	}))
+	require.NoError(t, store.Close())
+
+	store, err = stuber.OpenBoltStore(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { store.Close() })
+
+	restored := stuber.New()
+
+	ids, err = restored.AttachBackend(store)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{hello.ID}, ids)
+	require.Equal(t, 5, restored.FindByID(hello.ID).Priority)
+
+	restored.Clear()
+
+	stubs, err := store.Load()
+	require.NoError(t, err)
+	require.Empty(t, stubs)
+
+	// The database is locked by the opened store.
+	_, err = stuber.OpenBoltStore(path)
+	require.Error(t, err)
+}
+
+func TestBoltStore_Encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stubs.db")
+
+	old := secretStub()
+
+	// A plain database is encrypted progressively.
+	store, err := stuber.OpenBoltStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Put([]*stuber.Stub{old}))
+	require.NoError(t, store.Close())
+
+	keys := stuber.StaticKeys{Current: "v1", Keys: map[string][]byte{"v1": bytes.Repeat([]byte{1}, 16)}}
+
+	store, err = stuber.OpenBoltStore(path, stuber.WithBoltEncryption(keys))
+	require.NoError(t, err)
+
+	stub := secretStub()
+	require.NoError(t, store.Put([]*stuber.Stub{stub}))
+
+	stubs, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, stubs, 2)
+	require.NoError(t, store.Close())
+
+	plain, err := stuber.OpenBoltStore(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { plain.Close() })
+
+	_, err = plain.Load()
+	require.ErrorIs(t, err, stuber.ErrDecryption)
+}
+
+func BenchmarkBoltStore_Attach(b *testing.B) {
+	store, err := stuber.OpenBoltStore(filepath.Join(b.TempDir(), "stubs.db"))
+	require.NoError(b, err)
+
+	b.Cleanup(func() { store.Close() })
+
+	stubs := make([]*stuber.Stub, 100_000)
+	for i := range stubs {
+		stubs[i] = &stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"id": i}},
+		}
+	}
+
+	require.NoError(b, store.Put(stubs))
+
+	b.ResetTimer()
+
+	for range b.N {
+		ids, err := stuber.New().AttachBackend(store)
+		require.NoError(b, err)
+		require.Len(b, ids, len(stubs))
+	}
+}
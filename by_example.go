@@ -0,0 +1,64 @@
+package stuber
+
+import (
+	"cmp"
+	"slices"
+)
+
+// matching returns the Stub values of the service and the method of the
+// query that match it, merged with their base stubs, ignoring their
+// priority, their usage, their ordered groups and their dependencies.
+//
+// The Stub values are sorted the way they compete: by decreasing priority,
+// then by decreasing rank.
+func (s *searcher) matching(query Query) ([]*Stub, error) {
+	values, err := s.storage.findAll(query.Service, query.Method)
+	if err != nil {
+		return nil, s.wrap(err)
+	}
+
+	query = normalizeQuery(query)
+
+	var matched []candidate
+
+	for _, stub := range s.castToStub(values) {
+		if current := s.evaluate(query, stub); current.valid && current.matched {
+			matched = append(matched, current)
+		}
+	}
+
+	slices.SortStableFunc(matched, func(a, b candidate) int {
+		if a.stub.Priority != b.stub.Priority {
+			return cmp.Compare(b.stub.Priority, a.stub.Priority)
+		}
+
+		return cmp.Compare(b.rank, a.rank)
+	})
+
+	stubs := make([]*Stub, len(matched))
+	for i, current := range matched {
+		stubs[i] = current.stub
+	}
+
+	return stubs, nil
+}
+
+// FindStubsByExample returns all the Stub values that match the example
+// query, answering "which stubs cover this request?" rather than "which one
+// would win?" for administrative tools.
+//
+// Unlike FindByQuery, the priority and the usage of the Stub values are
+// ignored, as are their ordered groups and dependencies, and the Stub values
+// are not marked as used.
+//
+// Parameters:
+// - query: The example Query.
+//
+// Returns:
+// - []*Stub: The matching Stub values merged with their base stubs, by
+// decreasing priority and then by decreasing rank.
+// - error: ErrServiceNotFound or ErrMethodNotFound if there is no Stub value
+// for the service or the method of the query.
+func (b *Budgerigar) FindStubsByExample(query Query) ([]*Stub, error) {
+	return b.searcher.matching(b.canonicalQuery(query))
+}
@@ -0,0 +1,69 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_FindStubsByExample(t *testing.T) {
+	s := stuber.New()
+
+	exact := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	partial := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Greeter",
+		Method:   "SayHello",
+		Priority: 1,
+		Input:    stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+	}
+	regex := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Matches: map[string]interface{}{"name": "^B"}},
+	}
+	other := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+	}
+	dependent := &stuber.Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		DependsOn: []uuid.UUID{other.ID},
+		Input:     stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+
+	s.PutMany(exact, partial, regex, other, dependent)
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	}
+
+	stubs, err := s.FindStubsByExample(query)
+	require.NoError(t, err)
+	require.Len(t, stubs, 4)
+	require.Equal(t, partial.ID, stubs[0].ID)
+	require.ElementsMatch(t,
+		[]uuid.UUID{exact.ID, regex.ID, dependent.ID},
+		[]uuid.UUID{stubs[1].ID, stubs[2].ID, stubs[3].ID},
+	)
+
+	require.Empty(t, s.Used())
+
+	_, err = s.FindStubsByExample(stuber.Query{Service: "Unknown", Method: "SayHello"})
+	require.ErrorIs(t, err, stuber.ErrServiceNotFound)
+}
@@ -0,0 +1,217 @@
+package stuber
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// StubChange is a change of a stub in a ChangeSet.
+type StubChange struct {
+	Revision uint64    `json:"revision"`       // The revision of the change.
+	Type     EventType `json:"type"`           // EventPut, EventUpdate, EventDelete or EventExpire.
+	StubID   uuid.UUID `json:"stubId"`         // The changed stub.
+	Stub     *Stub     `json:"stub,omitempty"` // The stub after the change, nil once deleted.
+}
+
+// ChangeSet is the last change of each stub changed since a revision, for
+// clients keeping a copy of the stubs, such as web UIs.
+type ChangeSet struct {
+	// Revision is the current revision, to pass to the next call.
+	Revision uint64 `json:"revision"`
+	// Reset is set when the changes since the revision are not all kept, or
+	// the stubs were cleared since: the copy of the client is to be replaced
+	// by the Changes, which put all the current stubs.
+	Reset bool `json:"reset,omitempty"`
+	// Changes are the last changes of the changed stubs, in revision order.
+	Changes []StubChange `json:"changes"`
+}
+
+// changeLog keeps the last changes of the stubs, numbered by a global
+// revision, as an EventListener.
+type changeLog struct {
+	mu       sync.Mutex
+	size     int
+	changes  []StubChange // The kept changes, oldest first.
+	revision uint64       // The revision of the last change.
+	cleared  uint64       // The revision of the last clear.
+	changed  chan struct{}
+}
+
+// newChangeLog creates a new changeLog keeping no changes.
+func newChangeLog() *changeLog {
+	return &changeLog{changed: make(chan struct{})}
+}
+
+// resize sets the number of kept changes and forgets the kept ones.
+func (l *changeLog) resize(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.size = max(size, 0)
+	l.changes = nil
+}
+
+// enabled checks if the log keeps changes.
+func (l *changeLog) enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.size > 0
+}
+
+// OnEvent records the changes of the stubs.
+func (l *changeLog) OnEvent(event StubEvent) {
+	var (
+		typ  EventType
+		stub *Stub
+	)
+
+	switch event := event.(type) {
+	case StubAdded:
+		typ, stub = EventPut, event.Stub
+	case StubUpdated:
+		typ, stub = EventUpdate, event.Stub
+	case StubDeleted:
+		typ, stub = EventDelete, event.Stub
+	case StubExpired:
+		typ, stub = EventExpire, event.Stub
+	case StubsCleared:
+		typ = EventClear
+	default:
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size <= 0 {
+		return
+	}
+
+	l.revision++
+
+	if typ == EventClear {
+		l.cleared = l.revision
+		l.changes = nil
+	} else {
+		change := StubChange{Revision: l.revision, Type: typ, StubID: stub.ID}
+		if typ == EventPut || typ == EventUpdate {
+			change.Stub = stub
+		}
+
+		l.changes = append(l.changes, change)
+		if len(l.changes) > l.size {
+			l.changes = slices.Delete(l.changes, 0, len(l.changes)-l.size)
+		}
+	}
+
+	// Wake the waiting clients up.
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// since returns the changes after the given revision, or nil and false if
+// they are not all kept, along with the current revision and a channel
+// closed on the next change.
+func (l *changeLog) since(revision uint64) ([]StubChange, bool, uint64, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The revision is unknown, such as of another instance, or a clear or
+	// dropped changes happened since.
+	oldest := l.cleared + 1
+	if len(l.changes) > 0 {
+		oldest = l.changes[0].Revision
+	}
+
+	if revision > l.revision || revision < l.cleared || revision+1 < oldest {
+		return nil, false, l.revision, l.changed
+	}
+
+	// Keep the last change of each stub.
+	last := make(map[uuid.UUID]int)
+
+	var changes []StubChange
+
+	for _, change := range l.changes {
+		if change.Revision <= revision {
+			continue
+		}
+
+		if i, ok := last[change.StubID]; ok {
+			changes[i].Revision = 0 // Superseded.
+		}
+
+		last[change.StubID] = len(changes)
+		changes = append(changes, change)
+	}
+
+	changes = slices.DeleteFunc(changes, func(change StubChange) bool { return change.Revision == 0 })
+
+	return changes, true, l.revision, l.changed
+}
+
+// Changes returns the last change of each Stub value changed since the given
+// revision, and the current revision, so clients such as web UIs stay in
+// sync without fetching all the Stub values again.
+//
+// Changes are only kept when enabled with WithChangeLog, and only the last
+// ones are kept: when the changes since the revision are not all kept, or
+// the Stub values were cleared since, the ChangeSet resets the copy of the
+// client with all the current Stub values.
+//
+// Parameters:
+// - since: The revision of the copy of the client, 0 for the first call.
+//
+// Returns:
+// - ChangeSet: The changes since the revision.
+func (b *Budgerigar) Changes(since uint64) ChangeSet {
+	set, _ := b.changeSet(since)
+
+	return set
+}
+
+// WaitChanges is Changes waiting until there are changes since the given
+// revision, for long polling and server-sent events.
+//
+// Parameters:
+// - ctx: The context of the wait.
+// - since: The revision of the copy of the client, 0 for the first call.
+//
+// Returns:
+// - ChangeSet: The changes since the revision.
+// - error: The error of the context if it is done before any change.
+func (b *Budgerigar) WaitChanges(ctx context.Context, since uint64) (ChangeSet, error) {
+	for {
+		set, changed := b.changeSet(since)
+		if set.Reset || len(set.Changes) > 0 {
+			return set, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return set, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// changeSet returns the changes since the given revision, and a channel
+// closed on the next change.
+func (b *Budgerigar) changeSet(since uint64) (ChangeSet, <-chan struct{}) {
+	changes, ok, revision, changed := b.changes.since(since)
+	if ok {
+		return ChangeSet{Revision: revision, Changes: changes}, changed
+	}
+
+	set := ChangeSet{Revision: revision, Reset: true}
+
+	for _, stub := range SortStubs(b.searcher.all()) {
+		set.Changes = append(set.Changes, StubChange{Revision: revision, Type: EventPut, StubID: stub.ID, Stub: stub})
+	}
+
+	return set, changed
+}
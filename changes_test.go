@@ -0,0 +1,81 @@
+package stuber_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Changes(t *testing.T) {
+	s := stuber.New(stuber.WithChangeLog(4))
+
+	hello := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"}
+	bye := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayGoodbye"}
+	s.PutMany(hello, bye)
+
+	set := s.Changes(0)
+	require.False(t, set.Reset)
+	require.Equal(t, uint64(2), set.Revision)
+	require.Len(t, set.Changes, 2)
+	require.Equal(t, stuber.EventPut, set.Changes[0].Type)
+	require.Equal(t, hello.ID, set.Changes[0].Stub.ID)
+
+	require.NoError(t, s.PatchByID(hello.ID, func(stub *stuber.Stub) error {
+		stub.Priority = 2
+
+		return nil
+	}))
+	s.DeleteByID(hello.ID)
+
+	// Only the last change of each stub is returned.
+	set = s.Changes(set.Revision)
+	require.Equal(t, uint64(4), set.Revision)
+	require.Equal(t, []stuber.StubChange{{Revision: 4, Type: stuber.EventDelete, StubID: hello.ID}}, set.Changes)
+
+	require.Empty(t, s.Changes(set.Revision).Changes)
+
+	// The changes since the revision are no longer all kept.
+	for range 4 {
+		s.PutMany(bye)
+	}
+
+	set = s.Changes(1)
+	require.True(t, set.Reset)
+	require.Equal(t, uint64(8), set.Revision)
+	require.Len(t, set.Changes, 1)
+	require.Equal(t, bye.ID, set.Changes[0].StubID)
+
+	s.Clear()
+
+	set = s.Changes(set.Revision)
+	require.True(t, set.Reset)
+	require.Empty(t, set.Changes)
+}
+
+func TestBudgerigar_WaitChanges(t *testing.T) {
+	s := stuber.New(stuber.WithChangeLog(16))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	set, err := s.WaitChanges(ctx, 0)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Empty(t, set.Changes)
+
+	stub := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.PutMany(stub)
+	}()
+
+	set, err = s.WaitChanges(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), set.Revision)
+	require.Equal(t, stub.ID, set.Changes[0].StubID)
+}
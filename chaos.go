@@ -0,0 +1,71 @@
+package stuber
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// defaultChaosMessage is the error message used when a ChaosProfile does not
+// define one.
+const defaultChaosMessage = "chaos: injected error"
+
+// ChaosProfile describes a global overlay applied to every match.
+//
+// It allows resiliency scenarios (slow responses, flaky backends) to be
+// toggled at runtime without editing the stubs themselves.
+type ChaosProfile struct {
+	// Services limits the profile to the given services.
+	// An empty list applies the profile to all services.
+	Services []string
+	// Delay is added to the delay of every matched output.
+	Delay time.Duration
+	// ErrorRate is the fraction (from 0 to 1) of matches replaced by an error.
+	ErrorRate float64
+	// Code is the status code of injected errors.
+	// codes.Unavailable is used when it is not set.
+	Code codes.Code
+	// Message is the error message of injected errors.
+	Message string
+}
+
+// appliesTo reports whether the profile targets the given service.
+func (p *ChaosProfile) appliesTo(service string) bool {
+	return len(p.Services) == 0 || slices.Contains(p.Services, service)
+}
+
+// apply returns the stub with the profile applied to its output.
+//
+// The stored stub is never modified; a copy is returned instead when the
+// output has to be changed.
+func (p *ChaosProfile) apply(stub *Stub) *Stub {
+	if !p.appliesTo(stub.Service) {
+		return stub
+	}
+
+	output := stub.Output
+	output.Delay += Duration(p.Delay)
+
+	if p.ErrorRate > 0 && rand.Float64() < p.ErrorRate { //nolint:gosec
+		code := p.Code
+		if code == codes.OK {
+			code = codes.Unavailable
+		}
+
+		message := p.Message
+		if message == "" {
+			message = defaultChaosMessage
+		}
+
+		output = Output{
+			Headers: stub.Output.Headers,
+			Error:   message,
+			Code:    &code,
+			Delay:   output.Delay,
+		}
+	}
+
+	return stub.withOutput(output)
+}
@@ -0,0 +1,90 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/gripmock/stuber"
+)
+
+func chaosBudgerigar() *stuber.Budgerigar {
+	s := stuber.NewBudgerigar(features.New())
+
+	s.PutMany(
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter1",
+			Method:  "SayHello1",
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "hello"}},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter2",
+			Method:  "SayHello1",
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "hello"}},
+		},
+	)
+
+	return s
+}
+
+func TestBudgerigar_ChaosDelay(t *testing.T) {
+	s := chaosBudgerigar()
+
+	s.SetChaos(&stuber.ChaosProfile{Services: []string{"Greeter1"}, Delay: time.Second})
+	require.NotNil(t, s.Chaos())
+
+	r, err := s.FindByQuery(stuber.Query{Service: "Greeter1", Method: "SayHello1"})
+	require.NoError(t, err)
+	require.Equal(t, time.Second, r.Found().Output.Delay.Std())
+
+	r, err = s.FindByQuery(stuber.Query{Service: "Greeter2", Method: "SayHello1"})
+	require.NoError(t, err)
+	require.Zero(t, r.Found().Output.Delay)
+
+	// The stored stub must not be modified.
+	all, err := s.FindBy("Greeter1", "SayHello1")
+	require.NoError(t, err)
+	require.Zero(t, all[0].Output.Delay)
+}
+
+func TestBudgerigar_ChaosError(t *testing.T) {
+	s := chaosBudgerigar()
+
+	s.SetChaos(&stuber.ChaosProfile{ErrorRate: 1})
+
+	r, err := s.FindByQuery(stuber.Query{Service: "Greeter1", Method: "SayHello1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, r.Found().Output.Error)
+	require.Equal(t, codes.Unavailable, *r.Found().Output.Code)
+	require.Nil(t, r.Found().Output.Data)
+
+	s.SetChaos(nil)
+	require.Nil(t, s.Chaos())
+
+	r, err = s.FindByQuery(stuber.Query{Service: "Greeter1", Method: "SayHello1"})
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+}
+
+func TestDuration_JSON(t *testing.T) {
+	var output stuber.Output
+
+	require.NoError(t, json.Unmarshal([]byte(`{"delay":"150ms"}`), &output))
+	require.Equal(t, 150*time.Millisecond, output.Delay.Std())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"delay":20}`), &output))
+	require.Equal(t, 20*time.Millisecond, output.Delay.Std())
+
+	require.Error(t, json.Unmarshal([]byte(`{"delay":"soon"}`), &output))
+
+	data, err := json.Marshal(stuber.Output{Delay: stuber.Duration(time.Second)})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"delay":"1s"`)
+}
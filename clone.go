@@ -0,0 +1,77 @@
+package stuber
+
+import "reflect"
+
+// Clone returns a deep copy of the stub, sharing none of its maps, slices
+// and pointers, so the copy can be modified while the stub is searched.
+//
+// Returns:
+// - *Stub: A deep copy of the stub.
+func (s *Stub) Clone() *Stub {
+	clone, _ := deepCopy(reflect.ValueOf(s)).Interface().(*Stub)
+
+	return clone
+}
+
+// deepCopy returns a copy of the given value, copying the values referenced
+// by its pointers, interfaces, maps, slices and exported struct fields.
+//
+// The unexported fields of the structs, such as the ones of time.Time, are
+// copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		clone := reflect.New(v.Type().Elem())
+		clone.Elem().Set(deepCopy(v.Elem()))
+
+		return clone
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(deepCopy(v.Elem()))
+
+		return clone
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			clone.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+
+		return clone
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			clone.Index(i).Set(deepCopy(v.Index(i)))
+		}
+
+		return clone
+	case reflect.Struct:
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(v)
+
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				clone.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+
+		return clone
+	default:
+		return v
+	}
+}
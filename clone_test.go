@@ -0,0 +1,53 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestStub_Clone(t *testing.T) {
+	times := 2
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stub := &stuber.Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		Input:     stuber.InputData{Equals: map[string]interface{}{"user": map[string]interface{}{"name": "Bob"}, "ids": []interface{}{1, 2}}},
+		Output:    stuber.Output{Data: map[string]interface{}{"message": "hi"}, Alternatives: []stuber.WeightedOutput{{Weight: 1}}},
+		DependsOn: []uuid.UUID{uuid.New()},
+		Meta:      map[string]string{"team": "core"},
+		Size:      stuber.Comparison{">": 1},
+		Expectations: &stuber.Expectations{
+			Times: &times,
+		},
+		CreatedAt: &created,
+	}
+
+	clone := stub.Clone()
+	require.Equal(t, stub, clone)
+
+	clone.Input.Equals["user"].(map[string]interface{})["name"] = "Alice"
+	clone.Input.Equals["ids"].([]interface{})[0] = 3
+	clone.Output.Data["message"] = "bye"
+	clone.Output.Alternatives[0].Weight = 2
+	clone.DependsOn[0] = uuid.Nil
+	clone.Meta["team"] = "edge"
+	clone.Size[">"] = 2
+	*clone.Expectations.Times = 3
+	*clone.CreatedAt = clone.CreatedAt.Add(time.Hour)
+
+	require.Equal(t, "Bob", stub.Input.Equals["user"].(map[string]interface{})["name"])
+	require.Equal(t, 1, stub.Input.Equals["ids"].([]interface{})[0])
+	require.Equal(t, "hi", stub.Output.Data["message"])
+	require.Equal(t, 1, stub.Output.Alternatives[0].Weight)
+	require.NotEqual(t, uuid.Nil, stub.DependsOn[0])
+	require.Equal(t, "core", stub.Meta["team"])
+	require.Equal(t, 1, stub.Size[">"])
+	require.Equal(t, 2, *stub.Expectations.Times)
+	require.Equal(t, created, *stub.CreatedAt)
+}
@@ -0,0 +1,332 @@
+package stuber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
+)
+
+// ErrSyncFailed is returned when a follower fails to synchronize with its leader.
+var ErrSyncFailed = errors.New("sync failed")
+
+// Snapshot is the replicated state of a Budgerigar.
+type Snapshot struct {
+	Stubs []*Stub                `json:"stubs"`          // The stubs of the leader.
+	Used  []uuid.UUID            `json:"used"`           // The IDs of the stubs used on any instance.
+	Hits  map[uuid.UUID]StubHits `json:"hits,omitempty"` // The usage of the used stubs aggregated over the instances, by ID.
+}
+
+// usageReport is the body sent by followers to report their usage.
+type usageReport struct {
+	// Used are the IDs of the used stubs, reported by the followers of
+	// earlier versions, each counting a single hit.
+	Used []uuid.UUID `json:"used,omitempty"`
+	// Hits are the hits of the stubs since the previous report, by ID.
+	Hits map[uuid.UUID]StubHits `json:"hits,omitempty"`
+}
+
+// NewSyncHandler returns the HTTP handler of a leader instance.
+//
+// GET requests return the Snapshot of the leader. POST requests report the
+// hits of a follower, which are added to the usage of the leader.
+//
+// Parameters:
+// - b: The Budgerigar of the leader.
+//
+// Returns:
+// - http.Handler: The handler to mount on the leader.
+func NewSyncHandler(b *Budgerigar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+
+			_ = json.NewEncoder(w).Encode(b.snapshot())
+		case http.MethodPost:
+			var report usageReport
+			if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+
+			b.searcher.markUsed(report.Used...)
+			b.searcher.addHits(report.Hits)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// Follower replicates the stubs and the usage of a leader instance.
+type Follower struct {
+	budgerigar *Budgerigar
+	leader     string
+	client     *http.Client
+
+	mu       sync.Mutex           // Serializes the synchronizations.
+	reported map[uuid.UUID]uint64 // The local hits counted by the leader, by ID.
+}
+
+// NewFollower creates a new Follower of the leader served at the given URL.
+//
+// Parameters:
+// - b: The Budgerigar of the follower.
+// - leader: The URL of the NewSyncHandler of the leader.
+// - client: The HTTP client to use, or nil for http.DefaultClient.
+//
+// Returns:
+// - *Follower: A new Follower.
+func NewFollower(b *Budgerigar, leader string, client *http.Client) *Follower {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Follower{budgerigar: b, leader: leader, client: client, reported: make(map[uuid.UUID]uint64)}
+}
+
+// Sync reports the local hits since the previous synchronization to the
+// leader, then replaces the local stubs and usage with the ones of the
+// leader, which aggregate the hits of all the instances. The hits counted
+// locally during the synchronization are kept. Only the stubs that differ are
+// put or deleted, so an unchanged leader leaves the local stubs untouched.
+//
+// Parameters:
+// - ctx: The context of the requests.
+//
+// Returns:
+// - error: An error if the leader cannot be reached.
+func (f *Follower) Sync(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current := f.budgerigar.searcher.exportHits()
+	report := usageReport{Hits: make(map[uuid.UUID]StubHits, len(current))}
+
+	for id, entry := range current {
+		if entry.Hits > f.reported[id] {
+			report.Hits[id] = StubHits{Hits: entry.Hits - f.reported[id], LastHit: entry.LastHit}
+		}
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.do(ctx, http.MethodPost, body); err != nil {
+		return err
+	}
+
+	// The reported hits are counted by the leader from now on.
+	for id, entry := range current {
+		f.reported[id] = entry.Hits
+	}
+
+	data, err := f.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+
+	var snapshot Snapshot
+	if err := decodeJSON(data, &snapshot); err != nil {
+		return errors.Join(ErrSyncFailed, err)
+	}
+
+	f.budgerigar.restore(snapshot)
+
+	// Leaders of earlier versions only send the IDs of the used stubs.
+	if snapshot.Hits == nil {
+		f.budgerigar.searcher.markUsed(snapshot.Used...)
+
+		return nil
+	}
+
+	f.reported = f.budgerigar.searcher.syncHits(snapshot.Hits, f.reported)
+
+	return nil
+}
+
+// Run synchronizes with the leader at the given interval until the context
+// is canceled. Synchronization errors are passed to onError, which may be nil.
+//
+// Parameters:
+// - ctx: The context stopping the synchronization.
+// - interval: The duration between two synchronizations.
+// - onError: The callback receiving synchronization errors, or nil.
+func (f *Follower) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Sync(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// do sends a request to the leader and returns the response body.
+func (f *Follower) do(ctx context.Context, method string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, f.leader, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Join(ErrSyncFailed, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, errors.Join(ErrSyncFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: leader responded with status %d", ErrSyncFailed, resp.StatusCode)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return nil, errors.Join(ErrSyncFailed, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// snapshot returns the replicated state of the Budgerigar.
+func (b *Budgerigar) snapshot() Snapshot {
+	return Snapshot{
+		Stubs: b.All(),
+		Used:  b.searcher.usedIDs(),
+		Hits:  b.searcher.exportHits(),
+	}
+}
+
+// restore applies the differences between the stubs of the Budgerigar and
+// the stubs of the snapshot.
+//
+// Only the new and changed stubs are put, before the missing ones are
+// deleted, so the searches never see an empty store, and an unchanged
+// snapshot records no revision nor event.
+func (b *Budgerigar) restore(snapshot Snapshot) {
+	for _, stub := range snapshot.Stubs {
+		stub.canonicalize()
+	}
+
+	b.searcher.inheritTargets(snapshot.Stubs)
+
+	if b.toggles.Has(LowerHeaders) {
+		lowerStubHeaders(snapshot.Stubs)
+	}
+
+	current := make(map[uuid.UUID]*Stub)
+	for _, stub := range b.searcher.all() {
+		current[stub.ID] = stub
+	}
+
+	changed := make([]*Stub, 0, len(snapshot.Stubs))
+
+	for _, stub := range snapshot.Stubs {
+		if stored, ok := current[stub.ID]; !ok || !sameStub(stored, stub) {
+			changed = append(changed, stub)
+		}
+
+		delete(current, stub.ID)
+	}
+
+	deleted := maps.Keys(current)
+
+	if len(changed) > 0 || len(deleted) > 0 {
+		b.searcher.apply(changed, deleted)
+
+		b.templateCache.invalidate(stubIDs(changed)...)
+		b.templateCache.forget(deleted...)
+	}
+}
+
+// addHits adds the given hits to the usage of the Stub values, such as the
+// hits reported by a follower, keeping the latest time of their last hit.
+func (s *searcher) addHits(hits map[uuid.UUID]StubHits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, entry := range hits {
+		if entry.Hits == 0 {
+			continue
+		}
+
+		usage := s.stubUsed[id]
+		usage.hits += entry.Hits
+
+		if entry.LastHit != nil && entry.LastHit.After(usage.lastHit) {
+			usage.lastHit = *entry.LastHit
+		}
+
+		s.stubUsed[id] = usage
+	}
+}
+
+// syncHits replaces the usage of the Stub values with the given hits of the
+// leader, keeping the local hits counted since the given reported ones.
+//
+// It returns the hits of the leader, by ID, which the next report is
+// counted from.
+func (s *searcher) syncHits(leader map[uuid.UUID]StubHits, reported map[uuid.UUID]uint64) map[uuid.UUID]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usages := make(map[uuid.UUID]stubUsage, max(len(leader), len(s.stubUsed)))
+	counted := make(map[uuid.UUID]uint64, len(leader))
+
+	// Keep the hits counted locally since the report.
+	for id, usage := range s.stubUsed {
+		if usage.hits > reported[id] {
+			usages[id] = stubUsage{hits: usage.hits - reported[id], lastHit: usage.lastHit}
+		}
+	}
+
+	for id, entry := range leader {
+		if entry.Hits == 0 {
+			continue
+		}
+
+		usage := usages[id]
+		usage.hits += entry.Hits
+
+		if entry.LastHit != nil && entry.LastHit.After(usage.lastHit) {
+			usage.lastHit = *entry.LastHit
+		}
+
+		usages[id] = usage
+		counted[id] = entry.Hits
+	}
+
+	s.stubUsed = usages
+
+	return counted
+}
+
+// sameStub checks if the given stubs are equal, regardless of their
+// timestamps, which each instance sets on insertion.
+func sameStub(a, b *Stub) bool {
+	x, y := *a, *b
+	x.CreatedAt, x.UpdatedAt = nil, nil
+	y.CreatedAt, y.UpdatedAt = nil, nil
+
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,167 @@
+package stuber_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestFollower_Sync(t *testing.T) {
+	leader := stuber.NewBudgerigar(features.New())
+
+	hello := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	bye := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayBye",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+
+	leader.PutMany(hello, bye)
+
+	server := httptest.NewServer(stuber.NewSyncHandler(leader))
+	defer server.Close()
+
+	local := stuber.NewBudgerigar(features.New())
+	local.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Local", Method: "Only"})
+
+	follower := stuber.NewFollower(local, server.URL, server.Client())
+
+	require.NoError(t, follower.Sync(context.Background()))
+	require.Len(t, local.All(), 2)
+	require.NotNil(t, local.FindByID(hello.ID))
+	require.Empty(t, local.Used())
+
+	_, err := local.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayBye",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+
+	_, err = leader.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, follower.Sync(context.Background()))
+	require.Len(t, leader.Used(), 2)
+	require.Len(t, local.Used(), 2)
+	require.Len(t, local.All(), 2)
+
+	stubs, err := local.FindBy("Greeter", "SayHello")
+	require.NoError(t, err)
+	require.Len(t, stubs, 1)
+}
+
+func TestFollower_Sync_Diff(t *testing.T) {
+	leader := stuber.New()
+
+	hello := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	bye := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayBye",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+
+	leader.PutMany(hello, bye)
+
+	server := httptest.NewServer(stuber.NewSyncHandler(leader))
+	defer server.Close()
+
+	local := stuber.New(stuber.WithRevisions(10))
+	follower := stuber.NewFollower(local, server.URL, server.Client())
+
+	require.NoError(t, follower.Sync(context.Background()))
+	require.Len(t, local.Revisions(hello.ID), 1)
+
+	sub := local.Subscribe(stuber.SubscribeOptions{})
+	defer sub.Close()
+
+	// An unchanged snapshot changes nothing.
+	require.NoError(t, follower.Sync(context.Background()))
+	require.Len(t, local.Revisions(hello.ID), 1)
+	require.Len(t, local.Revisions(bye.ID), 1)
+	require.Empty(t, sub.Events())
+
+	// Only the changed and missing stubs are applied.
+	leader.DeleteByID(bye.ID)
+	leader.PutMany(&stuber.Stub{
+		ID:      hello.ID,
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+	})
+
+	require.NoError(t, follower.Sync(context.Background()))
+	require.Len(t, local.Revisions(hello.ID), 2)
+	require.Nil(t, local.FindByID(bye.ID))
+	require.Len(t, local.All(), 1)
+
+	events := []stuber.EventType{(<-sub.Events()).Type, (<-sub.Events()).Type}
+	require.Equal(t, []stuber.EventType{stuber.EventPut, stuber.EventDelete}, events)
+	require.Empty(t, sub.Events())
+}
+
+func TestFollower_Sync_Hits(t *testing.T) {
+	leader := stuber.New()
+
+	charge := &stuber.Stub{ID: uuid.New(), Service: "Payments", Method: "Charge", MaxCalls: 2}
+	leader.PutMany(charge)
+
+	server := httptest.NewServer(stuber.NewSyncHandler(leader))
+	defer server.Close()
+
+	query := stuber.Query{Service: "Payments", Method: "Charge"}
+
+	first := stuber.New()
+	second := stuber.New()
+
+	firstFollower := stuber.NewFollower(first, server.URL, server.Client())
+	secondFollower := stuber.NewFollower(second, server.URL, server.Client())
+
+	require.NoError(t, firstFollower.Sync(context.Background()))
+	require.NoError(t, secondFollower.Sync(context.Background()))
+
+	r, err := first.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+
+	_, err = leader.FindByQuery(query)
+	require.NoError(t, err)
+
+	// The hits of the instances are added up on the leader.
+	require.NoError(t, firstFollower.Sync(context.Background()))
+	require.Equal(t, uint64(2), leader.HitsByID(charge.ID))
+	require.Equal(t, uint64(2), first.HitsByID(charge.ID))
+
+	// Syncing again reports no hit twice.
+	require.NoError(t, firstFollower.Sync(context.Background()))
+	require.Equal(t, uint64(2), leader.HitsByID(charge.ID))
+
+	// The calls are used up across the cluster.
+	require.NoError(t, secondFollower.Sync(context.Background()))
+	require.Equal(t, uint64(2), second.HitsByID(charge.ID))
+
+	r, err = second.FindByQuery(query)
+	require.True(t, err != nil || r.Found() == nil)
+}
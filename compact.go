@@ -0,0 +1,95 @@
+package stuber
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// StorageStats are the statistics of the storage of the stubs.
+type StorageStats struct {
+	Stubs    int `json:"stubs"`    // The number of stored stubs.
+	Buckets  int `json:"buckets"`  // The number of service method buckets, including the empty ones.
+	Entries  int `json:"entries"`  // The number of entries of the buckets, which exceeds Stubs with stale entries.
+	Capacity int `json:"capacity"` // The total capacity of the buckets, in entries.
+}
+
+// CompactionReport are the statistics of the storage before and after a
+// compaction, along with the number of dropped stale entries.
+type CompactionReport struct {
+	Before  StorageStats `json:"before"`  // The statistics before the compaction.
+	After   StorageStats `json:"after"`   // The statistics after the compaction.
+	Dropped int          `json:"dropped"` // The number of dropped stale and duplicate entries.
+}
+
+// stats returns the statistics of the storage.
+func (s *storage) stats() StorageStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := StorageStats{Stubs: len(s.itemsByID), Buckets: len(s.items)}
+
+	for _, values := range s.items {
+		stats.Entries += len(values)
+		stats.Capacity += cap(values)
+	}
+
+	return stats
+}
+
+// compact drops the stale entries and the empty buckets, and reallocates the
+// maps and the buckets to their size, so the memory left by deletions can be
+// reclaimed by the garbage collector.
+//
+// The function returns the number of dropped entries.
+func (s *storage) compact() int {
+	dropped := s.reindex()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make(map[uuid.UUID][]Value, len(s.items))
+
+	for pos, values := range s.items {
+		if len(values) > 0 {
+			items[pos] = slices.Clip(slices.Clone(values))
+		}
+	}
+
+	itemsByID := make(map[uuid.UUID]Value, len(s.itemsByID))
+	for key, v := range s.itemsByID {
+		itemsByID[key] = v
+	}
+
+	s.items = items
+	s.itemsByID = itemsByID
+
+	return dropped
+}
+
+// compact compacts the storage of the searcher.
+func (s *searcher) compact() int {
+	defer s.cache.invalidate()
+
+	return s.storage.compact()
+}
+
+// Compact rebuilds the index of the Stub values and reallocates the storage
+// to its size, so the memory left by heavy churn, such as large imports and
+// deletions, can be released. The parsed templates of the deleted Stub values
+// are released too.
+//
+// Returns:
+// - CompactionReport: The statistics of the storage before and after.
+func (b *Budgerigar) Compact() CompactionReport {
+	report := CompactionReport{Before: b.searcher.storage.stats()}
+
+	report.Dropped = b.searcher.compact()
+	report.After = b.searcher.storage.stats()
+
+	b.templateCache.retain(func(id uuid.UUID) bool {
+		return b.searcher.findByID(id) != nil
+	})
+
+	return report
+}
@@ -0,0 +1,92 @@
+package stuber
+
+import (
+	"encoding/json"
+)
+
+// Comparison matches a number against bounds keyed by their operator, such
+// as {">=": 3} or {">": 0, "<=": 1024}. All the bounds must hold.
+//
+// The operators are "==", "!=", "<", "<=", ">" and ">=". A bound with an
+// unknown operator never holds.
+type Comparison map[string]int
+
+// Match checks if the value holds all the bounds of the comparison.
+//
+// An empty comparison matches any value.
+func (c Comparison) Match(value int) bool {
+	for operator, bound := range c {
+		var ok bool
+
+		switch operator {
+		case "==":
+			ok = value == bound
+		case "!=":
+			ok = value != bound
+		case "<":
+			ok = value < bound
+		case "<=":
+			ok = value <= bound
+		case ">":
+			ok = value > bound
+		case ">=":
+			ok = value >= bound
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// payloadSize returns the size of the payload of the query, the size of the
+// JSON encoding of its data if the size is not known.
+func payloadSize(query Query) int {
+	if query.Size > 0 || query.Data == nil {
+		return query.Size
+	}
+
+	data, err := json.Marshal(query.Data)
+	if err != nil {
+		return 0
+	}
+
+	return len(data)
+}
+
+// matchCounts checks if the query matches the size and the message count
+// matchers of the stub.
+func matchCounts(query Query, stub *Stub) bool {
+	if len(stub.Size) > 0 && !stub.Size.Match(payloadSize(query)) {
+		return false
+	}
+
+	return stub.MessageCount.Match(query.MessageCount)
+}
+
+// rankCounts ranks the size and the message count matchers of the stub by
+// the number of their bounds the query holds, so stubs caring only about the
+// counts can be found.
+func rankCounts(query Query, stub *Stub) float64 {
+	var rank float64
+
+	if len(stub.Size) > 0 {
+		size := payloadSize(query)
+
+		for operator, bound := range stub.Size {
+			if (Comparison{operator: bound}).Match(size) {
+				rank++
+			}
+		}
+	}
+
+	for operator, bound := range stub.MessageCount {
+		if (Comparison{operator: bound}).Match(query.MessageCount) {
+			rank++
+		}
+	}
+
+	return rank
+}
@@ -0,0 +1,90 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestComparison_Match(t *testing.T) {
+	tests := []struct {
+		comparison stuber.Comparison
+		value      int
+		want       bool
+	}{
+		{nil, 5, true},
+		{stuber.Comparison{"==": 5}, 5, true},
+		{stuber.Comparison{"!=": 5}, 5, false},
+		{stuber.Comparison{"<": 5}, 4, true},
+		{stuber.Comparison{"<=": 5}, 6, false},
+		{stuber.Comparison{">": 0, "<=": 1024}, 512, true},
+		{stuber.Comparison{">=": 3}, 2, false},
+		{stuber.Comparison{"~": 3}, 3, false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.comparison.Match(tt.value), "%v %d", tt.comparison, tt.value)
+	}
+}
+
+func TestBudgerigar_FindByQuery_Size(t *testing.T) {
+	small := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Uploader",
+		Method:  "Upload",
+		Size:    stuber.Comparison{"<": 100},
+		Output:  stuber.Output{Data: map[string]interface{}{"chunked": false}},
+	}
+	large := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Uploader",
+		Method:  "Upload",
+		Size:    stuber.Comparison{">=": 100},
+		Output:  stuber.Output{Data: map[string]interface{}{"chunked": true}},
+	}
+
+	s := stuber.New()
+	s.PutMany(small, large)
+
+	result, err := s.FindByQuery(stuber.Query{
+		Service: "Uploader",
+		Method:  "Upload",
+		Data:    map[string]interface{}{"chunk": "abc"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, small.ID, result.Found().ID)
+
+	result, err = s.FindByQuery(stuber.Query{Service: "Uploader", Method: "Upload", Size: 4096})
+	require.NoError(t, err)
+	require.Equal(t, large.ID, result.Found().ID)
+}
+
+func TestBidiResult_Next_MessageCount(t *testing.T) {
+	first := &stuber.Stub{
+		ID:           uuid.New(),
+		Service:      "Pager",
+		Method:       "Pages",
+		MessageCount: stuber.Comparison{"<": 3},
+	}
+	last := &stuber.Stub{
+		ID:           uuid.New(),
+		Service:      "Pager",
+		Method:       "Pages",
+		MessageCount: stuber.Comparison{">=": 3},
+	}
+
+	s := stuber.New()
+	s.PutMany(first, last)
+
+	stream, err := s.FindByQueryBidi(stuber.Query{Service: "Pager", Method: "Pages"})
+	require.NoError(t, err)
+
+	for _, want := range []uuid.UUID{first.ID, first.ID, last.ID, last.ID} {
+		stub, err := stream.Next(map[string]interface{}{"page": "next"})
+		require.NoError(t, err)
+		require.Equal(t, want, stub.ID)
+	}
+}
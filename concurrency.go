@@ -0,0 +1,167 @@
+package stuber
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// defaultConcurrencyMessage is the error message used when a
+// ConcurrencyLimit does not define an output.
+const defaultConcurrencyMessage = "too many concurrent calls"
+
+// globalConcurrencyKey is the key of the slots of the global limit.
+const globalConcurrencyKey = "global"
+
+// ConcurrencyLimit describes the number of simultaneous calls allowed.
+//
+// A slot is taken by each match and released when the embedding server calls
+// Result.Done once the call is answered. With WithCallTracking, the slots are
+// held until then, so the calls are limited whatever their delays. Without
+// it, as the server may never call Done, a slot is also released once the
+// delay of its output elapses, the delay including the latency added by the
+// faults and the chaos profile: a call answered without delay then holds no
+// slot, so the calls of a stub without delay are never limited. Once all the
+// slots are held, the Output is returned instead of the matched output,
+// without holding a slot.
+type ConcurrencyLimit struct {
+	Limit  int     `json:"limit"`            // The number of simultaneous calls allowed.
+	Output *Output `json:"output,omitempty"` // The output returned once the limit is reached.
+}
+
+// concurrencySlot is a slot held by a call in flight.
+type concurrencySlot struct {
+	end time.Time // The end of the delay of the call, or zero if held until released.
+}
+
+// concurrencyLimiter tracks the slots held by the calls in flight.
+type concurrencyLimiter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	global  *ConcurrencyLimit
+	tracked bool                          // Whether the slots are held until released, ignoring the delays.
+	slots   map[string][]*concurrencySlot // The held slots, by stub or globalConcurrencyKey.
+}
+
+// newConcurrencyLimiter creates a new concurrencyLimiter.
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{
+		now:   time.Now,
+		slots: make(map[string][]*concurrencySlot),
+	}
+}
+
+// setGlobal sets the limit shared by all the stubs.
+//
+// Passing nil removes the limit.
+func (l *concurrencyLimiter) setGlobal(limit *ConcurrencyLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.global = limit
+
+	delete(l.slots, globalConcurrencyKey)
+}
+
+// reset releases all the slots.
+func (l *concurrencyLimiter) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.slots = make(map[string][]*concurrencySlot)
+}
+
+// apply takes a slot of the given stub and of the global limit, released
+// by the returned function or, unless the calls are tracked, once the delay
+// of its output elapses.
+//
+// It returns the stub unchanged if the slots are free, otherwise a copy of
+// the stub with the busy output. The returned function is nil if no slot is
+// taken.
+func (l *concurrencyLimiter) apply(stub *Stub) (*Stub, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if stub.MaxConcurrent == nil && l.global == nil {
+		return stub, nil
+	}
+
+	now := l.now()
+	key := "stub:" + stub.ID.String()
+
+	if l.global != nil && !l.free(globalConcurrencyKey, l.global, now) {
+		return stub.withOutput(l.global.output()), nil
+	}
+
+	if stub.MaxConcurrent != nil && !l.free(key, stub.MaxConcurrent, now) {
+		return stub.withOutput(stub.MaxConcurrent.output()), nil
+	}
+
+	slot := &concurrencySlot{}
+	if !l.tracked {
+		slot.end = now.Add(stub.Output.Delay.Std())
+	}
+
+	keys := make([]string, 0, 2) //nolint:mnd
+
+	if l.global != nil {
+		keys = append(keys, globalConcurrencyKey)
+	}
+
+	if stub.MaxConcurrent != nil {
+		keys = append(keys, key)
+	}
+
+	for _, key := range keys {
+		l.slots[key] = append(l.slots[key], slot)
+	}
+
+	return stub, sync.OnceFunc(func() { l.release(slot, keys) })
+}
+
+// release releases the given slot held with the given keys.
+func (l *concurrencyLimiter) release(slot *concurrencySlot, keys []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		held := slices.DeleteFunc(l.slots[key], func(other *concurrencySlot) bool { return other == slot })
+
+		if len(held) == 0 {
+			delete(l.slots, key)
+		} else {
+			l.slots[key] = held
+		}
+	}
+}
+
+// free releases the slots with the given key whose delays elapsed, and
+// checks if one is left for a new call.
+//
+// The mutex must be held.
+func (l *concurrencyLimiter) free(key string, limit *ConcurrencyLimit, now time.Time) bool {
+	held := slices.DeleteFunc(l.slots[key], func(slot *concurrencySlot) bool {
+		return !slot.end.IsZero() && !slot.end.After(now)
+	})
+
+	if len(held) == 0 {
+		delete(l.slots, key)
+	} else {
+		l.slots[key] = held
+	}
+
+	return len(held) < limit.Limit
+}
+
+// output returns the output used once the limit is reached.
+func (c *ConcurrencyLimit) output() Output {
+	if c.Output != nil {
+		return *c.Output
+	}
+
+	code := codes.ResourceExhausted
+
+	return Output{Error: defaultConcurrencyMessage, Code: &code}
+}
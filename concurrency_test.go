@@ -0,0 +1,234 @@
+package stuber_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_MaxConcurrent(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	busy := stuber.Output{Error: "busy"}
+	stub := &stuber.Stub{
+		ID:            uuid.New(),
+		Service:       "Search",
+		Method:        "Query",
+		Output:        stuber.Output{Data: map[string]interface{}{"ok": true}, Delay: stuber.Duration(time.Second)},
+		MaxConcurrent: &stuber.ConcurrencyLimit{Limit: 2, Output: &busy},
+	}
+	s.PutMany(stub)
+
+	query := stuber.Query{Service: "Search", Method: "Query"}
+
+	find := func() stuber.Output {
+		result, err := s.FindByQuery(query)
+		require.NoError(t, err)
+
+		return result.Found().Output
+	}
+
+	require.Empty(t, find().Error)
+
+	now = now.Add(500 * time.Millisecond)
+	require.Empty(t, find().Error)
+	require.Equal(t, "busy", find().Error)
+
+	// The first call is over, which frees its slot.
+	now = now.Add(500 * time.Millisecond)
+	require.Empty(t, find().Error)
+	require.Equal(t, "busy", find().Error)
+
+	// A clear frees all the slots.
+	s.Clear()
+	s.PutMany(stub)
+	require.Empty(t, find().Error)
+}
+
+func TestBudgerigar_MaxConcurrent_NoDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	busy := stuber.Output{Error: "busy"}
+	s.PutMany(&stuber.Stub{
+		ID:            uuid.New(),
+		Service:       "Search",
+		Method:        "Query",
+		MaxConcurrent: &stuber.ConcurrencyLimit{Limit: 1, Output: &busy},
+	})
+
+	// The calls answered without delay are over once matched, so they are
+	// never limited, even at the same time.
+	for range 3 {
+		result, err := s.FindByQuery(stuber.Query{Service: "Search", Method: "Query"})
+		require.NoError(t, err)
+		require.Empty(t, result.Found().Output.Error)
+	}
+}
+
+func TestBudgerigar_MaxConcurrent_Done(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	busy := stuber.Output{Error: "busy"}
+	s.PutMany(&stuber.Stub{
+		ID:            uuid.New(),
+		Service:       "Search",
+		Method:        "Query",
+		Output:        stuber.Output{Delay: stuber.Duration(time.Second)},
+		MaxConcurrent: &stuber.ConcurrencyLimit{Limit: 1, Output: &busy},
+	})
+
+	query := stuber.Query{Service: "Search", Method: "Query"}
+
+	first, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Empty(t, first.Found().Output.Error)
+
+	second, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, "busy", second.Found().Output.Error)
+
+	// The call is over before its delay elapses, which frees its slot.
+	second.Done()
+	first.Done()
+	first.Done()
+
+	result, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Empty(t, result.Found().Output.Error)
+}
+
+func TestBudgerigar_MaxConcurrent_CallTracking(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }), stuber.WithCallTracking(true))
+
+	busy := stuber.Output{Error: "busy"}
+	s.PutMany(&stuber.Stub{
+		ID:            uuid.New(),
+		Service:       "Search",
+		Method:        "Query",
+		MaxConcurrent: &stuber.ConcurrencyLimit{Limit: 1, Output: &busy},
+	})
+
+	query := stuber.Query{Service: "Search", Method: "Query"}
+
+	first, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Empty(t, first.Found().Output.Error)
+
+	// The calls without delay hold their slots until they are done.
+	now = now.Add(time.Hour)
+
+	result, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, "busy", result.Found().Output.Error)
+
+	first.Done()
+
+	result, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Empty(t, result.Found().Output.Error)
+}
+
+func TestBudgerigar_SetMaxConcurrent(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+	s.SetMaxConcurrent(&stuber.ConcurrencyLimit{Limit: 1})
+
+	for _, method := range []string{"Get", "List"} {
+		s.PutMany(&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Items",
+			Method:  method,
+			Output:  stuber.Output{Delay: stuber.Duration(time.Second)},
+		})
+	}
+
+	result, err := s.FindByQuery(stuber.Query{Service: "Items", Method: "Get"})
+	require.NoError(t, err)
+	require.Nil(t, result.Found().Output.Code)
+
+	result, err = s.FindByQuery(stuber.Query{Service: "Items", Method: "List"})
+	require.NoError(t, err)
+	require.Equal(t, codes.ResourceExhausted, *result.Found().Output.Code)
+
+	s.SetMaxConcurrent(nil)
+
+	result, err = s.FindByQuery(stuber.Query{Service: "Items", Method: "List"})
+	require.NoError(t, err)
+	require.Nil(t, result.Found().Output.Code)
+}
+
+func TestBudgerigar_MaxConcurrent_Chaos(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+	s.SetMaxConcurrent(&stuber.ConcurrencyLimit{Limit: 1})
+	s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Items", Method: "Get"})
+
+	query := stuber.Query{Service: "Items", Method: "Get"}
+
+	// Without delay, the calls are over once matched.
+	for range 2 {
+		result, err := s.FindByQuery(query)
+		require.NoError(t, err)
+		require.Nil(t, result.Found().Output.Code)
+	}
+
+	// The latency of the chaos profile holds the slot.
+	s.SetChaos(&stuber.ChaosProfile{Delay: time.Second})
+
+	result, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Nil(t, result.Found().Output.Code)
+
+	result, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, codes.ResourceExhausted, *result.Found().Output.Code)
+
+	now = now.Add(time.Second)
+
+	result, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Nil(t, result.Found().Output.Code)
+}
+
+func TestBudgerigar_SetMaxConcurrent_Concurrent(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Items", Method: "Get"})
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 100 {
+			s.SetMaxConcurrent(&stuber.ConcurrencyLimit{Limit: i + 1})
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for range 100 {
+			_, _ = s.FindByQuery(stuber.Query{Service: "Items", Method: "Get"})
+		}
+	}()
+
+	wg.Wait()
+}
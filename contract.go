@@ -0,0 +1,178 @@
+package stuber
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// Contract is a test case derived from a stub: the request expected to match
+// the stub and the response it returns. Run against a real service, the
+// contracts check that the stubs still describe it.
+type Contract struct {
+	Stub     uuid.UUID              `json:"stub"`              // The stub the contract is derived from.
+	Service  string                 `json:"service"`           // The service of the call.
+	Method   string                 `json:"method"`            // The method of the call.
+	Headers  map[string]interface{} `json:"headers,omitempty"` // The headers of the request.
+	Request  map[string]interface{} `json:"request"`           // The data of the request.
+	Response map[string]interface{} `json:"response"`          // The data of the expected response.
+	Code     codes.Code             `json:"code"`              // The status code of the expected response.
+	Error    string                 `json:"error,omitempty"`   // The error message of the expected response.
+}
+
+// Contracts derives a Contract from each stored Stub value, abstract stubs
+// excepted, ordered by service, method and stub ID.
+//
+// The request of a contract is the first example of the Stub value, or the
+// request generated by GenerateExample.
+//
+// Returns:
+// - []Contract: The contracts of the Stub values.
+func (b *Budgerigar) Contracts() []Contract {
+	var contracts []Contract
+
+	for _, stub := range b.searcher.all() {
+		if stub.Abstract {
+			continue
+		}
+
+		resolved := b.searcher.resolve(stub)
+
+		example := GenerateExample(resolved)
+		if len(resolved.Examples) > 0 {
+			example = resolved.Examples[0]
+		}
+
+		contract := Contract{
+			Stub:     stub.ID,
+			Service:  resolved.Service,
+			Method:   resolved.Method,
+			Headers:  example.Headers,
+			Request:  example.Data,
+			Response: resolved.Output.Data,
+			Error:    resolved.Output.Error,
+		}
+
+		switch {
+		case resolved.Output.Code != nil:
+			contract.Code = *resolved.Output.Code
+		case resolved.Output.Error != "":
+			contract.Code = codes.Unknown
+		}
+
+		contracts = append(contracts, contract)
+	}
+
+	slices.SortFunc(contracts, func(a, b Contract) int {
+		return cmp.Or(
+			cmp.Compare(a.Service, b.Service),
+			cmp.Compare(a.Method, b.Method),
+			cmp.Compare(a.Stub.String(), b.Stub.String()),
+		)
+	})
+
+	return contracts
+}
+
+// GCTF encodes the contract as a grpctestify test file calling the service
+// at the given address.
+//
+// Parameters:
+// - address: The address of the service under test, such as localhost:4770.
+//
+// Returns:
+// - []byte: The content of the .gctf file.
+// - error: An error if the request or the response cannot be encoded.
+func (c Contract) GCTF(address string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "--- ADDRESS ---\n%s\n\n", address)
+	fmt.Fprintf(&buf, "--- ENDPOINT ---\n%s/%s\n\n", c.Service, c.Method)
+
+	if len(c.Headers) > 0 {
+		buf.WriteString("--- REQUEST_HEADERS ---\n")
+
+		for _, name := range slices.Sorted(maps.Keys(c.Headers)) {
+			fmt.Fprintf(&buf, "%s: %v\n", name, c.Headers[name])
+		}
+
+		buf.WriteString("\n")
+	}
+
+	if err := writeSection(&buf, "REQUEST", c.Request); err != nil {
+		return nil, err
+	}
+
+	buf.WriteString("\n")
+
+	name, expected := "RESPONSE", c.Response
+	if c.Code != codes.OK {
+		name, expected = "ERROR", map[string]interface{}{"code": int(c.Code), "message": c.Error}
+	}
+
+	if err := writeSection(&buf, name, expected); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeSection writes a section of a grpctestify test file with the given
+// JSON content.
+func writeSection(buf *bytes.Buffer, name string, content map[string]interface{}) error {
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(buf, "--- %s ---\n%s\n", name, data)
+
+	return nil
+}
+
+// ghzConfig is the configuration file of the ghz load testing tool.
+type ghzConfig struct {
+	Call     string                 `json:"call"`
+	Host     string                 `json:"host"`
+	Data     map[string]interface{} `json:"data"`
+	Metadata map[string]string      `json:"metadata,omitempty"`
+	Insecure bool                   `json:"insecure"`
+}
+
+// GHZ encodes the contract as a ghz configuration file calling the service
+// at the given address.
+//
+// Parameters:
+// - address: The address of the service under test, such as localhost:4770.
+//
+// Returns:
+// - []byte: The content of the JSON configuration file.
+// - error: An error if the request cannot be encoded.
+func (c Contract) GHZ(address string) ([]byte, error) {
+	config := ghzConfig{
+		Call:     c.Service + "." + c.Method,
+		Host:     address,
+		Data:     c.Request,
+		Insecure: true,
+	}
+
+	if len(c.Headers) > 0 {
+		config.Metadata = make(map[string]string, len(c.Headers))
+
+		for name, value := range c.Headers {
+			config.Metadata[name] = fmt.Sprint(value)
+		}
+	}
+
+	return json.MarshalIndent(config, "", "  ")
+}
@@ -0,0 +1,77 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Contracts(t *testing.T) {
+	s := stuber.New()
+
+	notFound := codes.NotFound
+
+	s.PutMany(
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Headers: stuber.InputHeader{Equals: map[string]interface{}{"x-user": "bob"}},
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "hi"}},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayGoodbye",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+			Output:  stuber.Output{Error: "unknown user", Code: &notFound},
+		},
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", Abstract: true},
+	)
+
+	contracts := s.Contracts()
+	require.Len(t, contracts, 2)
+	require.Equal(t, "SayGoodbye", contracts[0].Method)
+	require.Equal(t, codes.NotFound, contracts[0].Code)
+	require.Equal(t, map[string]interface{}{"name": "Bob"}, contracts[1].Request)
+
+	gctf, err := contracts[1].GCTF("localhost:4770")
+	require.NoError(t, err)
+	require.Equal(t, `--- ADDRESS ---
+localhost:4770
+
+--- ENDPOINT ---
+Greeter/SayHello
+
+--- REQUEST_HEADERS ---
+x-user: bob
+
+--- REQUEST ---
+{
+  "name": "Bob"
+}
+
+--- RESPONSE ---
+{
+  "message": "hi"
+}
+`, string(gctf))
+
+	gctf, err = contracts[0].GCTF("localhost:4770")
+	require.NoError(t, err)
+	require.Contains(t, string(gctf), "--- ERROR ---\n{\n  \"code\": 5,\n  \"message\": \"unknown user\"\n}\n")
+
+	data, err := contracts[1].GHZ("localhost:4770")
+	require.NoError(t, err)
+
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &config))
+	require.Equal(t, "Greeter.SayHello", config["call"])
+	require.Equal(t, map[string]interface{}{"x-user": "bob"}, config["metadata"])
+}
@@ -0,0 +1,177 @@
+package stuber
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StubRef identifies a stub in the dashboard data, with its labels.
+type StubRef struct {
+	ID          uuid.UUID `json:"id"`                    // The ID of the stub.
+	Service     string    `json:"service"`               // The service of the stub.
+	Method      string    `json:"method"`                // The method of the stub.
+	Description string    `json:"description,omitempty"` // The description of the stub.
+	Owner       string    `json:"owner,omitempty"`       // The owner of the stub.
+}
+
+// DashboardStub is a stub joined with its usage, for dashboards.
+type DashboardStub struct {
+	StubRef
+
+	Priority  int               `json:"priority,omitempty"`  // The priority of the stub.
+	Meta      map[string]string `json:"meta,omitempty"`      // The labels of the stub.
+	Used      bool              `json:"used"`                // Whether the stub was used.
+	Matches   int               `json:"matches"`             // The number of its matches in the match history.
+	LastMatch *time.Time        `json:"lastMatch,omitempty"` // Its last match in the match history, if any.
+}
+
+// DashboardMatch is a match of the match history joined with its stub, for
+// dashboards.
+type DashboardMatch struct {
+	MatchRecord
+
+	Stub *StubRef `json:"stub,omitempty"` // The stub that answered, nil if it was deleted since.
+}
+
+// DashboardMiss is a miss of the miss log joined with its most similar stub,
+// for dashboards.
+type DashboardMiss struct {
+	Miss
+
+	Similar *StubRef `json:"similar,omitempty"` // The most similar stub, nil if none or deleted since.
+}
+
+// refTo returns the StubRef of the stub.
+func refTo(stub *Stub) StubRef {
+	return StubRef{
+		ID:          stub.ID,
+		Service:     stub.Service,
+		Method:      stub.Method,
+		Description: stub.Description,
+		Owner:       stub.Owner,
+	}
+}
+
+// refOf returns the StubRef of the stub with the given ID, or nil.
+func (b *Budgerigar) refOf(id uuid.UUID) *StubRef {
+	stub := b.FindByID(id)
+	if stub == nil {
+		return nil
+	}
+
+	ref := refTo(stub)
+
+	return &ref
+}
+
+// DashboardStubs returns all the Stub values in their canonical order, with
+// their usage and their matches in the match history.
+//
+// Returns:
+// - []DashboardStub: The Stub values with their usage.
+func (b *Budgerigar) DashboardStubs() []DashboardStub {
+	used := make(map[uuid.UUID]struct{})
+	for _, id := range b.searcher.usedIDs() {
+		used[id] = struct{}{}
+	}
+
+	matches := make(map[uuid.UUID]int)
+	last := make(map[uuid.UUID]time.Time)
+
+	for _, record := range b.history.list() {
+		matches[record.StubID]++
+		last[record.StubID] = record.Time
+	}
+
+	stubs := SortStubs(b.searcher.all())
+	result := make([]DashboardStub, len(stubs))
+
+	for i, stub := range stubs {
+		_, ok := used[stub.ID]
+
+		result[i] = DashboardStub{
+			StubRef:  refTo(stub),
+			Priority: stub.Priority,
+			Meta:     stub.Meta,
+			Used:     ok,
+			Matches:  matches[stub.ID],
+		}
+
+		if at, ok := last[stub.ID]; ok {
+			result[i].LastMatch = &at
+		}
+	}
+
+	return result
+}
+
+// DashboardMatches returns the kept matches, from the newest to the oldest,
+// with the Stub values that answered them.
+//
+// Returns:
+// - []DashboardMatch: The kept matches.
+func (b *Budgerigar) DashboardMatches() []DashboardMatch {
+	records := b.history.list()
+	slices.Reverse(records)
+
+	result := make([]DashboardMatch, len(records))
+	for i, record := range records {
+		result[i] = DashboardMatch{MatchRecord: record, Stub: b.refOf(record.StubID)}
+	}
+
+	return result
+}
+
+// DashboardMisses returns the kept misses, from the newest to the oldest,
+// with their most similar Stub values.
+//
+// Returns:
+// - []DashboardMiss: The kept misses.
+func (b *Budgerigar) DashboardMisses() []DashboardMiss {
+	misses := b.misses.list()
+	slices.Reverse(misses)
+
+	result := make([]DashboardMiss, len(misses))
+	for i, miss := range misses {
+		result[i] = DashboardMiss{Miss: miss}
+
+		if miss.SimilarID != nil {
+			result[i].Similar = b.refOf(*miss.SimilarID)
+		}
+	}
+
+	return result
+}
+
+// NewDashboardHandler returns the HTTP handler of the dashboard data of the
+// Budgerigar, pre-joined for user interfaces:
+//
+//   - GET /api/dashboard/stubs answers the DashboardStubs.
+//   - GET /api/dashboard/matches answers the DashboardMatches, kept with
+//     WithMatchHistory.
+//   - GET /api/dashboard/misses answers the DashboardMisses, kept with
+//     WithMissLog.
+//
+// Parameters:
+// - b: The Budgerigar to display.
+//
+// Returns:
+// - http.Handler: The handler to mount at the root of a server.
+func NewDashboardHandler(b *Budgerigar) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/dashboard/stubs", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, b.DashboardStubs())
+	})
+	mux.HandleFunc("GET /api/dashboard/matches", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, b.DashboardMatches())
+	})
+	mux.HandleFunc("GET /api/dashboard/misses", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, b.DashboardMisses())
+	})
+
+	return mux
+}
@@ -0,0 +1,83 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestNewDashboardHandler(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(
+		stuber.WithClock(func() time.Time { return now }),
+		stuber.WithMatchHistory(10),
+		stuber.WithMissLog(10),
+	)
+
+	hello := &stuber.Stub{
+		ID:          uuid.New(),
+		Service:     "Greeter",
+		Method:      "SayHello",
+		Description: "Greets Bob",
+		Input:       stuber.InputData{Equals: map[string]interface{}{"name": "Bob", "lang": "en"}},
+	}
+	goodbye := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayGoodbye",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	s.PutMany(hello, goodbye)
+
+	for _, name := range []string{"Bob", "Bob", "Alice"} {
+		_, _ = s.FindByQuery(stuber.Query{
+			Service: "Greeter",
+			Method:  "SayHello",
+			Data:    map[string]interface{}{"name": name, "lang": "en"},
+		})
+	}
+
+	handler := stuber.NewDashboardHandler(s)
+
+	get := func(path string, target any) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), target))
+	}
+
+	var stubs []stuber.DashboardStub
+
+	get("/api/dashboard/stubs", &stubs)
+	require.Len(t, stubs, 2)
+	require.Equal(t, goodbye.ID, stubs[0].ID)
+	require.False(t, stubs[0].Used)
+	require.Equal(t, hello.ID, stubs[1].ID)
+	require.Equal(t, "Greets Bob", stubs[1].Description)
+	require.True(t, stubs[1].Used)
+	require.Equal(t, 2, stubs[1].Matches)
+	require.Equal(t, now, *stubs[1].LastMatch)
+
+	var matches []stuber.DashboardMatch
+
+	get("/api/dashboard/matches", &matches)
+	require.Len(t, matches, 2)
+	require.Equal(t, hello.ID, matches[0].Stub.ID)
+	require.Equal(t, s.RecentMatches()[1], matches[0].MatchRecord)
+
+	var misses []stuber.DashboardMiss
+
+	get("/api/dashboard/misses", &misses)
+	require.Len(t, misses, 1)
+	require.Equal(t, hello.ID, misses[0].Similar.ID)
+	require.Equal(t, "data.name", misses[0].Diff[0].Field)
+}
@@ -0,0 +1,104 @@
+package stuber
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// DryRun previews the destructive operations of a Budgerigar: each method
+// returns the IDs of the Stub values the operation would delete or replace,
+// without applying it.
+type DryRun struct {
+	budgerigar *Budgerigar
+}
+
+// DryRun returns a preview of the destructive operations of the Budgerigar.
+//
+// Returns:
+// - *DryRun: The preview of the Budgerigar.
+func (b *Budgerigar) DryRun() *DryRun {
+	return &DryRun{budgerigar: b}
+}
+
+// Clear returns the IDs of the Stub values Clear would delete.
+//
+// Returns:
+// - []uuid.UUID: The sorted IDs of the affected Stub values.
+func (d *DryRun) Clear() []uuid.UUID {
+	return d.DeleteWhere(func(*Stub) bool { return true })
+}
+
+// DeleteByID returns the IDs of the Stub values DeleteByID would delete.
+//
+// Parameters:
+// - ids: The UUIDs of the Stub values to delete.
+//
+// Returns:
+// - []uuid.UUID: The sorted IDs of the affected Stub values.
+func (d *DryRun) DeleteByID(ids ...uuid.UUID) []uuid.UUID {
+	var affected []uuid.UUID
+
+	for _, id := range ids {
+		if d.budgerigar.FindByID(id) != nil {
+			affected = append(affected, id)
+		}
+	}
+
+	return sortIDs(affected)
+}
+
+// DeleteWhere returns the IDs of the Stub values DeleteWhere would delete.
+//
+// Parameters:
+// - pred: The predicate selecting the Stub values to delete.
+//
+// Returns:
+// - []uuid.UUID: The sorted IDs of the affected Stub values.
+func (d *DryRun) DeleteWhere(pred func(*Stub) bool) []uuid.UUID {
+	var affected []uuid.UUID
+
+	for _, stub := range d.budgerigar.All() {
+		if pred(stub) {
+			affected = append(affected, stub.ID)
+		}
+	}
+
+	return sortIDs(affected)
+}
+
+// Import returns the IDs of the stored Stub values Import would replace.
+//
+// Parameters:
+// - data: The document to import.
+//
+// Returns:
+// - []uuid.UUID: The sorted IDs of the affected Stub values.
+// - error: An error if the document cannot be decoded.
+func (d *DryRun) Import(data []byte) ([]uuid.UUID, error) {
+	b := d.budgerigar
+
+	stubs, err := b.importer.decode(data, b.toggles.Has(ImportEnv))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(stubs))
+	for _, stub := range stubs {
+		if stub.ID != uuid.Nil {
+			ids = append(ids, stub.ID)
+		}
+	}
+
+	return d.DeleteByID(slices.Compact(sortIDs(ids))...), nil
+}
+
+// sortIDs sorts the given IDs in place and returns them.
+func sortIDs(ids []uuid.UUID) []uuid.UUID {
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return cmp.Compare(a.String(), b.String())
+	})
+
+	return ids
+}
@@ -0,0 +1,43 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_DryRun(t *testing.T) {
+	s := stuber.New()
+
+	id1 := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	id2 := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	s.PutMany(
+		&stuber.Stub{ID: id2, Service: "Greeter", Method: "SayHello"},
+		&stuber.Stub{ID: id1, Service: "Greeter", Method: "SayGoodbye"},
+	)
+
+	require.Equal(t, []uuid.UUID{id1, id2}, s.DryRun().Clear())
+	require.Equal(t, []uuid.UUID{id2}, s.DryRun().DeleteByID(id2, uuid.New()))
+	require.Equal(t, []uuid.UUID{id1}, s.DryRun().DeleteWhere(func(stub *stuber.Stub) bool {
+		return stub.Method == "SayGoodbye"
+	}))
+
+	affected, err := s.DryRun().Import([]byte(`
+- id: 00000000-0000-0000-0000-000000000001
+  service: Greeter
+  method: SayGoodbye
+- service: Greeter
+  method: SayHi
+`))
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{id1}, affected)
+
+	_, err = s.DryRun().Import([]byte(`{`))
+	require.ErrorIs(t, err, stuber.ErrInvalidImport)
+
+	require.Len(t, s.All(), 2)
+}
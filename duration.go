@@ -0,0 +1,53 @@
+package stuber
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidDuration is returned when a duration cannot be decoded.
+var ErrInvalidDuration = errors.New("invalid duration")
+
+// Duration is a time.Duration that is encoded in JSON as a human readable
+// string (e.g. "150ms", "2s").
+//
+// For convenience, a plain JSON number is accepted on decoding and is
+// interpreted as a number of milliseconds.
+type Duration time.Duration
+
+// Std returns the value as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes the duration from a string or a number of milliseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		*d = Duration(time.Duration(v * float64(time.Millisecond)))
+
+		return nil
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Join(ErrInvalidDuration, err)
+		}
+
+		*d = Duration(parsed)
+
+		return nil
+	default:
+		return ErrInvalidDuration
+	}
+}
@@ -0,0 +1,150 @@
+package stuber
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrDecryption is returned when encrypted stubs cannot be decrypted, such
+// as without the key they were encrypted with.
+var ErrDecryption = errors.New("cannot decrypt stubs")
+
+// sealedPrefix starts the encrypted data, followed by the ID of the key and
+// the nonce and ciphertext, separated by dots and encoded in unpadded
+// base64url, so the data fits on a single line.
+var sealedPrefix = []byte("stuberenc1.") //nolint:gochecknoglobals
+
+// KeyProvider provides the AES keys encrypting the persisted stubs, such as
+// from a KMS or a secret store. A key is 16, 24 or 32 bytes long, selecting
+// AES-128, AES-192 or AES-256.
+//
+// The ID of the key is stored with the encrypted data, so keys can be
+// rotated: new data is encrypted with the current key, and the data
+// encrypted before is decrypted with the key of its ID.
+type KeyProvider interface {
+	// CurrentKey returns the ID and the key encrypting new data.
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns the key with the given ID, to decrypt data.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider of fixed keys.
+type StaticKeys struct {
+	Current string            // The ID of the key encrypting new data.
+	Keys    map[string][]byte // The keys by ID, including the retired ones still decrypting older data.
+}
+
+// CurrentKey returns the key with the Current ID.
+func (k StaticKeys) CurrentKey() (string, []byte, error) {
+	key, err := k.Key(k.Current)
+
+	return k.Current, key, err
+}
+
+// Key returns the key with the given ID.
+func (k StaticKeys) Key(id string) ([]byte, error) {
+	key, ok := k.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrDecryption, id)
+	}
+
+	return key, nil
+}
+
+// sealed checks if the data is encrypted by seal.
+func sealed(data []byte) bool {
+	return bytes.HasPrefix(data, sealedPrefix)
+}
+
+// newGCM returns the AES-GCM cipher of the key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the data with the current key of the provider. The ID of the
+// key is authenticated along with the data.
+func seal(keys KeyProvider, data []byte) ([]byte, error) {
+	id, key, err := keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(data)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, data, []byte(id))
+
+	encoding := base64.RawURLEncoding
+
+	out := make([]byte, 0, len(sealedPrefix)+encoding.EncodedLen(len(id))+1+encoding.EncodedLen(len(ciphertext)))
+	out = append(out, sealedPrefix...)
+	out = encoding.AppendEncode(out, []byte(id))
+	out = append(out, '.')
+	out = encoding.AppendEncode(out, ciphertext)
+
+	return out, nil
+}
+
+// unseal decrypts the data encrypted by seal with the key of its ID.
+func unseal(keys KeyProvider, data []byte) ([]byte, error) {
+	if keys == nil {
+		return nil, fmt.Errorf("%w: no key provider", ErrDecryption)
+	}
+
+	encodedID, encoded, ok := bytes.Cut(bytes.TrimPrefix(data, sealedPrefix), []byte("."))
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed data", ErrDecryption)
+	}
+
+	encoding := base64.RawURLEncoding
+
+	id, err := encoding.AppendDecode(nil, encodedID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+
+	ciphertext, err := encoding.AppendDecode(nil, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+
+	key, err := keys.Key(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed data", ErrDecryption)
+	}
+
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,107 @@
+package stuber_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func secretStub() *stuber.Stub {
+	return &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Users",
+		Method:  "GetUser",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"ssn": "078-05-1120"}},
+	}
+}
+
+func TestBudgerigar_SaveToFile_Encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stubs.yaml")
+	keys := stuber.StaticKeys{Current: "2026", Keys: map[string][]byte{"2026": bytes.Repeat([]byte{1}, 32)}}
+
+	stub := secretStub()
+
+	s := stuber.New(stuber.WithEncryption(keys))
+	s.PutMany(stub)
+	require.NoError(t, s.SaveToFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "078-05-1120")
+
+	restored := stuber.New(stuber.WithEncryption(keys))
+
+	ids, err := restored.LoadFromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{stub.ID}, ids)
+
+	_, err = stuber.New().LoadFromFile(path)
+	require.ErrorIs(t, err, stuber.ErrDecryption)
+
+	// Another key cannot decrypt the document.
+	other := stuber.StaticKeys{Current: "2026", Keys: map[string][]byte{"2026": bytes.Repeat([]byte{2}, 32)}}
+
+	_, err = stuber.New(stuber.WithEncryption(other)).LoadFromFile(path)
+	require.ErrorIs(t, err, stuber.ErrDecryption)
+}
+
+func TestLogStore_Encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stubs.log")
+
+	old := secretStub()
+
+	// A plain log is encrypted progressively.
+	store, err := stuber.OpenLogStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Put([]*stuber.Stub{old}))
+	require.NoError(t, store.Close())
+
+	keys := stuber.StaticKeys{Current: "v1", Keys: map[string][]byte{"v1": bytes.Repeat([]byte{1}, 16)}}
+
+	store, err = stuber.OpenLogStore(path, stuber.WithLogEncryption(keys))
+	require.NoError(t, err)
+
+	stub := secretStub()
+	require.NoError(t, store.Put([]*stuber.Stub{stub}))
+	require.NoError(t, store.Close())
+
+	// Rotate the key and re-encrypt the log.
+	keys.Keys["v2"] = bytes.Repeat([]byte{2}, 32)
+	keys.Current = "v2"
+
+	store, err = stuber.OpenLogStore(path, stuber.WithLogEncryption(keys))
+	require.NoError(t, err)
+	require.NoError(t, store.Compact())
+	require.NoError(t, store.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "078-05-1120")
+
+	delete(keys.Keys, "v1")
+
+	store, err = stuber.OpenLogStore(path, stuber.WithLogEncryption(keys))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { store.Close() })
+
+	stubs, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, stubs, 2)
+	require.Equal(t, old.ID, stubs[0].ID)
+	require.Equal(t, stub.ID, stubs[1].ID)
+
+	plain, err := stuber.OpenLogStore(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { plain.Close() })
+
+	_, err = plain.Load()
+	require.ErrorIs(t, err, stuber.ErrDecryption)
+}
@@ -0,0 +1,275 @@
+package stuber
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default settings of the subscriptions.
+const (
+	defaultEventBuffer  = 64
+	defaultBlockTimeout = 100 * time.Millisecond
+)
+
+// EventType is the type of an Event.
+type EventType string
+
+// The types of the events published by a Budgerigar.
+const (
+	EventPut    EventType = "put"    // A stub was inserted or replaced.
+	EventUpdate EventType = "update" // A stub was patched.
+	EventDelete EventType = "delete" // A stub was deleted.
+	EventExpire EventType = "expire" // A stub fetched from the remote source expired and was deleted.
+	EventClear  EventType = "clear"  // All the stubs were deleted.
+	EventMatch  EventType = "match"  // A query matched a stub.
+)
+
+// Event describes a change of the stubs or a match.
+type Event struct {
+	Type    EventType `json:"type"`              // The type of the event.
+	Time    time.Time `json:"time"`              // When the event happened.
+	StubID  uuid.UUID `json:"stubId"`            // The stub of the event, or uuid.Nil for EventClear.
+	Service string    `json:"service,omitempty"` // The service of the stub.
+	Method  string    `json:"method,omitempty"`  // The method of the stub.
+
+	Meta map[string]string `json:"meta,omitempty"` // The labels of the stub.
+}
+
+// OverflowPolicy decides what happens to an event when the buffer of a
+// subscription is full.
+type OverflowPolicy int
+
+const (
+	// DropNewest drops the new event.
+	DropNewest OverflowPolicy = iota
+	// DropOldest drops the oldest buffered event to make room for the new one.
+	DropOldest
+	// Block waits for room in the buffer, up to the BlockTimeout of the
+	// subscription, then drops the new event.
+	Block
+)
+
+// SubscribeOptions configures a Subscription.
+type SubscribeOptions struct {
+	// Buffer is the number of buffered events, 64 by default.
+	Buffer int
+	// Policy decides what happens to events when the buffer is full.
+	Policy OverflowPolicy
+	// BlockTimeout is the maximum duration the Block policy waits, 100ms by default.
+	BlockTimeout time.Duration
+	// Rate is the maximum number of events delivered per second, the
+	// others being dropped. Zero disables the limit.
+	Rate int
+	// Services limits the events to the given services. An empty list accepts all services.
+	// EventClear has no service, so it is only delivered without this filter.
+	Services []string
+	// Types limits the events to the given types. An empty list accepts all types.
+	Types []EventType
+}
+
+// Subscription receives the events of a Budgerigar.
+//
+// Events are delivered synchronously by the operation publishing them, so
+// slow consumers only affect operations through the Block policy, and only
+// up to its timeout.
+type Subscription struct {
+	bus     *eventBus
+	opts    SubscribeOptions
+	events  chan Event
+	dropped atomic.Uint64
+
+	mu          sync.Mutex // protects the rate window
+	windowStart time.Time
+	windowCount int
+}
+
+// Events returns the channel of the events, closed by Close.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+// or the rate was exceeded.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops the subscription and closes its channel.
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+}
+
+// accepts checks if the subscription accepts the given event.
+func (s *Subscription) accepts(event Event) bool {
+	return (len(s.opts.Services) == 0 || slices.Contains(s.opts.Services, event.Service)) &&
+		(len(s.opts.Types) == 0 || slices.Contains(s.opts.Types, event.Type))
+}
+
+// allow counts the event against the rate of the subscription, in fixed
+// windows of one second.
+func (s *Subscription) allow(now time.Time) bool {
+	if s.opts.Rate <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.windowStart) >= time.Second {
+		s.windowStart, s.windowCount = now, 0
+	}
+
+	if s.windowCount >= s.opts.Rate {
+		return false
+	}
+
+	s.windowCount++
+
+	return true
+}
+
+// deliver sends the event to the subscription, applying its rate and its
+// overflow policy.
+func (s *Subscription) deliver(event Event) {
+	if !s.allow(event.Time) {
+		s.dropped.Add(1)
+
+		return
+	}
+
+	select {
+	case s.events <- event:
+		return
+	default:
+	}
+
+	switch s.opts.Policy {
+	case DropOldest:
+		for {
+			select {
+			case <-s.events:
+				s.dropped.Add(1)
+			default:
+			}
+
+			select {
+			case s.events <- event:
+				return
+			default:
+			}
+		}
+	case Block:
+		timer := time.NewTimer(s.opts.BlockTimeout)
+		defer timer.Stop()
+
+		select {
+		case s.events <- event:
+			return
+		case <-timer.C:
+		}
+	case DropNewest:
+	}
+
+	s.dropped.Add(1)
+}
+
+// eventBus publishes events to subscriptions.
+type eventBus struct {
+	mu        sync.RWMutex
+	now       func() time.Time
+	subs      map[*Subscription]struct{}
+	listeners []*listenerEntry
+}
+
+// newEventBus creates a new eventBus without subscriptions.
+func newEventBus() *eventBus {
+	return &eventBus{
+		now:  time.Now,
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// active checks if the bus has subscriptions or listeners, so events can be
+// skipped entirely without them.
+func (b *eventBus) active() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subs) > 0 || len(b.listeners) > 0
+}
+
+// subscribe adds a new subscription.
+func (b *eventBus) subscribe(opts SubscribeOptions) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultEventBuffer
+	}
+
+	if opts.BlockTimeout <= 0 {
+		opts.BlockTimeout = defaultBlockTimeout
+	}
+
+	sub := &Subscription{bus: b, opts: opts, events: make(chan Event, opts.Buffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs[sub] = struct{}{}
+
+	return sub
+}
+
+// unsubscribe removes the subscription and closes its channel.
+func (b *eventBus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// publish delivers an event of the given type for each given stub, to the
+// subscriptions and to the listeners.
+func (b *eventBus) publish(typ EventType, stubs ...*Stub) {
+	defer b.changed(typ, stubs)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.subs) == 0 {
+		return
+	}
+
+	now := b.now()
+
+	for _, stub := range stubs {
+		event := Event{Type: typ, Time: now}
+		if stub != nil {
+			event.StubID, event.Service, event.Method = stub.ID, stub.Service, stub.Method
+			event.Meta = stub.Meta
+		}
+
+		for sub := range b.subs {
+			if sub.accepts(event) {
+				sub.deliver(event)
+			}
+		}
+	}
+}
+
+// Subscribe subscribes to the events of the Budgerigar: changes of the stubs
+// and matches of non-internal queries.
+//
+// Parameters:
+// - opts: The buffer, overflow policy and filters of the subscription.
+//
+// Returns:
+// - *Subscription: The new Subscription, to close once done.
+func (b *Budgerigar) Subscribe(opts SubscribeOptions) *Subscription {
+	return b.searcher.events.subscribe(opts)
+}
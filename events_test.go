@@ -0,0 +1,114 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Subscribe(t *testing.T) {
+	s := stuber.New()
+
+	all := s.Subscribe(stuber.SubscribeOptions{})
+	defer all.Close()
+
+	matches := s.Subscribe(stuber.SubscribeOptions{
+		Services: []string{"Greeter"},
+		Types:    []stuber.EventType{stuber.EventMatch},
+	})
+	defer matches.Close()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Meta:    map[string]string{"owner": "payments"},
+	}
+
+	s.PutMany(stub, &stuber.Stub{ID: uuid.New(), Service: "Other", Method: "SayHello"})
+
+	_, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+		stub.Priority = 1
+
+		return nil
+	}))
+	require.Equal(t, 1, s.DeleteByID(stub.ID))
+	s.Clear()
+
+	var types []stuber.EventType
+	for range 6 {
+		types = append(types, (<-all.Events()).Type)
+	}
+
+	require.Equal(t, []stuber.EventType{
+		stuber.EventPut, stuber.EventPut, stuber.EventMatch,
+		stuber.EventUpdate, stuber.EventDelete, stuber.EventClear,
+	}, types)
+
+	event := <-matches.Events()
+	require.Equal(t, stuber.EventMatch, event.Type)
+	require.Equal(t, stub.ID, event.StubID)
+	require.Equal(t, "payments", event.Meta["owner"])
+	require.Empty(t, matches.Events())
+}
+
+func TestSubscription_Overflow(t *testing.T) {
+	s := stuber.New()
+
+	newest := s.Subscribe(stuber.SubscribeOptions{Buffer: 1, Policy: stuber.DropNewest})
+	oldest := s.Subscribe(stuber.SubscribeOptions{Buffer: 1, Policy: stuber.DropOldest})
+	block := s.Subscribe(stuber.SubscribeOptions{Buffer: 1, Policy: stuber.Block, BlockTimeout: time.Millisecond})
+
+	first, second := uuid.New(), uuid.New()
+
+	s.PutMany(&stuber.Stub{ID: first, Service: "Greeter", Method: "SayHello"})
+	s.PutMany(&stuber.Stub{ID: second, Service: "Greeter", Method: "SayHello"})
+
+	require.Equal(t, first, (<-newest.Events()).StubID)
+	require.Equal(t, uint64(1), newest.Dropped())
+
+	require.Equal(t, second, (<-oldest.Events()).StubID)
+	require.Equal(t, uint64(1), oldest.Dropped())
+
+	require.Equal(t, first, (<-block.Events()).StubID)
+	require.Equal(t, uint64(1), block.Dropped())
+
+	block.Close()
+	block.Close()
+
+	_, ok := <-block.Events()
+	require.False(t, ok)
+}
+
+func TestSubscription_Rate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	sub := s.Subscribe(stuber.SubscribeOptions{Rate: 2})
+	defer sub.Close()
+
+	for range 3 {
+		s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"})
+	}
+
+	require.Len(t, sub.Events(), 2)
+	require.Equal(t, uint64(1), sub.Dropped())
+
+	now = now.Add(time.Second)
+
+	s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"})
+	require.Len(t, sub.Events(), 3)
+}
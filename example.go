@@ -0,0 +1,150 @@
+package stuber
+
+import (
+	"maps"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxExampleRepeat is the number of repetitions generated for unbounded
+// regular expression repeats, such as x+ or x{2,}.
+const maxExampleRepeat = 1
+
+// inputMatchers is implemented by the matchers of the input data and headers.
+type inputMatchers interface {
+	GetEquals() map[string]interface{}
+	GetContains() map[string]interface{}
+	GetMatches() map[string]interface{}
+}
+
+// GenerateExample synthesizes a request satisfying the matchers of the given
+// stub: equals values are used as is, otherwise contains values are used
+// literally and a sample string is generated for each regular expression of
+// the matches.
+//
+// The generated request is a best effort: regular expressions using
+// lookarounds or conflicting matchers may not be satisfied.
+//
+// Parameters:
+// - stub: The stub to generate a request for.
+//
+// Returns:
+// - Example: A request expected to match the stub.
+func GenerateExample(stub *Stub) Example {
+	return Example{
+		Headers: generateValues(stub.Headers),
+		Data:    generateValues(stub.Input),
+	}
+}
+
+// generateValues merges the samples of the matches and the contains values.
+//
+// As equals matchers only accept the exact same values, they are used alone
+// when they are set.
+func generateValues(m inputMatchers) map[string]interface{} {
+	if equals := m.GetEquals(); len(equals) > 0 {
+		return maps.Clone(equals)
+	}
+
+	result := make(map[string]interface{})
+
+	for name, value := range m.GetMatches() {
+		result[name] = generateSample(value)
+	}
+
+	for name, value := range m.GetContains() {
+		result[name] = value
+	}
+
+	return result
+}
+
+// generateSample returns a value matching the given regular expressions,
+// which may be nested in maps and slices.
+func generateSample(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return generateString(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			result[name] = generateSample(item)
+		}
+
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = generateSample(item)
+		}
+
+		return result
+	default:
+		return value
+	}
+}
+
+// generateString returns a string matching the given regular expression, or
+// the expression itself if it cannot be parsed or no sample matches it.
+func generateString(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return pattern
+	}
+
+	var sb strings.Builder
+
+	writeSample(&sb, re.Simplify())
+
+	if sample := sb.String(); regexp.MustCompile(pattern).MatchString(sample) {
+		return sample
+	}
+
+	return pattern
+}
+
+// writeSample writes the shortest simple string matching the given regular
+// expression to the builder.
+func writeSample(sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op { //nolint:exhaustive
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			sb.WriteRune(sampleRune(re.Rune))
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte('a')
+	case syntax.OpCapture:
+		writeSample(sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeSample(sb, sub)
+		}
+	case syntax.OpAlternate:
+		writeSample(sb, re.Sub[0])
+	case syntax.OpPlus:
+		for range maxExampleRepeat {
+			writeSample(sb, re.Sub[0])
+		}
+	case syntax.OpRepeat:
+		for range re.Min {
+			writeSample(sb, re.Sub[0])
+		}
+	}
+}
+
+// sampleRune returns a readable rune of the given character class ranges,
+// preferring letters and digits.
+func sampleRune(ranges []rune) rune {
+	for _, preferred := range []rune{'a', 'A', '0'} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= preferred && preferred <= ranges[i+1] {
+				return preferred
+			}
+		}
+	}
+
+	return ranges[0]
+}
@@ -0,0 +1,58 @@
+package stuber_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestGenerateExample(t *testing.T) {
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: stuber.InputHeader{
+			Matches: map[string]interface{}{"authorization": "^Bearer [a-z0-9]{8,}$"},
+		},
+		Input: stuber.InputData{
+			Contains: map[string]interface{}{"name": "Bob", "tags": []interface{}{"vip"}},
+			Matches: map[string]interface{}{
+				"email":   `^\w+@(example|test)\.com$`,
+				"address": map[string]interface{}{"zip": `\d{5}`},
+			},
+		},
+	}
+
+	example := stuber.GenerateExample(stub)
+
+	require.Regexp(t, regexp.MustCompile("^Bearer [a-z0-9]{8,}$"), example.Headers["authorization"])
+	require.Equal(t, "Bob", example.Data["name"])
+	require.Equal(t, []interface{}{"vip"}, example.Data["tags"])
+	require.Regexp(t, regexp.MustCompile(`^\w+@(example|test)\.com$`), example.Data["email"])
+	require.Regexp(t, regexp.MustCompile(`\d{5}`), example.Data["address"].(map[string]interface{})["zip"])
+
+	s := stuber.New()
+	s.PutMany(stub)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: stub.Service,
+		Method:  stub.Method,
+		Headers: example.Headers,
+		Data:    example.Data,
+	})
+	require.NoError(t, err)
+	require.Equal(t, stub.ID, r.Found().ID)
+	require.Empty(t, s.SelfTest())
+
+	exact := stuber.GenerateExample(&stuber.Stub{
+		Input: stuber.InputData{
+			Equals:  map[string]interface{}{"name": "Bob"},
+			Matches: map[string]interface{}{"email": ".+"},
+		},
+	})
+	require.Equal(t, map[string]interface{}{"name": "Bob"}, exact.Data)
+}
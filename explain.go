@@ -0,0 +1,234 @@
+package stuber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+)
+
+// RankComponents are the parts of the rank of a stub for a query.
+type RankComponents struct {
+	Data    float64 `json:"data"`    // The rank of the input data matchers.
+	Headers float64 `json:"headers"` // The rank of the header matchers.
+	Counts  float64 `json:"counts"`  // The rank of the size and message count bounds.
+	Total   float64 `json:"total"`   // The rank used by the search, which a custom RankFunc may compute differently.
+}
+
+// CandidateExplanation describes why a stub matched a query or not.
+type CandidateExplanation struct {
+	Stub     uuid.UUID      `json:"stub"`              // The ID of the stub.
+	Priority int            `json:"priority"`          // The priority of the stub.
+	Matched  bool           `json:"matched"`           // Whether the matchers of the stub match the query.
+	Found    bool           `json:"found"`             // Whether the stub answers the query.
+	Reasons  []string       `json:"reasons,omitempty"` // Why the stub cannot answer the query, besides its matchers.
+	Diff     []FieldDiff    `json:"diff,omitempty"`    // The differences between the query and the matchers of the stub.
+	Rank     RankComponents `json:"rank"`              // The rank of the stub for the query.
+}
+
+// Explanation is the breakdown of the search of a query, stub by stub.
+type Explanation struct {
+	Service    string                 `json:"service"`           // The service of the query.
+	Method     string                 `json:"method"`            // The method of the query.
+	Found      *uuid.UUID             `json:"found,omitempty"`   // The stub answering the query, if any.
+	Similar    *uuid.UUID             `json:"similar,omitempty"` // The most similar stub, if none answers.
+	Candidates []CandidateExplanation `json:"candidates"`        // The stubs of the method, in their canonical order.
+}
+
+// ExplainQuery searches the query and explains, for each Stub value of its
+// service and method, why it matched or not: the fields diverging from its
+// matchers, its unmet dependencies, scenario state or order in its group,
+// and the components of its rank.
+//
+// The search is internal: it doesn't mark Stub values as used, move the
+// scenarios or trigger rate limits, chaos, hooks or the remote source.
+//
+// Parameters:
+// - query: The Query to explain.
+//
+// Returns:
+// - *Explanation: The breakdown of the search.
+// - error: ErrServiceNotFound or ErrMethodNotFound if there are no Stub
+// values for the service or the method of the query.
+func (b *Budgerigar) ExplainQuery(query Query) (*Explanation, error) {
+	return b.ExplainQueryContext(context.Background(), query)
+}
+
+// ExplainQueryContext is ExplainQuery stopping once the given context is
+// done, and tracing the explanation within the span of the context, with the
+// Tracer set by WithTracer, as a SpanExplain span.
+//
+// Parameters:
+// - ctx: The context of the explanation, holding the parent span, if any.
+// - query: The Query to explain.
+//
+// Returns:
+// - *Explanation: The breakdown of the search.
+// - error: ErrServiceNotFound or ErrMethodNotFound if there are no Stub
+// values for the service or the method of the query, or the error of the
+// context.
+func (b *Budgerigar) ExplainQueryContext(ctx context.Context, query Query) (*Explanation, error) {
+	if b.tracer == nil {
+		return b.explainQuery(ctx, query)
+	}
+
+	ctx, span := b.tracer.Start(ctx, SpanExplain)
+
+	explanation, err := b.explainQuery(ctx, query)
+
+	attrs := queryAttributes(query)
+	if explanation != nil {
+		attrs = append(attrs,
+			AttrCandidates.Int(len(explanation.Candidates)),
+			AttrFound.Bool(explanation.Found != nil),
+		)
+	}
+
+	endSpan(span, attrs, err)
+
+	return explanation, err
+}
+
+// explainQuery explains the query, checking the context between the Stub
+// values, as explaining a large method takes a while.
+func (b *Budgerigar) explainQuery(ctx context.Context, query Query) (*Explanation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query = b.canonicalQuery(query)
+
+	stubs, err := b.searcher.findBy(query.Service, query.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := []features.Flag{RequestInternalFlag}
+	if query.ExactOnly() {
+		flags = append(flags, RequestExactFlag)
+	}
+
+	internal := query
+	internal.toggles = features.New(flags...)
+
+	explanation := &Explanation{
+		Service:    query.Service,
+		Method:     query.Method,
+		Candidates: make([]CandidateExplanation, 0, len(stubs)),
+	}
+
+	// Without any similar stub, the search finds nothing but explains as well.
+	result, err := b.searcher.find(internal)
+	if err != nil && !errors.Is(err, ErrStubNotFound) {
+		return nil, err
+	}
+
+	if err == nil && result.found != nil {
+		explanation.Found = &result.found.ID
+	} else if err == nil && result.similar != nil {
+		explanation.Similar = &result.similar.ID
+	}
+
+	query = normalizeQuery(query)
+
+	for _, stub := range SortStubs(stubs) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		candidate := b.searcher.explain(query, stub)
+		candidate.Found = explanation.Found != nil && *explanation.Found == stub.ID
+
+		explanation.Candidates = append(explanation.Candidates, candidate)
+	}
+
+	return explanation, nil
+}
+
+// explain describes why the given Stub value matches the normalized query
+// or not.
+func (s *searcher) explain(query Query, stub *Stub) CandidateExplanation {
+	explanation := CandidateExplanation{Stub: stub.ID, Priority: stub.Priority}
+
+	if stub.Abstract {
+		explanation.Reasons = []string{"abstract stubs never match"}
+
+		return explanation
+	}
+
+	current := s.evaluate(query, stub)
+	if !current.valid {
+		explanation.Reasons = []string{"evaluation panicked"}
+
+		return explanation
+	}
+
+	resolved, matcher := s.prepare(stub)
+
+	explanation.Matched = current.matched
+	explanation.Diff = diffStub(query, resolved)
+	explanation.Reasons = s.blockers(query, resolved, matcher)
+
+	data, headers, counts := rankParts(query, matcher)
+	explanation.Rank = RankComponents{Data: data, Headers: headers, Counts: counts, Total: current.rank}
+
+	return explanation
+}
+
+// blockers returns why the given Stub value cannot answer the query, besides
+// the differences with its data and header matchers.
+func (s *searcher) blockers(query Query, stub, matcher *Stub) []string {
+	var reasons []string
+
+	if stub.enabled(StrictFields, s.toggles) && !strictFields(query, matcher) {
+		reasons = append(reasons, "the query has fields the stub does not mention, rejected by strict fields")
+	}
+
+	if len(stub.Size) > 0 && !stub.Size.Match(payloadSize(query)) {
+		reasons = append(reasons, fmt.Sprintf("the size %d of the query is out of bounds", payloadSize(query)))
+	}
+
+	if !stub.MessageCount.Match(query.MessageCount) {
+		reasons = append(reasons, fmt.Sprintf("the message count %d of the query is out of bounds", query.MessageCount))
+	}
+
+	for _, id := range stub.DependsOn {
+		if !s.isUsed(id) {
+			reasons = append(reasons, fmt.Sprintf("dependency %s has not been used", id))
+		}
+	}
+
+	if stub.RequiredState != "" {
+		s.mu.RLock()
+		state := s.scenarioState(stub.Scenario)
+		s.mu.RUnlock()
+
+		if state != stub.RequiredState {
+			reasons = append(reasons, fmt.Sprintf(
+				"scenario %q is in state %q, not %q", stub.Scenario, state, stub.RequiredState,
+			))
+		}
+	}
+
+	if stub.MaxCalls > 0 {
+		s.mu.RLock()
+		exhausted := s.exhausted(stub)
+		s.mu.RUnlock()
+
+		if exhausted {
+			reasons = append(reasons, fmt.Sprintf("the stub was already matched its maximum of %d times", stub.MaxCalls))
+		}
+	}
+
+	if stub.OrderedGroup != "" {
+		if head := s.groupHead(stub.OrderedGroup); head != stub.ID {
+			reasons = append(reasons, fmt.Sprintf(
+				"stub %s is next in ordered group %q", head, stub.OrderedGroup,
+			))
+		}
+	}
+
+	return reasons
+}
@@ -0,0 +1,77 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_ExplainQuery(t *testing.T) {
+	s := stuber.New()
+
+	first := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: stuber.InputHeader{Equals: map[string]interface{}{"x-tenant": "acme"}},
+		Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+	}
+	dependent := &stuber.Stub{
+		ID:        uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		Priority:  1,
+		Input:     stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		DependsOn: []uuid.UUID{first.ID},
+	}
+	fallback := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"lang": "en"}},
+	}
+	s.PutMany(first, dependent, fallback)
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: map[string]interface{}{"x-tenant": "globex"},
+		Data:    map[string]interface{}{"name": "Bob", "lang": "en"},
+	}
+
+	explanation, err := s.ExplainQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, &fallback.ID, explanation.Found)
+	require.Len(t, explanation.Candidates, 3)
+
+	require.Equal(t, dependent.ID, explanation.Candidates[0].Stub)
+	require.True(t, explanation.Candidates[0].Matched)
+	require.False(t, explanation.Candidates[0].Found)
+	require.Equal(t, []string{"dependency " + first.ID.String() + " has not been used"}, explanation.Candidates[0].Reasons)
+
+	require.Equal(t, first.ID, explanation.Candidates[1].Stub)
+	require.False(t, explanation.Candidates[1].Matched)
+	require.Equal(t, []stuber.FieldDiff{{
+		Field:    "headers.x-tenant",
+		Matcher:  stuber.MatcherEquals,
+		Reason:   stuber.DiffMismatch,
+		Expected: "acme",
+		Actual:   "globex",
+	}}, explanation.Candidates[1].Diff)
+	require.Positive(t, explanation.Candidates[1].Rank.Data)
+
+	require.Equal(t, fallback.ID, explanation.Candidates[2].Stub)
+	require.True(t, explanation.Candidates[2].Matched)
+	require.True(t, explanation.Candidates[2].Found)
+	require.Empty(t, explanation.Candidates[2].Reasons)
+	require.Equal(t, explanation.Candidates[2].Rank.Data, explanation.Candidates[2].Rank.Total)
+
+	// The explanation doesn't mark the found stub as used.
+	require.Empty(t, s.Used())
+
+	_, err = s.ExplainQuery(stuber.Query{Service: "Greeter", Method: "SayGoodbye"})
+	require.ErrorIs(t, err, stuber.ErrMethodNotFound)
+}
@@ -0,0 +1,88 @@
+package stuber
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// SortStubs sorts the given Stub values in place in their canonical order
+// and returns them: by service, by method, by decreasing priority, and then
+// by ID.
+//
+// The canonical order is independent of the insertion order and of the
+// internal storage, which makes listings and exported files diff-stable.
+//
+// Parameters:
+// - stubs: The Stub values to sort.
+//
+// Returns:
+// - []*Stub: The sorted Stub values.
+func SortStubs(stubs []*Stub) []*Stub {
+	slices.SortFunc(stubs, func(a, b *Stub) int {
+		return cmp.Or(
+			cmp.Compare(a.Service, b.Service),
+			cmp.Compare(a.Method, b.Method),
+			cmp.Compare(b.Priority, a.Priority),
+			cmp.Compare(a.ID.String(), b.ID.String()),
+		)
+	})
+
+	return stubs
+}
+
+// listing returns the given Stub values sorted in their canonical order if
+// the listings of the Budgerigar are sorted.
+func (b *Budgerigar) listing(stubs []*Stub) []*Stub {
+	if b.sorted {
+		return SortStubs(stubs)
+	}
+
+	return stubs
+}
+
+// Export returns the JSON encoding of all the Stub values, indented and in
+// their canonical order, which can be imported back with Import. The Stub
+// values are stamped with the current SchemaVersion, and their fields are
+// masked by the rules of WithRedaction, if any.
+//
+// The timestamps of the Stub values are left out, so exporting the same
+// stubs gives the same file, unless WithExportedTimestamps is used.
+//
+// Returns:
+// - []byte: The JSON list of the Stub values.
+// - error: An error if a Stub value cannot be encoded or redacted.
+func (b *Budgerigar) Export() ([]byte, error) {
+	return b.export(b.redaction, b.timestamps)
+}
+
+// export returns the JSON encoding of all the Stub values, with the fields of
+// the given rules masked and with their timestamps or not.
+func (b *Budgerigar) export(rules []RedactionRule, timestamps bool) ([]byte, error) {
+	stubs := SortStubs(b.searcher.all())
+
+	// The redacted Stub values are kept as encoded.
+	exported := make([]any, len(stubs))
+	for i, stub := range stubs {
+		copied := *stub
+		copied.SchemaVersion = SchemaVersion
+
+		if !timestamps {
+			copied.CreatedAt, copied.UpdatedAt = nil, nil
+		}
+
+		exported[i] = copied
+
+		if len(rules) > 0 {
+			redacted, err := redact(rules, copied)
+			if err != nil {
+				return nil, fmt.Errorf("stub %s: %w", stub.ID, err)
+			}
+
+			exported[i] = redacted
+		}
+	}
+
+	return json.MarshalIndent(exported, "", "  ")
+}
@@ -0,0 +1,96 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func exportStubs() []*stuber.Stub {
+	stub := func(id, service, method string, priority int) *stuber.Stub {
+		return &stuber.Stub{
+			ID:       uuid.MustParse(id),
+			Service:  service,
+			Method:   method,
+			Priority: priority,
+			Input:    stuber.InputData{Equals: map[string]interface{}{"id": id}},
+		}
+	}
+
+	return []*stuber.Stub{
+		stub("00000000-0000-0000-0000-000000000004", "Greeter", "SayHello", 0),
+		stub("00000000-0000-0000-0000-000000000001", "Greeter", "SayHello", 0),
+		stub("00000000-0000-0000-0000-000000000003", "Greeter", "SayHello", 1),
+		stub("00000000-0000-0000-0000-000000000002", "Greeter", "Bye", 0),
+		stub("00000000-0000-0000-0000-000000000005", "Admin", "Reset", 0),
+	}
+}
+
+func TestSortStubs(t *testing.T) {
+	var ids []string
+	for _, stub := range stuber.SortStubs(exportStubs()) {
+		ids = append(ids, stub.ID.String()[35:])
+	}
+
+	require.Equal(t, []string{"5", "2", "3", "1", "4"}, ids)
+}
+
+func TestBudgerigar_Export(t *testing.T) {
+	clock := stuber.WithClock(func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) })
+
+	stubs := exportStubs()
+
+	first := stuber.New(clock)
+	first.PutMany(stubs...)
+
+	// The timestamps of the stubs are left out of the exports.
+	second := stuber.New()
+	for i := len(stubs) - 1; i >= 0; i-- {
+		clone := *stubs[i]
+		clone.CreatedAt = nil
+		second.PutMany(&clone)
+	}
+
+	data, err := first.Export()
+	require.NoError(t, err)
+
+	other, err := second.Export()
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(other))
+
+	imported := stuber.New(clock)
+	_, err = imported.Import(data)
+	require.NoError(t, err)
+
+	again, err := imported.Export()
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(again))
+
+	empty, err := stuber.New().Export()
+	require.NoError(t, err)
+	require.Equal(t, "[]", string(empty))
+}
+
+func TestNew_WithExportedTimestamps(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }), stuber.WithExportedTimestamps())
+	s.PutMany(exportStubs()[0])
+
+	data, err := s.Export()
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"createdAt": "2024-01-01T00:00:00Z"`)
+	require.Contains(t, string(data), `"updatedAt": "2024-01-01T00:00:00Z"`)
+}
+
+func TestNew_WithSortedListings(t *testing.T) {
+	s := stuber.New(stuber.WithSortedListings())
+	s.PutMany(exportStubs()...)
+
+	require.Equal(t, stuber.SortStubs(exportStubs())[0].ID, s.All()[0].ID)
+	require.Equal(t, stuber.SortStubs(exportStubs())[4].ID, s.Unused()[4].ID)
+}
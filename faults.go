@@ -0,0 +1,186 @@
+package stuber
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Error messages of the injected faults.
+const (
+	defaultFaultMessage = "fault: injected error"
+	faultAbortMessage   = "fault: call aborted"
+	faultResetMessage   = "fault: connection reset by peer"
+)
+
+// LatencyDistribution is the distribution of the latency of a LatencyConfig.
+type LatencyDistribution string
+
+// Distributions of a LatencyConfig.
+const (
+	LatencyFixed       LatencyDistribution = "fixed"       // Always the mean.
+	LatencyNormal      LatencyDistribution = "normal"      // Normal around the mean, with the standard deviation.
+	LatencyExponential LatencyDistribution = "exponential" // Exponential with the mean, for long tails.
+)
+
+// LatencyConfig describes the latency added to the delay of the output of a
+// stub, drawn from a distribution for each match.
+type LatencyConfig struct {
+	Distribution LatencyDistribution `json:"distribution,omitempty"` // The distribution, LatencyFixed if empty.
+	Mean         Duration            `json:"mean"`                   // The mean latency.
+	StdDev       Duration            `json:"stdDev,omitempty"`       // The standard deviation of the normal distribution.
+	Max          Duration            `json:"max,omitempty"`          // The latency cap, none if zero.
+}
+
+// FaultConfig describes the faults injected into the matches of a stub by
+// the FaultInjector of the Budgerigar.
+//
+// The rates are fractions from 0 to 1 of the matches. A match is reset,
+// aborted or failed, in this order of precedence, and its latency is added
+// to the delay of its output in all cases.
+type FaultConfig struct {
+	ErrorRate    float64        `json:"errorRate,omitempty"`    // The fraction of the matches replaced by an error.
+	ErrorCode    *codes.Code    `json:"errorCode,omitempty"`    // The status code of the errors, codes.Unavailable if unset.
+	ErrorMessage string         `json:"errorMessage,omitempty"` // The message of the errors.
+	AbortRate    float64        `json:"abortRate,omitempty"`    // The fraction of the matches aborted with codes.Aborted.
+	ResetRate    float64        `json:"resetRate,omitempty"`    // The fraction of the matches failing like a reset connection, with codes.Unavailable.
+	Latency      *LatencyConfig `json:"latency,omitempty"`      // The latency added to the delay of the output.
+}
+
+// FaultInjector injects the faults of the FaultConfig of the matched stubs
+// into their outputs, after the matching.
+//
+// The zero value draws from the global generator. A FaultInjector is safe
+// for concurrent use.
+type FaultInjector struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewFaultInjector creates a FaultInjector drawing from a generator seeded
+// by the given seed, so the same sequence of matches injects the same
+// faults across runs.
+//
+// Parameters:
+// - seed: The seed of the generator.
+//
+// Returns:
+// - *FaultInjector: The new injector.
+func NewFaultInjector(seed uint64) *FaultInjector {
+	return &FaultInjector{rng: rand.New(rand.NewPCG(seed, seed))} //nolint:gosec
+}
+
+// Inject returns the stub unchanged if it has no faults, otherwise a copy of
+// the stub with its faults injected into its output.
+//
+// Parameters:
+// - stub: The matched stub.
+//
+// Returns:
+// - *Stub: The stub with the injected faults.
+func (f *FaultInjector) Inject(stub *Stub) *Stub {
+	faults := stub.Faults
+	if faults == nil {
+		return stub
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := stub.Output
+
+	var (
+		code    codes.Code
+		message string
+	)
+
+	// Draw once, so the rates of the faults add up.
+	switch draw := f.uniform(); {
+	case draw < faults.ResetRate:
+		code, message = codes.Unavailable, faultResetMessage
+	case draw < faults.ResetRate+faults.AbortRate:
+		code, message = codes.Aborted, faultAbortMessage
+	case draw < faults.ResetRate+faults.AbortRate+faults.ErrorRate:
+		code, message = codes.Unavailable, defaultFaultMessage
+
+		if faults.ErrorCode != nil {
+			code = *faults.ErrorCode
+		}
+
+		if faults.ErrorMessage != "" {
+			message = faults.ErrorMessage
+		}
+	}
+
+	if message != "" {
+		output = Output{Headers: stub.Output.Headers, Error: message, Code: &code, Delay: output.Delay}
+	}
+
+	if faults.Latency != nil {
+		output.Delay += Duration(f.latency(faults.Latency))
+	}
+
+	return stub.withOutput(output)
+}
+
+// latency draws a latency from the distribution of the config.
+//
+// The mutex must be held.
+func (f *FaultInjector) latency(config *LatencyConfig) time.Duration {
+	mean := float64(config.Mean)
+
+	var latency float64
+
+	switch config.Distribution {
+	case LatencyNormal:
+		latency = mean + f.normal()*float64(config.StdDev)
+	case LatencyExponential:
+		latency = f.exponential() * mean
+	default:
+		latency = mean
+	}
+
+	latency = max(latency, 0)
+
+	if config.Max > 0 {
+		latency = min(latency, float64(config.Max))
+	}
+
+	return time.Duration(latency)
+}
+
+// uniform returns a random number in [0, 1).
+//
+// The mutex must be held.
+func (f *FaultInjector) uniform() float64 {
+	if f.rng == nil {
+		return rand.Float64() //nolint:gosec
+	}
+
+	return f.rng.Float64()
+}
+
+// normal returns a random number of the standard normal distribution.
+//
+// The mutex must be held.
+func (f *FaultInjector) normal() float64 {
+	if f.rng == nil {
+		return rand.NormFloat64() //nolint:gosec
+	}
+
+	return f.rng.NormFloat64()
+}
+
+// exponential returns a random number of the exponential distribution with
+// a mean of 1.
+//
+// The mutex must be held.
+func (f *FaultInjector) exponential() float64 {
+	if f.rng == nil {
+		return rand.ExpFloat64() //nolint:gosec
+	}
+
+	return f.rng.ExpFloat64()
+}
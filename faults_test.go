@@ -0,0 +1,114 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestFaultInjector_Rates(t *testing.T) {
+	injector := stuber.NewFaultInjector(7)
+
+	stub := &stuber.Stub{
+		ID:     uuid.New(),
+		Output: stuber.Output{Data: map[string]interface{}{"ok": true}},
+		Faults: &stuber.FaultConfig{ResetRate: 0.1, AbortRate: 0.2, ErrorRate: 0.3},
+	}
+
+	counts := make(map[string]int)
+
+	for range 10000 {
+		output := injector.Inject(stub).Output
+
+		switch {
+		case output.Code == nil:
+			counts["ok"]++
+		case *output.Code == codes.Aborted:
+			counts["abort"]++
+		case output.Error == "fault: connection reset by peer":
+			counts["reset"]++
+		default:
+			require.Equal(t, codes.Unavailable, *output.Code)
+			counts["error"]++
+		}
+	}
+
+	require.InDelta(t, 1000, counts["reset"], 200)
+	require.InDelta(t, 2000, counts["abort"], 200)
+	require.InDelta(t, 3000, counts["error"], 200)
+	require.InDelta(t, 4000, counts["ok"], 200)
+
+	// The stored stub is left untouched.
+	require.Empty(t, stub.Output.Error)
+}
+
+func TestFaultInjector_Latency(t *testing.T) {
+	injector := stuber.NewFaultInjector(7)
+
+	latency := func(config stuber.LatencyConfig) []time.Duration {
+		stub := &stuber.Stub{
+			Output: stuber.Output{Delay: stuber.Duration(time.Second)},
+			Faults: &stuber.FaultConfig{Latency: &config},
+		}
+
+		delays := make([]time.Duration, 2000)
+		for i := range delays {
+			delays[i] = injector.Inject(stub).Output.Delay.Std() - time.Second
+		}
+
+		return delays
+	}
+
+	mean := func(delays []time.Duration) time.Duration {
+		var sum time.Duration
+		for _, delay := range delays {
+			sum += delay
+		}
+
+		return sum / time.Duration(len(delays))
+	}
+
+	fixed := latency(stuber.LatencyConfig{Mean: stuber.Duration(50 * time.Millisecond)})
+	require.Equal(t, 50*time.Millisecond, fixed[0])
+
+	normal := latency(stuber.LatencyConfig{
+		Distribution: stuber.LatencyNormal,
+		Mean:         stuber.Duration(100 * time.Millisecond),
+		StdDev:       stuber.Duration(10 * time.Millisecond),
+	})
+	require.InDelta(t, float64(100*time.Millisecond), float64(mean(normal)), float64(2*time.Millisecond))
+
+	exponential := latency(stuber.LatencyConfig{
+		Distribution: stuber.LatencyExponential,
+		Mean:         stuber.Duration(100 * time.Millisecond),
+		Max:          stuber.Duration(300 * time.Millisecond),
+	})
+	require.InDelta(t, float64(95*time.Millisecond), float64(mean(exponential)), float64(10*time.Millisecond))
+
+	for _, delay := range exponential {
+		require.LessOrEqual(t, delay, 300*time.Millisecond)
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+	}
+}
+
+func TestBudgerigar_Faults(t *testing.T) {
+	code := codes.Internal
+
+	s := stuber.New(stuber.WithFaultInjector(stuber.NewFaultInjector(1)))
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Orders",
+		Method:  "Create",
+		Faults:  &stuber.FaultConfig{ErrorRate: 1, ErrorCode: &code, ErrorMessage: "boom"},
+	})
+
+	result, err := s.FindByQuery(stuber.Query{Service: "Orders", Method: "Create"})
+	require.NoError(t, err)
+	require.Equal(t, "boom", result.Found().Output.Error)
+	require.Equal(t, codes.Internal, *result.Found().Output.Code)
+}
@@ -0,0 +1,79 @@
+package stuber
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bavix/features"
+	"golang.org/x/exp/maps"
+)
+
+// ErrUnknownFlag is returned when a feature flag name is not registered.
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// flagNames maps the stable names of the feature flags to the flags.
+//
+// The names are part of the public API: they must never be renamed.
+var flagNames = map[string]features.Flag{ //nolint:gochecknoglobals
+	"method-title":  MethodTitle,
+	"import-env":    ImportEnv,
+	"strict-fields": StrictFields,
+	"lower-headers": LowerHeaders,
+	"strict-query":  StrictQuery,
+}
+
+// FlagNames returns the sorted names of all the feature flags.
+//
+// Returns:
+// - []string: The names of the feature flags.
+func FlagNames() []string {
+	names := maps.Keys(flagNames)
+	slices.Sort(names)
+
+	return names
+}
+
+// FlagName returns the name of the given feature flag.
+//
+// Parameters:
+// - flag: The feature flag.
+//
+// Returns:
+// - string: The name of the flag, or an empty string if it is not registered.
+func FlagName(flag features.Flag) string {
+	for name, f := range flagNames {
+		if f == flag {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// ParseFlags returns the feature flags with the given names.
+//
+// Names are case-insensitive and surrounding spaces are ignored, so they can
+// be read from configuration files as is.
+//
+// Parameters:
+// - names: The names of the feature flags.
+//
+// Returns:
+// - []features.Flag: The feature flags.
+// - error: An error wrapping ErrUnknownFlag if a name is not registered.
+func ParseFlags(names []string) ([]features.Flag, error) {
+	flags := make([]features.Flag, 0, len(names))
+
+	for _, name := range names {
+		flag, ok := flagNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+		}
+
+		flags = append(flags, flag)
+	}
+
+	return flags, nil
+}
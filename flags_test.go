@@ -0,0 +1,110 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/bavix/features"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestParseFlags(t *testing.T) {
+	flags, err := stuber.ParseFlags([]string{"method-title", " Import-Env "})
+	require.NoError(t, err)
+	require.Equal(t, []features.Flag{stuber.MethodTitle, stuber.ImportEnv}, flags)
+
+	_, err = stuber.ParseFlags([]string{"method-title", "strict-fieldz"})
+	require.ErrorIs(t, err, stuber.ErrUnknownFlag)
+
+	require.Equal(t, "method-title", stuber.FlagName(stuber.MethodTitle))
+	require.Contains(t, stuber.FlagNames(), "import-env")
+
+	for _, name := range stuber.FlagNames() {
+		flags, err := stuber.ParseFlags([]string{name})
+		require.NoError(t, err)
+		require.Equal(t, name, stuber.FlagName(flags[0]))
+	}
+}
+
+func TestStub_FlagsOverride(t *testing.T) {
+	lenient := &stuber.Stub{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "lenient"}},
+	}
+
+	strict := &stuber.Stub{
+		Service: "Greeter",
+		Method:  "SayBye",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		Flags:   map[string]bool{"strict-fields": true},
+	}
+
+	s := stuber.New()
+	s.PutMany(lenient, strict)
+
+	data := map[string]interface{}{"name": "Bob", "age": 42}
+
+	r, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: data})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+
+	r, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayBye", Data: data})
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+
+	// The stub may also opt out of a flag enabled globally.
+	lenient.Flags = map[string]bool{"strict-fields": false}
+
+	s = stuber.New(stuber.WithFlags(stuber.StrictFields))
+	s.PutMany(lenient, strict)
+
+	r, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: data})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+
+	r, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayBye", Data: map[string]interface{}{"name": "Bob"}})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+}
+
+func TestBudgerigar_LowerHeaders(t *testing.T) {
+	newStub := func() *stuber.Stub {
+		return &stuber.Stub{
+			Service: "Greeter",
+			Method:  "SayHello",
+			Headers: stuber.InputHeader{Equals: map[string]interface{}{"Authorization": "token"}},
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		}
+	}
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: map[string]interface{}{"authorization": "token"},
+		Data:    map[string]interface{}{"name": "Bob"},
+	}
+
+	s := stuber.New()
+	s.PutMany(newStub())
+
+	r, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+
+	s = stuber.New(stuber.WithFlags(stuber.LowerHeaders))
+	s.PutMany(newStub())
+
+	r, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+
+	query.Headers = map[string]interface{}{"AUTHORIZATION": "token"}
+
+	r, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.Contains(t, query.Headers, "AUTHORIZATION")
+}
@@ -0,0 +1,709 @@
+package stuber
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// ErrDivisionByZero is returned by the div and mod template functions when
+// the divisor is zero and strict template math is enabled.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// errNotNumber is returned when an arithmetic template function is given a
+// value that is not a number.
+var errNotNumber = errors.New("not a number")
+
+// TemplateFunctions returns the functions available to the templates of the
+// outputs, by name. The map is a copy that can be modified freely.
+//
+// The string functions taking other arguments than the string take it last,
+// to be used in pipelines such as {{ .Request.name | replace "-" "_" }}:
+// - replace: replaces all the occurrences of a string by another one.
+// - substr: the runes from a start index to an end index, clamped to the
+// bounds of the string.
+// - padLeft, padRight: pads to a width in runes with a pad string.
+// - contains, hasPrefix, hasSuffix: whether the string contains, starts or
+// ends with another one.
+// - regexReplace: replaces the matches of a regular expression, with $1
+// expanding to the first submatch.
+// - regexFind: the first match of a regular expression, or "".
+//
+// The number formatting functions return strings, the same whatever the
+// locale of the server, with a dot as the decimal separator:
+// - formatFloat: a number with a fixed number of decimals, or the fewest
+// decimals needed to represent it if the precision is negative.
+// - percent: a ratio as a percentage, such as 12.5% for 0.125, with an
+// optional number of decimals.
+// - currency: a number with two decimals, or the given number of decimals,
+// and commas grouping the thousands, such as 1,234.50.
+//
+// The collection functions accept slices of any type, such as the received
+// messages of a client stream, and select the fields of their items with a
+// path traversing maps by key and arrays by index, such as "user.name" or
+// "items[0].id":
+// - first, last: the first or last item, or nil if there is none.
+// - slice: the items from a start index to an optional end index, clamped
+// to the bounds of the collection.
+// - reverse: the items in reverse order.
+// - sortBy: the items sorted by a field, numerically if it holds numbers.
+// - uniq: the items without the duplicates.
+// - groupBy: the items grouped by the string form of a field.
+// - mapField: the values of a field of the items.
+// - extract: the same as mapField, with the items first.
+//
+// The functions taking a field take it first, to be used in pipelines such
+// as {{ .Requests | sortBy "price" | first }}.
+//
+// The arithmetic functions accept numbers of any type, such as the
+// json.Number values of the requests, and return a json.Number. Integers
+// are computed exactly as long as they fit in an int64, and integral results
+// are formatted without a fraction, so {{ add .Request.id 1 }} renders 43
+// rather than 43.0 or 4.3e+01. A division by zero returns zero, unless strict
+// template math is enabled with WithStrictTemplateMath.
+//
+// Returns:
+// - template.FuncMap: The template functions.
+func TemplateFunctions() template.FuncMap {
+	return templateFunctions(time.Now, false)
+}
+
+// templateFunctions returns the template functions, with the given clock.
+//
+// With strict math, div and mod return ErrDivisionByZero instead of zero.
+func templateFunctions(now func() time.Time, strictMath bool) template.FuncMap {
+	return template.FuncMap{
+		// Strings.
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"title":   titleString,
+		"trim":    strings.TrimSpace,
+		"split":   strings.Split,
+		"join":    joinValues,
+		"sprintf": fmt.Sprintf,
+		"json":    toJSON,
+
+		"replace":      replaceString,
+		"substr":       substring,
+		"padLeft":      padLeft,
+		"padRight":     padRight,
+		"contains":     containsString,
+		"hasPrefix":    hasPrefix,
+		"hasSuffix":    hasSuffix,
+		"regexReplace": regexReplace,
+		"regexFind":    regexFind,
+
+		// Time.
+		"now":    now,
+		"unix":   func(t time.Time) int64 { return t.Unix() },
+		"format": func(t time.Time, layout string) string { return t.Format(layout) },
+
+		// Randomness, seeded by seededFunctions.
+		"uuid":   uuid.NewString,
+		"random": randomInt,
+
+		// Arithmetic.
+		"add":   arithmetic(addInt, func(a, b float64) float64 { return a + b }),
+		"sub":   arithmetic(subInt, func(a, b float64) float64 { return a - b }),
+		"mul":   arithmetic(mulInt, func(a, b float64) float64 { return a * b }),
+		"div":   division(strictMath, nil, divFloat),
+		"mod":   division(strictMath, modInt, math.Mod),
+		"pow":   arithmetic(nil, math.Pow),
+		"abs":   absNumber,
+		"clamp": clampNumber,
+		"round": roundNumber,
+
+		// Number formatting.
+		"formatFloat": formatFloat,
+		"percent":     formatPercent,
+		"currency":    formatCurrency,
+
+		// Collections.
+		"first":    firstItem,
+		"last":     lastItem,
+		"slice":    sliceItems,
+		"reverse":  reverseItems,
+		"sortBy":   sortItemsBy,
+		"uniq":     uniqItems,
+		"groupBy":  groupItemsBy,
+		"mapField": mapItemsField,
+		"extract":  extractItems,
+	}
+}
+
+// titleString title cases the string. A Caser keeps state, so a new one is
+// used by each call, the templates being rendered concurrently.
+func titleString(s string) string {
+	return cases.Title(language.English).String(s)
+}
+
+func replaceString(old, replacement, s string) string {
+	return strings.ReplaceAll(s, old, replacement)
+}
+
+func substring(start, end int, s string) string {
+	runes := []rune(s)
+
+	end = min(max(end, 0), len(runes))
+	start = min(max(start, 0), end)
+
+	return string(runes[start:end])
+}
+
+func padLeft(width int, pad, s string) string {
+	return padding(width, pad, s) + s
+}
+
+func padRight(width int, pad, s string) string {
+	return s + padding(width, pad, s)
+}
+
+// padding returns the pad string repeated to pad the string to the width.
+func padding(width int, pad, s string) string {
+	missing := width - utf8.RuneCountInString(s)
+	if missing <= 0 || pad == "" {
+		return ""
+	}
+
+	runes := []rune(strings.Repeat(pad, missing/utf8.RuneCountInString(pad)+1))
+
+	return string(runes[:missing])
+}
+
+func containsString(substr, s string) bool {
+	return strings.Contains(s, substr)
+}
+
+func hasPrefix(prefix, s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+func hasSuffix(suffix, s string) bool {
+	return strings.HasSuffix(s, suffix)
+}
+
+func regexReplace(expr, replacement, s string) (string, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", err
+	}
+
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+func regexFind(expr, s string) (string, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", err
+	}
+
+	return re.FindString(s), nil
+}
+
+// joinValues joins the string forms of the given values with the separator.
+func joinValues(values []any, sep string) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+
+	return strings.Join(parts, sep)
+}
+
+// toJSON returns the JSON encoding of the given value.
+func toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+
+	return string(data), err
+}
+
+// randomInt returns a random integer in [lo, hi).
+func randomInt(lo, hi int) int {
+	if hi <= lo {
+		return lo
+	}
+
+	return lo + rand.IntN(hi-lo) //nolint:gosec
+}
+
+// seededFunctions returns the random template functions drawing from the
+// given generator, which replace the unseeded ones for reproducible outputs.
+func seededFunctions(rng *rand.Rand) template.FuncMap {
+	return template.FuncMap{
+		"uuid": func() (string, error) {
+			id, err := uuid.NewRandomFromReader(randReader{rng})
+
+			return id.String(), err
+		},
+		"random": func(lo, hi int) int {
+			if hi <= lo {
+				return lo
+			}
+
+			return lo + rng.IntN(hi-lo)
+		},
+	}
+}
+
+// randReader reads random bytes from a generator.
+type randReader struct {
+	rng *rand.Rand
+}
+
+func (r randReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r.rng.Uint32())
+	}
+
+	return len(p), nil
+}
+
+// arithmetic returns a template function applying the given operation to two
+// numbers of any type. The integer operation, if any, is used when both
+// numbers are integers and it does not overflow.
+func arithmetic(
+	ints func(a, b int64) (int64, bool),
+	floats func(a, b float64) float64,
+) func(a, b any) (json.Number, error) {
+	return func(a, b any) (json.Number, error) {
+		x, err := toNumber(a)
+		if err != nil {
+			return "", err
+		}
+
+		y, err := toNumber(b)
+		if err != nil {
+			return "", err
+		}
+
+		if ints != nil {
+			if i, j, ok := bothInts(x, y); ok {
+				if r, ok := ints(i, j); ok {
+					return json.Number(strconv.FormatInt(r, 10)), nil
+				}
+			}
+		}
+
+		f, _ := x.Float64()
+		g, _ := y.Float64()
+
+		return canonicalFloat(floats(f, g)), nil
+	}
+}
+
+// division returns a template function applying the given division to two
+// numbers of any type, which returns zero or ErrDivisionByZero when the
+// divisor is zero.
+func division(
+	strict bool,
+	ints func(a, b int64) (int64, bool),
+	floats func(a, b float64) float64,
+) func(a, b any) (json.Number, error) {
+	divide := arithmetic(ints, floats)
+
+	return func(a, b any) (json.Number, error) {
+		y, err := toNumber(b)
+		if err != nil {
+			return "", err
+		}
+
+		if g, _ := y.Float64(); g == 0 {
+			if strict {
+				return "", ErrDivisionByZero
+			}
+
+			return "0", nil
+		}
+
+		return divide(a, b)
+	}
+}
+
+func addInt(a, b int64) (int64, bool) {
+	r := a + b
+
+	return r, (a >= 0) != (b >= 0) || (r >= 0) == (a >= 0)
+}
+
+func subInt(a, b int64) (int64, bool) {
+	if b == math.MinInt64 {
+		return 0, false
+	}
+
+	return addInt(a, -b)
+}
+
+func mulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	r := a * b
+
+	return r, r/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+}
+
+func divFloat(a, b float64) float64 {
+	return a / b
+}
+
+func modInt(a, b int64) (int64, bool) {
+	return a % b, true
+}
+
+// absNumber returns the absolute value of a number of any type.
+func absNumber(value any) (json.Number, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		return "", err
+	}
+
+	if i, err := n.Int64(); err == nil && i != math.MinInt64 {
+		return json.Number(strconv.FormatInt(max(i, -i), 10)), nil
+	}
+
+	f, _ := n.Float64()
+
+	return canonicalFloat(math.Abs(f)), nil
+}
+
+// clampNumber returns the number of any type limited to the range [lo, hi].
+func clampNumber(value, lo, hi any) (json.Number, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := [2]json.Number{}
+
+	for i, bound := range []any{lo, hi} {
+		if bounds[i], err = toNumber(bound); err != nil {
+			return "", err
+		}
+	}
+
+	f, _ := n.Float64()
+	low, _ := bounds[0].Float64()
+	high, _ := bounds[1].Float64()
+
+	switch {
+	case f < low:
+		return bounds[0], nil
+	case f > high:
+		return bounds[1], nil
+	default:
+		return n, nil
+	}
+}
+
+// roundNumber rounds a number of any type to the given number of decimal
+// places, half away from zero.
+func roundNumber(value any, places int) (json.Number, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := n.Int64(); err == nil && places >= 0 {
+		return n, nil
+	}
+
+	f, _ := n.Float64()
+	scale := math.Pow10(places)
+
+	return canonicalFloat(math.Round(f*scale) / scale), nil
+}
+
+// bothInts returns the given numbers as integers, if they both are.
+func bothInts(a, b json.Number) (int64, int64, bool) {
+	i, err := a.Int64()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	j, err := b.Int64()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return i, j, true
+}
+
+// toNumber converts a number of any type, or its string form, to its
+// canonical json.Number.
+func toNumber(value any) (json.Number, error) {
+	if s, ok := value.(string); ok {
+		value = json.Number(s)
+	}
+
+	n, ok := normalize(value).(json.Number)
+	if !ok {
+		return "", fmt.Errorf("%w: %v", errNotNumber, value)
+	}
+
+	if _, err := n.Float64(); err != nil {
+		return "", fmt.Errorf("%w: %v", errNotNumber, value)
+	}
+
+	return n, nil
+}
+
+func formatFloat(value any, precision int) (string, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		return "", err
+	}
+
+	f, _ := n.Float64()
+
+	return strconv.FormatFloat(f, 'f', precision, 64), nil
+}
+
+func formatPercent(value any, precision ...int) (string, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		return "", err
+	}
+
+	f, _ := n.Float64()
+
+	places := -1
+	if len(precision) > 0 {
+		places = precision[0]
+	}
+
+	// Round the percentage to the shortest form, as 0.07*100 is 7.000000000000001.
+	percent, _ := strconv.ParseFloat(strconv.FormatFloat(f*100, 'g', 15, 64), 64)
+
+	return strconv.FormatFloat(percent, 'f', places, 64) + "%", nil
+}
+
+func formatCurrency(value any, precision ...int) (string, error) {
+	places := 2
+	if len(precision) > 0 {
+		places = max(precision[0], 0)
+	}
+
+	formatted, err := formatFloat(value, places)
+	if err != nil {
+		return "", err
+	}
+
+	sign, digits := "", formatted
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	integer, fraction, _ := strings.Cut(digits, ".")
+
+	var grouped strings.Builder
+
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+
+		grouped.WriteRune(digit)
+	}
+
+	if fraction != "" {
+		return sign + grouped.String() + "." + fraction, nil
+	}
+
+	return sign + grouped.String(), nil
+}
+
+// errNotCollection is returned when a collection template function is given
+// a value that is not a slice.
+var errNotCollection = errors.New("not a collection")
+
+// toList converts a slice or an array of any type to a []any.
+func toList(value any) ([]any, error) {
+	if list, ok := value.([]any); ok {
+		return list, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%w: %T", errNotCollection, value)
+	}
+
+	list := make([]any, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+
+	return list, nil
+}
+
+// fieldOf returns the value at the given path of the given item, or nil.
+//
+// The path traverses maps by key and arrays by index, such as "user.id",
+// "items.0.id" or "items[0].id".
+func fieldOf(item any, path string) any {
+	value, _ := lookupJSONPath(item, parseJSONPath(path))
+
+	return value
+}
+
+func firstItem(value any) (any, error) {
+	list, err := toList(value)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+
+	return list[0], nil
+}
+
+func lastItem(value any) (any, error) {
+	list, err := toList(value)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+
+	return list[len(list)-1], nil
+}
+
+func sliceItems(value any, start int, end ...int) ([]any, error) {
+	list, err := toList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := len(list)
+	if len(end) > 0 {
+		stop = min(max(end[0], 0), stop)
+	}
+
+	start = min(max(start, 0), stop)
+
+	return slices.Clone(list[start:stop]), nil
+}
+
+func reverseItems(value any) ([]any, error) {
+	list, err := toList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	list = slices.Clone(list)
+	slices.Reverse(list)
+
+	return list, nil
+}
+
+func sortItemsBy(field string, value any) ([]any, error) {
+	list, err := toList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	list = slices.Clone(list)
+	slices.SortStableFunc(list, func(a, b any) int {
+		return compareValues(fieldOf(a, field), fieldOf(b, field))
+	})
+
+	return list, nil
+}
+
+// compareValues compares two values numerically if they both are numbers,
+// and by their string forms otherwise. Missing values come first.
+func compareValues(a, b any) int {
+	if a == nil || b == nil {
+		return cmp.Compare(boolInt(a != nil), boolInt(b != nil))
+	}
+
+	x, errA := toNumber(a)
+	y, errB := toNumber(b)
+
+	if errA == nil && errB == nil {
+		f, _ := x.Float64()
+		g, _ := y.Float64()
+
+		return cmp.Compare(f, g)
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func uniqItems(value any) ([]any, error) {
+	list, err := toList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, 0, len(list))
+	seen := make([]any, 0, len(list))
+
+	for _, item := range list {
+		normalized := normalize(item)
+
+		if !slices.ContainsFunc(seen, func(other any) bool { return reflect.DeepEqual(normalized, other) }) {
+			seen = append(seen, normalized)
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
+
+func groupItemsBy(field string, value any) (map[string][]any, error) {
+	list, err := toList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]any)
+
+	for _, item := range list {
+		key := fmt.Sprint(fieldOf(item, field))
+		groups[key] = append(groups[key], item)
+	}
+
+	return groups, nil
+}
+
+func extractItems(value any, path string) ([]any, error) {
+	return mapItemsField(path, value)
+}
+
+func mapItemsField(field string, value any) ([]any, error) {
+	list, err := toList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, len(list))
+	for i, item := range list {
+		result[i] = fieldOf(item, field)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,252 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func render(t *testing.T, s *stuber.Budgerigar, text string, data any) (string, error) {
+	t.Helper()
+
+	tmpl, err := s.ParseTemplate("test", text)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	err = tmpl.Execute(&buf, data)
+
+	return buf.String(), err
+}
+
+func TestTemplateFunctions_Math(t *testing.T) {
+	s := stuber.New()
+
+	data := map[string]interface{}{
+		"id":    json.Number("9007199254740993"),
+		"price": json.Number("2.50"),
+		"count": json.Number("3"),
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{ add .id 1 }}`, "9007199254740994"},
+		{`{{ sub .count 5 }}`, "-2"},
+		{`{{ mul .price .count }}`, "7.5"},
+		{`{{ mul .count 2.0 }}`, "6"},
+		{`{{ div .count 2 }}`, "1.5"},
+		{`{{ div .count 0 }}`, "0"},
+		{`{{ mod .id 10 }}`, "3"},
+		{`{{ mod 7.5 2 }}`, "1.5"},
+		{`{{ mod .count 0 }}`, "0"},
+		{`{{ pow 2 10 }}`, "1024"},
+		{`{{ abs -4.25 }}`, "4.25"},
+		{`{{ abs .id }}`, "9007199254740993"},
+		{`{{ clamp 15 0 10 }}`, "10"},
+		{`{{ clamp -1 0 10 }}`, "0"},
+		{`{{ clamp .price 0 10 }}`, "2.5"},
+		{`{{ round 3.14159 2 }}`, "3.14"},
+		{`{{ round 2.5 0 }}`, "3"},
+		{`{{ round 1234 -2 }}`, "1200"},
+		{`{{ add "1" .count }}`, "4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := render(t, s, tt.text, data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := render(t, s, `{{ add "one" 1 }}`, nil)
+	require.Error(t, err)
+}
+
+func TestNew_WithStrictTemplateMath(t *testing.T) {
+	s := stuber.New(stuber.WithStrictTemplateMath())
+
+	_, err := render(t, s, `{{ div 1 0 }}`, nil)
+	require.ErrorIs(t, err, stuber.ErrDivisionByZero)
+
+	_, err = render(t, s, `{{ mod 1 0.0 }}`, nil)
+	require.ErrorIs(t, err, stuber.ErrDivisionByZero)
+
+	got, err := render(t, s, `{{ div 1 4 }}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, "0.25", got)
+}
+
+func TestTemplateFunctions_Collections(t *testing.T) {
+	s := stuber.New()
+
+	data := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "b", "price": json.Number("10"), "kind": "fruit"},
+			map[string]interface{}{"name": "a", "price": json.Number("9.5"), "kind": "veg"},
+			map[string]interface{}{"name": "c", "price": json.Number("100"), "kind": "fruit"},
+		},
+		"tags": []string{"x", "y", "x", "z"},
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{ first .tags }}`, "x"},
+		{`{{ last .tags }}`, "z"},
+		{`{{ first (slice .tags 9) }}`, "<no value>"},
+		{`{{ join (slice .tags 1 3) "," }}`, "y,x"},
+		{`{{ join (slice .tags 2) "," }}`, "x,z"},
+		{`{{ join (reverse .tags) "," }}`, "z,x,y,x"},
+		{`{{ join (uniq .tags) "," }}`, "x,y,z"},
+		{`{{ join (.items | sortBy "price" | mapField "name") "," }}`, "a,b,c"},
+		{`{{ join (.items | sortBy "name" | mapField "price") "," }}`, "9.5,10,100"},
+		{`{{ len (index (groupBy "kind" .items) "fruit") }}`, "2"},
+		{`{{ (.items | sortBy "price" | last).name }}`, "c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := render(t, s, tt.text, data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := render(t, s, `{{ first .items }}`, map[string]interface{}{"items": 1})
+	require.Error(t, err)
+}
+
+func TestBudgerigar_RenderStreamOutput(t *testing.T) {
+	stub := &stuber.Stub{
+		Output: stuber.Output{
+			Data: map[string]interface{}{
+				"total": `{{ len .Requests }}`,
+				"names": `{{ join (.Requests | mapField "name") "," }}`,
+				"last":  `{{ .Request.name }}`,
+			},
+		},
+	}
+
+	output, err := stuber.New().RenderStreamOutput(stub, nil, []map[string]interface{}{
+		{"name": "Alice"},
+		{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"total": "2",
+		"names": "Alice,Bob",
+		"last":  "Bob",
+	}, output.Data)
+}
+
+func TestTemplateFunctions_Strings(t *testing.T) {
+	s := stuber.New()
+
+	data := map[string]interface{}{"name": "jean-luc picard", "id": "42"}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{ .name | replace "-" "_" }}`, "jean_luc picard"},
+		{`{{ substr 0 4 .name }}`, "jean"},
+		{`{{ substr 9 100 .name }}`, "picard"},
+		{`{{ substr 3 1 "héllo" }}`, ""},
+		{`{{ substr 1 2 "héllo" }}`, "é"},
+		{`{{ padLeft 6 "0" .id }}`, "000042"},
+		{`{{ padRight 5 "ab" .id }}`, "42aba"},
+		{`{{ padLeft 1 "0" .id }}`, "42"},
+		{`{{ contains "luc" .name }}`, "true"},
+		{`{{ hasPrefix "jean" .name }}`, "true"},
+		{`{{ hasSuffix "jean" .name }}`, "false"},
+		{`{{ regexReplace "(\\w+)-(\\w+)" "$2-$1" .name }}`, "luc-jean picard"},
+		{`{{ regexFind "[a-z]+$" .name }}`, "picard"},
+		{`{{ regexFind "[0-9]+" .name }}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := render(t, s, tt.text, data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := render(t, s, `{{ regexFind "(" .name }}`, data)
+	require.Error(t, err)
+}
+
+func TestTemplateFunctions_Extract(t *testing.T) {
+	s := stuber.New()
+
+	data := map[string]interface{}{
+		"messages": []interface{}{
+			map[string]interface{}{
+				"user":  map[string]interface{}{"id": json.Number("1")},
+				"items": []interface{}{map[string]interface{}{"sku": "a"}, map[string]interface{}{"sku": "b"}},
+			},
+			map[string]interface{}{
+				"user":  map[string]interface{}{"id": json.Number("2")},
+				"items": []interface{}{map[string]interface{}{"sku": "c"}},
+			},
+			map[string]interface{}{"user": "anonymous"},
+		},
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{ extract .messages "user.id" }}`, "[1 2 <nil>]"},
+		{`{{ extract .messages "items.0.sku" }}`, "[a c <nil>]"},
+		{`{{ extract .messages "items[1].sku" }}`, "[b <nil> <nil>]"},
+		{`{{ .messages | mapField "items[0].sku" | uniq | len }}`, "3"},
+		{`{{ (.messages | sortBy "user.id" | last).user.id }}`, "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := render(t, s, tt.text, data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTemplateFunctions_NumberFormatting(t *testing.T) {
+	s := stuber.New()
+
+	data := map[string]interface{}{"price": json.Number("1234567.891"), "ratio": json.Number("0.07")}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{ formatFloat .price 2 }}`, "1234567.89"},
+		{`{{ formatFloat .price 0 }}`, "1234568"},
+		{`{{ formatFloat .price -1 }}`, "1234567.891"},
+		{`{{ formatFloat 1e21 1 }}`, "1000000000000000000000.0"},
+		{`{{ percent .ratio }}`, "7%"},
+		{`{{ percent 0.125 }}`, "12.5%"},
+		{`{{ percent 0.125 2 }}`, "12.50%"},
+		{`{{ currency .price }}`, "1,234,567.89"},
+		{`{{ currency -1234.5 }}`, "-1,234.50"},
+		{`{{ currency 999 0 }}`, "999"},
+		{`{{ currency 0.5 }}`, "0.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := render(t, s, tt.text, data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package stuber //nolint:testpackage
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gripmock/deeply"
+)
+
+// decodeFuzzMap decodes a JSON object the way queries are decoded, or
+// returns false if the data is not a JSON object.
+func decodeFuzzMap(data []byte) (map[string]any, bool) {
+	var m map[string]any
+	if err := decodeJSON(data, &m); err != nil || m == nil {
+		return nil, false
+	}
+
+	return m, true
+}
+
+func FuzzEquals(f *testing.F) {
+	f.Add([]byte(`{"name":"Bob"}`), []byte(`{"name":"Bob"}`))
+	f.Add([]byte(`{"name":"Bob","age":1}`), []byte(`{"name":"Bob","age":1.0}`))
+	f.Add([]byte(`{"tags":["a","b"]}`), []byte(`{"tags":["b","a"]}`))
+	f.Add([]byte(`{"a":{"b":{"c":null}}}`), []byte(`{"a":"b"}`))
+
+	f.Fuzz(func(t *testing.T, expectedData, actualData []byte) {
+		expected, ok := decodeFuzzMap(expectedData)
+		if !ok {
+			return
+		}
+
+		actual, ok := decodeFuzzMap(actualData)
+		if !ok {
+			return
+		}
+
+		if !equals(expected, expected, false) {
+			t.Fatalf("%s does not equal itself", expectedData)
+		}
+
+		// The fast path must agree with the deeply package.
+		if result, ok := equalsFlatStrings(expected, actual); ok && result != deeply.Equals(expected, actual) {
+			t.Fatalf("fast path of %s and %s disagrees", expectedData, actualData)
+		}
+
+		equals(expected, actual, true)
+		contains(expected, actual, false)
+		matches(expected, actual, false)
+	})
+}
+
+func FuzzMatch(f *testing.F) {
+	f.Add([]byte(`{"input":{"equals":{"name":"Bob"}}}`), []byte(`{"data":{"name":"Bob"}}`))
+	f.Add([]byte(`{"input":{"matches":{"name":"^B(o+)b$"}}}`), []byte(`{"data":{"name":"Boob"}}`))
+	f.Add([]byte(`{"headers":{"contains":{"x":"1"}},"input":{"contains":{"a":[1,{"b":2}]}}}`), []byte(`{"headers":{"x":"1"},"data":{"a":[{"b":2},1,3]}}`))
+	f.Add([]byte(`{"input":{"matches":{"name":"("}}}`), []byte(`{"data":{"name":"("}}`))
+
+	f.Fuzz(func(t *testing.T, stubData, queryData []byte) {
+		var stub Stub
+		if err := decodeJSON(stubData, &stub); err != nil {
+			return
+		}
+
+		var query Query
+		if err := decodeJSON(queryData, &query); err != nil {
+			return
+		}
+
+		_, matcher := newSearcher().prepare(&stub)
+		normalized := normalizeQuery(query)
+
+		match(normalized, matcher)
+		strictFields(normalized, matcher)
+
+		// The rank short-circuit relies on the bound of the rank.
+		rank := rankMatch(normalized, matcher)
+		if bound := stubRankBound(matcher, len(normalized.Data) == 0, len(normalized.Headers) == 0); rank > bound {
+			t.Fatalf("rank %v of %s against %s exceeds its bound %v", rank, stubData, queryData, bound)
+		}
+	})
+}
+
+func FuzzStubJSON(f *testing.F) {
+	f.Add([]byte(`{"service":"Greeter","method":"SayHello","stream":[{"equals":{"name":"Bob"}}]}`))
+	f.Add([]byte(`[null]`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(_ *testing.T, data []byte) {
+		var stubs []Stub
+		_ = json.Unmarshal(data, &stubs)
+
+		b := New()
+		id := b.PutMany(&Stub{Service: "Greeter", Method: "SayHello"})[0]
+
+		_ = b.PatchJSON(id, data)
+	})
+}
+
+func FuzzNewQuery(f *testing.F) {
+	f.Add([]byte(`{"service":"Greeter","method":"SayHello","data":{"name":"Bob"}}`))
+	f.Add([]byte(`[{"service":"Greeter","method":"SayHello"},{"service":""}]`))
+	f.Add([]byte(`{"service":"Greeter","method":"SayHello","headers":{"x":{"y":1}}}`))
+	f.Add([]byte(` `))
+
+	f.Fuzz(func(_ *testing.T, body []byte) {
+		req := httptest.NewRequest(http.MethodPost, "/api/stubs/search", bytes.NewReader(body))
+		if q, err := NewQuery(req); err == nil {
+			_ = QueryHash(q)
+		}
+
+		req = httptest.NewRequest(http.MethodPost, "/api/stubs/search", bytes.NewReader(body))
+		_, _ = NewQueries(req)
+	})
+}
@@ -0,0 +1,94 @@
+package stuber
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details of the gRPC errors
+// returned by ToGRPCError.
+const ErrorDomain = "stuber.gripmock.io"
+
+// grpcMapping maps an error of the package to its gRPC code and the reason of
+// its ErrorInfo details.
+type grpcMapping struct {
+	err    error
+	code   codes.Code
+	reason string
+}
+
+// grpcMappings are the gRPC codes of the errors of the package, the first
+// matching one winning.
+var grpcMappings = []grpcMapping{ //nolint:gochecknoglobals
+	{context.Canceled, codes.Canceled, "CANCELED"},
+	{context.DeadlineExceeded, codes.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+	{ErrInvalidQuery, codes.InvalidArgument, "INVALID_QUERY"},
+	{ErrUnsupportedEncoding, codes.InvalidArgument, "UNSUPPORTED_ENCODING"},
+	{ErrServiceNotFound, codes.Unimplemented, "SERVICE_NOT_FOUND"},
+	{ErrMethodNotFound, codes.Unimplemented, "METHOD_NOT_FOUND"},
+	{ErrStubNotFound, codes.NotFound, "STUB_NOT_FOUND"},
+	{ErrBidiInvalidated, codes.Aborted, "BIDI_INVALIDATED"},
+	{ErrScanLimitReached, codes.ResourceExhausted, "SCAN_LIMIT_REACHED"},
+}
+
+// ToGRPCError returns the gRPC status error of the given error of the
+// package, so all the embedders answer the same codes:
+//
+//   - ErrServiceNotFound and ErrMethodNotFound: Unimplemented, like a server
+//     not implementing the method.
+//   - ErrStubNotFound: NotFound, or the code of a NotFoundError.
+//   - ErrInvalidQuery and ErrUnsupportedEncoding: InvalidArgument.
+//   - ErrBidiInvalidated: Aborted.
+//   - ErrScanLimitReached: ResourceExhausted.
+//   - The context errors: Canceled and DeadlineExceeded.
+//
+// The status carries an ErrorInfo detail of the ErrorDomain whose reason
+// names the error, such as "METHOD_NOT_FOUND", with the service and the
+// method of a NotFoundError as metadata. Errors already carrying a gRPC
+// status are returned as is, and the other ones are Unknown.
+//
+// Parameters:
+// - err: The error to convert, or nil.
+//
+// Returns:
+// - error: The gRPC status error, or nil if err is nil.
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if notFound := (*NotFoundError)(nil); errors.As(err, &notFound) {
+		return notFound.GRPCStatus().Err()
+	}
+
+	for _, mapping := range grpcMappings {
+		if errors.Is(err, mapping.err) {
+			return withErrorInfo(status.New(mapping.code, err.Error()), mapping.reason, nil).Err()
+		}
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// withErrorInfo returns the status with an ErrorInfo detail of the given
+// reason and metadata, or the status as is if the detail cannot be added.
+func withErrorInfo(st *status.Status, reason string, metadata map[string]string) *status.Status {
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st
+	}
+
+	return detailed
+}
@@ -0,0 +1,53 @@
+package stuber_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestToGRPCError(t *testing.T) {
+	require.NoError(t, stuber.ToGRPCError(nil))
+
+	tests := []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{stuber.ErrServiceNotFound, codes.Unimplemented, "SERVICE_NOT_FOUND"},
+		{fmt.Errorf("find: %w", stuber.ErrMethodNotFound), codes.Unimplemented, "METHOD_NOT_FOUND"},
+		{stuber.ErrStubNotFound, codes.NotFound, "STUB_NOT_FOUND"},
+		{fmt.Errorf("%w: service is required", stuber.ErrInvalidQuery), codes.InvalidArgument, "INVALID_QUERY"},
+		{stuber.ErrBidiInvalidated, codes.Aborted, "BIDI_INVALIDATED"},
+		{context.DeadlineExceeded, codes.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+		{&stuber.NotFoundError{Service: "Greeter", Method: "SayHello", Code: codes.Unavailable, Message: "down"}, codes.Unavailable, "STUB_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		st := status.Convert(stuber.ToGRPCError(tt.err))
+		require.Equal(t, tt.code, st.Code(), tt.err.Error())
+		require.Equal(t, tt.err.Error(), st.Message())
+
+		require.Len(t, st.Details(), 1)
+
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		require.Equal(t, tt.reason, info.GetReason())
+		require.Equal(t, stuber.ErrorDomain, info.GetDomain())
+	}
+
+	st := status.Convert(stuber.ToGRPCError(&stuber.NotFoundError{Service: "Greeter", Method: "SayHello", Code: codes.NotFound}))
+	require.Equal(t, "Greeter", st.Details()[0].(*errdetails.ErrorInfo).GetMetadata()["service"])
+
+	passthrough := status.Error(codes.PermissionDenied, "denied")
+	require.Equal(t, codes.PermissionDenied, status.Code(stuber.ToGRPCError(passthrough)))
+	require.Equal(t, codes.Unknown, status.Code(stuber.ToGRPCError(errors.New("boom"))))
+}
@@ -0,0 +1,37 @@
+package stuber
+
+import "strings"
+
+// lowerKeys returns a copy of the map with lowercase keys, or the map itself
+// if its keys are already lowercase.
+func lowerKeys(m map[string]interface{}) map[string]interface{} {
+	lower := true
+
+	for key := range m {
+		if key != strings.ToLower(key) {
+			lower = false
+
+			break
+		}
+	}
+
+	if lower {
+		return m
+	}
+
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		result[strings.ToLower(key)] = value
+	}
+
+	return result
+}
+
+// lowerStubHeaders lowercases the header names of the matchers of the stubs.
+func lowerStubHeaders(stubs []*Stub) {
+	for _, stub := range stubs {
+		stub.Headers.Equals = lowerKeys(stub.Headers.Equals)
+		stub.Headers.Contains = lowerKeys(stub.Headers.Contains)
+		stub.Headers.Matches = lowerKeys(stub.Headers.Matches)
+	}
+}
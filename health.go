@@ -0,0 +1,89 @@
+package stuber
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// The service and method of the gRPC health checking protocol.
+const (
+	HealthService = "grpc.health.v1.Health"
+	HealthMethod  = "Check"
+)
+
+// HealthStatus is the serving status of a service in the gRPC health
+// checking protocol.
+type HealthStatus string
+
+// The serving statuses of the gRPC health checking protocol.
+const (
+	HealthUnknown        HealthStatus = "UNKNOWN"
+	HealthServing        HealthStatus = "SERVING"
+	HealthNotServing     HealthStatus = "NOT_SERVING"
+	HealthServiceUnknown HealthStatus = "SERVICE_UNKNOWN"
+)
+
+// healthNamespace is the namespace of the IDs of the health stubs.
+var healthNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte(HealthService)) //nolint:gochecknoglobals
+
+// HealthStubID returns the ID of the built-in health stub of the given service.
+//
+// Parameters:
+// - service: The name of the checked service, or an empty string for the server.
+//
+// Returns:
+// - uuid.UUID: The ID of the health stub.
+func HealthStubID(service string) uuid.UUID {
+	return uuid.NewSHA1(healthNamespace, []byte(service))
+}
+
+// healthStub returns the health stub answering the checks of the given
+// service with the given status.
+func healthStub(service string, status HealthStatus) *Stub {
+	// The server is checked with an empty service, which is omitted from
+	// the request by the JSON encoding of protobuf messages.
+	equals := map[string]interface{}{}
+	if service != "" {
+		equals["service"] = service
+	}
+
+	return &Stub{
+		ID:      HealthStubID(service),
+		Service: HealthService,
+		Method:  HealthMethod,
+		Input:   InputData{Equals: equals},
+		Output:  Output{Data: map[string]interface{}{"status": string(status)}},
+	}
+}
+
+// SetHealthStatus sets the status answered by the built-in grpc.health.v1.Health
+// stubs for the given service, creating the stub on its first call. The
+// status can be switched at any time, for example to test how clients react
+// to a service becoming unavailable.
+//
+// Parameters:
+// - service: The name of the checked service, or an empty string for the server.
+// - status: The HealthStatus to answer.
+func (b *Budgerigar) SetHealthStatus(service string, status HealthStatus) {
+	stub := healthStub(service, status)
+
+	err := b.PatchByID(stub.ID, func(current *Stub) error {
+		current.Output = stub.Output
+
+		return nil
+	})
+	if errors.Is(err, ErrStubNotFound) {
+		b.PutMany(stub)
+	}
+}
+
+// WithHealth enables the built-in grpc.health.v1.Health stubs, answering
+// HealthServing for the server and the given services. The stubs are
+// inserted once all the options are applied.
+func WithHealth(services ...string) Option {
+	return func(b *Budgerigar) {
+		b.health = append(b.health, "")
+		b.health = append(b.health, services...)
+	}
+}
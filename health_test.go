@@ -0,0 +1,54 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Health(t *testing.T) {
+	s := stuber.New(stuber.WithHealth("Greeter"))
+
+	check := func(data map[string]interface{}) interface{} {
+		r, err := s.FindByQuery(stuber.Query{
+			Service: stuber.HealthService,
+			Method:  stuber.HealthMethod,
+			Data:    data,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, r.Found())
+
+		return r.Found().Output.Data["status"]
+	}
+
+	require.Equal(t, "SERVING", check(map[string]interface{}{}))
+	require.Equal(t, "SERVING", check(map[string]interface{}{"service": "Greeter"}))
+
+	s.SetHealthStatus("Greeter", stuber.HealthNotServing)
+	require.Equal(t, "NOT_SERVING", check(map[string]interface{}{"service": "Greeter"}))
+	require.Equal(t, "SERVING", check(map[string]interface{}{}))
+
+	s.SetHealthStatus("Other", stuber.HealthServiceUnknown)
+	require.Equal(t, "SERVICE_UNKNOWN", check(map[string]interface{}{"service": "Other"}))
+
+	require.Len(t, s.All(), 3)
+	require.NotNil(t, s.FindByID(stuber.HealthStubID("Greeter")))
+}
+
+func TestNew_WithHealth_Options(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// The options after WithHealth apply to the health stubs too.
+	s := stuber.New(
+		stuber.WithHealth(),
+		stuber.WithClock(func() time.Time { return now }),
+		stuber.WithRevisions(10),
+	)
+
+	id := stuber.HealthStubID("")
+	require.Equal(t, now, *s.FindByID(id).CreatedAt)
+	require.Len(t, s.Revisions(id), 1)
+}
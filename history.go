@@ -0,0 +1,168 @@
+package stuber
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchRecord describes the stub that answered a query.
+type MatchRecord struct {
+	Hash    string    `json:"hash"`    // The QueryHash of the query.
+	StubID  uuid.UUID `json:"stubId"`  // The stub that answered the query.
+	Rank    float64   `json:"rank"`    // The rank of the stub for the query.
+	Service string    `json:"service"` // The service of the query.
+	Method  string    `json:"method"`  // The method of the query.
+	Time    time.Time `json:"time"`    // When the query was answered.
+}
+
+// QueryHash returns a stable hash of the service, method, headers and data
+// of the query, identifying it in the match history.
+//
+// Parameters:
+// - query: The query to hash.
+//
+// Returns:
+// - string: The hexadecimal SHA-256 hash of the query.
+func QueryHash(query Query) string {
+	// Maps are encoded with sorted keys, which makes the encoding stable.
+	data, err := json.Marshal(struct {
+		Service string                 `json:"service"`
+		Method  string                 `json:"method"`
+		Headers map[string]interface{} `json:"headers"`
+		Data    map[string]interface{} `json:"data"`
+	}{query.Service, query.Method, query.Headers, query.Data})
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// matchHistory keeps the last matches in a ring buffer.
+type matchHistory struct {
+	mu      sync.RWMutex
+	now     func() time.Time
+	records []MatchRecord
+	next    int
+	byHash  map[string]int
+}
+
+// newMatchHistory creates a new matchHistory keeping no matches.
+func newMatchHistory() *matchHistory {
+	return &matchHistory{now: time.Now, byHash: make(map[string]int)}
+}
+
+// resize sets the number of kept matches and forgets the recorded ones.
+func (h *matchHistory) resize(size int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records = make([]MatchRecord, max(size, 0))
+	h.next = 0
+	h.byHash = make(map[string]int)
+}
+
+// enabled checks if matches are kept.
+func (h *matchHistory) enabled() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.records) > 0
+}
+
+// record keeps the match of the query by the given stub.
+func (h *matchHistory) record(query Query, stub *Stub, rank float64) {
+	if !h.enabled() {
+		return
+	}
+
+	record := MatchRecord{
+		Hash:    QueryHash(query),
+		StubID:  stub.ID,
+		Rank:    rank,
+		Service: query.Service,
+		Method:  query.Method,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.records) == 0 {
+		return
+	}
+
+	record.Time = h.now()
+
+	// Forget the hash of the evicted record, unless it was recorded again since.
+	if evicted := h.records[h.next]; evicted.Hash != "" && h.byHash[evicted.Hash] == h.next {
+		delete(h.byHash, evicted.Hash)
+	}
+
+	h.records[h.next] = record
+	h.byHash[record.Hash] = h.next
+	h.next = (h.next + 1) % len(h.records)
+}
+
+// lookup returns the last match of the query with the given hash.
+func (h *matchHistory) lookup(hash string) (MatchRecord, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	i, ok := h.byHash[hash]
+	if !ok {
+		return MatchRecord{}, false
+	}
+
+	return h.records[i], true
+}
+
+// list returns the kept matches, from the oldest to the newest.
+func (h *matchHistory) list() []MatchRecord {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var result []MatchRecord
+
+	for i := range h.records {
+		if record := h.records[(h.next+i)%len(h.records)]; record.Hash != "" {
+			result = append(result, record)
+		}
+	}
+
+	return result
+}
+
+// RecentMatches returns the kept matches, from the oldest to the newest.
+//
+// Matches are only kept when enabled with WithMatchHistory, and only the
+// last ones are kept.
+//
+// Returns:
+// - []MatchRecord: The kept matches.
+func (b *Budgerigar) RecentMatches() []MatchRecord {
+	return b.history.list()
+}
+
+// WhoAnswered returns the stub that last answered the query with the given
+// QueryHash, and its rank at the time, to find which stub answered a request
+// seen in a journal.
+//
+// Matches are only kept when enabled with WithMatchHistory, and only the
+// last ones are kept.
+//
+// Parameters:
+// - hash: The QueryHash of the query.
+//
+// Returns:
+// - MatchRecord: The last match of the query.
+// - bool: Whether the query is in the history.
+func (b *Budgerigar) WhoAnswered(hash string) (MatchRecord, bool) {
+	return b.history.lookup(hash)
+}
@@ -0,0 +1,68 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_WhoAnswered(t *testing.T) {
+	s := stuber.New(stuber.WithMatchHistory(2), stuber.WithFlags(stuber.MethodTitle))
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+	}
+
+	s.PutMany(stub)
+
+	query := func(age int) stuber.Query {
+		return stuber.Query{
+			Service: "Greeter",
+			Method:  "sayHello",
+			Data:    map[string]interface{}{"name": "Bob", "age": age},
+		}
+	}
+
+	for age := range 3 {
+		_, err := s.FindByQuery(query(age))
+		require.NoError(t, err)
+	}
+
+	// The oldest match is evicted.
+	_, ok := s.WhoAnswered(stuber.QueryHash(query(0)))
+	require.False(t, ok)
+
+	record, ok := s.WhoAnswered(stuber.QueryHash(query(2)))
+	require.True(t, ok)
+	require.Equal(t, stub.ID, record.StubID)
+	require.Equal(t, "sayHello", record.Method)
+	require.Positive(t, record.Rank)
+
+	require.Equal(t, stuber.QueryHash(query(1)), stuber.QueryHash(query(1)))
+	require.NotEqual(t, stuber.QueryHash(query(1)), stuber.QueryHash(query(2)))
+}
+
+func TestBudgerigar_WhoAnsweredDisabled(t *testing.T) {
+	s := stuber.New()
+
+	q := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	})
+
+	_, err := s.FindByQuery(q)
+	require.NoError(t, err)
+
+	_, ok := s.WhoAnswered(stuber.QueryHash(q))
+	require.False(t, ok)
+}
@@ -0,0 +1,174 @@
+package stuber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// webhookTimeout is the maximum duration of a webhook call.
+const webhookTimeout = 5 * time.Second
+
+const (
+	// defaultHookQueue is the default number of pending deliveries of the hooks.
+	defaultHookQueue = 1024
+	// defaultHookWorkers is the default number of concurrent deliveries of the hooks.
+	defaultHookWorkers = 16
+)
+
+// MatchHook is a callback invoked after a query matched a stub.
+type MatchHook func(stub *Stub, query Query)
+
+// WebhookPayload is the JSON body sent to the webhook of a matched stub.
+type WebhookPayload struct {
+	ID      uuid.UUID              `json:"id"`      // The unique identifier of the matched stub.
+	Service string                 `json:"service"` // The service of the query.
+	Method  string                 `json:"method"`  // The method of the query.
+	Headers map[string]interface{} `json:"headers"` // The headers of the query.
+	Data    map[string]interface{} `json:"data"`    // The data of the query.
+
+	Meta map[string]string `json:"meta,omitempty"` // The labels of the matched stub.
+}
+
+// hooks holds the callbacks invoked after a match.
+//
+// The deliveries of the callbacks and the webhooks are queued and run by a
+// bounded number of workers, which exit once the queue is empty. Deliveries
+// are dropped when the queue is full.
+type hooks struct {
+	mu      sync.RWMutex
+	onMatch []MatchHook
+	client  *http.Client
+	logger  *slog.Logger
+
+	queueMu sync.Mutex
+	queue   chan func() // The pending deliveries.
+	workers int         // The maximum number of running workers.
+	running int         // The number of running workers.
+}
+
+// newHooks creates a new hooks instance.
+func newHooks() *hooks {
+	return &hooks{
+		client:  &http.Client{Timeout: webhookTimeout},
+		queue:   make(chan func(), defaultHookQueue),
+		workers: defaultHookWorkers,
+	}
+}
+
+// add registers a new MatchHook.
+func (h *hooks) add(hook MatchHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onMatch = append(h.onMatch, hook)
+}
+
+// matched asynchronously invokes the registered hooks and the webhook of
+// the given stub.
+func (h *hooks) matched(stub *Stub, query Query) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, hook := range h.onMatch {
+		h.enqueue(stub, func() { hook(stub, query) })
+	}
+
+	if stub.Webhook != "" {
+		h.enqueue(stub, func() { h.notify(stub, query) })
+	}
+}
+
+// enqueue queues the given delivery for the given stub, starting a worker if
+// fewer than the maximum are running. The delivery is dropped and logged if
+// the queue is full.
+func (h *hooks) enqueue(stub *Stub, delivery func()) {
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+
+	select {
+	case h.queue <- func() { h.deliver(stub, delivery) }:
+	default:
+		h.logger.Warn("stuber: hook queue full, delivery dropped", "stub", stub.ID)
+
+		return
+	}
+
+	if h.running < h.workers {
+		h.running++
+
+		go h.work()
+	}
+}
+
+// work runs the queued deliveries until the queue is empty.
+func (h *hooks) work() {
+	for {
+		h.queueMu.Lock()
+
+		select {
+		case delivery := <-h.queue:
+			h.queueMu.Unlock()
+			delivery()
+		default:
+			h.running--
+			h.queueMu.Unlock()
+
+			return
+		}
+	}
+}
+
+// deliver runs the given delivery for the given stub, recovering and logging
+// its panic, if any, so a faulty hook doesn't kill the worker, the process
+// with it.
+func (h *hooks) deliver(stub *Stub, delivery func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.Error("stuber: hook panicked", "stub", stub.ID, "panic", r)
+		}
+	}()
+
+	delivery()
+}
+
+// notify posts the WebhookPayload of a match to the webhook of the stub.
+//
+// Errors are only logged: webhooks are best effort and must never affect matching.
+func (h *hooks) notify(stub *Stub, query Query) {
+	body, err := json.Marshal(WebhookPayload{
+		ID:      stub.ID,
+		Service: query.Service,
+		Method:  query.Method,
+		Headers: query.Headers,
+		Data:    query.Data,
+		Meta:    stub.Meta,
+	})
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, stub.Webhook, bytes.NewReader(body))
+	if err != nil {
+		h.logger.Error("stuber: invalid webhook", "stub", stub.ID, "error", err)
+
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		h.logger.Warn("stuber: webhook failed", "stub", stub.ID, "error", err)
+
+		return
+	}
+
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,194 @@
+package stuber_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_OnMatch(t *testing.T) {
+	payloads := make(chan stuber.WebhookPayload, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var payload stuber.WebhookPayload
+
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			payloads <- payload
+		}
+	}))
+	defer server.Close()
+
+	s := stuber.NewBudgerigar(features.New())
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Webhook: server.URL,
+		Meta:    map[string]string{"ticket": "QA-42"},
+	}
+	s.PutMany(stub)
+
+	matched := make(chan uuid.UUID, 1)
+
+	s.OnMatch(func(stub *stuber.Stub, _ stuber.Query) {
+		matched <- stub.ID
+	})
+
+	_, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+
+	select {
+	case id := <-matched:
+		require.Equal(t, stub.ID, id)
+	case <-time.After(time.Second):
+		require.Fail(t, "hook was not called")
+	}
+
+	select {
+	case payload := <-payloads:
+		require.Equal(t, stub.ID, payload.ID)
+		require.Equal(t, "Bob", payload.Data["name"])
+		require.Equal(t, map[string]string{"ticket": "QA-42"}, payload.Meta)
+	case <-time.After(time.Second):
+		require.Fail(t, "webhook was not called")
+	}
+}
+
+func TestBudgerigar_OnMatch_Queue(t *testing.T) {
+	var logs bytes.Buffer
+
+	s := stuber.New(
+		stuber.WithHookQueue(2, 1),
+		stuber.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	})
+
+	var calls, running, overlaps atomic.Int32
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	s.OnMatch(func(*stuber.Stub, stuber.Query) {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+
+		calls.Add(1)
+
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+
+		<-release
+		running.Add(-1)
+	})
+
+	find := func() {
+		_, err := s.FindByQuery(stuber.Query{
+			Service: "Greeter",
+			Method:  "SayHello",
+			Data:    map[string]interface{}{"name": "Bob"},
+		})
+		require.NoError(t, err)
+	}
+
+	// The first delivery blocks the only worker.
+	find()
+	<-started
+
+	// Two deliveries are queued, the others dropped.
+	for range 4 {
+		find()
+	}
+
+	require.Equal(t, 2, strings.Count(logs.String(), "hook queue full"))
+
+	close(release)
+
+	require.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, time.Millisecond)
+	require.Never(t, func() bool { return calls.Load() > 3 }, 50*time.Millisecond, time.Millisecond)
+	require.Zero(t, overlaps.Load())
+}
+
+func TestBudgerigar_OnMatch_Panic(t *testing.T) {
+	var logs syncBuffer
+
+	s := stuber.New(stuber.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	})
+
+	var calls atomic.Int32
+
+	s.OnMatch(func(*stuber.Stub, stuber.Query) {
+		panic("boom")
+	})
+	s.OnMatch(func(*stuber.Stub, stuber.Query) {
+		calls.Add(1)
+	})
+
+	for range 2 {
+		_, err := s.FindByQuery(stuber.Query{
+			Service: "Greeter",
+			Method:  "SayHello",
+			Data:    map[string]interface{}{"name": "Bob"},
+		})
+		require.NoError(t, err)
+	}
+
+	// The panics are logged and the other hooks still delivered.
+	require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool {
+		return strings.Count(logs.String(), "hook panicked") == 2
+	}, time.Second, time.Millisecond)
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of the workers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
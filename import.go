@@ -0,0 +1,209 @@
+package stuber
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidImport is returned when an imported document cannot be decoded.
+var ErrInvalidImport = errors.New("invalid import document")
+
+// variablePattern matches the ${name} placeholders of imported documents.
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.-]*)}`)
+
+// importDocument is an imported document with a variables section.
+type importDocument struct {
+	Variables map[string]any  `json:"variables"`
+	Stubs     json.RawMessage `json:"stubs"`
+}
+
+// importer holds the configuration used to import stub documents.
+type importer struct {
+	mu   sync.RWMutex
+	vars map[string]any
+}
+
+// newImporter creates a new importer.
+func newImporter() *importer {
+	return &importer{vars: make(map[string]any)}
+}
+
+// setVars replaces the variables available to imported documents.
+func (i *importer) setVars(vars map[string]any) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.vars = maps.Clone(vars)
+}
+
+// decode decodes the given JSON or YAML document into stubs.
+//
+// The document is either a list of stubs, a single stub, or an object with
+// a "variables" section and a "stubs" list. The ${name} placeholders are
+// substituted with the document variables, falling back to the variables of
+// the importer and, if env is true, to the environment variables.
+func (i *importer) decode(data []byte, env bool) ([]*Stub, error) {
+	var tree any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, errors.Join(ErrInvalidImport, err)
+	}
+
+	vars := i.lookup(tree, env)
+	tree = substitute(tree, vars)
+
+	// Re-encode the tree to decode the stubs with their JSON tags.
+	raw, err := json.Marshal(tree)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidImport, err)
+	}
+
+	if doc, ok := tree.(map[string]any); ok {
+		if _, ok := doc["stubs"]; ok {
+			var document importDocument
+			if err := decodeJSON(raw, &document); err != nil {
+				return nil, err
+			}
+
+			raw = document.Stubs
+		} else {
+			raw = append(append([]byte{'['}, raw...), ']')
+		}
+	}
+
+	var stubs []*Stub
+	if err := decodeJSON(raw, &stubs); err != nil {
+		return nil, err
+	}
+
+	// A null entry decodes to a nil stub, which cannot be inserted.
+	for n, stub := range stubs {
+		if stub == nil {
+			return nil, fmt.Errorf("%w: stub %d is null", ErrInvalidImport, n)
+		}
+	}
+
+	return stubs, nil
+}
+
+// lookup returns a function resolving variables from the variables section
+// of the given document, from the variables of the importer and, if env is
+// true, from the environment variables.
+func (i *importer) lookup(tree any, env bool) func(string) (any, bool) {
+	i.mu.RLock()
+	vars := maps.Clone(i.vars)
+	i.mu.RUnlock()
+
+	if doc, ok := tree.(map[string]any); ok {
+		if local, ok := doc["variables"].(map[string]any); ok {
+			maps.Copy(vars, local)
+		}
+
+		delete(doc, "variables")
+	}
+
+	return func(name string) (any, bool) {
+		if value, ok := vars[name]; ok {
+			return value, true
+		}
+
+		if env {
+			return os.LookupEnv(name)
+		}
+
+		return nil, false
+	}
+}
+
+// substitute replaces the ${name} placeholders of all the strings in the tree.
+//
+// A string consisting of a single placeholder is replaced by the variable
+// value itself, which allows whole objects to be reused. Unknown variables
+// are left untouched.
+func substitute(value any, lookup func(string) (any, bool)) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = substitute(item, lookup)
+		}
+
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = substitute(item, lookup)
+		}
+
+		return v
+	case string:
+		if m := variablePattern.FindStringSubmatch(v); m != nil && m[0] == v {
+			if resolved, ok := lookup(m[1]); ok {
+				return resolved
+			}
+
+			return v
+		}
+
+		return variablePattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			resolved, ok := lookup(placeholder[2 : len(placeholder)-1])
+			if !ok {
+				return placeholder
+			}
+
+			return fmt.Sprint(resolved)
+		})
+	default:
+		return value
+	}
+}
+
+// decodeJSON decodes JSON data keeping numbers as json.Number, the same way
+// queries are decoded.
+func decodeJSON(data []byte, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(target); err != nil {
+		return errors.Join(ErrInvalidImport, err)
+	}
+
+	return nil
+}
+
+// Import decodes the given JSON or YAML document and inserts its stubs.
+//
+// The document is either a list of stubs, a single stub, or an object with
+// a "variables" section and a "stubs" list. Placeholders such as
+// ${baseCustomer} are substituted with the document variables, falling back
+// to the variables set with SetImportVars and, if the ImportEnv feature flag
+// is enabled, to the environment variables.
+//
+// Parameters:
+// - data: The document to import.
+//
+// Returns:
+// - []uuid.UUID: The keys of the inserted Stub values.
+// - error: An error if the document cannot be decoded.
+func (b *Budgerigar) Import(data []byte) ([]uuid.UUID, error) {
+	stubs, err := b.importer.decode(data, b.toggles.Has(ImportEnv))
+	if err != nil {
+		return nil, err
+	}
+
+	return b.PutMany(stubs...), nil
+}
+
+// SetImportVars sets the variables available to imported documents.
+//
+// Parameters:
+// - vars: The variables, by name.
+func (b *Budgerigar) SetImportVars(vars map[string]any) {
+	b.importer.setVars(vars)
+}
@@ -0,0 +1,118 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/bavix/features"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_ImportVariables(t *testing.T) {
+	s := stuber.NewBudgerigar(features.New())
+	s.SetImportVars(map[string]any{"tenant": "acme", "service": "Customers"})
+
+	ids, err := s.Import([]byte(`
+variables:
+  baseCustomer:
+    name: John
+    tier: gold
+stubs:
+  - service: ${service}
+    method: Get
+    headers:
+      equals:
+        tenant: ${tenant}
+    input:
+      equals:
+        id: 1
+    output:
+      data:
+        customer: ${baseCustomer}
+        note: "tenant ${tenant}, unknown ${missing}"
+`))
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	stub := s.FindByID(ids[0])
+	require.NotNil(t, stub)
+	require.Equal(t, "Customers", stub.Service)
+	require.Equal(t, "acme", stub.Headers.Equals["tenant"])
+	require.Equal(t, map[string]interface{}{"name": "John", "tier": "gold"}, stub.Output.Data["customer"])
+	require.Equal(t, "tenant acme, unknown ${missing}", stub.Output.Data["note"])
+}
+
+func TestBudgerigar_ImportJSON(t *testing.T) {
+	s := stuber.NewBudgerigar(features.New())
+
+	ids, err := s.Import([]byte(`[
+		{"service":"Greeter","method":"SayHello","input":{"equals":{"name":"Bob"}},"output":{"data":{"message":"Hi"}}},
+		{"service":"Greeter","method":"SayBye","output":{"data":{"message":"Bye"}}}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	require.Len(t, s.All(), 2)
+
+	ids, err = s.Import([]byte(`{"service":"Greeter","method":"SayHi"}`))
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	_, err = s.Import([]byte(`{"service": [}`))
+	require.ErrorIs(t, err, stuber.ErrInvalidImport)
+
+	// Null stubs are rejected instead of being inserted.
+	_, err = s.Import([]byte(`[null]`))
+	require.ErrorIs(t, err, stuber.ErrInvalidImport)
+
+	_, err = s.Import([]byte(`{"stubs":[{"service":"Greeter","method":"SayHello"},null]}`))
+	require.ErrorIs(t, err, stuber.ErrInvalidImport)
+	require.Len(t, s.All(), 3)
+}
+
+func TestBudgerigar_ImportEnv(t *testing.T) {
+	t.Setenv("STUBER_TEST_HOST", "api.example.com")
+
+	doc := []byte(`{"service":"Greeter","method":"SayHello","output":{"data":{"host":"${STUBER_TEST_HOST}"}}}`)
+
+	s := stuber.NewBudgerigar(features.New())
+
+	ids, err := s.Import(doc)
+	require.NoError(t, err)
+	require.Equal(t, "${STUBER_TEST_HOST}", s.FindByID(ids[0]).Output.Data["host"])
+
+	s = stuber.NewBudgerigar(features.New(stuber.ImportEnv))
+
+	ids, err = s.Import(doc)
+	require.NoError(t, err)
+	require.Equal(t, "api.example.com", s.FindByID(ids[0]).Output.Data["host"])
+}
+
+func TestBudgerigar_ImportDescription(t *testing.T) {
+	s := stuber.New()
+
+	ids, err := s.Import([]byte(`
+- service: Greeter
+  method: SayHello
+  description: Greets Bob in the onboarding tests
+  owner: team-onboarding
+  input:
+    equals:
+      name: Bob
+`))
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	all := s.All()
+	require.Len(t, all, 1)
+	require.Equal(t, "Greets Bob in the onboarding tests", all[0].Description)
+	require.Equal(t, "team-onboarding", all[0].Owner)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ids[0], r.Found().ID)
+}
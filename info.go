@@ -0,0 +1,24 @@
+package stuber
+
+// Info describes the state of a Budgerigar.
+type Info struct {
+	Stubs     int                `json:"stubs"`     // The number of stored stubs.
+	Templates TemplateCacheStats `json:"templates"` // The statistics of the cache of parsed templates.
+
+	// TemplateSeed is the seed of the random template functions, set with
+	// WithTemplateSeed, to reproduce the rendered outputs.
+	TemplateSeed *uint64 `json:"templateSeed,omitempty"`
+}
+
+// Info returns the state of the Budgerigar.
+//
+// Returns:
+// - Info: The number of stubs and the statistics of the caches.
+func (b *Budgerigar) Info() Info {
+	return Info{
+		Stubs:     len(b.searcher.all()),
+		Templates: b.templateCache.snapshot(),
+
+		TemplateSeed: b.templates.currentSeed(),
+	}
+}
@@ -0,0 +1,144 @@
+package stuber
+
+import (
+	"maps"
+
+	"github.com/google/uuid"
+)
+
+// maxBaseDepth is the maximum length of a chain of base stubs.
+//
+// It protects resolution against cycles between stubs.
+const maxBaseDepth = 16
+
+// resolve returns the given stub merged with its chain of base stubs.
+//
+// The stub is returned unchanged if it has no base. Missing base stubs and
+// chains longer than maxBaseDepth stop the resolution.
+func (s *searcher) resolve(stub *Stub) *Stub {
+	if stub.Base == nil {
+		return stub
+	}
+
+	chain := []*Stub{stub}
+
+	for current := stub; current.Base != nil && len(chain) <= maxBaseDepth; {
+		base := s.findByID(*current.Base)
+		if base == nil {
+			break
+		}
+
+		chain = append(chain, base)
+		current = base
+	}
+
+	// Merge from the farthest base down to the stub itself.
+	resolved := *chain[len(chain)-1]
+
+	for i := len(chain) - 2; i >= 0; i-- {
+		resolved = chain[i].inherit(&resolved)
+	}
+
+	return &resolved
+}
+
+// inherit returns a copy of the stub with the matchers and output of the
+// given base merged under its own values.
+func (s *Stub) inherit(base *Stub) Stub {
+	result := *s
+
+	result.Headers = InputHeader{
+		Equals:   mergeMap(base.Headers.Equals, s.Headers.Equals),
+		Contains: mergeMap(base.Headers.Contains, s.Headers.Contains),
+		Matches:  mergeMap(base.Headers.Matches, s.Headers.Matches),
+	}
+
+	result.Input = InputData{
+		IgnoreArrayOrder: base.Input.IgnoreArrayOrder || s.Input.IgnoreArrayOrder,
+		Equals:           mergeMap(base.Input.Equals, s.Input.Equals),
+		Contains:         mergeMap(base.Input.Contains, s.Input.Contains),
+		Matches:          mergeMap(base.Input.Matches, s.Input.Matches),
+	}
+
+	result.Size = mergeMap(base.Size, s.Size)
+	result.MessageCount = mergeMap(base.MessageCount, s.MessageCount)
+
+	// The fields of the output not merged below, such as the seed and the
+	// alternatives, are the stub's own.
+	result.Output = s.Output
+	result.Output.Headers = mergeMap(base.Output.Headers, s.Output.Headers)
+	result.Output.Data = mergeMap(base.Output.Data, s.Output.Data)
+
+	if result.Output.Error == "" {
+		result.Output.Error = base.Output.Error
+	}
+
+	if result.Output.Code == nil {
+		result.Output.Code = base.Output.Code
+	}
+
+	if result.Output.Delay == 0 {
+		result.Output.Delay = base.Output.Delay
+	}
+
+	if result.Output.Seed == nil {
+		result.Output.Seed = base.Output.Seed
+	}
+
+	if len(result.Output.Alternatives) == 0 {
+		result.Output.Alternatives = base.Output.Alternatives
+	}
+
+	return result
+}
+
+// inheritTarget fills the service and method of the stub from its base
+// when they are not set.
+func (s *Stub) inheritTarget(base *Stub) {
+	if s.Service == "" {
+		s.Service = base.Service
+	}
+
+	if s.Method == "" {
+		s.Method = base.Method
+	}
+}
+
+// mergeMap returns a new map with the values of override set over the values
+// of base. The override is returned as is when both maps are empty.
+func mergeMap[V any](base, override map[string]V) map[string]V {
+	if len(base) == 0 && len(override) == 0 {
+		return override
+	}
+
+	result := make(map[string]V, len(base)+len(override))
+	maps.Copy(result, base)
+	maps.Copy(result, override)
+
+	return result
+}
+
+// inheritTargets fills the service and method of the given stubs from their
+// base, looked up first among the given stubs and then in the searcher.
+func (s *searcher) inheritTargets(values []*Stub) {
+	batch := make(map[uuid.UUID]*Stub, len(values))
+
+	for _, value := range values {
+		batch[value.ID] = value
+	}
+
+	for _, value := range values {
+		if value.Base == nil {
+			continue
+		}
+
+		base, ok := batch[*value.Base]
+		if !ok {
+			base = s.findByID(*value.Base)
+		}
+
+		if base != nil {
+			value.inheritTarget(base)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Base(t *testing.T) {
+	s := stuber.NewBudgerigar(features.New())
+
+	base := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Users",
+		Method:   "Get",
+		Abstract: true,
+		Headers:  stuber.InputHeader{Equals: map[string]interface{}{"tenant": "acme"}},
+		Output: stuber.Output{Data: map[string]interface{}{
+			"name":   "John",
+			"status": "active",
+		}},
+	}
+
+	child := &stuber.Stub{
+		ID:     uuid.New(),
+		Base:   &base.ID,
+		Input:  stuber.InputData{Equals: map[string]interface{}{"id": "2"}},
+		Output: stuber.Output{Data: map[string]interface{}{"status": "blocked"}},
+	}
+
+	s.PutMany(base, child)
+
+	require.Equal(t, "Users", child.Service)
+	require.Equal(t, "Get", child.Method)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Users",
+		Method:  "Get",
+		Headers: map[string]interface{}{"tenant": "acme"},
+		Data:    map[string]interface{}{"id": "2"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.Equal(t, child.ID, r.Found().ID)
+	require.Equal(t, map[string]interface{}{"name": "John", "status": "blocked"}, r.Found().Output.Data)
+
+	// The inherited headers are part of the matchers.
+	r, err = s.FindByQuery(stuber.Query{
+		Service: "Users",
+		Method:  "Get",
+		Headers: map[string]interface{}{"tenant": "other"},
+		Data:    map[string]interface{}{"id": "2"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+	require.Equal(t, child.ID, r.Similar().ID)
+}
@@ -0,0 +1,196 @@
+package stuber
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// IntegrityIssue describes a reference of a stub that cannot be resolved.
+type IntegrityIssue struct {
+	Stub      uuid.UUID `json:"stub"`            // The stub holding the reference.
+	Field     string    `json:"field"`           // The field of the reference, "base", "dependsOn" or "requiredState".
+	Reference uuid.UUID `json:"reference"`       // The referenced stub, or the nil UUID for a state.
+	State     string    `json:"state,omitempty"` // The referenced state of the scenario, for the requiredState field.
+	Reason    string    `json:"reason"`          // Why the reference cannot be resolved.
+}
+
+// Reasons of the integrity issues.
+const (
+	reasonMissing     = "missing"
+	reasonSelf        = "self reference"
+	reasonCycle       = "cycle"
+	reasonTooDeep     = "chain too long"
+	reasonUnreachable = "unreachable"
+	fieldBase         = "base"
+	fieldDependsOn    = "dependsOn"
+	fieldState        = "requiredState"
+)
+
+// checkIntegrity returns the unresolvable references of the given stubs,
+// looking the referenced stubs up with the given function. The required
+// states are looked up among the states the transitions of all the given
+// stubs reach.
+func checkIntegrity(stubs, all []*Stub, lookup func(uuid.UUID) *Stub) []IntegrityIssue {
+	var (
+		issues []IntegrityIssue
+		states = reachableStates(all)
+	)
+
+	for _, stub := range stubs {
+		for _, id := range stub.DependsOn {
+			switch {
+			case id == stub.ID:
+				issues = append(issues, IntegrityIssue{stub.ID, fieldDependsOn, id, "", reasonSelf})
+			case lookup(id) == nil:
+				issues = append(issues, IntegrityIssue{stub.ID, fieldDependsOn, id, "", reasonMissing})
+			}
+		}
+
+		if stub.Base != nil {
+			if reason := checkBase(stub, lookup); reason != "" {
+				issues = append(issues, IntegrityIssue{stub.ID, fieldBase, *stub.Base, "", reason})
+			}
+		}
+
+		if _, ok := states[stub.Scenario][stub.RequiredState]; stub.RequiredState != "" && !ok {
+			issues = append(issues, IntegrityIssue{stub.ID, fieldState, uuid.Nil, stub.RequiredState, reasonUnreachable})
+		}
+	}
+
+	slices.SortFunc(issues, func(a, b IntegrityIssue) int {
+		return cmp.Or(
+			cmp.Compare(a.Stub.String(), b.Stub.String()),
+			cmp.Compare(a.Field, b.Field),
+			cmp.Compare(a.Reference.String(), b.Reference.String()),
+			cmp.Compare(a.State, b.State),
+		)
+	})
+
+	return issues
+}
+
+// reachableStates returns the states of the scenarios reachable from
+// ScenarioStarted by the transitions of the given stubs, by scenario.
+//
+// A stub requiring no state moves its scenario to its new state from any
+// state. Abstract stubs never match, so they never move their scenario.
+func reachableStates(stubs []*Stub) map[string]map[string]struct{} {
+	states := make(map[string]map[string]struct{})
+
+	for _, stub := range stubs {
+		if _, ok := states[stub.Scenario]; !ok {
+			states[stub.Scenario] = map[string]struct{}{ScenarioStarted: {}}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, stub := range stubs {
+			if stub.Abstract || stub.NewState == "" {
+				continue
+			}
+
+			reached := states[stub.Scenario]
+
+			if _, ok := reached[stub.RequiredState]; stub.RequiredState != "" && !ok {
+				continue
+			}
+
+			if _, ok := reached[stub.NewState]; !ok {
+				reached[stub.NewState] = struct{}{}
+				changed = true
+			}
+		}
+	}
+
+	return states
+}
+
+// checkBase walks the chain of base stubs of the given stub and returns why
+// it cannot be resolved, or an empty string.
+func checkBase(stub *Stub, lookup func(uuid.UUID) *Stub) string {
+	if *stub.Base == stub.ID {
+		return reasonSelf
+	}
+
+	seen := map[uuid.UUID]struct{}{stub.ID: {}}
+
+	for current, depth := stub, 0; current.Base != nil; depth++ {
+		if depth == maxBaseDepth {
+			return reasonTooDeep
+		}
+
+		if _, ok := seen[*current.Base]; ok {
+			return reasonCycle
+		}
+
+		base := lookup(*current.Base)
+		if base == nil {
+			// Only the missing base of the stub itself is reported, the
+			// missing bases of its chain are reported on their own stubs.
+			if current == stub {
+				return reasonMissing
+			}
+
+			return ""
+		}
+
+		seen[base.ID] = struct{}{}
+		current = base
+	}
+
+	return ""
+}
+
+// CheckIntegrity reports the references of the stored Stub values that
+// cannot be resolved: missing or self referencing DependsOn and Base stubs,
+// cycles and too long chains of Base stubs, and required states that no
+// Stub value of the scenario moves it to from ScenarioStarted.
+//
+// Such references don't fail searches, but silently prevent the Stub values
+// from matching or from inheriting their Base stub.
+//
+// Returns:
+// - []IntegrityIssue: The unresolvable references, or nil.
+func (b *Budgerigar) CheckIntegrity() []IntegrityIssue {
+	stubs := b.searcher.all()
+
+	return checkIntegrity(stubs, stubs, b.searcher.findByID)
+}
+
+// Validate reports the references of the given Stub values that cannot be
+// resolved, before they are inserted. The referenced stubs are looked up
+// among the given Stub values, then among the stored ones, and the required
+// states among the states reached by both, the given Stub values replacing
+// the stored ones with the same ID.
+//
+// Parameters:
+// - stubs: The Stub values to validate.
+//
+// Returns:
+// - []IntegrityIssue: The unresolvable references, or nil.
+func (b *Budgerigar) Validate(stubs ...*Stub) []IntegrityIssue {
+	batch := make(map[uuid.UUID]*Stub, len(stubs))
+	for _, stub := range stubs {
+		batch[stub.ID] = stub
+	}
+
+	all := slices.Clone(stubs)
+
+	for _, stub := range b.searcher.all() {
+		if _, ok := batch[stub.ID]; !ok {
+			all = append(all, stub)
+		}
+	}
+
+	return checkIntegrity(stubs, all, func(id uuid.UUID) *Stub {
+		if stub, ok := batch[id]; ok {
+			return stub
+		}
+
+		return b.searcher.findByID(id)
+	})
+}
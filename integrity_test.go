@@ -0,0 +1,90 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_CheckIntegrity(t *testing.T) {
+	s := stuber.New()
+
+	base, missing := uuid.New(), uuid.New()
+	a, b := uuid.New(), uuid.New()
+
+	s.PutMany(
+		&stuber.Stub{ID: base, Service: "Greeter", Method: "SayHello", Abstract: true},
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", Base: &base, DependsOn: []uuid.UUID{base}},
+		&stuber.Stub{ID: a, Service: "Greeter", Method: "SayHello", Base: &b},
+		&stuber.Stub{ID: b, Service: "Greeter", Method: "SayHello", Base: &a},
+	)
+
+	require.Len(t, s.CheckIntegrity(), 2)
+
+	for _, issue := range s.CheckIntegrity() {
+		require.Equal(t, "base", issue.Field)
+		require.Equal(t, "cycle", issue.Reason)
+	}
+
+	self := uuid.New()
+
+	issues := s.Validate(
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", DependsOn: []uuid.UUID{missing}},
+		&stuber.Stub{ID: self, Service: "Greeter", Method: "SayHello", Base: &self},
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", Base: &base},
+	)
+	require.Len(t, issues, 2)
+
+	reasons := make(map[string]string, len(issues))
+	for _, issue := range issues {
+		reasons[issue.Field] = issue.Reason
+	}
+
+	require.Equal(t, map[string]string{"dependsOn": "missing", "base": "self reference"}, reasons)
+}
+
+func TestBudgerigar_CheckIntegrity_States(t *testing.T) {
+	s := stuber.New()
+
+	stub := func(scenario, required, next string) *stuber.Stub {
+		return &stuber.Stub{
+			ID:            uuid.New(),
+			Service:       "Orders",
+			Method:        "Get",
+			Scenario:      scenario,
+			RequiredState: required,
+			NewState:      next,
+		}
+	}
+
+	typo := stub("order", "shiped", "")
+
+	s.PutMany(
+		stub("order", stuber.ScenarioStarted, "created"),
+		stub("order", "created", "paid"),
+		stub("order", "paid", "shipped"),
+		stub("order", "shipped", ""),
+		stub("order", "", "cancelled"),
+		stub("order", "cancelled", ""),
+		typo,
+	)
+
+	require.Equal(t, []stuber.IntegrityIssue{{
+		Stub:   typo.ID,
+		Field:  "requiredState",
+		State:  "shiped",
+		Reason: "unreachable",
+	}}, s.CheckIntegrity())
+
+	// The states are reached by the transitions of the validated stubs too,
+	// and not by the ones of other scenarios.
+	refund := stub("order", "refunding", "")
+
+	issues := s.Validate(refund, stub("payment", "refunding", ""))
+	require.Len(t, issues, 2)
+
+	require.Empty(t, s.Validate(refund, stub("order", "cancelled", "refunding")))
+}
@@ -0,0 +1,161 @@
+package stuber
+
+import (
+	"iter"
+
+	"github.com/google/uuid"
+)
+
+// iterValues returns an iterator over all the values stored in the storage
+// when the iteration starts.
+//
+// The storage is only locked to take the slices of the positions, which are
+// never modified in place, so the loop body may modify the storage.
+func (s *storage) iterValues() iter.Seq[Value] {
+	return func(yield func(Value) bool) {
+		s.mu.RLock()
+
+		positions := make([][]Value, 0, len(s.items))
+		for _, values := range s.items {
+			positions = append(positions, values)
+		}
+
+		s.mu.RUnlock()
+
+		for _, values := range positions {
+			for _, v := range values {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// iterAll returns an iterator over the values associated with the given left
+// and right values when the iteration starts, which is empty if they are not
+// found.
+//
+// The storage is only locked to take the slice of the position, which is
+// never modified in place, so the loop body may modify the storage.
+func (s *storage) iterAll(left, right string) iter.Seq[Value] {
+	return func(yield func(Value) bool) {
+		values, err := s.findAll(left, right)
+		if err != nil {
+			return
+		}
+
+		for _, v := range values {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// stubs converts an iterator of Value interface{} to an iterator of *Stub
+// keeping the values accepted by the given filter.
+func (s *searcher) stubs(values iter.Seq[Value], filter func(*Stub) bool) iter.Seq[*Stub] {
+	return func(yield func(*Stub) bool) {
+		for v := range values {
+			if stub, ok := v.(*Stub); ok && filter(stub) && !yield(stub) {
+				return
+			}
+		}
+	}
+}
+
+// iterUsage returns an iterator over the Stub values that have been used, or
+// that have not been used, by the searcher when the iteration starts.
+func (s *searcher) iterUsage(used bool) iter.Seq[*Stub] {
+	return func(yield func(*Stub) bool) {
+		ids := make(map[uuid.UUID]struct{})
+
+		for _, id := range s.usedIDs() {
+			ids[id] = struct{}{}
+		}
+
+		s.stubs(s.storage.iterValues(), func(stub *Stub) bool {
+			_, ok := ids[stub.ID]
+
+			return ok == used
+		})(yield)
+	}
+}
+
+// everything accepts all the Stub values.
+func everything(*Stub) bool {
+	return true
+}
+
+// Iter returns an iterator over all Stub values, in an arbitrary order.
+//
+// Unlike All, the Stub values are streamed without being collected first.
+// The iterator yields the Stub values stored when the iteration starts,
+// without holding a lock, so the loop body may modify or search the
+// Budgerigar.
+//
+// Returns:
+// - iter.Seq[*Stub]: An iterator over all Stub values.
+func (b *Budgerigar) Iter() iter.Seq[*Stub] {
+	return b.searcher.stubs(b.searcher.storage.iterValues(), everything)
+}
+
+// IterBy returns an iterator over the Stub values of the given service and
+// method, in insertion order. The iterator is empty if they are not found.
+//
+// The iterator yields the Stub values stored when the iteration starts,
+// without holding a lock, so the loop body may modify or search the
+// Budgerigar.
+//
+// Parameters:
+// - service: The service field used to search for Stub values.
+// - method: The method field used to search for Stub values.
+//
+// Returns:
+// - iter.Seq[*Stub]: An iterator over the matching Stub values.
+func (b *Budgerigar) IterBy(service, method string) iter.Seq[*Stub] {
+	return b.searcher.stubs(b.searcher.storage.iterAll(service, method), everything)
+}
+
+// IterMeta returns an iterator over the Stub values having all the given
+// labels in their Meta, in an arbitrary order.
+//
+// The iterator yields the Stub values stored when the iteration starts,
+// without holding a lock, so the loop body may modify or search the
+// Budgerigar.
+//
+// Parameters:
+// - meta: The labels the Stub values must have.
+//
+// Returns:
+// - iter.Seq[*Stub]: An iterator over the matching Stub values.
+func (b *Budgerigar) IterMeta(meta map[string]string) iter.Seq[*Stub] {
+	return b.searcher.stubs(b.searcher.storage.iterValues(), func(stub *Stub) bool {
+		return stub.MetaEquals(meta)
+	})
+}
+
+// IterUsed returns an iterator over the Stub values that have been used.
+//
+// The iterator yields the Stub values stored when the iteration starts,
+// without holding a lock, so the loop body may modify or search the
+// Budgerigar.
+//
+// Returns:
+// - iter.Seq[*Stub]: An iterator over the used Stub values.
+func (b *Budgerigar) IterUsed() iter.Seq[*Stub] {
+	return b.searcher.iterUsage(true)
+}
+
+// IterUnused returns an iterator over the Stub values that have not been used.
+//
+// The iterator yields the Stub values stored when the iteration starts,
+// without holding a lock, so the loop body may modify or search the
+// Budgerigar.
+//
+// Returns:
+// - iter.Seq[*Stub]: An iterator over the unused Stub values.
+func (b *Budgerigar) IterUnused() iter.Seq[*Stub] {
+	return b.searcher.iterUsage(false)
+}
@@ -0,0 +1,122 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Iter(t *testing.T) {
+	s := stuber.New()
+
+	greet := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	bye := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayGoodbye",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Meta:    map[string]string{"env": "staging", "ticket": "QA-42"},
+	}
+
+	s.PutMany(greet, bye)
+
+	var all []uuid.UUID
+	for stub := range s.Iter() {
+		all = append(all, stub.ID)
+	}
+
+	require.ElementsMatch(t, []uuid.UUID{greet.ID, bye.ID}, all)
+
+	count := 0
+	for range s.Iter() {
+		count++
+
+		break
+	}
+
+	require.Equal(t, 1, count)
+
+	for stub := range s.IterBy("Greeter", "SayHello") {
+		require.Equal(t, greet.ID, stub.ID)
+	}
+
+	for range s.IterBy("Greeter", "Unknown") {
+		t.Fatal("unexpected stub")
+	}
+
+	for stub := range s.IterMeta(map[string]string{"env": "staging"}) {
+		require.Equal(t, bye.ID, stub.ID)
+	}
+
+	for range s.IterMeta(map[string]string{"env": "production"}) {
+		t.Fatal("unexpected stub")
+	}
+
+	_, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+
+	for stub := range s.IterUsed() {
+		require.Equal(t, greet.ID, stub.ID)
+	}
+
+	for stub := range s.IterUnused() {
+		require.Equal(t, bye.ID, stub.ID)
+	}
+}
+
+func TestBudgerigar_Iter_Modify(t *testing.T) {
+	s := stuber.New()
+
+	for _, method := range []string{"SayHello", "SayHello", "SayGoodbye"} {
+		s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: method})
+	}
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello"}
+
+	// The loop bodies modify and search the Budgerigar, which would deadlock
+	// if the iterators held its lock.
+	var seen int
+
+	for stub := range s.Iter() {
+		seen++
+
+		require.NoError(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+			stub.Priority++
+
+			return nil
+		}))
+		s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Other", Method: stub.Method})
+	}
+
+	require.Equal(t, 3, seen)
+	require.Len(t, s.All(), 6)
+
+	for stub := range s.IterBy("Greeter", "SayHello") {
+		_, err := s.FindByQuery(query)
+		require.NoError(t, err)
+		require.Equal(t, 1, s.DeleteByID(stub.ID))
+	}
+
+	for stub := range s.IterUsed() {
+		require.Equal(t, 1, s.DeleteByID(stub.ID))
+	}
+
+	for stub := range s.IterUnused() {
+		s.Clear()
+		require.NotNil(t, stub)
+	}
+
+	require.Empty(t, s.All())
+}
@@ -0,0 +1,250 @@
+package stuber
+
+import (
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+)
+
+// Layered is a stack of Budgerigar instances searched in order.
+//
+// It allows a handful of local overrides (the primary layer) to be placed on
+// top of large shared stub sets (the fallback layers). Writes always go to
+// the primary layer; the fallback layers are never modified.
+type Layered struct {
+	layers []*Budgerigar
+}
+
+// NewLayered creates a new Layered with the given primary and fallback layers.
+//
+// Parameters:
+// - primary: The layer searched first and receiving all the writes.
+// - fallbacks: The layers searched in order when the primary layer has no match.
+//
+// Returns:
+// - *Layered: A new Layered.
+func NewLayered(primary *Budgerigar, fallbacks ...*Budgerigar) *Layered {
+	return &Layered{layers: append([]*Budgerigar{primary}, fallbacks...)}
+}
+
+// PutMany inserts the given Stub values into the primary layer.
+//
+// Parameters:
+// - values: The Stub values to insert.
+//
+// Returns:
+// - []uuid.UUID: The keys of the inserted Stub values.
+func (l *Layered) PutMany(values ...*Stub) []uuid.UUID {
+	return l.layers[0].PutMany(values...)
+}
+
+// UpdateMany updates the given Stub values in the primary layer.
+//
+// Parameters:
+// - values: The Stub values to update.
+//
+// Returns:
+// - []uuid.UUID: The keys of the updated Stub values.
+func (l *Layered) UpdateMany(values ...*Stub) []uuid.UUID {
+	return l.layers[0].UpdateMany(values...)
+}
+
+// DeleteByID deletes the Stub values with the given IDs from the primary layer.
+//
+// Parameters:
+// - ids: The UUIDs of the Stub values to delete.
+//
+// Returns:
+// - int: The number of Stub values that were successfully deleted.
+func (l *Layered) DeleteByID(ids ...uuid.UUID) int {
+	return l.layers[0].DeleteByID(ids...)
+}
+
+// FindByID retrieves the Stub value with the given ID from the first layer
+// containing it.
+//
+// Parameters:
+// - id: The UUID of the Stub value to retrieve.
+//
+// Returns:
+// - *Stub: The Stub value associated with the given ID, or nil if not found.
+func (l *Layered) FindByID(id uuid.UUID) *Stub {
+	for _, layer := range l.layers {
+		if stub := layer.FindByID(id); stub != nil {
+			return stub
+		}
+	}
+
+	return nil
+}
+
+// FindByQuery searches the layers in order and returns the first exact match.
+// Stub values of upper layers hide the ones with the same ID in lower layers,
+// so a lower layer never answers with a Stub value overridden above it.
+//
+// The layers are probed without side effects, and only the layer answering
+// the query searches it, so the other layers neither record a miss nor
+// notify their listeners. If no layer has an exact match, the primary layer
+// searches the query, answering as configured for its misses, and the similar
+// Stub value of the first layer having one is returned otherwise.
+//
+// Parameters:
+// - query: The Query used to search for a Stub value.
+//
+// Returns:
+// - *Result: The Result containing the found Stub value (if any), or nil.
+// - error: An error if no layer knows the service and method of the query.
+func (l *Layered) FindByQuery(query Query) (*Result, error) {
+	var (
+		similar *Result
+		lastErr error
+	)
+
+	for i, layer := range l.layers {
+		result, err := layer.probe(query)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		if found := result.Found(); found != nil && !l.shadowed(i, found.ID) {
+			return layer.FindByQuery(query)
+		}
+
+		if similar == nil && result.Similar() != nil {
+			similar = &Result{similar: result.similar, similars: result.similars}
+		}
+	}
+
+	// The primary layer records the miss of the stack.
+	result, err := l.layers[0].FindByQuery(query)
+	if err == nil && (result.Found() != nil || result.Similar() != nil) {
+		return result, nil
+	}
+
+	if similar != nil {
+		return similar, nil
+	}
+
+	if err != nil && lastErr == nil {
+		lastErr = err
+	}
+
+	return result, lastErr
+}
+
+// shadowed checks if a layer above the given one has a Stub value with the
+// given ID.
+func (l *Layered) shadowed(layer int, id uuid.UUID) bool {
+	for _, upper := range l.layers[:layer] {
+		if upper.FindByID(id) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// probe searches the query without side effects: the query is internal, so
+// no Stub value is marked as used and no miss is recorded.
+func (b *Budgerigar) probe(query Query) (*Result, error) {
+	query = b.canonicalQuery(query)
+
+	flags := []features.Flag{RequestInternalFlag}
+	if query.ExactOnly() {
+		flags = append(flags, RequestExactFlag)
+	}
+
+	query.toggles = features.New(flags...)
+
+	return b.find(query)
+}
+
+// FindBy retrieves the Stub values matching the given service and method from
+// all the layers. Stub values of upper layers hide the ones with the same ID
+// in lower layers.
+//
+// Parameters:
+// - service: The service field used to search for Stub values.
+// - method: The method field used to search for Stub values.
+//
+// Returns:
+// - []*Stub: The Stub values that match the given service and method.
+// - error: An error if no layer knows the service and method.
+func (l *Layered) FindBy(service, method string) ([]*Stub, error) {
+	var (
+		groups  [][]*Stub
+		lastErr error
+	)
+
+	for _, layer := range l.layers {
+		stubs, err := layer.FindBy(service, method)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		groups = append(groups, stubs)
+	}
+
+	if groups == nil {
+		return nil, lastErr
+	}
+
+	return mergeLayers(groups...), nil
+}
+
+// All returns the Stub values of all the layers.
+//
+// Returns:
+// - []*Stub: All Stub values.
+func (l *Layered) All() []*Stub {
+	return l.collect((*Budgerigar).All)
+}
+
+// Used returns the used Stub values of all the layers.
+//
+// Returns:
+// - []*Stub: All used Stub values.
+func (l *Layered) Used() []*Stub {
+	return l.collect((*Budgerigar).Used)
+}
+
+// Unused returns the unused Stub values of all the layers.
+//
+// Returns:
+// - []*Stub: All unused Stub values.
+func (l *Layered) Unused() []*Stub {
+	return l.collect((*Budgerigar).Unused)
+}
+
+// collect merges the Stub values returned by fn for each layer.
+func (l *Layered) collect(fn func(*Budgerigar) []*Stub) []*Stub {
+	groups := make([][]*Stub, len(l.layers))
+	for i, layer := range l.layers {
+		groups[i] = fn(layer)
+	}
+
+	return mergeLayers(groups...)
+}
+
+// mergeLayers concatenates the given groups of Stub values, skipping the
+// values whose ID was already seen in a previous group.
+func mergeLayers(groups ...[]*Stub) []*Stub {
+	seen := make(map[uuid.UUID]struct{})
+	result := make([]*Stub, 0)
+
+	for _, group := range groups {
+		for _, stub := range group {
+			if _, ok := seen[stub.ID]; ok {
+				continue
+			}
+
+			seen[stub.ID] = struct{}{}
+			result = append(result, stub)
+		}
+	}
+
+	return result
+}
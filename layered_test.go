@@ -0,0 +1,123 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestLayered_FindByQuery(t *testing.T) {
+	shared := stuber.NewBudgerigar(features.New())
+	local := stuber.NewBudgerigar(features.New())
+
+	shared.PutMany(
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "shared"}},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayBye",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "bye"}},
+		},
+	)
+
+	s := stuber.NewLayered(local, shared)
+
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "local"}},
+	})
+
+	require.Len(t, local.All(), 1)
+	require.Len(t, shared.All(), 2)
+	require.Len(t, s.All(), 3)
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	r, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, "local", r.Found().Output.Data["message"])
+
+	// The primary layer doesn't know the method: the fallback answers.
+	query.Method = "SayBye"
+
+	r, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, "bye", r.Found().Output.Data["message"])
+
+	stubs, err := s.FindBy("Greeter", "SayHello")
+	require.NoError(t, err)
+	require.Len(t, stubs, 2)
+
+	_, err = s.FindByQuery(stuber.Query{Service: "Unknown", Method: "SayBye"})
+	require.ErrorIs(t, err, stuber.ErrServiceNotFound)
+}
+
+func TestLayered_FindByQuery_Shadowed(t *testing.T) {
+	shared := stuber.New(stuber.WithMissLog(10))
+	local := stuber.New(stuber.WithMissLog(10))
+
+	id := uuid.New()
+
+	shared.PutMany(
+		&stuber.Stub{
+			ID:      id,
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "shared"}},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "alice"}},
+		},
+	)
+
+	s := stuber.NewLayered(local, shared)
+
+	// The local override hides the shared stub with the same ID.
+	s.PutMany(&stuber.Stub{
+		ID:      id,
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Eve"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "local"}},
+	})
+
+	query := func(name string) stuber.Query {
+		return stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": name}}
+	}
+
+	_, err := s.FindByQuery(query("Bob"))
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+	require.Len(t, local.Misses(), 1)
+
+	// The fallback answering the query, the primary records no miss.
+	r, err := s.FindByQuery(query("Alice"))
+	require.NoError(t, err)
+	require.Equal(t, "alice", r.Found().Output.Data["message"])
+	require.Len(t, local.Misses(), 1)
+	require.Empty(t, shared.Misses())
+	require.Len(t, shared.Used(), 1)
+	require.Empty(t, local.Used())
+
+	r, err = s.FindByQuery(query("Eve"))
+	require.NoError(t, err)
+	require.Equal(t, "local", r.Found().Output.Data["message"])
+}
@@ -0,0 +1,303 @@
+package stuber
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// Severity is the severity of a lint finding.
+type Severity string
+
+// Severities of the lint findings.
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Names of the default lint rules.
+const (
+	RuleRegexMatchesEverything = "regex-matches-everything"
+	RuleFloatEquals            = "float-equals"
+	RulePriorityCollision      = "priority-collision"
+	RuleErrorWithoutCode       = "error-without-code"
+)
+
+// LintFinding is a problem found in a stub by a lint rule.
+type LintFinding struct {
+	Stub     uuid.UUID `json:"stub"`            // The stub with the problem.
+	Rule     string    `json:"rule"`            // The name of the rule that found it.
+	Severity Severity  `json:"severity"`        // The severity of the rule.
+	Field    string    `json:"field,omitempty"` // The field with the problem, such as "input.matches.name".
+	Message  string    `json:"message"`         // What the problem is.
+}
+
+// LintRule checks the stubs for a kind of problem.
+//
+// The Rule and Severity of the findings returned by Check are set by Lint,
+// so the severity of a rule can be changed without changing its check.
+type LintRule struct {
+	Name     string                            // The name of the rule.
+	Severity Severity                          // The severity of its findings.
+	Check    func(stubs []*Stub) []LintFinding // Returns the problems found in the stubs.
+}
+
+// DefaultLintRules returns the built-in lint rules with their default
+// severities:
+//   - regex-matches-everything: a regular expression matcher accepts any
+//     value, such as ".*", which is probably a mistake.
+//   - float-equals: an exact matcher compares a fractional number, which
+//     may not survive the encoding of the request.
+//   - priority-collision: stubs of the same method have the same priority
+//     and the same matchers, so which one answers is arbitrary.
+//   - error-without-code: an output has an error message but no error code,
+//     so the server answers it with its default code.
+//
+// Returns:
+// - []LintRule: The default rules.
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{Name: RuleRegexMatchesEverything, Severity: SeverityWarning, Check: lintRegexMatchesEverything},
+		{Name: RuleFloatEquals, Severity: SeverityWarning, Check: lintFloatEquals},
+		{Name: RulePriorityCollision, Severity: SeverityError, Check: lintPriorityCollision},
+		{Name: RuleErrorWithoutCode, Severity: SeverityInfo, Check: lintErrorWithoutCode},
+	}
+}
+
+// Lint checks the given stubs with the given rules, such as to gate the
+// quality of the stub files in CI.
+//
+// Parameters:
+// - stubs: The Stub values to check.
+// - rules: The rules to check them with, DefaultLintRules if none.
+//
+// Returns:
+// - []LintFinding: The problems found, sorted by stub, rule and field.
+func Lint(stubs []*Stub, rules []LintRule) []LintFinding {
+	if rules == nil {
+		rules = DefaultLintRules()
+	}
+
+	var findings []LintFinding
+
+	for _, rule := range rules {
+		for _, finding := range rule.Check(stubs) {
+			finding.Rule = rule.Name
+			finding.Severity = rule.Severity
+
+			findings = append(findings, finding)
+		}
+	}
+
+	slices.SortFunc(findings, func(a, b LintFinding) int {
+		return cmp.Or(
+			cmp.Compare(a.Stub.String(), b.Stub.String()),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.Field, b.Field),
+		)
+	})
+
+	return findings
+}
+
+// Lint checks the stored Stub values with the given rules, DefaultLintRules
+// if none.
+//
+// Parameters:
+// - rules: The rules to check the Stub values with.
+//
+// Returns:
+// - []LintFinding: The problems found, sorted by stub, rule and field.
+func (b *Budgerigar) Lint(rules ...LintRule) []LintFinding {
+	return Lint(b.searcher.all(), rules)
+}
+
+// lintInputs calls the given function with the matchers of the input and
+// the headers of each stub, along with the prefix of their fields.
+func lintInputs(stubs []*Stub, fn func(stub *Stub, prefix string, matchers Matchers)) {
+	for _, stub := range stubs {
+		fn(stub, "headers.", stub.Headers.Matchers())
+		fn(stub, "input.", stub.Input.Matchers())
+
+		for i, input := range stub.Inputs {
+			fn(stub, "inputs["+strconv.Itoa(i)+"].", input.Matchers())
+		}
+	}
+}
+
+// walkValues calls the given function with each scalar value of the given
+// value, along with its field.
+func walkValues(field string, value any, fn func(field string, value any)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			walkValues(field+"."+key, v[key], fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkValues(field+"["+strconv.Itoa(i)+"]", item, fn)
+		}
+	default:
+		fn(field, value)
+	}
+}
+
+// regexProbes are values that only a regular expression matching everything
+// matches all together.
+var regexProbes = []string{"", "\n", "stuber lint probe ☃"} //nolint:gochecknoglobals
+
+// lintRegexMatchesEverything finds the regular expression matchers that
+// match any value.
+func lintRegexMatchesEverything(stubs []*Stub) []LintFinding {
+	var findings []LintFinding
+
+	lintInputs(stubs, func(stub *Stub, prefix string, matchers Matchers) {
+		walkValues(prefix+MatcherMatches.String(), matchers[MatcherMatches], func(field string, value any) {
+			pattern, ok := value.(string)
+			if !ok {
+				return
+			}
+
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return
+			}
+
+			for _, probe := range regexProbes {
+				if !re.MatchString(probe) {
+					return
+				}
+			}
+
+			findings = append(findings, LintFinding{
+				Stub:    stub.ID,
+				Field:   field,
+				Message: fmt.Sprintf("regular expression %q matches everything", pattern),
+			})
+		})
+	})
+
+	return findings
+}
+
+// lintFloatEquals finds the exact matchers of fractional numbers.
+func lintFloatEquals(stubs []*Stub) []LintFinding {
+	var findings []LintFinding
+
+	lintInputs(stubs, func(stub *Stub, prefix string, matchers Matchers) {
+		walkValues(prefix+MatcherEquals.String(), matchers[MatcherEquals], func(field string, value any) {
+			if !isFractional(value) {
+				return
+			}
+
+			findings = append(findings, LintFinding{
+				Stub:    stub.ID,
+				Field:   field,
+				Message: fmt.Sprintf("exact match on the floating point number %v", value),
+			})
+		})
+	})
+
+	return findings
+}
+
+// isFractional checks if the value is a number with a fractional part.
+func isFractional(value any) bool {
+	switch v := value.(type) {
+	case float64:
+		return v != math.Trunc(v)
+	case float32:
+		return float64(v) != math.Trunc(float64(v))
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return false
+		}
+
+		f, err := v.Float64()
+
+		return err == nil && f != math.Trunc(f)
+	default:
+		return false
+	}
+}
+
+// lintPriorityCollision finds the stubs of the same method with the same
+// priority and the same matchers.
+//
+// Abstract stubs and stubs of ordered groups are ignored, as they are never
+// found by their matchers alone.
+func lintPriorityCollision(stubs []*Stub) []LintFinding {
+	type criteria struct {
+		Headers       InputHeader
+		Input         InputData
+		Inputs        []InputData
+		Size          Comparison
+		MessageCount  Comparison
+		Scenario      string
+		RequiredState string
+	}
+
+	seen := make(map[string]*Stub)
+
+	var findings []LintFinding
+
+	for _, stub := range SortStubs(slices.Clone(stubs)) {
+		if stub.Abstract || stub.OrderedGroup != "" {
+			continue
+		}
+
+		encoded, err := json.Marshal(criteria{
+			stub.Headers, stub.Input, stub.Inputs, stub.Size, stub.MessageCount, stub.Scenario, stub.RequiredState,
+		})
+		if err != nil {
+			continue
+		}
+
+		key := strings.Join([]string{stub.Service, stub.Method, strconv.Itoa(stub.Priority), string(encoded)}, "\x00")
+
+		first, ok := seen[key]
+		if !ok {
+			seen[key] = stub
+
+			continue
+		}
+
+		findings = append(findings, LintFinding{
+			Stub:    stub.ID,
+			Field:   "priority",
+			Message: fmt.Sprintf("same priority %d and matchers as stub %s", stub.Priority, first.ID),
+		})
+	}
+
+	return findings
+}
+
+// lintErrorWithoutCode finds the outputs with an error message but no error
+// code.
+func lintErrorWithoutCode(stubs []*Stub) []LintFinding {
+	var findings []LintFinding
+
+	for _, stub := range stubs {
+		if stub.Output.Error == "" || (stub.Output.Code != nil && *stub.Output.Code != codes.OK) {
+			continue
+		}
+
+		findings = append(findings, LintFinding{
+			Stub:    stub.ID,
+			Field:   "output.code",
+			Message: "output error without an error code",
+		})
+	}
+
+	return findings
+}
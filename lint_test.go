@@ -0,0 +1,80 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestLint(t *testing.T) {
+	internal := codes.Internal
+
+	first := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: stuber.InputHeader{Matches: map[string]interface{}{"authorization": "^Bearer .+$"}},
+		Input: stuber.InputData{
+			Equals:  map[string]interface{}{"amount": 12.5, "count": 3.0},
+			Matches: map[string]interface{}{"name": ".*", "tags": []interface{}{"^a.*"}},
+		},
+		Output: stuber.Output{Error: "boom"},
+	}
+	second := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: first.Headers,
+		Input:   first.Input,
+		Output:  stuber.Output{Error: "boom", Code: &internal},
+	}
+	other := &stuber.Stub{
+		ID:       uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+		Service:  "Greeter",
+		Method:   "SayHello",
+		Priority: 1,
+		Input:    first.Input,
+	}
+
+	findings := stuber.Lint([]*stuber.Stub{other, second, first}, nil)
+
+	require.Equal(t, []stuber.LintFinding{
+		{Stub: first.ID, Rule: stuber.RuleErrorWithoutCode, Severity: stuber.SeverityInfo, Field: "output.code", Message: "output error without an error code"},
+		{Stub: first.ID, Rule: stuber.RuleFloatEquals, Severity: stuber.SeverityWarning, Field: "input.equals.amount", Message: "exact match on the floating point number 12.5"},
+		{Stub: first.ID, Rule: stuber.RuleRegexMatchesEverything, Severity: stuber.SeverityWarning, Field: "input.matches.name", Message: `regular expression ".*" matches everything`},
+		{Stub: second.ID, Rule: stuber.RuleFloatEquals, Severity: stuber.SeverityWarning, Field: "input.equals.amount", Message: "exact match on the floating point number 12.5"},
+		{Stub: second.ID, Rule: stuber.RulePriorityCollision, Severity: stuber.SeverityError, Field: "priority", Message: "same priority 0 and matchers as stub " + first.ID.String()},
+		{Stub: second.ID, Rule: stuber.RuleRegexMatchesEverything, Severity: stuber.SeverityWarning, Field: "input.matches.name", Message: `regular expression ".*" matches everything`},
+		{Stub: other.ID, Rule: stuber.RuleFloatEquals, Severity: stuber.SeverityWarning, Field: "input.equals.amount", Message: "exact match on the floating point number 12.5"},
+		{Stub: other.ID, Rule: stuber.RuleRegexMatchesEverything, Severity: stuber.SeverityWarning, Field: "input.matches.name", Message: `regular expression ".*" matches everything`},
+	}, findings)
+}
+
+func TestLint_Rules(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"amount": 12.5}},
+		Output:  stuber.Output{Error: "boom"},
+	})
+
+	rules := stuber.DefaultLintRules()
+	for i := range rules {
+		rules[i].Severity = stuber.SeverityError
+	}
+
+	findings := s.Lint(rules[1], rules[3])
+	require.Len(t, findings, 2)
+
+	for _, finding := range findings {
+		require.Equal(t, stuber.SeverityError, finding.Severity)
+	}
+
+	require.Len(t, s.Lint(), 2)
+	require.Empty(t, s.Lint(rules[0]))
+}
@@ -0,0 +1,185 @@
+package stuber
+
+import (
+	"slices"
+	"time"
+)
+
+// StubEvent is a typed event delivered to an EventListener: StubAdded,
+// StubUpdated, StubDeleted, StubExpired, StubsCleared, StubMatched or
+// StubMissed.
+type StubEvent interface {
+	// EventTime returns when the event happened.
+	EventTime() time.Time
+}
+
+// StubAdded is delivered when a stub is inserted or replaced.
+type StubAdded struct {
+	Time time.Time
+	Stub *Stub
+}
+
+// StubUpdated is delivered when a stub is patched.
+type StubUpdated struct {
+	Time time.Time
+	Stub *Stub
+}
+
+// StubDeleted is delivered when a stub is deleted.
+type StubDeleted struct {
+	Time time.Time
+	Stub *Stub
+}
+
+// StubExpired is delivered when a stub fetched from the remote source is
+// deleted because its cache expired.
+type StubExpired struct {
+	Time time.Time
+	Stub *Stub
+}
+
+// StubsCleared is delivered when all the stubs are deleted by Clear.
+type StubsCleared struct {
+	Time time.Time
+}
+
+// StubMatched is delivered when a non-internal query matches a stub.
+type StubMatched struct {
+	Time  time.Time
+	Stub  *Stub // The matched stub, with the output as answered.
+	Query Query // The query.
+}
+
+// StubMissed is delivered when a non-internal query matches no stub.
+type StubMissed struct {
+	Time    time.Time
+	Query   Query // The query.
+	Closest *Stub // The most similar stub, if any.
+}
+
+func (e StubAdded) EventTime() time.Time    { return e.Time }
+func (e StubUpdated) EventTime() time.Time  { return e.Time }
+func (e StubDeleted) EventTime() time.Time  { return e.Time }
+func (e StubExpired) EventTime() time.Time  { return e.Time }
+func (e StubsCleared) EventTime() time.Time { return e.Time }
+func (e StubMatched) EventTime() time.Time  { return e.Time }
+func (e StubMissed) EventTime() time.Time   { return e.Time }
+
+// EventListener receives the typed events of a Budgerigar, such as to log,
+// trace or audit the matcher activity.
+//
+// The events are delivered synchronously by the operation publishing them,
+// once it is applied, so the listener must return quickly. It may call the
+// Budgerigar.
+type EventListener interface {
+	OnEvent(event StubEvent)
+}
+
+// EventListenerFunc is a function used as an EventListener.
+type EventListenerFunc func(event StubEvent)
+
+// OnEvent calls the function.
+func (f EventListenerFunc) OnEvent(event StubEvent) {
+	f(event)
+}
+
+// listenerEntry is a registered EventListener, compared by identity when
+// it is removed.
+type listenerEntry struct {
+	listener EventListener
+}
+
+// listen registers the listener and returns the function removing it.
+func (b *eventBus) listen(listener EventListener) func() {
+	entry := &listenerEntry{listener: listener}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.listeners = append(b.listeners, entry)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.listeners = slices.DeleteFunc(b.listeners, func(other *listenerEntry) bool { return other == entry })
+	}
+}
+
+// notify delivers the events built by the given function to the listeners,
+// outside of the mutex so they may call the Budgerigar.
+func (b *eventBus) notify(events func(now time.Time) []StubEvent) {
+	b.mu.RLock()
+	listeners := slices.Clone(b.listeners)
+	b.mu.RUnlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	for _, event := range events(b.now()) {
+		for _, entry := range listeners {
+			entry.listener.OnEvent(event)
+		}
+	}
+}
+
+// changed delivers the typed events of a change of the given type to the
+// listeners. The matches are delivered by matched, with their query.
+func (b *eventBus) changed(typ EventType, stubs []*Stub) {
+	b.notify(func(now time.Time) []StubEvent {
+		events := make([]StubEvent, 0, len(stubs))
+
+		for _, stub := range stubs {
+			switch typ {
+			case EventPut:
+				events = append(events, StubAdded{Time: now, Stub: stub})
+			case EventUpdate:
+				events = append(events, StubUpdated{Time: now, Stub: stub})
+			case EventDelete:
+				events = append(events, StubDeleted{Time: now, Stub: stub})
+			case EventExpire:
+				events = append(events, StubExpired{Time: now, Stub: stub})
+			case EventClear:
+				events = append(events, StubsCleared{Time: now})
+			case EventMatch:
+			}
+		}
+
+		return events
+	})
+}
+
+// matched publishes the match of the query.
+func (b *eventBus) matched(stub *Stub, query Query) {
+	b.publish(EventMatch, stub)
+	b.notify(func(now time.Time) []StubEvent {
+		return []StubEvent{StubMatched{Time: now, Stub: stub, Query: query}}
+	})
+}
+
+// missed publishes the miss of the query to the listeners.
+func (b *eventBus) missed(query Query, result *Result) {
+	b.notify(func(now time.Time) []StubEvent {
+		missed := StubMissed{Time: now, Query: query}
+		if result != nil {
+			missed.Closest = result.similar
+		}
+
+		return []StubEvent{missed}
+	})
+}
+
+// Listen registers a listener of the typed events of the Budgerigar: the
+// changes of the stubs, and the matches and misses of non-internal queries.
+//
+// Subscribe delivers the same changes and matches through a channel.
+//
+// Parameters:
+// - listener: The EventListener to register.
+//
+// Returns:
+// - func(): The function removing the listener.
+func (b *Budgerigar) Listen(listener EventListener) func() {
+	return b.searcher.events.listen(listener)
+}
@@ -0,0 +1,145 @@
+package stuber_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+// recorder is an EventListener recording the events.
+type recorder struct {
+	mu     sync.Mutex
+	events []stuber.StubEvent
+}
+
+func (r *recorder) OnEvent(event stuber.StubEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) take() []stuber.StubEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.events
+	r.events = nil
+
+	return events
+}
+
+// staticSource is a RemoteSource of fixed stubs.
+type staticSource []*stuber.Stub
+
+func (s staticSource) Fetch(service, method string) ([]*stuber.Stub, error) {
+	var stubs []*stuber.Stub
+
+	for _, stub := range s {
+		if stub.Service == service && stub.Method == method {
+			clone := *stub
+			clone.ID = uuid.New()
+			stubs = append(stubs, &clone)
+		}
+	}
+
+	return stubs, nil
+}
+
+func TestBudgerigar_Listen(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	events := &recorder{}
+	stop := s.Listen(events)
+
+	hello := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	s.PutMany(hello)
+	require.Equal(t, []stuber.StubEvent{stuber.StubAdded{Time: now, Stub: hello}}, events.take())
+
+	require.NoError(t, s.PatchByID(hello.ID, func(stub *stuber.Stub) error {
+		stub.Priority = 1
+
+		return nil
+	}))
+
+	updated := events.take()
+	require.Len(t, updated, 1)
+	require.IsType(t, stuber.StubUpdated{}, updated[0])
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	_, err := s.FindByQuery(query)
+	require.NoError(t, err)
+
+	matched := events.take()
+	require.Len(t, matched, 1)
+	require.Equal(t, hello.ID, matched[0].(stuber.StubMatched).Stub.ID)
+	require.Equal(t, "Bob", matched[0].(stuber.StubMatched).Query.Data["name"])
+
+	_, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bo"}})
+	require.NoError(t, err)
+
+	missed := events.take()
+	require.Len(t, missed, 1)
+	require.Equal(t, hello.ID, missed[0].(stuber.StubMissed).Closest.ID)
+
+	// Internal queries are not delivered.
+	_, err = s.ExplainQuery(query)
+	require.NoError(t, err)
+	require.Empty(t, events.take())
+
+	s.DeleteByID(hello.ID)
+
+	deleted := events.take()
+	require.Len(t, deleted, 1)
+	require.Equal(t, hello.ID, deleted[0].(stuber.StubDeleted).Stub.ID)
+
+	s.Clear()
+	require.Equal(t, []stuber.StubEvent{stuber.StubsCleared{Time: now}}, events.take())
+
+	stop()
+	s.PutMany(hello)
+	require.Empty(t, events.take())
+}
+
+func TestBudgerigar_Listen_Expired(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(
+		stuber.WithClock(func() time.Time { return now }),
+		stuber.WithRemoteSource(staticSource{{Service: "Greeter", Method: "SayHello"}}, time.Minute),
+	)
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello"}
+
+	_, err := s.FindByQuery(query)
+	require.NoError(t, err)
+
+	fetched := s.All()[0]
+
+	var expired []uuid.UUID
+
+	s.Listen(stuber.EventListenerFunc(func(event stuber.StubEvent) {
+		if event, ok := event.(stuber.StubExpired); ok {
+			expired = append(expired, event.Stub.ID)
+		}
+	}))
+
+	now = now.Add(time.Minute)
+
+	_, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{fetched.ID}, expired)
+}
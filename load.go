@@ -0,0 +1,367 @@
+package stuber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ErrNoQueries is returned when a LoadGenerator has neither stubs nor
+// methods to generate queries for.
+var ErrNoQueries = errors.New("no queries to generate")
+
+// ErrInvalidRate is returned when a load run has no positive rate.
+var ErrInvalidRate = errors.New("invalid query rate")
+
+// Default settings of a LoadGenerator.
+const (
+	defaultStubWeight = 0.8
+	maxLoadDepth      = 3 // The depth of the nested messages of the random queries.
+	maxLoadRepeated   = 3 // The number of items of the repeated fields of the random queries.
+)
+
+// unsupportedLoadMessages are the well-known messages whose random values
+// cannot be encoded in JSON, left unset in the random queries.
+var unsupportedLoadMessages = map[protoreflect.FullName]struct{}{ //nolint:gochecknoglobals
+	"google.protobuf.Any":       {},
+	"google.protobuf.Struct":    {},
+	"google.protobuf.Value":     {},
+	"google.protobuf.ListValue": {},
+}
+
+// LoadOptions configures a LoadGenerator.
+type LoadOptions struct {
+	// Seed seeds the generator, so the same options generate the same queries.
+	Seed uint64
+	// StubWeight is the share of the queries built from the stubs, 0.8 by
+	// default. The others are random messages of the methods. A negative
+	// weight builds none from the stubs.
+	StubWeight float64
+}
+
+// withDefaults returns the options with the zero values replaced by the defaults.
+func (o LoadOptions) withDefaults() LoadOptions {
+	if o.StubWeight == 0 {
+		o.StubWeight = defaultStubWeight
+	}
+
+	return o
+}
+
+// LoadReport is the outcome of a load run.
+type LoadReport struct {
+	Queries   int           `json:"queries"`   // The number of queries sent.
+	Matches   int           `json:"matches"`   // The number of queries a stub answered.
+	MatchRate float64       `json:"matchRate"` // The share of the queries a stub answered.
+	Elapsed   time.Duration `json:"elapsed"`   // How long the run took.
+	P50       time.Duration `json:"p50"`       // The median latency of the searches.
+	P90       time.Duration `json:"p90"`       // The 90th percentile of the latency of the searches.
+	P99       time.Duration `json:"p99"`       // The 99th percentile of the latency of the searches.
+	Max       time.Duration `json:"max"`       // The highest latency of the searches.
+}
+
+// loadMethod is a method of the descriptors with the name of its service
+// used by the stubs.
+type loadMethod struct {
+	service string
+	desc    protoreflect.MethodDescriptor
+}
+
+// LoadGenerator generates random queries for the stubs of a Budgerigar and
+// the methods of its services, to size a mock deployment.
+//
+// A LoadGenerator is safe for concurrent use.
+type LoadGenerator struct {
+	budgerigar *Budgerigar
+	stubs      []*Stub
+	methods    []loadMethod
+	stubWeight float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewLoadGenerator creates a LoadGenerator of queries for the stored Stub
+// values, which are snapshotted, and for the methods of the descriptors.
+//
+// The services are named like the Stub values name them, by their full name
+// or by their name.
+//
+// Parameters:
+// - b: The Budgerigar to generate queries for.
+// - descriptors: A serialized FileDescriptorSet of the services, such as
+// written by protoc --descriptor_set_out --include_imports, or nil.
+// - opts: The seed and the weight of the stubs.
+//
+// Returns:
+// - *LoadGenerator: The new generator.
+// - error: An error if the descriptors cannot be decoded, or ErrNoQueries
+// if there are neither Stub values nor methods.
+func NewLoadGenerator(b *Budgerigar, descriptors []byte, opts LoadOptions) (*LoadGenerator, error) {
+	opts = opts.withDefaults()
+
+	g := &LoadGenerator{
+		budgerigar: b,
+		stubWeight: opts.StubWeight,
+		rand:       rand.New(rand.NewPCG(opts.Seed, opts.Seed)), //nolint:gosec
+	}
+
+	services := make(map[string]struct{})
+
+	for _, stub := range SortStubs(b.searcher.all()) {
+		if !stub.Abstract {
+			g.stubs = append(g.stubs, b.searcher.resolve(stub))
+			services[stub.Service] = struct{}{}
+		}
+	}
+
+	files, err := parseDescriptors(descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := range file.Services().Len() {
+			service := file.Services().Get(i)
+
+			name := string(service.FullName())
+			if _, ok := services[name]; !ok {
+				if _, ok := services[string(service.Name())]; ok {
+					name = string(service.Name())
+				}
+			}
+
+			for j := range service.Methods().Len() {
+				g.methods = append(g.methods, loadMethod{service: name, desc: service.Methods().Get(j)})
+			}
+		}
+
+		return true
+	})
+
+	slices.SortFunc(g.methods, func(a, b loadMethod) int {
+		return strings.Compare(string(a.desc.FullName()), string(b.desc.FullName()))
+	})
+
+	if len(g.stubs) == 0 && len(g.methods) == 0 {
+		return nil, ErrNoQueries
+	}
+
+	return g, nil
+}
+
+// Next returns a random query: an example of a random Stub value, as many
+// times as the weight of the stubs, or a random message of a random method.
+//
+// Returns:
+// - Query: The random query.
+func (g *LoadGenerator) Next() Query {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.stubs) > 0 && (len(g.methods) == 0 || g.rand.Float64() < g.stubWeight) {
+		return g.stubQuery(g.stubs[g.rand.IntN(len(g.stubs))])
+	}
+
+	return g.methodQuery(g.methods[g.rand.IntN(len(g.methods))])
+}
+
+// stubQuery returns a query of one of the examples of the stub, or of the
+// example generated from its matchers.
+func (g *LoadGenerator) stubQuery(stub *Stub) Query {
+	var example Example
+	if len(stub.Examples) > 0 {
+		example = stub.Examples[g.rand.IntN(len(stub.Examples))]
+	} else {
+		example = GenerateExample(stub)
+	}
+
+	return Query{Service: stub.Service, Method: stub.Method, Headers: example.Headers, Data: example.Data}
+}
+
+// methodQuery returns a query of a random message of the method.
+func (g *LoadGenerator) methodQuery(method loadMethod) Query {
+	query := Query{Service: method.service, Method: string(method.desc.Name())}
+
+	// The random values are valid, so the message can always be encoded.
+	query.Data, _ = messageData(g.randomMessage(method.desc.Input(), 0))
+
+	return query
+}
+
+// randomMessage returns a message of the descriptor with random values, its
+// nested messages being left unset below maxLoadDepth.
+func (g *LoadGenerator) randomMessage(desc protoreflect.MessageDescriptor, depth int) *dynamicpb.Message {
+	message := dynamicpb.NewMessage(desc)
+	fields := desc.Fields()
+
+	for i := range fields.Len() {
+		field := fields.Get(i)
+
+		switch {
+		case field.IsMap():
+			continue
+		case field.IsList():
+			list := message.Mutable(field).List()
+
+			for range g.rand.IntN(maxLoadRepeated + 1) {
+				if value, ok := g.randomValue(field, depth); ok {
+					list.Append(value)
+				}
+			}
+		default:
+			if value, ok := g.randomValue(field, depth); ok {
+				message.Set(field, value)
+			}
+		}
+	}
+
+	return message
+}
+
+// randomValue returns a random value of the field, and whether it has one.
+func (g *LoadGenerator) randomValue(field protoreflect.FieldDescriptor, depth int) (protoreflect.Value, bool) {
+	const bound = 1000
+
+	r := g.rand
+
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(r.IntN(2) == 1), true
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(r.Int32N(bound)), true
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(r.Int64N(bound)), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(r.Uint32N(bound)), true
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(r.Uint64N(bound)), true
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(r.Float32() * bound), true
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(r.Float64() * bound), true
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(g.randomWord()), true
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(g.randomWord())), true
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+
+		return protoreflect.ValueOfEnum(values.Get(r.IntN(values.Len())).Number()), true
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if _, ok := unsupportedLoadMessages[field.Message().FullName()]; ok || depth >= maxLoadDepth {
+			return protoreflect.Value{}, false
+		}
+
+		return protoreflect.ValueOfMessage(g.randomMessage(field.Message(), depth+1)), true
+	default:
+		return protoreflect.Value{}, false
+	}
+}
+
+// randomWord returns a random lowercase word.
+func (g *LoadGenerator) randomWord() string {
+	word := make([]byte, 4+g.rand.IntN(7))
+	for i := range word {
+		word[i] = byte('a' + g.rand.IntN(26))
+	}
+
+	return string(word)
+}
+
+// RunLoad sends the generated queries to the Budgerigar at the given rate
+// for the given duration, and reports the share of them a stub answered and
+// the latency percentiles of their searches.
+//
+// The queries are sent one after the other: when a search is late, the next
+// ones are sent without waiting until the run catches up. The queries are
+// not internal, so they mark the Stub values as used.
+//
+// Parameters:
+// - ctx: The context of the run, which stops it early when done.
+// - qps: The number of queries sent per second.
+// - duration: How long the run lasts.
+//
+// Returns:
+// - LoadReport: The outcome of the run, partial if it stopped early.
+// - error: ErrInvalidRate if the rate is not positive, or the error of the
+// context if it stopped the run early.
+func (g *LoadGenerator) RunLoad(ctx context.Context, qps int, duration time.Duration) (LoadReport, error) {
+	if qps <= 0 {
+		return LoadReport{}, fmt.Errorf("%w: %d", ErrInvalidRate, qps)
+	}
+
+	var (
+		interval  = time.Second / time.Duration(qps)
+		start     = time.Now()
+		latencies []time.Duration
+		report    LoadReport
+		err       error
+	)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for i := 0; ; i++ {
+		at := start.Add(time.Duration(i) * interval)
+		if at.Sub(start) >= duration {
+			break
+		}
+
+		timer.Reset(time.Until(at))
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-timer.C:
+		}
+
+		if err != nil {
+			break
+		}
+
+		query := g.Next()
+
+		began := time.Now()
+		result, findErr := g.budgerigar.FindByQuery(query)
+		latencies = append(latencies, time.Since(began))
+
+		if findErr == nil && result.Found() != nil {
+			report.Matches++
+		}
+
+		result.Done()
+	}
+
+	report.Elapsed = time.Since(start)
+	report.Queries = len(latencies)
+
+	if report.Queries > 0 {
+		report.MatchRate = float64(report.Matches) / float64(report.Queries)
+
+		slices.Sort(latencies)
+
+		report.P50 = percentile(latencies, 0.50)
+		report.P90 = percentile(latencies, 0.90)
+		report.P99 = percentile(latencies, 0.99)
+		report.Max = latencies[len(latencies)-1]
+	}
+
+	return report, err
+}
+
+// percentile returns the given percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+	return sorted[max(i, 0)]
+}
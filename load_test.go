@@ -0,0 +1,81 @@
+package stuber_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestLoadGenerator(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		Service: "helloworld.Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"user_name": "Bob"}},
+	})
+
+	g, err := stuber.NewLoadGenerator(s, greeterDescriptors(t), stuber.LoadOptions{Seed: 1})
+	require.NoError(t, err)
+
+	var fromStubs, random int
+
+	for range 100 {
+		query := g.Next()
+		require.Equal(t, "helloworld.Greeter", query.Service)
+		require.Equal(t, "SayHello", query.Method)
+
+		if query.Data["user_name"] == "Bob" {
+			fromStubs++
+		} else {
+			random++
+
+			require.Contains(t, query.Data, "user_name")
+		}
+	}
+
+	require.Greater(t, fromStubs, random)
+	require.Positive(t, random)
+
+	report, err := g.RunLoad(context.Background(), 1000, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Positive(t, report.Queries)
+	require.Positive(t, report.Matches)
+	require.InDelta(t, float64(report.Matches)/float64(report.Queries), report.MatchRate, 1e-9)
+	require.LessOrEqual(t, report.P50, report.P90)
+	require.LessOrEqual(t, report.P90, report.P99)
+	require.LessOrEqual(t, report.P99, report.Max)
+
+	_, err = g.RunLoad(context.Background(), 0, time.Second)
+	require.ErrorIs(t, err, stuber.ErrInvalidRate)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = g.RunLoad(ctx, 10, time.Second)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoadGenerator_Random(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"user_name": "Bob"}},
+	})
+
+	g, err := stuber.NewLoadGenerator(s, greeterDescriptors(t), stuber.LoadOptions{StubWeight: -1})
+	require.NoError(t, err)
+
+	for range 20 {
+		query := g.Next()
+		require.Equal(t, "Greeter", query.Service)
+		require.NotEqual(t, "Bob", query.Data["user_name"])
+	}
+
+	_, err = stuber.NewLoadGenerator(stuber.New(), nil, stuber.LoadOptions{})
+	require.ErrorIs(t, err, stuber.ErrNoQueries)
+}
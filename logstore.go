@@ -0,0 +1,384 @@
+package stuber
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrCorruptLog is returned when a transaction of a LogStore cannot be
+// decoded.
+var ErrCorruptLog = errors.New("corrupt stub log")
+
+// Operations of the transactions of a LogStore.
+const (
+	logPut    = "put"
+	logDelete = "delete"
+	logClear  = "clear"
+)
+
+// logBufferSize is the size of the buffer reading a LogStore, large enough
+// for most transactions to be read without growing it.
+const logBufferSize = 1 << 20
+
+// logRecord is a transaction of a LogStore.
+type logRecord struct {
+	Op    string      `json:"op"`              // logPut, logDelete or logClear.
+	Stubs []*Stub     `json:"stubs,omitempty"` // The stubs of a put.
+	IDs   []uuid.UUID `json:"ids,omitempty"`   // The IDs of a delete.
+}
+
+// LogStore is an embedded Backend keeping the stubs in an append-only log
+// file, with no dependency besides the standard library.
+//
+// Each Put, Delete or Clear is a transaction written as a single JSON line
+// and synced to the disk before returning. A transaction interrupted by a
+// crash leaves an incomplete last line, which is discarded when the store is
+// opened again, so transactions are applied entirely or not at all.
+//
+// Loading replays the log in a single pass. The log grows with each change
+// until Compact rewrites it with the live stubs only. BoltStore keeps the
+// live stubs only, for large sets of stubs changing often.
+type LogStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	keys KeyProvider // The keys encrypting the transactions, if any.
+}
+
+// LogStoreOption configures a LogStore opened with OpenLogStore.
+type LogStoreOption func(*LogStore)
+
+// WithLogEncryption encrypts the transactions of the LogStore with AES-GCM,
+// using the keys of the provider.
+//
+// The transactions written without encryption are still read, so a log is
+// encrypted progressively, or at once with Compact.
+func WithLogEncryption(keys KeyProvider) LogStoreOption {
+	return func(l *LogStore) {
+		l.keys = keys
+	}
+}
+
+// OpenLogStore opens the log at the given path, creating it if needed, and
+// discards its interrupted last transaction, if any.
+//
+// Parameters:
+// - path: The path of the log file.
+// - opts: The options to apply.
+//
+// Returns:
+// - *LogStore: The opened store, to be closed with Close.
+// - error: An error if the log cannot be opened or repaired.
+func OpenLogStore(path string, opts ...LogStoreOption) (*LogStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := discardTornTail(file); err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	l := &LogStore{path: path, file: file}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l, nil
+}
+
+// discardTornTail truncates the file after its last complete line.
+func discardTornTail(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	end := info.Size()
+	buf := make([]byte, 4096)
+
+	// Walk back from the end to the last newline.
+	for end > 0 {
+		n := min(int64(len(buf)), end)
+
+		if _, err := file.ReadAt(buf[:n], end-n); err != nil {
+			return err
+		}
+
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = end - n + int64(i) + 1
+
+			break
+		}
+
+		end -= n
+	}
+
+	if end == info.Size() {
+		return nil
+	}
+
+	return file.Truncate(end)
+}
+
+// Load replays the log and returns the live stubs.
+//
+// Returns:
+// - []*Stub: The live stubs, in the order of their first insertion.
+// - error: An error if the log cannot be read, one matching ErrCorruptLog if
+// a transaction cannot be decoded, or one matching ErrDecryption if it
+// cannot be decrypted.
+func (l *LogStore) Load() ([]*Stub, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.replay()
+}
+
+// replay reads the log and returns the live stubs.
+//
+// The mutex must be held.
+func (l *LogStore) replay() ([]*Stub, error) {
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var (
+		order []uuid.UUID
+		live  = make(map[uuid.UUID]*Stub)
+	)
+
+	reader := bufio.NewReaderSize(l.file, logBufferSize)
+
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := l.decode(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", l.path, n, err)
+		}
+
+		switch record.Op {
+		case logPut:
+			for _, stub := range record.Stubs {
+				if _, ok := live[stub.ID]; !ok {
+					order = append(order, stub.ID)
+				}
+
+				live[stub.ID] = stub
+			}
+		case logDelete:
+			for _, id := range record.IDs {
+				delete(live, id)
+			}
+		case logClear:
+			clear(live)
+		default:
+			return nil, fmt.Errorf("%w: %s:%d: unknown operation %q", ErrCorruptLog, l.path, n, record.Op)
+		}
+	}
+
+	stubs := make([]*Stub, 0, len(live))
+
+	for _, id := range order {
+		if stub, ok := live[id]; ok {
+			stubs = append(stubs, stub)
+			delete(live, id) // A stub deleted and inserted again is listed once.
+		}
+	}
+
+	return stubs, nil
+}
+
+// Put appends a transaction inserting or replacing the given stubs.
+//
+// Parameters:
+// - stubs: The stubs to persist.
+//
+// Returns:
+// - error: An error if the transaction cannot be written.
+func (l *LogStore) Put(stubs []*Stub) error {
+	if len(stubs) == 0 {
+		return nil
+	}
+
+	// Stamp the stubs with the current version, so loading them doesn't upgrade them.
+	stamped := make([]*Stub, len(stubs))
+
+	for i, stub := range stubs {
+		clone := *stub
+		clone.SchemaVersion = SchemaVersion
+		stamped[i] = &clone
+	}
+
+	return l.append(logRecord{Op: logPut, Stubs: stamped})
+}
+
+// Delete appends a transaction deleting the stubs with the given IDs.
+//
+// Parameters:
+// - ids: The IDs of the stubs to delete.
+//
+// Returns:
+// - error: An error if the transaction cannot be written.
+func (l *LogStore) Delete(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return l.append(logRecord{Op: logDelete, IDs: ids})
+}
+
+// Clear appends a transaction deleting all the stubs.
+//
+// Returns:
+// - error: An error if the transaction cannot be written.
+func (l *LogStore) Clear() error {
+	return l.append(logRecord{Op: logClear})
+}
+
+// encode encodes the transaction as a single line, encrypted if the store
+// has keys.
+func (l *LogStore) encode(record logRecord) ([]byte, error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.keys != nil {
+		if line, err = seal(l.keys, line); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(line, '\n'), nil
+}
+
+// decode decodes the transaction of the line, decrypting it if needed.
+func (l *LogStore) decode(line []byte) (logRecord, error) {
+	var record logRecord
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+
+	if sealed(line) {
+		var err error
+		if line, err = unseal(l.keys, line); err != nil {
+			return record, err
+		}
+	}
+
+	if err := json.Unmarshal(line, &record); err != nil {
+		return record, fmt.Errorf("%w: %w", ErrCorruptLog, err)
+	}
+
+	return record, nil
+}
+
+// append writes the transaction as a single line and syncs it.
+func (l *LogStore) append(record logRecord) error {
+	line, err := l.encode(record)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(line); err != nil {
+		return err
+	}
+
+	return l.file.Sync()
+}
+
+// Compact rewrites the log with a single transaction inserting the live
+// stubs, replacing the file atomically. The transaction is encrypted with
+// the current key if the store has keys, re-encrypting the log after a key
+// rotation.
+//
+// Returns:
+// - error: An error if the log cannot be read or rewritten.
+func (l *LogStore) Compact() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stubs, err := l.replay()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), "."+filepath.Base(l.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if len(stubs) > 0 {
+		line, err := l.encode(logRecord{Op: logPut, Stubs: stubs})
+		if err != nil {
+			tmp.Close()
+
+			return err
+		}
+
+		if _, err := tmp.Write(line); err != nil {
+			tmp.Close()
+
+			return err
+		}
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	l.file.Close()
+	l.file = file
+
+	return nil
+}
+
+// Close closes the log file.
+//
+// Returns:
+// - error: An error if the file cannot be closed.
+func (l *LogStore) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
@@ -0,0 +1,134 @@
+package stuber_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestLogStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stubs.log")
+
+	store, err := stuber.OpenLogStore(path)
+	require.NoError(t, err)
+
+	s := stuber.New()
+
+	ids, err := s.AttachBackend(store)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	hello := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	bye := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayGoodbye"}
+	s.PutMany(hello, bye)
+	s.DeleteByID(bye.ID)
+	require.NoError(t, s.PatchByID(hello.ID, func(stub *stuber.Stub) error {
+		stub.Priority = 5
+
+		return nil
+	}))
+	require.NoError(t, store.Close())
+
+	// Simulate a transaction interrupted by a crash.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"op":"clear"`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store, err = stuber.OpenLogStore(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { store.Close() })
+
+	restored := stuber.New()
+
+	ids, err = restored.AttachBackend(store)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{hello.ID}, ids)
+	require.Equal(t, 5, restored.FindByID(hello.ID).Priority)
+
+	require.NoError(t, store.Compact())
+
+	stubs, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, stubs, 1)
+
+	restored.Clear()
+
+	stubs, err = store.Load()
+	require.NoError(t, err)
+	require.Empty(t, stubs)
+}
+
+func TestLogStore_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stubs.log")
+	require.NoError(t, os.WriteFile(path, []byte("{\"op\":\"put\"}\nnot json\n"), 0o600))
+
+	store, err := stuber.OpenLogStore(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { store.Close() })
+
+	_, err = stuber.New().AttachBackend(store)
+	require.ErrorIs(t, err, stuber.ErrCorruptLog)
+}
+
+func TestMigrateToBackend(t *testing.T) {
+	dir := t.TempDir()
+
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, s.SaveToFile(filepath.Join(dir, "stubs.json")))
+
+	store, err := stuber.OpenLogStore(filepath.Join(dir, "stubs.log"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { store.Close() })
+
+	n, err := stuber.MigrateToBackend(filepath.Join(dir, "stubs.json"), store)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	stubs, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, stubs, 1)
+	require.Equal(t, "Greeter", stubs[0].Service)
+}
+
+func BenchmarkLogStore_Attach(b *testing.B) {
+	store, err := stuber.OpenLogStore(filepath.Join(b.TempDir(), "stubs.log"))
+	require.NoError(b, err)
+
+	b.Cleanup(func() { store.Close() })
+
+	stubs := make([]*stuber.Stub, 100_000)
+	for i := range stubs {
+		stubs[i] = &stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"id": i}},
+		}
+	}
+
+	require.NoError(b, store.Put(stubs))
+
+	b.ResetTimer()
+
+	for range b.N {
+		ids, err := stuber.New().AttachBackend(store)
+		require.NoError(b, err)
+		require.Len(b, ids, len(stubs))
+	}
+}
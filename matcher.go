@@ -19,8 +19,25 @@ func match(query Query, stub *Stub) bool {
 		contains(stub.Headers.Contains, query.Headers, false) &&
 		matches(stub.Headers.Matches, query.Headers, false)
 
-	// Return true if both the data and headers match, otherwise false.
-	return dataMatch && headersMatch
+	// Return true if the data, the headers, the size and the message count
+	// match, otherwise false.
+	return dataMatch && headersMatch && matchCounts(query, stub)
+}
+
+// strictFields checks if every top-level field of the query's data is
+// mentioned by one of the stub's input matchers.
+func strictFields(query Query, stub *Stub) bool {
+	for key := range query.Data {
+		_, inEquals := stub.Input.Equals[key]
+		_, inContains := stub.Input.Contains[key]
+		_, inMatches := stub.Input.Matches[key]
+
+		if !inEquals && !inContains && !inMatches {
+			return false
+		}
+	}
+
+	return true
 }
 
 // rankMatch ranks how well a given query matches a given stub.
@@ -28,6 +45,15 @@ func match(query Query, stub *Stub) bool {
 // It ranks the query's input data and headers against the stub's input data
 // and headers using the RankMatch method from the deeply package.
 func rankMatch(query Query, stub *Stub) float64 {
+	data, headers, counts := rankParts(query, stub)
+
+	// Return the sum of the data, headers, size and message count ranks.
+	return data + headers + counts
+}
+
+// rankParts returns the ranks of the input data, the headers, and the size
+// and message count of the query against the stub, whose sum is rankMatch.
+func rankParts(query Query, stub *Stub) (float64, float64, float64) {
 	// Rank the query's input data against the stub's input data.
 	dataRank := deeply.RankMatch(stub.Input.Equals, query.Data) +
 		deeply.RankMatch(stub.Input.Contains, query.Data) +
@@ -41,8 +67,7 @@ func rankMatch(query Query, stub *Stub) float64 {
 			deeply.RankMatch(stub.Headers.Matches, query.Headers)
 	}
 
-	// Return the sum of the data and headers ranks.
-	return dataRank + headersRank
+	return dataRank, headersRank, rankCounts(query, stub)
 }
 
 // equals checks if the expected map matches the actual value.
@@ -55,6 +80,11 @@ func equals(expected map[string]any, actual any, orderIgnore bool) bool {
 		return true
 	}
 
+	// Compare flat string maps without reflection.
+	if result, ok := equalsFlatStrings(expected, actual); ok {
+		return result
+	}
+
 	// If orderIgnore is true, use the EqualsIgnoreArrayOrder method from the deeply package.
 	if orderIgnore {
 		return deeply.EqualsIgnoreArrayOrder(expected, actual)
@@ -64,6 +94,38 @@ func equals(expected map[string]any, actual any, orderIgnore bool) bool {
 	return deeply.Equals(expected, actual)
 }
 
+// equalsFlatStrings is the fast path of equals for the common case of
+// expected maps holding only string values.
+//
+// It returns the result of the comparison and true if the fast path applies,
+// otherwise false as the second value. The result is the same as the one of
+// the deeply package: maps must have the same keys and equal values.
+func equalsFlatStrings(expected map[string]any, actual any) (bool, bool) {
+	values, ok := actual.(map[string]any)
+	if !ok {
+		return false, false
+	}
+
+	for _, value := range expected {
+		if _, ok := value.(string); !ok {
+			return false, false
+		}
+	}
+
+	// Maps with different numbers of keys can't be equal.
+	if len(expected) != len(values) {
+		return false, true
+	}
+
+	for key, value := range expected {
+		if actual, ok := values[key].(string); !ok || actual != value {
+			return false, true
+		}
+	}
+
+	return true, true
+}
+
 // contains checks if the expected map is a subset of the actual value.
 //
 // It returns true if the expected map is a subset of the actual value,
@@ -0,0 +1,140 @@
+package stuber
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownMatcherKind is returned when a matcher kind name is not known.
+var ErrUnknownMatcherKind = errors.New("unknown matcher kind")
+
+// MatcherKind is a kind of matcher of the input data or headers of a stub.
+type MatcherKind int
+
+const (
+	// MatcherEquals matches the fields exactly.
+	MatcherEquals MatcherKind = iota
+	// MatcherContains matches the fields partially.
+	MatcherContains
+	// MatcherMatches matches the fields using regular expressions.
+	MatcherMatches
+)
+
+// matcherKindNames are the names of the matcher kinds, as in the JSON
+// format of the stubs.
+var matcherKindNames = [...]string{ //nolint:gochecknoglobals
+	MatcherEquals:   "equals",
+	MatcherContains: "contains",
+	MatcherMatches:  "matches",
+}
+
+// MatcherKinds returns all the matcher kinds, in order.
+func MatcherKinds() []MatcherKind {
+	return []MatcherKind{MatcherEquals, MatcherContains, MatcherMatches}
+}
+
+// ParseMatcherKind returns the matcher kind with the given name, such as
+// "equals".
+func ParseMatcherKind(name string) (MatcherKind, error) {
+	for kind, kindName := range matcherKindNames {
+		if kindName == name {
+			return MatcherKind(kind), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrUnknownMatcherKind, name)
+}
+
+// String returns the name of the matcher kind.
+func (k MatcherKind) String() string {
+	if k < 0 || int(k) >= len(matcherKindNames) {
+		return fmt.Sprintf("MatcherKind(%d)", int(k))
+	}
+
+	return matcherKindNames[k]
+}
+
+// MarshalText encodes the matcher kind as its name.
+func (k MatcherKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(matcherKindNames) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownMatcherKind, int(k))
+	}
+
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes the matcher kind from its name.
+func (k *MatcherKind) UnmarshalText(text []byte) error {
+	kind, err := ParseMatcherKind(string(text))
+	if err != nil {
+		return err
+	}
+
+	*k = kind
+
+	return nil
+}
+
+// Matchers are the fields matched by each kind of matcher.
+//
+// Matchers is a view of the Equals, Contains and Matches fields of InputData
+// and InputHeader, which remain the fields the stubs are stored, encoded and
+// matched with: the Matchers methods build the view and NewInputData and
+// NewInputHeader build the fields back from it.
+type Matchers map[MatcherKind]map[string]interface{}
+
+// Len returns the total number of fields to match.
+func (m Matchers) Len() int {
+	var n int
+
+	for _, fields := range m {
+		n += len(fields)
+	}
+
+	return n
+}
+
+// matchersOf returns the non-empty matchers of the given kinds.
+func matchersOf(equals, contains, matches map[string]interface{}) Matchers {
+	m := make(Matchers, len(matcherKindNames))
+
+	for kind, fields := range map[MatcherKind]map[string]interface{}{
+		MatcherEquals:   equals,
+		MatcherContains: contains,
+		MatcherMatches:  matches,
+	} {
+		if len(fields) > 0 {
+			m[kind] = fields
+		}
+	}
+
+	return m
+}
+
+// Matchers returns the non-empty matchers of the input data, by kind.
+func (i InputData) Matchers() Matchers {
+	return matchersOf(i.Equals, i.Contains, i.Matches)
+}
+
+// Matchers returns the non-empty matchers of the headers, by kind.
+func (i InputHeader) Matchers() Matchers {
+	return matchersOf(i.Equals, i.Contains, i.Matches)
+}
+
+// NewInputData returns the input data matching the given matchers.
+func NewInputData(m Matchers) InputData {
+	return InputData{
+		Equals:   m[MatcherEquals],
+		Contains: m[MatcherContains],
+		Matches:  m[MatcherMatches],
+	}
+}
+
+// NewInputHeader returns the headers matching the given matchers.
+func NewInputHeader(m Matchers) InputHeader {
+	return InputHeader{
+		Equals:   m[MatcherEquals],
+		Contains: m[MatcherContains],
+		Matches:  m[MatcherMatches],
+	}
+}
@@ -0,0 +1,51 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestMatcherKind(t *testing.T) {
+	for _, kind := range stuber.MatcherKinds() {
+		parsed, err := stuber.ParseMatcherKind(kind.String())
+		require.NoError(t, err)
+		require.Equal(t, kind, parsed)
+	}
+
+	_, err := stuber.ParseMatcherKind("jsonpath")
+	require.ErrorIs(t, err, stuber.ErrUnknownMatcherKind)
+
+	require.Equal(t, "MatcherKind(42)", stuber.MatcherKind(42).String())
+}
+
+func TestMatchers(t *testing.T) {
+	input := stuber.NewInputData(stuber.Matchers{
+		stuber.MatcherEquals:  {"name": "Bob"},
+		stuber.MatcherMatches: {"email": ".+@example.com"},
+	})
+
+	require.Equal(t, map[string]interface{}{"name": "Bob"}, input.Equals)
+	require.Nil(t, input.Contains)
+	require.Equal(t, map[string]interface{}{"email": ".+@example.com"}, input.Matches)
+
+	matchers := input.Matchers()
+	require.Len(t, matchers, 2)
+	require.Equal(t, 2, matchers.Len())
+
+	data, err := json.Marshal(matchers)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"equals":{"name":"Bob"},"matches":{"email":".+@example.com"}}`, string(data))
+
+	var decoded stuber.Matchers
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, matchers, decoded)
+
+	headers := stuber.NewInputHeader(stuber.Matchers{stuber.MatcherContains: {"x-user": "Bob"}})
+	require.Equal(t, 1, headers.Len())
+	require.Equal(t, stuber.Matchers{stuber.MatcherContains: {"x-user": "Bob"}}, headers.Matchers())
+}
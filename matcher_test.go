@@ -0,0 +1,72 @@
+package stuber //nolint:testpackage
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/gripmock/deeply"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqualsFlatStrings(t *testing.T) {
+	expected := map[string]any{"name": "Bob", "city": "Paris"}
+
+	tests := []struct {
+		actual  any
+		applies bool
+	}{
+		{map[string]any{"name": "Bob", "city": "Paris"}, true},
+		{map[string]any{"name": "Bob", "city": "Rome"}, true},
+		{map[string]any{"name": "Bob"}, true},
+		{map[string]any{"name": "Bob", "city": "Paris", "age": "42"}, true},
+		{map[string]any{"name": "Bob", "city": json.Number("1")}, true},
+		{map[string]any{"name": "Bob", "town": "Paris"}, true},
+		{nil, false},
+		{"Bob", false},
+	}
+
+	for _, test := range tests {
+		result, applies := equalsFlatStrings(expected, test.actual)
+		require.Equal(t, test.applies, applies)
+
+		if applies {
+			require.Equal(t, deeply.Equals(expected, test.actual), result)
+		}
+	}
+
+	_, applies := equalsFlatStrings(map[string]any{"age": json.Number("42")}, map[string]any{"age": json.Number("42")})
+	require.False(t, applies)
+}
+
+func flatStringMaps(size int) (map[string]any, map[string]any) {
+	expected := make(map[string]any, size)
+	actual := make(map[string]any, size)
+
+	for i := range size {
+		expected["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+		actual["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+	}
+
+	return expected, actual
+}
+
+func BenchmarkEquals_FlatStrings(b *testing.B) {
+	expected, actual := flatStringMaps(16)
+
+	b.ReportAllocs()
+
+	for range b.N {
+		equals(expected, actual, false)
+	}
+}
+
+func BenchmarkEquals_Deeply(b *testing.B) {
+	expected, actual := flatStringMaps(16)
+
+	b.ReportAllocs()
+
+	for range b.N {
+		deeply.Equals(expected, actual)
+	}
+}
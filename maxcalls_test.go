@@ -0,0 +1,122 @@
+package stuber_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_MaxCalls(t *testing.T) {
+	s := stuber.New()
+
+	failure := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Payments",
+		Method:   "Charge",
+		Priority: 10,
+		MaxCalls: 1,
+		Input:    stuber.InputData{Equals: map[string]interface{}{"amount": 5}},
+		Output:   stuber.Output{Error: "unavailable"},
+	}
+	success := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Payments",
+		Method:  "Charge",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"amount": 5}},
+		Output:  stuber.Output{Data: map[string]interface{}{"status": "charged"}},
+	}
+	s.PutMany(failure, success)
+
+	query := stuber.Query{Service: "Payments", Method: "Charge", Data: map[string]interface{}{"amount": 5}}
+
+	// Internal queries don't use the calls up.
+	explanation, err := s.ExplainQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, failure.ID, *explanation.Found)
+
+	for _, want := range []uuid.UUID{failure.ID, success.ID, success.ID} {
+		result, err := s.FindByQuery(query)
+		require.NoError(t, err)
+		require.Equal(t, want, result.Found().ID)
+	}
+
+	explanation, err = s.ExplainQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, failure.ID, explanation.Candidates[0].Stub)
+	require.Contains(t, explanation.Candidates[0].Reasons, "the stub was already matched its maximum of 1 times")
+	require.Equal(t, uint64(1), s.HitsByID(failure.ID))
+
+	// Resetting the usage gives the calls back.
+	s.Clear()
+	s.PutMany(failure, success)
+
+	result, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, failure.ID, result.Found().ID)
+}
+
+func TestBudgerigar_MaxCalls_Concurrent(t *testing.T) {
+	const searches = 8
+
+	failure := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Payments",
+		Method:   "Charge",
+		Priority: 10,
+		MaxCalls: 1,
+		Output:   stuber.Output{Error: "unavailable"},
+	}
+	success := &stuber.Stub{ID: uuid.New(), Service: "Payments", Method: "Charge"}
+
+	// The first searches wait for each other once the failure is found
+	// ready, ranking the success after it, so they all mark it at once.
+	var (
+		ranked  sync.WaitGroup
+		waiting atomic.Int32
+	)
+
+	ranked.Add(searches)
+
+	s := stuber.New(stuber.WithRanker(func(query stuber.Query, stub *stuber.Stub) float64 {
+		if stub.ID == success.ID && waiting.Add(1) <= searches {
+			ranked.Done()
+			ranked.Wait()
+		}
+
+		return stuber.DefaultRank(query, stub) + 1
+	}))
+	s.PutMany(failure, success)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		found = make(map[uuid.UUID]int)
+	)
+
+	for range searches {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result, err := s.FindByQuery(stuber.Query{Service: "Payments", Method: "Charge"})
+			if err != nil || result.Found() == nil {
+				return
+			}
+
+			mu.Lock()
+			found[result.Found().ID]++
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, map[uuid.UUID]int{failure.ID: 1, success.ID: searches - 1}, found)
+	require.Equal(t, uint64(searches), s.Stats().Methods[0].Hits)
+}
@@ -0,0 +1,114 @@
+package stuber
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidPatch is returned when a merge patch cannot be applied to a stub.
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// mergePatch applies the RFC 7386 merge patch to the target document.
+//
+// Objects of the patch are merged recursively, null members delete the
+// corresponding members of the target and any other value replaces it.
+func mergePatch(target, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	doc, ok := target.(map[string]any)
+	if !ok {
+		doc = make(map[string]any, len(fields))
+	}
+
+	for name, value := range fields {
+		if value == nil {
+			delete(doc, name)
+
+			continue
+		}
+
+		doc[name] = mergePatch(doc[name], value)
+	}
+
+	return doc
+}
+
+// decodePatch decodes JSON data keeping numbers as json.Number.
+func decodePatch(data []byte, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(target); err != nil {
+		return errors.Join(ErrInvalidPatch, err)
+	}
+
+	return nil
+}
+
+// applyMergePatch applies the RFC 7386 merge patch to the given Stub value.
+func (s *Stub) applyMergePatch(patch any) error {
+	current, err := json.Marshal(s)
+	if err != nil {
+		return errors.Join(ErrInvalidPatch, err)
+	}
+
+	var doc any
+	if err := decodePatch(current, &doc); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return errors.Join(ErrInvalidPatch, err)
+	}
+
+	var result Stub
+	if err := decodePatch(merged, &result); err != nil {
+		return err
+	}
+
+	// A stub without service or method would be moved to a bucket no query reaches.
+	if result.Service == "" || result.Method == "" {
+		return fmt.Errorf("%w: the service and the method of a stub cannot be removed", ErrInvalidPatch)
+	}
+
+	*s = result
+
+	return nil
+}
+
+// PatchJSON atomically applies the RFC 7386 JSON merge patch to the Stub
+// value with the given ID, so a single field can be changed without sending
+// the whole Stub value.
+//
+// The ID of the Stub value cannot be changed, and its service and method
+// cannot be removed. The patch must be an object: a null patch, which would
+// delete the whole document, is rejected.
+//
+// Parameters:
+// - id: The UUID of the Stub value to patch.
+// - patch: The JSON merge patch, such as {"output":{"data":{"message":"hi"}}}.
+//
+// Returns:
+// - error: ErrStubNotFound if there is no such Stub value, or ErrInvalidPatch.
+func (b *Budgerigar) PatchJSON(id uuid.UUID, patch []byte) error {
+	var doc any
+	if err := decodePatch(patch, &doc); err != nil {
+		return err
+	}
+
+	if _, ok := doc.(map[string]any); !ok {
+		return fmt.Errorf("%w: the patch is not an object", ErrInvalidPatch)
+	}
+
+	return b.PatchByID(id, func(stub *Stub) error {
+		return stub.applyMergePatch(doc)
+	})
+}
@@ -0,0 +1,58 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_PatchJSON(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output: stuber.Output{
+			Data:    map[string]interface{}{"message": "hi", "extra": true},
+			Headers: map[string]string{"x-id": "1"},
+		},
+	}
+
+	s.PutMany(stub)
+
+	require.ErrorIs(t, s.PatchJSON(uuid.New(), []byte(`{}`)), stuber.ErrStubNotFound)
+	require.ErrorIs(t, s.PatchJSON(stub.ID, []byte(`{`)), stuber.ErrInvalidPatch)
+	require.ErrorIs(t, s.PatchJSON(stub.ID, []byte(`{"output":{"data":"oops"}}`)), stuber.ErrInvalidPatch)
+
+	// Patches that are not objects or remove the service or the method are rejected.
+	for _, patch := range []string{`null`, `[]`, `"Greeter"`, `{"service":null}`, `{"method":null}`, `{"service":""}`} {
+		require.ErrorIs(t, s.PatchJSON(stub.ID, []byte(patch)), stuber.ErrInvalidPatch, patch)
+	}
+
+	require.Equal(t, "Greeter", s.FindByID(stub.ID).Service)
+
+	require.NoError(t, s.PatchJSON(stub.ID, []byte(`{
+		"id": "00000000-0000-0000-0000-000000000000",
+		"priority": 3,
+		"output": {"data": {"message": "hello", "extra": null}}
+	}`)))
+
+	patched := s.FindByID(stub.ID)
+	require.NotNil(t, patched)
+	require.Equal(t, 3, patched.Priority)
+	require.Equal(t, map[string]interface{}{"message": "hello"}, patched.Output.Data)
+	require.Equal(t, map[string]string{"x-id": "1"}, patched.Output.Headers)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, stub.ID, r.Found().ID)
+}
@@ -0,0 +1,93 @@
+package stuber
+
+import (
+	"slices"
+	"sync"
+)
+
+// serviceMetadata holds arbitrary values attached to services.
+type serviceMetadata struct {
+	mu     sync.RWMutex
+	values map[string]map[string]any
+}
+
+// newServiceMetadata creates a new serviceMetadata instance.
+func newServiceMetadata() *serviceMetadata {
+	return &serviceMetadata{values: make(map[string]map[string]any)}
+}
+
+// set attaches the value to the service under the given key, or removes it
+// if the value is nil.
+func (m *serviceMetadata) set(service, key string, value any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if value == nil {
+		delete(m.values[service], key)
+
+		if len(m.values[service]) == 0 {
+			delete(m.values, service)
+		}
+
+		return
+	}
+
+	if m.values[service] == nil {
+		m.values[service] = make(map[string]any)
+	}
+
+	m.values[service][key] = value
+}
+
+// get returns the value attached to the service under the given key.
+func (m *serviceMetadata) get(service, key string) (any, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, ok := m.values[service][key]
+
+	return value, ok
+}
+
+// SetServiceMetadata attaches an arbitrary value to the given service, such
+// as its file descriptor bytes, so an embedding server can serve gRPC
+// reflection for the stubbed services from the Budgerigar.
+//
+// Passing a nil value removes it. Metadata is kept by Clear.
+//
+// Parameters:
+// - service: The name of the service.
+// - key: The key of the value, such as "descriptor".
+// - value: The value to attach, or nil.
+func (b *Budgerigar) SetServiceMetadata(service, key string, value any) {
+	b.metadata.set(service, key, value)
+}
+
+// ServiceMetadata returns the value attached to the given service.
+//
+// Parameters:
+// - service: The name of the service.
+// - key: The key of the value.
+//
+// Returns:
+// - any: The attached value, or nil.
+// - bool: Whether a value is attached.
+func (b *Budgerigar) ServiceMetadata(service, key string) (any, bool) {
+	return b.metadata.get(service, key)
+}
+
+// Services returns the sorted names of the services having at least one stub.
+//
+// Returns:
+// - []string: The names of the stubbed services.
+func (b *Budgerigar) Services() []string {
+	var services []string
+
+	for stub := range b.Iter() {
+		services = append(services, stub.Service)
+	}
+
+	slices.Sort(services)
+
+	return slices.Compact(services)
+}
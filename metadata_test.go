@@ -0,0 +1,42 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_ServiceMetadata(t *testing.T) {
+	s := stuber.New()
+
+	s.PutMany(
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"},
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayGoodbye"},
+		&stuber.Stub{ID: uuid.New(), Service: "Billing", Method: "Charge"},
+	)
+
+	require.Equal(t, []string{"Billing", "Greeter"}, s.Services())
+
+	s.SetServiceMetadata("Greeter", "descriptor", []byte{1, 2, 3})
+
+	value, ok := s.ServiceMetadata("Greeter", "descriptor")
+	require.True(t, ok)
+	require.Equal(t, []byte{1, 2, 3}, value)
+
+	_, ok = s.ServiceMetadata("Billing", "descriptor")
+	require.False(t, ok)
+
+	s.Clear()
+	require.Empty(t, s.Services())
+
+	_, ok = s.ServiceMetadata("Greeter", "descriptor")
+	require.True(t, ok)
+
+	s.SetServiceMetadata("Greeter", "descriptor", nil)
+
+	_, ok = s.ServiceMetadata("Greeter", "descriptor")
+	require.False(t, ok)
+}
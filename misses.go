@@ -0,0 +1,199 @@
+package stuber
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons of the field differences.
+const (
+	DiffMissing    = "missing"    // The field expected by the stub is absent from the query.
+	DiffMismatch   = "mismatch"   // The field of the query doesn't match the stub.
+	DiffUnexpected = "unexpected" // The field of the query is absent from the exact matchers of the stub.
+)
+
+// FieldDiff is a difference between a field of a query and the matcher of a
+// stub.
+type FieldDiff struct {
+	Field    string      `json:"field"`              // The field, such as "data.name" or "headers.authorization".
+	Matcher  MatcherKind `json:"matcher"`            // The matcher of the field.
+	Reason   string      `json:"reason"`             // DiffMissing, DiffMismatch or DiffUnexpected.
+	Expected any         `json:"expected,omitempty"` // The value expected by the stub.
+	Actual   any         `json:"actual,omitempty"`   // The value of the query.
+}
+
+// Miss is a query no stub matched, along with the differences between the
+// query and the most similar stub.
+type Miss struct {
+	Time      time.Time              `json:"time"`                // When the query was searched.
+	Hash      string                 `json:"hash"`                // The QueryHash of the query.
+	Service   string                 `json:"service"`             // The service of the query.
+	Method    string                 `json:"method"`              // The method of the query.
+	Headers   map[string]interface{} `json:"headers,omitempty"`   // The headers of the query.
+	Data      map[string]interface{} `json:"data,omitempty"`      // The data of the query.
+	SimilarID *uuid.UUID             `json:"similarId,omitempty"` // The most similar stub, if any.
+	Diff      []FieldDiff            `json:"diff,omitempty"`      // The differences with the most similar stub.
+}
+
+// missLog keeps the last misses in a ring buffer.
+type missLog struct {
+	mu      sync.RWMutex
+	now     func() time.Time
+	entries []Miss
+	next    int
+	count   int
+}
+
+// newMissLog creates a new missLog keeping no misses.
+func newMissLog() *missLog {
+	return &missLog{now: time.Now}
+}
+
+// resize sets the number of kept misses and forgets the kept ones.
+func (l *missLog) resize(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = make([]Miss, max(size, 0))
+	l.next = 0
+	l.count = 0
+}
+
+// enabled checks if misses are kept.
+func (l *missLog) enabled() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.entries) > 0
+}
+
+// record keeps the miss of the query if the outcome of its search is one,
+// with the differences between the query and the most similar stub.
+func (l *missLog) record(query Query, result *Result, err error) {
+	switch {
+	case !l.enabled():
+		return
+	case err == nil && result.found != nil:
+		return
+	case err != nil && !errors.Is(err, ErrStubNotFound) &&
+		!errors.Is(err, ErrServiceNotFound) && !errors.Is(err, ErrMethodNotFound):
+		return
+	}
+
+	miss := Miss{
+		Hash:    QueryHash(query),
+		Service: query.Service,
+		Method:  query.Method,
+		Headers: query.Headers,
+		Data:    query.Data,
+	}
+
+	if err == nil && result.similar != nil {
+		miss.SimilarID = &result.similar.ID
+		miss.Diff = diffStub(query, result.similar)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.entries) == 0 {
+		return
+	}
+
+	miss.Time = l.now()
+
+	l.entries[l.next] = miss
+	l.next = (l.next + 1) % len(l.entries)
+	l.count = min(l.count+1, len(l.entries))
+}
+
+// list returns the kept misses, from the oldest to the newest.
+func (l *missLog) list() []Miss {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make([]Miss, 0, l.count)
+
+	for i := range l.count {
+		result = append(result, l.entries[(l.next-l.count+i+len(l.entries))%len(l.entries)])
+	}
+
+	return result
+}
+
+// diffStub returns the differences between the data and the headers of the
+// query and the matchers of the stub, by field.
+func diffStub(query Query, stub *Stub) []FieldDiff {
+	query = normalizeQuery(query)
+
+	diffs := diffFields("data.", stub.Input.Matchers(), query.Data, stub.Input.IgnoreArrayOrder)
+
+	return append(diffs, diffFields("headers.", stub.Headers.Matchers(), query.Headers, false)...)
+}
+
+// diffFields returns the differences between the given values and matchers,
+// whose fields are reported with the given prefix.
+func diffFields(prefix string, matchers Matchers, values map[string]interface{}, ignoreOrder bool) []FieldDiff {
+	var diffs []FieldDiff
+
+	for _, kind := range MatcherKinds() {
+		expected := normalizeMap(matchers[kind])
+
+		for _, field := range slices.Sorted(maps.Keys(expected)) {
+			actual, ok := values[field]
+			if !ok {
+				diffs = append(diffs, FieldDiff{prefix + field, kind, DiffMissing, expected[field], nil})
+
+				continue
+			}
+
+			if !matchField(kind, field, expected[field], actual, ignoreOrder) {
+				diffs = append(diffs, FieldDiff{prefix + field, kind, DiffMismatch, expected[field], actual})
+			}
+		}
+	}
+
+	// Exact matchers reject the fields they don't mention.
+	if equals := matchers[MatcherEquals]; len(equals) > 0 {
+		for _, field := range slices.Sorted(maps.Keys(values)) {
+			if _, ok := equals[field]; !ok {
+				diffs = append(diffs, FieldDiff{prefix + field, MatcherEquals, DiffUnexpected, nil, values[field]})
+			}
+		}
+	}
+
+	return diffs
+}
+
+// matchField checks if the value of a field matches its expected value with
+// the given matcher.
+func matchField(kind MatcherKind, field string, expected, actual any, ignoreOrder bool) bool {
+	want := map[string]any{field: expected}
+	got := map[string]any{field: actual}
+
+	switch kind {
+	case MatcherEquals:
+		return equals(want, got, ignoreOrder)
+	case MatcherContains:
+		return contains(want, got, ignoreOrder)
+	default:
+		return matches(want, got, ignoreOrder)
+	}
+}
+
+// Misses returns the last queries no stub matched, from the oldest to the
+// newest, with the differences between each query and its most similar stub.
+//
+// Misses are only kept when enabled with WithMissLog, and only the last ones
+// are kept.
+//
+// Returns:
+// - []Miss: The kept misses.
+func (b *Budgerigar) Misses() []Miss {
+	return b.misses.list()
+}
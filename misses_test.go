@@ -0,0 +1,76 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Misses(t *testing.T) {
+	s := stuber.New(stuber.WithMissLog(2))
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: stuber.InputHeader{Contains: map[string]interface{}{"x-tenant": "acme"}},
+		Input: stuber.InputData{
+			Equals:  map[string]interface{}{"name": "Bob", "age": 42},
+			Matches: map[string]interface{}{"email": "^.+@example\\.com$"},
+		},
+	}
+	s.PutMany(stub)
+
+	result, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: map[string]interface{}{"x-tenant": "acme"},
+		Data:    map[string]interface{}{"name": "Alice", "age": 42, "email": "alice@example.org", "vip": true},
+	})
+	require.NoError(t, err)
+	require.Nil(t, result.Found())
+
+	_, err = s.FindByQuery(stuber.Query{Service: "Unknown", Method: "SayHello"})
+	require.ErrorIs(t, err, stuber.ErrServiceNotFound)
+
+	misses := s.Misses()
+	require.Len(t, misses, 2)
+
+	require.Equal(t, stub.ID, *misses[0].SimilarID)
+	require.Equal(t, []stuber.FieldDiff{
+		{Field: "data.name", Matcher: stuber.MatcherEquals, Reason: stuber.DiffMismatch, Expected: "Bob", Actual: "Alice"},
+		{Field: "data.email", Matcher: stuber.MatcherMatches, Reason: stuber.DiffMismatch, Expected: "^.+@example\\.com$", Actual: "alice@example.org"},
+		{Field: "data.email", Matcher: stuber.MatcherEquals, Reason: stuber.DiffUnexpected, Actual: "alice@example.org"},
+		{Field: "data.vip", Matcher: stuber.MatcherEquals, Reason: stuber.DiffUnexpected, Actual: true},
+	}, misses[0].Diff)
+
+	require.Equal(t, "Unknown", misses[1].Service)
+	require.Nil(t, misses[1].SimilarID)
+
+	// Matches are not misses.
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Carol"}},
+	})
+
+	result, err = s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Carol"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Found())
+	require.Equal(t, misses, s.Misses())
+
+	// The oldest misses are evicted.
+	_, err = s.FindByQuery(stuber.Query{Service: "Other", Method: "SayHello"})
+	require.ErrorIs(t, err, stuber.ErrServiceNotFound)
+	require.Equal(t, []string{"Unknown", "Other"}, []string{s.Misses()[0].Service, s.Misses()[1].Service})
+
+	require.Empty(t, stuber.New().Misses())
+}
@@ -0,0 +1,173 @@
+package stuber
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// prepared is a stub ready to be compared with queries.
+type prepared struct {
+	stub    *Stub // The stub merged with its base stubs.
+	matcher *Stub // The same stub with normalized matchers.
+}
+
+// preparedCache caches the prepared stubs by ID, the stubs of the ordered
+// groups by group, and the rank bounds of the buckets by service and method.
+//
+// The whole cache is invalidated on every write, since a write to a base
+// stub affects all the stubs inheriting from it.
+type preparedCache struct {
+	mu     sync.Mutex
+	gen    uint64
+	items  map[uuid.UUID]prepared
+	groups map[string][]*Stub
+	bounds map[string]rankBounds
+}
+
+// invalidate forgets all the prepared stubs.
+func (c *preparedCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.items = nil
+	c.groups = nil
+	c.bounds = nil
+}
+
+// prepare returns the given stub merged with its base stubs, along with a
+// copy whose matchers are normalized the same way queries are.
+func (s *searcher) prepare(stub *Stub) (*Stub, *Stub) {
+	s.cache.mu.Lock()
+	item, ok := s.cache.items[stub.ID]
+	gen := s.cache.gen
+	s.cache.mu.Unlock()
+
+	if ok {
+		return item.stub, item.matcher
+	}
+
+	resolved := s.resolve(stub)
+
+	matcher := *resolved
+	matcher.Headers = InputHeader{
+		Equals:   normalizeMap(resolved.Headers.Equals),
+		Contains: normalizeMap(resolved.Headers.Contains),
+		Matches:  normalizeMap(resolved.Headers.Matches),
+	}
+	matcher.Input = InputData{
+		IgnoreArrayOrder: resolved.Input.IgnoreArrayOrder,
+		Equals:           normalizeMap(resolved.Input.Equals),
+		Contains:         normalizeMap(resolved.Input.Contains),
+		Matches:          normalizeMap(resolved.Input.Matches),
+	}
+
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	// Skip the cache if a write happened while the stub was prepared.
+	if s.cache.gen == gen {
+		if s.cache.items == nil {
+			s.cache.items = make(map[uuid.UUID]prepared)
+		}
+
+		s.cache.items[stub.ID] = prepared{stub: resolved, matcher: &matcher}
+	}
+
+	return resolved, &matcher
+}
+
+// normalizeQuery returns a copy of the query with normalized data and headers.
+//
+// It is called once per search, so the normalization is shared by all the
+// candidate comparisons.
+func normalizeQuery(query Query) Query {
+	query.Data = normalizeMap(query.Data)
+	query.Headers = normalizeMap(query.Headers)
+
+	return query
+}
+
+// normalizeMap returns a normalized copy of the given map.
+func normalizeMap(values map[string]any) map[string]any {
+	if values == nil {
+		return nil
+	}
+
+	result := make(map[string]any, len(values))
+	for key, value := range values {
+		result[key] = normalize(value)
+	}
+
+	return result
+}
+
+// normalize returns the canonical form of the given value.
+//
+// Numbers of any type are converted to a canonical json.Number, so that
+// 1, 1.0, "1e0" and json.Number("1") compare as equal. Maps and slices are
+// normalized recursively; other values are returned as is.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return normalizeMap(v)
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = normalize(item)
+		}
+
+		return result
+	case json.Number:
+		return canonicalNumber(v)
+	case float64:
+		return canonicalFloat(v)
+	case float32:
+		return canonicalFloat(float64(v))
+	case int:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int32:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	default:
+		return value
+	}
+}
+
+// canonicalNumber returns the canonical form of a json.Number.
+//
+// Invalid numbers are returned as is.
+func canonicalNumber(n json.Number) json.Number {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return json.Number(strconv.FormatInt(i, 10))
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return n
+	}
+
+	return canonicalFloat(f)
+}
+
+// canonicalFloat returns the canonical json.Number of a float.
+//
+// Integral values within the int64 range are formatted as integers.
+func canonicalFloat(f float64) json.Number {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		return json.Number(strconv.FormatInt(int64(f), 10))
+	}
+
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
@@ -0,0 +1,60 @@
+package stuber //nolint:testpackage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected any
+	}{
+		{json.Number("1"), json.Number("1")},
+		{json.Number("1.0"), json.Number("1")},
+		{json.Number("1e2"), json.Number("100")},
+		{json.Number("1.50"), json.Number("1.5")},
+		{json.Number("abc"), json.Number("abc")},
+		{42, json.Number("42")},
+		{2.5, json.Number("2.5")},
+		{uint64(7), json.Number("7")},
+		{"42", "42"},
+		{true, true},
+		{
+			map[string]any{"a": []any{1, map[string]any{"b": 2.0}}},
+			map[string]any{"a": []any{json.Number("1"), map[string]any{"b": json.Number("2")}}},
+		},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, normalize(test.value))
+	}
+}
+
+func TestSearcher_NormalizedNumbers(t *testing.T) {
+	s := NewBudgerigar(features.New())
+
+	equals := map[string]interface{}{"id": 1, "price": 9.5}
+
+	s.PutMany(&Stub{
+		ID:      uuid.New(),
+		Service: "Shop",
+		Method:  "Get",
+		Input:   InputData{Equals: equals},
+	})
+
+	r, err := s.FindByQuery(Query{
+		Service: "Shop",
+		Method:  "Get",
+		Data:    map[string]interface{}{"id": json.Number("1.0"), "price": json.Number("9.50")},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+
+	// The stored stub is not modified by the normalization.
+	require.Equal(t, 1, r.Found().Input.Equals["id"])
+}
@@ -0,0 +1,152 @@
+package stuber
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NotFound describes what happens when no stub matches a query of a service
+// or a method, or when it has no stubs at all.
+//
+// By default the outcome of the search is unchanged: a Result carrying the
+// most similar Stub values, or an error. With an Output, it is returned
+// instead by a found Stub value with a nil ID, which is neither recorded nor
+// rate limited. With a Code, FindByQuery fails with a NotFoundError carrying
+// it.
+type NotFound struct {
+	Output  *Output     `json:"output,omitempty"`  // The canned output returned instead of no match.
+	Code    *codes.Code `json:"code,omitempty"`    // The status code of the NotFoundError returned instead of no match.
+	Message string      `json:"message,omitempty"` // The message of the NotFoundError, a default one if empty.
+}
+
+// NotFoundError is the error returned by FindByQuery when no stub matches a
+// query whose service or method is configured with a NotFound code.
+//
+// It matches ErrStubNotFound, and gRPC servers can return it as is, since it
+// carries its status.
+type NotFoundError struct {
+	Service string
+	Method  string
+	Code    codes.Code
+	Message string
+}
+
+// Error returns the message of the error.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s: %s/%s", e.Message, e.Service, e.Method)
+}
+
+// Unwrap returns ErrStubNotFound.
+func (e *NotFoundError) Unwrap() error {
+	return ErrStubNotFound
+}
+
+// GRPCStatus returns the gRPC status of the error, with an ErrorInfo detail
+// carrying its service and its method.
+func (e *NotFoundError) GRPCStatus() *status.Status {
+	return withErrorInfo(status.New(e.Code, e.Error()), "STUB_NOT_FOUND", map[string]string{
+		"service": e.Service,
+		"method":  e.Method,
+	})
+}
+
+// notFound holds the NotFound behaviors of the services and the methods.
+type notFound struct {
+	mu        sync.RWMutex
+	behaviors map[[2]string]*NotFound
+}
+
+// newNotFound creates a new notFound instance.
+func newNotFound() *notFound {
+	return &notFound{behaviors: make(map[[2]string]*NotFound)}
+}
+
+// set sets the behavior of the given service and method, the whole service
+// if the method is empty.
+//
+// Passing nil removes the behavior.
+func (n *notFound) set(service, method string, behavior *NotFound) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if behavior == nil {
+		delete(n.behaviors, [2]string{service, method})
+	} else {
+		n.behaviors[[2]string{service, method}] = behavior
+	}
+}
+
+// get returns the behavior of the given method, or else of its service.
+func (n *notFound) get(service, method string) *NotFound {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if behavior := n.behaviors[[2]string{service, method}]; behavior != nil {
+		return behavior
+	}
+
+	return n.behaviors[[2]string{service, ""}]
+}
+
+// apply applies the behavior of the service and the method of the query to
+// the outcome of a search that found no Stub value.
+//
+// The outcome is returned unchanged if the search failed otherwise, or if
+// there is no behavior.
+func (n *notFound) apply(query Query, result *Result, err error) (*Result, error) {
+	if err != nil && !errors.Is(err, ErrStubNotFound) &&
+		!errors.Is(err, ErrServiceNotFound) && !errors.Is(err, ErrMethodNotFound) {
+		return nil, err
+	}
+
+	behavior := n.get(query.Service, query.Method)
+	if behavior == nil {
+		behavior = &NotFound{}
+	}
+
+	switch {
+	case behavior.Code != nil:
+		message := behavior.Message
+		if message == "" {
+			message = ErrStubNotFound.Error()
+		}
+
+		return nil, &NotFoundError{
+			Service: query.Service,
+			Method:  query.Method,
+			Code:    *behavior.Code,
+			Message: message,
+		}
+	case behavior.Output != nil:
+		if result == nil {
+			result = &Result{}
+		}
+
+		result.found = &Stub{Service: query.Service, Method: query.Method, Output: *behavior.Output}
+
+		return result, nil
+	case err != nil:
+		return nil, err
+	default:
+		return result, nil
+	}
+}
+
+// SetNotFound sets what happens when no stub of the given service and method
+// matches a query, or when they have no stubs at all: the default similar
+// stubs, a canned output or a gRPC status code. An empty method sets the
+// behavior of all the methods of the service without their own.
+//
+// Passing nil restores the default behavior. The behaviors are kept by Clear.
+//
+// Parameters:
+// - service: The name of the service.
+// - method: The name of the method, or empty.
+// - behavior: The NotFound behavior, or nil.
+func (b *Budgerigar) SetNotFound(service, method string, behavior *NotFound) {
+	b.notFound.set(service, method, behavior)
+}
@@ -0,0 +1,51 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_SetNotFound(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	})
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Alice"},
+	}
+
+	_, err := s.FindByQuery(query)
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+
+	s.SetNotFound("Greeter", "", &stuber.NotFound{Output: &stuber.Output{Data: map[string]interface{}{"message": "default"}}})
+
+	result, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, result.Found().ID)
+	require.Equal(t, "default", result.Found().Output.Data["message"])
+
+	code := codes.Unimplemented
+	s.SetNotFound("Greeter", "SayGoodbye", &stuber.NotFound{Code: &code})
+
+	_, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayGoodbye"})
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	s.SetNotFound("Greeter", "", nil)
+
+	_, err = s.FindByQuery(query)
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+	require.NotErrorAs(t, err, new(*stuber.NotFoundError))
+}
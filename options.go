@@ -0,0 +1,348 @@
+package stuber
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/bavix/features"
+)
+
+// Option configures a Budgerigar created with New.
+type Option func(*Budgerigar)
+
+// RankFunc ranks how well a query matches a stub. Higher is better.
+type RankFunc func(query Query, stub *Stub) float64
+
+// Metrics receives measurements of the Budgerigar.
+type Metrics interface {
+	// ObserveSearch is called after each search with its outcome and duration.
+	ObserveSearch(service, method string, found bool, duration time.Duration)
+}
+
+// DefaultRank is the default RankFunc of a Budgerigar.
+//
+// It can be wrapped by custom ranking strategies.
+func DefaultRank(query Query, stub *Stub) float64 {
+	return rankMatch(query, stub)
+}
+
+// WithToggles sets the feature flags of the Budgerigar.
+func WithToggles(toggles features.Toggles) Option {
+	return func(b *Budgerigar) {
+		b.toggles = toggles
+	}
+}
+
+// WithFlags enables the given feature flags on the Budgerigar.
+func WithFlags(flags ...features.Flag) Option {
+	return WithToggles(features.New(flags...))
+}
+
+// WithClock sets the clock used by time based features such as rate limits,
+// concurrency limits, remote source caching, event times, stub timestamps, match history, the
+// slow log, the miss log, the traffic log and the time template functions.
+func WithClock(now func() time.Time) Option {
+	return func(b *Budgerigar) {
+		b.limiter.now = now
+		b.inflight.now = now
+		b.remote.now = now
+		b.searcher.events.now = now
+		b.searcher.now = now
+		b.history.now = now
+		b.templates.setClock(now)
+		b.searcher.slowLog.now = now
+		b.misses.now = now
+		b.traffic.now = now
+	}
+}
+
+// WithLogger sets the logger reporting the failures that don't affect
+// matching, such as webhook or remote source errors and recovered panics.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Budgerigar) {
+		b.logger = logger
+		b.hooks.logger = logger
+		b.searcher.logger = logger
+	}
+}
+
+// WithRemoteSource sets the remote source consulted when a service or a
+// method is not found, and the TTL of its local cache.
+func WithRemoteSource(source RemoteSource, ttl time.Duration) Option {
+	return func(b *Budgerigar) {
+		b.remote.set(source, ttl)
+	}
+}
+
+// WithBackend attaches the given backend once all the options are applied,
+// as AttachBackend does: the persisted Stub values are loaded and the
+// changes are written through to it.
+//
+// As an option cannot fail, a backend that cannot be loaded or written is
+// not attached, and the error is logged with the logger of WithLogger. Use
+// AttachBackend to handle the error.
+func WithBackend(backend Backend) Option {
+	return func(b *Budgerigar) {
+		b.backend = backend
+	}
+}
+
+// WithRanker sets the ranking strategy used to pick the best stub.
+func WithRanker(rank RankFunc) Option {
+	return func(b *Budgerigar) {
+		b.searcher.rank = rank
+		b.searcher.customRank = true
+	}
+}
+
+// WithHookQueue sets the number of pending deliveries of the OnMatch hooks
+// and the webhooks, beyond which they are dropped and logged, and the number
+// of deliveries run concurrently. They default to 1024 and 16.
+func WithHookQueue(size, workers int) Option {
+	return func(b *Budgerigar) {
+		b.hooks.queue = make(chan func(), max(size, 1))
+		b.hooks.workers = max(workers, 1)
+	}
+}
+
+// WithParallelRanking sets the number of candidates of a bucket above which
+// they are evaluated by a pool of the given number of workers.
+//
+// A threshold of zero disables the parallel evaluation.
+func WithParallelRanking(threshold, workers int) Option {
+	return func(b *Budgerigar) {
+		b.searcher.parallel = parallelism{threshold: threshold, workers: workers}
+	}
+}
+
+// WithRankShortCircuit stops the search of a bucket as soon as a stub of its
+// top priority is a perfect match, instead of ranking the remaining stubs.
+//
+// A match is perfect when it satisfies every matcher of the stub and no other
+// stub of the bucket can rank higher, as bounded by the structure of their
+// matchers, so the search finds the same stub as the full ranking. It only
+// applies to the default rank, and to the buckets without ordered groups.
+func WithRankShortCircuit(enabled bool) Option {
+	return func(b *Budgerigar) {
+		b.searcher.shortCircuit = enabled
+	}
+}
+
+// WithCallTracking sets whether the embedding server calls Result.Done once
+// each call is answered, so the concurrency slots are held until then
+// instead of for the delays of the outputs. Once enabled, a call whose
+// Result.Done is never called holds its slots until a Clear.
+func WithCallTracking(enabled bool) Option {
+	return func(b *Budgerigar) {
+		b.inflight.tracked = enabled
+	}
+}
+
+// WithSimilarLimit sets the number of most similar stubs tracked by a
+// search and returned by Result.Similars. It defaults to one.
+func WithSimilarLimit(limit int) Option {
+	return func(b *Budgerigar) {
+		b.searcher.similars = limit
+	}
+}
+
+// WithRevisions sets the number of revisions kept for each stub, which
+// allows them to be rolled back with RollbackByID.
+//
+// A limit of zero, the default, keeps no revisions.
+func WithRevisions(limit int) Option {
+	return func(b *Budgerigar) {
+		b.searcher.revisions.setLimit(limit)
+	}
+}
+
+// WithMatchHistory keeps the last matches, up to the given number, so
+// WhoAnswered can tell which stub answered a query.
+//
+// A size of zero, the default, keeps no matches.
+func WithMatchHistory(size int) Option {
+	return func(b *Budgerigar) {
+		b.history.resize(size)
+	}
+}
+
+// WithMissLog keeps the last queries no stub matched, up to the given
+// number, with their differences with the most similar stub, retrievable
+// with Misses.
+//
+// A size of zero, the default, keeps no misses.
+func WithMissLog(size int) Option {
+	return func(b *Budgerigar) {
+		b.misses.resize(size)
+	}
+}
+
+// WithTrafficLog keeps the last matched queries, up to the given number,
+// with the responses rendered by their stubs, retrievable with Traffic and
+// ExportTraffic.
+//
+// A size of zero, the default, keeps no queries.
+func WithTrafficLog(size int) Option {
+	return func(b *Budgerigar) {
+		b.traffic.resize(size)
+	}
+}
+
+// WithPanicRecovery sets whether a panic while matching or ranking a stub,
+// such as in a custom RankFunc, is recovered. A recovered panic is logged
+// and the stub is not a candidate, instead of crashing the whole server.
+//
+// Panics are recovered by default.
+func WithPanicRecovery(enabled bool) Option {
+	return func(b *Budgerigar) {
+		b.searcher.recoverPanics = enabled
+	}
+}
+
+// WithTemplateFunctions restricts the template functions available to the
+// output templates to the given ones.
+func WithTemplateFunctions(names ...string) Option {
+	return func(b *Budgerigar) {
+		b.templates.restrict(names, nil)
+	}
+}
+
+// WithoutTemplateFunctions makes the given template functions unavailable to
+// the output templates, such as now and random for deterministic outputs.
+func WithoutTemplateFunctions(names ...string) Option {
+	return func(b *Budgerigar) {
+		b.templates.restrict(nil, names)
+	}
+}
+
+// WithStrictTemplateMath makes the div and mod template functions fail with
+// ErrDivisionByZero when the divisor is zero, instead of returning zero,
+// which hides mistakes.
+func WithStrictTemplateMath() Option {
+	return func(b *Budgerigar) {
+		b.templates.setStrictMath(true)
+	}
+}
+
+// WithTemplateSeed seeds the random template functions, such as random and
+// uuid, so the same request renders the same output across runs. The seed of
+// an output takes precedence.
+func WithTemplateSeed(seed uint64) Option {
+	return func(b *Budgerigar) {
+		b.templates.setSeed(seed)
+	}
+}
+
+// WithExportedTimestamps includes the creation and update times of the Stub
+// values in Export, which otherwise leaves them out to keep the exported
+// files diff-stable.
+func WithExportedTimestamps() Option {
+	return func(b *Budgerigar) {
+		b.timestamps = true
+	}
+}
+
+// WithTemplateCache limits the cache of the parsed output templates to the
+// given number of templates, evicting an arbitrary one once it is full. A
+// size of zero disables the cache, the templates being parsed on each
+// rendering. Without this option, the cache is not limited.
+func WithTemplateCache(size int) Option {
+	return func(b *Budgerigar) {
+		b.templateCache.limit = max(size, 0)
+	}
+}
+
+// WithSortedListings sorts the Stub values returned by All, Used and Unused
+// in their canonical order, so API listings are stable across runs.
+func WithSortedListings() Option {
+	return func(b *Budgerigar) {
+		b.sorted = true
+	}
+}
+
+// WithSlowLog keeps the last searches, up to the given number, that took at
+// least the given threshold, with the size of their bucket and the number of
+// evaluated stubs, retrievable with SlowMatches.
+//
+// A size of zero, the default, keeps no searches.
+func WithSlowLog(threshold time.Duration, size int) Option {
+	return func(b *Budgerigar) {
+		b.searcher.slowLog.configure(threshold, size)
+	}
+}
+
+// WithScanLimit sets the number of Stub values evaluated by FindAnywhere,
+// which defaults to DefaultScanLimit.
+//
+// A limit of zero evaluates all the Stub values.
+func WithScanLimit(limit int) Option {
+	return func(b *Budgerigar) {
+		b.searcher.scanLimit = limit
+	}
+}
+
+// WithMetrics sets the receiver of the measurements of the Budgerigar.
+func WithMetrics(metrics Metrics) Option {
+	return func(b *Budgerigar) {
+		b.metrics = metrics
+	}
+}
+
+// WithTracer sets the tracer of the searches of FindByQueryContext, such as
+// the tracer of an OpenTelemetry TracerProvider. No spans are emitted by
+// default.
+func WithTracer(tracer Tracer) Option {
+	return func(b *Budgerigar) {
+		b.tracer = tracer
+	}
+}
+
+// WithEncryption encrypts the documents written by SaveToFile with AES-GCM,
+// using the keys of the provider, and decrypts the encrypted documents read
+// by LoadFromFile. The documents written without encryption are still read.
+//
+// A Backend is encrypted on its own, such as with WithLogEncryption.
+func WithEncryption(keys KeyProvider) Option {
+	return func(b *Budgerigar) {
+		b.keys = keys
+	}
+}
+
+// WithRedaction masks the fields of the given rules in the documents of
+// Export and ExportTraffic, so the artifacts shared outside the team are
+// scrubbed. The documents of SaveToFile are not redacted.
+func WithRedaction(rules ...RedactionRule) Option {
+	return func(b *Budgerigar) {
+		b.redaction = rules
+	}
+}
+
+// WithFaultInjector sets the FaultInjector injecting the faults of the
+// matched Stub values, such as one created by NewFaultInjector for
+// reproducible faults.
+func WithFaultInjector(injector *FaultInjector) Option {
+	return func(b *Budgerigar) {
+		b.faults = injector
+	}
+}
+
+// WithChangeLog keeps the last changes of the Stub values, up to the given
+// number, retrievable with Changes and WaitChanges.
+//
+// A size of zero, the default, keeps no changes.
+func WithChangeLog(size int) Option {
+	return func(b *Budgerigar) {
+		b.changes.resize(size)
+	}
+}
+
+// nopMetrics is a Metrics discarding all measurements.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveSearch(string, string, bool, time.Duration) {}
+
+// discardLogger returns a logger discarding all records.
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,238 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+type testMetrics struct {
+	searches int
+	found    int
+}
+
+func (m *testMetrics) ObserveSearch(_, _ string, found bool, _ time.Duration) {
+	m.searches++
+
+	if found {
+		m.found++
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	metrics := &testMetrics{}
+
+	s := stuber.New(
+		stuber.WithFlags(stuber.MethodTitle),
+		stuber.WithMetrics(metrics),
+		stuber.WithRanker(func(query stuber.Query, stub *stuber.Stub) float64 {
+			// Prefer the stubs with the most specific output.
+			return stuber.DefaultRank(query, stub) + float64(len(stub.Output.Data))
+		}),
+	)
+
+	s.PutMany(
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "hi"}},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "hi", "extra": true}},
+		},
+	)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "sayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, true, r.Found().Output.Data["extra"])
+
+	_, err = s.FindByQuery(stuber.Query{Service: "Unknown", Method: "SayHello"})
+	require.Error(t, err)
+
+	require.Equal(t, 2, metrics.searches)
+	require.Equal(t, 1, metrics.found)
+}
+
+func TestNew_WithClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+	s.PutMany(&stuber.Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		Input:     stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		RateLimit: &stuber.RateLimit{Limit: 1, Window: stuber.Duration(time.Minute)},
+	})
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	_, err := s.FindByQuery(query)
+	require.NoError(t, err)
+
+	r, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotEmpty(t, r.Found().Output.Error)
+
+	now = now.Add(time.Minute)
+
+	r, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+}
+
+func TestNew_WithRankShortCircuit(t *testing.T) {
+	s := stuber.New(stuber.WithRankShortCircuit(true), stuber.WithSlowLog(0, 10))
+
+	for range 10 {
+		s.PutMany(&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		})
+	}
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.Equal(t, 1, s.SlowMatches()[0].Candidates)
+}
+
+func TestNew_WithRankShortCircuit_FullRanking(t *testing.T) {
+	stub := func(input stuber.InputData) *stuber.Stub {
+		return &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", Input: input}
+	}
+
+	tests := []struct {
+		name      string
+		stubs     func() []*stuber.Stub
+		data      map[string]interface{}
+		evaluated int
+	}{
+		{
+			name: "partial match",
+			stubs: func() []*stuber.Stub {
+				result := []*stuber.Stub{
+					stub(stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}}),
+					stub(stuber.InputData{Contains: map[string]interface{}{"name": "Bob", "age": "42"}}),
+				}
+
+				for range 8 {
+					result = append(result, stub(stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}}))
+				}
+
+				return result
+			},
+			data:      map[string]interface{}{"name": "Bob", "age": "42"},
+			evaluated: 2,
+		},
+		{
+			name: "combined matchers",
+			stubs: func() []*stuber.Stub {
+				return []*stuber.Stub{
+					stub(stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}}),
+					stub(stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}}),
+					stub(stuber.InputData{
+						Equals:   map[string]interface{}{"name": "Bob"},
+						Contains: map[string]interface{}{"name": "Bob"},
+					}),
+				}
+			},
+			data:      map[string]interface{}{"name": "Bob"},
+			evaluated: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: tt.data}
+
+			stubs := tt.stubs()
+
+			s := stuber.New()
+			s.PutMany(stubs...)
+
+			expected, err := s.FindByQuery(query)
+			require.NoError(t, err)
+
+			s = stuber.New(stuber.WithRankShortCircuit(true), stuber.WithSlowLog(0, 10))
+			s.PutMany(stubs...)
+
+			// The short-circuit finds the stub found by the full ranking.
+			r, err := s.FindByQuery(query)
+			require.NoError(t, err)
+			require.Equal(t, expected.Found().ID, r.Found().ID)
+			require.Equal(t, tt.evaluated, s.SlowMatches()[0].Candidates)
+		})
+	}
+}
+
+func TestNew_WithPanicRecovery(t *testing.T) {
+	broken := uuid.New()
+
+	rank := func(query stuber.Query, stub *stuber.Stub) float64 {
+		if stub.ID == broken {
+			panic("malformed stub")
+		}
+
+		return stuber.DefaultRank(query, stub)
+	}
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	}
+
+	stubs := func() []*stuber.Stub {
+		return []*stuber.Stub{
+			{
+				ID:       broken,
+				Service:  "Greeter",
+				Method:   "SayHello",
+				Priority: 1,
+				Input:    stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			},
+			{
+				ID:      uuid.New(),
+				Service: "Greeter",
+				Method:  "SayHello",
+				Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			},
+		}
+	}
+
+	s := stuber.New(stuber.WithRanker(rank))
+	s.PutMany(stubs()...)
+
+	r, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.NotEqual(t, broken, r.Found().ID)
+
+	s = stuber.New(stuber.WithRanker(rank), stuber.WithPanicRecovery(false))
+	s.PutMany(stubs()...)
+
+	require.Panics(t, func() {
+		_, _ = s.FindByQuery(query)
+	})
+}
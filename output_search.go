@@ -0,0 +1,116 @@
+package stuber
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SearchOutputs returns the stored Stub values whose output contains the
+// given value, to find which stub emitted an unexpected response.
+//
+// A query starting with "$" is a JSONPath selecting a value of the output,
+// such as $.data.user.name, $.data.items[0].id or $.error, and the Stub values
+// are returned if the value exists and is not null or an empty string. Otherwise, the Stub values are returned if
+// the JSON encoding of their output contains the query.
+//
+// Parameters:
+// - query: The substring or the JSONPath to search.
+//
+// Returns:
+// - []*Stub: The sorted matching Stub values.
+func (b *Budgerigar) SearchOutputs(query string) []*Stub {
+	var path []string
+	if strings.HasPrefix(query, "$") {
+		path = parseJSONPath(query)
+	}
+
+	var results []*Stub
+
+	for _, stub := range b.searcher.all() {
+		data, err := json.Marshal(stub.Output)
+		if err != nil {
+			continue
+		}
+
+		if path == nil {
+			if bytes.Contains(data, []byte(query)) {
+				results = append(results, stub)
+			}
+
+			continue
+		}
+
+		var doc any
+		if err := decodeJSON(data, &doc); err == nil && selectJSONPath(doc, path) {
+			results = append(results, stub)
+		}
+	}
+
+	return sortStubs(results)
+}
+
+// parseJSONPath splits a JSONPath such as $.a.b[0] into its segments, the
+// indexes being segments too. An empty, non-nil slice selects the root.
+func parseJSONPath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+
+	segments := make([]string, 0, strings.Count(path, "."))
+
+	for _, segment := range strings.Split(path, ".") {
+		if segment != "" {
+			segments = append(segments, strings.Trim(segment, `'"`))
+		}
+	}
+
+	return segments
+}
+
+// selectJSONPath checks if the given path selects a value of the document
+// other than null or an empty string.
+func selectJSONPath(doc any, path []string) bool {
+	value, ok := lookupJSONPath(doc, path)
+
+	return ok && value != nil && value != ""
+}
+
+// lookupJSONPath returns the value of the document at the given path, which
+// traverses maps by key and arrays by index.
+func lookupJSONPath(doc any, path []string) (any, bool) {
+	for _, segment := range path {
+		switch v := doc.(type) {
+		case map[string]any:
+			value, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+
+			doc = value
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+
+			doc = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return doc, true
+}
+
+// sortStubs sorts the given stubs by ID in place and returns them.
+func sortStubs(stubs []*Stub) []*Stub {
+	slices.SortFunc(stubs, func(a, b *Stub) int {
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+
+	return stubs
+}
@@ -0,0 +1,50 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_SearchOutputs(t *testing.T) {
+	s := stuber.New()
+
+	hello := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Output: stuber.Output{Data: map[string]interface{}{
+			"message": "Hello, Bob",
+			"items":   []interface{}{map[string]interface{}{"id": 7}},
+		}},
+	}
+	failure := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayGoodbye",
+		Output:  stuber.Output{Error: "goodbye failed"},
+	}
+
+	s.PutMany(hello, failure)
+
+	ids := func(stubs []*stuber.Stub) []uuid.UUID {
+		result := make([]uuid.UUID, 0, len(stubs))
+		for _, stub := range stubs {
+			result = append(result, stub.ID)
+		}
+
+		return result
+	}
+
+	require.Equal(t, []uuid.UUID{hello.ID}, ids(s.SearchOutputs("Hello, Bob")))
+	require.Equal(t, []uuid.UUID{failure.ID}, ids(s.SearchOutputs("goodbye")))
+	require.Empty(t, s.SearchOutputs("Alice"))
+
+	require.Equal(t, []uuid.UUID{hello.ID}, ids(s.SearchOutputs("$.data.message")))
+	require.Equal(t, []uuid.UUID{hello.ID}, ids(s.SearchOutputs("$.data.items[0].id")))
+	require.Empty(t, s.SearchOutputs("$.data.items[1].id"))
+	require.Equal(t, []uuid.UUID{failure.ID}, ids(s.SearchOutputs("$.error")))
+}
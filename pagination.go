@@ -0,0 +1,134 @@
+package stuber
+
+import (
+	"cmp"
+	"maps"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// Default fields of a Pagination.
+const (
+	DefaultPageItemsField     = "items"
+	DefaultPageTokenField     = "page_token"
+	DefaultNextPageTokenField = "next_page_token"
+)
+
+// invalidPageTokenMessage is the error message of the calls with a page
+// token the stub did not issue.
+const invalidPageTokenMessage = "invalid page token"
+
+// Pagination describes a dataset served page by page by a stub, the page
+// tokens being issued and tracked by the Budgerigar.
+//
+// A call without a page token gets the first page, along with the token of
+// the next page, if any. A call with the token gets the next page, and so
+// on. The matchers of the stub should not require the token to be absent,
+// such as with Equals, so the calls with a token match it as well.
+type Pagination struct {
+	PageSize       int           `json:"pageSize"`                 // The number of items of a page.
+	Items          []interface{} `json:"items"`                    // The dataset.
+	ItemsField     string        `json:"itemsField,omitempty"`     // The output field of the items of the page, DefaultPageItemsField if empty.
+	TokenField     string        `json:"tokenField,omitempty"`     // The input field of the page token, DefaultPageTokenField if empty.
+	NextTokenField string        `json:"nextTokenField,omitempty"` // The output field of the next page token, DefaultNextPageTokenField if empty.
+}
+
+// pageToken is the position a page token was issued for.
+type pageToken struct {
+	stub   uuid.UUID
+	offset int
+}
+
+// paginator issues and resolves the page tokens of the paginated stubs.
+type paginator struct {
+	mu     sync.Mutex
+	tokens map[string]pageToken
+}
+
+// newPaginator creates a new paginator.
+func newPaginator() *paginator {
+	return &paginator{tokens: make(map[string]pageToken)}
+}
+
+// reset forgets the issued tokens.
+func (p *paginator) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tokens = make(map[string]pageToken)
+}
+
+// apply returns the stub unchanged if it is not paginated, otherwise a copy
+// of the stub with the page of the token of the query in its output, or an
+// InvalidArgument error if the stub did not issue the token.
+func (p *paginator) apply(query Query, stub *Stub) *Stub {
+	pagination := stub.Pagination
+	if pagination == nil {
+		return stub
+	}
+
+	offset, ok := p.offset(stub, query.Data[cmp.Or(pagination.TokenField, DefaultPageTokenField)])
+	if !ok {
+		code := codes.InvalidArgument
+
+		return stub.withOutput(Output{Error: invalidPageTokenMessage, Code: &code, Delay: stub.Output.Delay})
+	}
+
+	size := max(pagination.PageSize, 1)
+	end := min(offset+size, len(pagination.Items))
+
+	output := stub.Output
+	output.Data = make(map[string]interface{}, len(stub.Output.Data)+2)
+	maps.Copy(output.Data, stub.Output.Data)
+
+	output.Data[cmp.Or(pagination.ItemsField, DefaultPageItemsField)] = pagination.Items[offset:end]
+	output.Data[cmp.Or(pagination.NextTokenField, DefaultNextPageTokenField)] = p.issue(stub, end)
+
+	return stub.withOutput(output)
+}
+
+// offset returns the offset of the given page token of the stub, 0 without
+// a token, and whether the token was issued by the stub.
+func (p *paginator) offset(stub *Stub, token interface{}) (int, bool) {
+	if token == nil || token == "" {
+		return 0, true
+	}
+
+	text, ok := token.(string)
+	if !ok {
+		return 0, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	position, ok := p.tokens[text]
+	if !ok || position.stub != stub.ID || position.offset > len(stub.Pagination.Items) {
+		return 0, false
+	}
+
+	return position.offset, true
+}
+
+// issue returns the token of the page of the stub starting at the given
+// offset, or an empty token after the last page.
+//
+// The tokens are derived from the stub and the offset, so the same pages
+// have the same tokens across runs.
+func (p *paginator) issue(stub *Stub, offset int) string {
+	if offset >= len(stub.Pagination.Items) {
+		return ""
+	}
+
+	token := uuid.NewSHA1(stub.ID, []byte(strconv.Itoa(offset))).String()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tokens[token] = pageToken{stub: stub.ID, offset: offset}
+
+	return token
+}
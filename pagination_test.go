@@ -0,0 +1,79 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Pagination(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Library",
+		Method:  "ListBooks",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"shelf": "sf"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"shelf": "sf"}},
+		Pagination: &stuber.Pagination{
+			PageSize: 2,
+			Items:    []interface{}{"Dune", "Hyperion", "Solaris", "Neuromancer", "Foundation"},
+		},
+	}
+	s.PutMany(stub)
+
+	list := func(token string) stuber.Output {
+		data := map[string]interface{}{"shelf": "sf"}
+		if token != "" {
+			data["page_token"] = token
+		}
+
+		result, err := s.FindByQuery(stuber.Query{Service: "Library", Method: "ListBooks", Data: data})
+		require.NoError(t, err)
+
+		return result.Found().Output
+	}
+
+	var (
+		token string
+		books []interface{}
+		pages int
+	)
+
+	for {
+		output := list(token)
+		require.Equal(t, "sf", output.Data["shelf"])
+
+		books = append(books, output.Data["items"].([]interface{})...)
+		pages++
+
+		if token = output.Data["next_page_token"].(string); token == "" {
+			break
+		}
+	}
+
+	require.Equal(t, stub.Pagination.Items, books)
+	require.Equal(t, 3, pages)
+
+	// The tokens are stable, so a page can be fetched again.
+	first := list("")
+	require.Equal(t, first.Data, list("").Data)
+	require.Equal(t, []interface{}{"Solaris", "Neuromancer"}, list(first.Data["next_page_token"].(string)).Data["items"])
+
+	// The stored stub is left untouched.
+	require.NotContains(t, s.FindByID(stub.ID).Output.Data, "items")
+
+	invalid := list("bogus")
+	require.Equal(t, codes.InvalidArgument, *invalid.Code)
+
+	// A clear forgets the issued tokens.
+	token = first.Data["next_page_token"].(string)
+
+	s.Clear()
+	s.PutMany(stub)
+	require.Equal(t, codes.InvalidArgument, *list(token).Code)
+}
@@ -0,0 +1,110 @@
+package stuber
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultParallelThreshold is the number of candidates above which they are
+// evaluated by a pool of workers.
+const defaultParallelThreshold = 512
+
+// parallelism configures the parallel evaluation of candidates.
+type parallelism struct {
+	threshold int // The number of candidates above which the pool is used, or 0 to disable it.
+	workers   int // The number of workers of the pool.
+}
+
+// defaultParallelism returns the default parallelism.
+func defaultParallelism() parallelism {
+	return parallelism{threshold: defaultParallelThreshold, workers: runtime.GOMAXPROCS(0)}
+}
+
+// candidate is a Stub value evaluated against a query.
+type candidate struct {
+	stub    *Stub   // The Stub value merged with its base stubs.
+	rank    float64 // The rank of the Stub value.
+	matched bool    // Whether the query matches the Stub value.
+	valid   bool    // Whether the Stub value is a candidate at all.
+}
+
+// evaluate ranks and matches the given Stub value against the query.
+//
+// Abstract stubs, which are never candidates, are not valid. Neither are
+// stubs whose evaluation panics, when panics are recovered.
+func (s *searcher) evaluate(query Query, stub *Stub) (result candidate) {
+	if stub.Abstract {
+		return candidate{}
+	}
+
+	if s.recoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				s.logger.Error("stuber: stub evaluation panicked", "stub", stub.ID, "panic", r)
+
+				result = candidate{}
+			}
+		}()
+	}
+
+	// Merge the Stub value with its base stubs and normalize its matchers.
+	stub, matcher := s.prepare(stub)
+
+	matched := s.match(query, matcher)
+
+	// Exact-match-only queries have no use for the rank of the other stubs.
+	if !matched && query.ExactOnly() {
+		return candidate{stub: stub, valid: true}
+	}
+
+	return candidate{
+		stub:    stub,
+		rank:    s.rank(query, matcher),
+		matched: matched,
+		valid:   true,
+	}
+}
+
+// candidates returns a function evaluating the i-th of the given Stub values,
+// and a function releasing the resources of the evaluation.
+//
+// Small buckets are evaluated lazily on the calling goroutine. Buckets larger
+// than the threshold are evaluated upfront by a bounded pool of workers; the
+// results are indexed like the Stub values, so the caller merges them in the
+// same deterministic order either way.
+func (s *searcher) candidates(query Query, stubs []*Stub) (func(int) candidate, func()) {
+	p := s.parallel
+
+	if p.threshold <= 0 || p.workers <= 1 || len(stubs) <= p.threshold {
+		return func(i int) candidate {
+			return s.evaluate(query, stubs[i])
+		}, func() {}
+	}
+
+	buf := getCandidates(len(stubs))
+	results := *buf
+
+	var (
+		next atomic.Int64
+		wg   sync.WaitGroup
+	)
+
+	for range min(p.workers, len(stubs)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := int(next.Add(1) - 1); i < len(stubs); i = int(next.Add(1) - 1) {
+				results[i] = s.evaluate(query, stubs[i])
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return func(i int) candidate {
+		return results[i]
+	}, func() { putCandidates(buf) }
+}
@@ -0,0 +1,59 @@
+package stuber_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_ParallelRanking(t *testing.T) {
+	stubs := make([]*stuber.Stub, 0, 1000)
+
+	for i := range 1000 {
+		stubs = append(stubs, &stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Catalog",
+			Method:  "Get",
+			Input: stuber.InputData{Contains: map[string]interface{}{
+				"sku":   strconv.Itoa(i % 100),
+				"store": strconv.Itoa(i % 7),
+			}},
+		})
+	}
+
+	query := stuber.Query{
+		Service: "Catalog",
+		Method:  "Get",
+		Data:    map[string]interface{}{"sku": "42", "store": "2"},
+	}
+
+	sequential := stuber.New(stuber.WithParallelRanking(0, 0))
+	sequential.PutMany(stubs...)
+
+	parallel := stuber.New(stuber.WithParallelRanking(10, 8))
+	parallel.PutMany(stubs...)
+
+	expected, err := sequential.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotNil(t, expected.Found())
+
+	for range 10 {
+		actual, err := parallel.FindByQuery(query)
+		require.NoError(t, err)
+		require.Equal(t, expected.Found().ID, actual.Found().ID)
+	}
+
+	query.Data["store"] = "9"
+
+	expected, err = sequential.FindByQuery(query)
+	require.NoError(t, err)
+
+	actual, err := parallel.FindByQuery(query)
+	require.NoError(t, err)
+	require.Nil(t, actual.Found())
+	require.Equal(t, expected.Similar().ID, actual.Similar().ID)
+}
@@ -0,0 +1,165 @@
+package stuber
+
+import "github.com/google/uuid"
+
+// normalize readies a patched stub value as PutMany does: it moves its
+// deprecated fields into their replacements, inherits its service and method
+// from its base, and lowercases its header names if the LowerHeaders feature
+// flag is enabled.
+//
+// The caller must hold the write lock of the storage.
+func (s *searcher) normalize(stub *Stub) {
+	stub.canonicalize()
+
+	if stub.Base != nil {
+		if base, ok := s.storage.findByIDLocked(*stub.Base).(*Stub); ok {
+			stub.inheritTarget(base)
+		}
+	}
+
+	if s.toggles.Has(LowerHeaders) {
+		lowerStubHeaders([]*Stub{stub})
+	}
+}
+
+// patchByID applies the given mutator to a copy of the stub value with the
+// given ID, keeping its ID.
+func (s *searcher) patchByID(id uuid.UUID, mutate func(*Stub) error) error {
+	defer s.cache.invalidate()
+
+	var patched *Stub
+
+	s.persistence.Lock()
+
+	err := s.storage.modifyByID(id, func(v Value) (Value, error) {
+		stub, ok := v.(*Stub)
+		if !ok {
+			return v, nil
+		}
+
+		// The mutator modifies a deep copy, as the stub may be searched
+		// concurrently and is kept if the mutator fails.
+		clone := stub.Clone()
+		if err := mutate(clone); err != nil {
+			return nil, err
+		}
+
+		clone.ID = stub.ID
+		now := s.now()
+		clone.UpdatedAt = &now
+		s.normalize(clone)
+		s.revisions.record(clone)
+		patched = clone
+
+		return clone, nil
+	})
+	if err != nil {
+		s.persistence.Unlock()
+
+		return err
+	}
+
+	s.write("patch", func(backend Backend) error { return backend.Put([]*Stub{patched}) })
+	s.persistence.Unlock()
+
+	// Publish after the storage is unlocked, as delivering may block.
+	s.events.publish(EventUpdate, patched)
+
+	return nil
+}
+
+// updateWhere applies the given mutator to copies of the stub values accepted
+// by the given predicate, keeping their IDs.
+func (s *searcher) updateWhere(pred func(*Stub) bool, mutate func(*Stub)) int {
+	defer s.cache.invalidate()
+
+	var patched []*Stub
+
+	s.persistence.Lock()
+
+	n := s.storage.modifyWhere(func(v Value) bool {
+		stub, ok := v.(*Stub)
+
+		return ok && pred(stub)
+	}, func(v Value) Value {
+		stub, _ := v.(*Stub) // accepted by the predicate
+
+		// The mutator modifies a deep copy, as the stub may be searched concurrently.
+		clone := stub.Clone()
+		mutate(clone)
+		clone.ID = stub.ID
+		now := s.now()
+		clone.UpdatedAt = &now
+		s.normalize(clone)
+		s.revisions.record(clone)
+		patched = append(patched, clone)
+
+		return clone
+	})
+
+	if len(patched) > 0 {
+		s.write("patch", func(backend Backend) error { return backend.Put(patched) })
+	}
+
+	s.persistence.Unlock()
+
+	// Publish after the storage is unlocked, as delivering may block.
+	s.events.publish(EventUpdate, patched...)
+
+	return n
+}
+
+// PatchByID atomically modifies the Stub value with the given ID.
+//
+// The mutator receives a deep copy of the Stub value, which replaces it if the
+// mutator returns no error. The ID of the Stub value cannot be changed.
+// The mutator must not access the Budgerigar.
+//
+// Parameters:
+// - id: The UUID of the Stub value to modify.
+// - mutate: The function modifying the Stub value.
+//
+// Returns:
+// - error: ErrStubNotFound if there is no such Stub value, or the error of the mutator.
+func (b *Budgerigar) PatchByID(id uuid.UUID, mutate func(*Stub) error) error {
+	if err := b.searcher.patchByID(id, mutate); err != nil {
+		return err
+	}
+
+	// Drop the cached templates of the modified Stub value.
+	b.templateCache.invalidate(id)
+
+	return nil
+}
+
+// UpdateWhere atomically modifies the Stub values accepted by the given
+// predicate, under a single lock.
+//
+// The mutator receives a deep copy of each Stub value, which replaces it. The ID
+// of the Stub values cannot be changed. The predicate and the mutator must
+// not access the Budgerigar.
+//
+// Parameters:
+// - pred: The predicate selecting the Stub values to modify.
+// - mutate: The function modifying each Stub value.
+//
+// Returns:
+// - int: The number of Stub values that were modified.
+func (b *Budgerigar) UpdateWhere(pred func(*Stub) bool, mutate func(*Stub)) int {
+	var ids []uuid.UUID
+
+	n := b.searcher.updateWhere(func(stub *Stub) bool {
+		if !pred(stub) {
+			return false
+		}
+
+		ids = append(ids, stub.ID)
+
+		return true
+	}, mutate)
+
+	// Drop the cached templates of the modified Stub values.
+	b.templateCache.invalidate(ids...)
+
+	return n
+}
@@ -0,0 +1,175 @@
+package stuber_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_PatchByID(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "hi"}},
+	}
+
+	s.PutMany(stub)
+
+	require.ErrorIs(t, s.PatchByID(uuid.New(), func(*stuber.Stub) error { return nil }), stuber.ErrStubNotFound)
+
+	errBoom := errors.New("boom")
+	require.ErrorIs(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+		stub.Method = "SayGoodbye"
+
+		return errBoom
+	}), errBoom)
+	require.Equal(t, "SayHello", s.FindByID(stub.ID).Method)
+
+	require.NoError(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+		stub.ID = uuid.New()
+		stub.Method = "SayGoodbye"
+		stub.Output = stuber.Output{Data: map[string]interface{}{"message": "bye"}}
+
+		return nil
+	}))
+
+	all, err := s.FindBy("Greeter", "SayHello")
+	require.NoError(t, err)
+	require.Empty(t, all)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayGoodbye",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, stub.ID, r.Found().ID)
+	require.Equal(t, "bye", r.Found().Output.Data["message"])
+}
+
+func TestBudgerigar_UpdateWhere(t *testing.T) {
+	s := stuber.New()
+
+	s.PutMany(
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"},
+		&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayGoodbye"},
+		&stuber.Stub{ID: uuid.New(), Service: "Other", Method: "SayHello"},
+	)
+
+	n := s.UpdateWhere(func(stub *stuber.Stub) bool {
+		return stub.Service == "Greeter"
+	}, func(stub *stuber.Stub) {
+		stub.Priority++
+	})
+	require.Equal(t, 2, n)
+
+	for _, stub := range s.All() {
+		require.Equal(t, stub.Service == "Greeter", stub.Priority == 1)
+	}
+}
+
+func TestBudgerigar_PatchByID_Normalize(t *testing.T) {
+	s := stuber.New(stuber.WithFlags(stuber.LowerHeaders))
+
+	stub := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"}
+	s.PutMany(stub)
+
+	require.NoError(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+		stub.Headers = stuber.InputHeader{Equals: map[string]interface{}{"Authorization": "token"}}
+
+		return nil
+	}))
+	require.Equal(t, map[string]interface{}{"authorization": "token"}, s.FindByID(stub.ID).Headers.Equals)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: map[string]interface{}{"Authorization": "token"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+
+	require.Equal(t, 1, s.UpdateWhere(func(*stuber.Stub) bool { return true }, func(stub *stuber.Stub) {
+		stub.Headers.Contains = map[string]interface{}{"X-Trace": "1"}
+		stub.Stream = []stuber.InputData{{Equals: map[string]interface{}{"name": "Bob"}}}
+	}))
+
+	patched := s.FindByID(stub.ID)
+	require.Equal(t, map[string]interface{}{"x-trace": "1"}, patched.Headers.Contains)
+	require.Empty(t, patched.Stream)
+	require.Len(t, patched.Inputs, 1)
+}
+
+func TestBudgerigar_PatchByID_Base(t *testing.T) {
+	s := stuber.New()
+
+	base := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", Abstract: true}
+	stub := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayBye"}
+	other := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayBye"}
+	s.PutMany(base, stub, other)
+
+	// The patched stubs inherit their service and method from their base,
+	// as the inserted ones do.
+	require.NoError(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+		stub.Method = ""
+		stub.Base = &base.ID
+
+		return nil
+	}))
+	require.Equal(t, "SayHello", s.FindByID(stub.ID).Method)
+
+	require.Equal(t, 1, s.UpdateWhere(func(stub *stuber.Stub) bool { return stub.ID == other.ID }, func(stub *stuber.Stub) {
+		stub.Service, stub.Method = "", ""
+		stub.Base = &base.ID
+	}))
+	require.Equal(t, "SayHello", s.FindByID(other.ID).Method)
+
+	r, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+
+	_, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayBye"})
+	require.Error(t, err)
+}
+
+func TestBudgerigar_PatchByID_Error(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "hi"}},
+	}
+	s.PutMany(stub)
+
+	errBoom := errors.New("boom")
+	require.ErrorIs(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+		stub.Input.Equals["name"] = "Alice"
+		stub.Output.Data["message"] = "bye"
+
+		return errBoom
+	}), errBoom)
+
+	stored := s.FindByID(stub.ID)
+	require.Equal(t, map[string]interface{}{"name": "Bob"}, stored.Input.Equals)
+	require.Equal(t, map[string]interface{}{"message": "hi"}, stored.Output.Data)
+
+	// The patched stub does not share its maps with the replaced one.
+	require.NoError(t, s.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+		stub.Input.Equals["name"] = "Alice"
+
+		return nil
+	}))
+	require.Equal(t, map[string]interface{}{"name": "Bob"}, stored.Input.Equals)
+	require.Equal(t, "Alice", s.FindByID(stub.ID).Input.Equals["name"])
+}
@@ -0,0 +1,195 @@
+package stuber
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// savedFileMode is the mode of the files created by SaveToFile.
+const savedFileMode = 0o644
+
+// isYAML checks if the file at the given path is a YAML document, by its
+// extension.
+func isYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// isStubFile checks if the file at the given path is a JSON or YAML document,
+// by its extension.
+func isStubFile(path string) bool {
+	return isYAML(path) || strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// SaveToFile writes all the Stub values to the file at the given path, as
+// exported by Export, so they can be loaded back with LoadFromFile. The
+// document is YAML if the path ends with .yaml or .yml, JSON otherwise.
+//
+// The file is replaced atomically: a temporary file is written in the same
+// directory, then renamed over it, keeping the mode of the replaced file, or
+// 0644 for a new file. The document is encrypted if the
+// Budgerigar has keys, set with WithEncryption.
+//
+// Parameters:
+// - path: The path of the file.
+//
+// Returns:
+// - error: An error if the Stub values cannot be encoded or written.
+func (b *Budgerigar) SaveToFile(path string) error {
+	// The saved Stub values are loaded back, so they are neither redacted
+	// nor stripped of their timestamps.
+	data, err := b.export(nil, true)
+	if err != nil {
+		return err
+	}
+
+	if isYAML(path) {
+		// Decode the numbers as json.Number, so they are encoded verbatim.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+
+		var tree any
+		if err := dec.Decode(&tree); err != nil {
+			return err
+		}
+
+		if data, err = yaml.Marshal(yamlNumbers(tree)); err != nil {
+			return err
+		}
+	}
+
+	mode := os.FileMode(savedFileMode)
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		mode = info.Mode().Perm()
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	if b.keys != nil {
+		if data, err = seal(b.keys, data); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	// The temporary files are created private.
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// yamlNumbers returns the JSON tree with its json.Number values replaced by
+// YAML scalars of the same text, which the YAML encoder would quote as
+// strings.
+func yamlNumbers(node any) any {
+	switch node := node.(type) {
+	case map[string]any:
+		for key, child := range node {
+			node[key] = yamlNumbers(child)
+		}
+	case []any:
+		for i, child := range node {
+			node[i] = yamlNumbers(child)
+		}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(node.String(), ".eE") {
+			tag = "!!float"
+		}
+
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: node.String()}
+	}
+
+	return node
+}
+
+// LoadFromFile inserts the Stub values of the JSON or YAML document at the
+// given path, in any of the formats accepted by Import.
+//
+// If the path is a directory, the .json, .yaml and .yml documents of the
+// directory and its subdirectories are loaded, in lexical order, so a server
+// can bootstrap from a stub directory. No Stub value is inserted unless all
+// the documents are decoded.
+//
+// Parameters:
+// - path: The path of the file or the directory.
+//
+// Returns:
+// - []uuid.UUID: The keys of the inserted Stub values.
+// - error: An error if a document cannot be read, one matching
+// ErrInvalidImport if it cannot be decoded, or one matching ErrDecryption if
+// it is encrypted and cannot be decrypted.
+func (b *Budgerigar) LoadFromFile(path string) ([]uuid.UUID, error) {
+	var stubs []*Stub
+
+	err := filepath.WalkDir(path, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip the other files of a directory, not the given file.
+		if entry.IsDir() || name != path && !isStubFile(name) {
+			return nil
+		}
+
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		if sealed(data) {
+			if data, err = unseal(b.keys, data); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+
+		decoded, err := b.importer.decode(data, b.toggles.Has(ImportEnv))
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		stubs = append(stubs, decoded...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.PutMany(stubs...), nil
+}
@@ -0,0 +1,133 @@
+package stuber_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_SaveToFile(t *testing.T) {
+	clock := stuber.WithClock(func() time.Time {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	s := stuber.New(clock)
+	s.PutMany(
+		&stuber.Stub{
+			ID:       uuid.New(),
+			Service:  "Greeter",
+			Method:   "SayHello",
+			Priority: 2,
+			Input:    stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			Output:   stuber.Output{Data: map[string]interface{}{"message": "Hello Bob"}},
+			Meta:     map[string]string{"ticket": "QA-42"},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayGoodbye",
+			Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		},
+	)
+
+	expected, err := s.Export()
+	require.NoError(t, err)
+
+	for _, name := range []string{"stubs.json", "stubs.yaml"} {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, s.SaveToFile(path))
+
+		loaded := stuber.New(clock)
+
+		ids, err := loaded.LoadFromFile(path)
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+
+		actual, err := loaded.Export()
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(actual), name)
+	}
+}
+
+func TestBudgerigar_LoadFromFile_Directory(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "greeter"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greeter", "hello.yml"), []byte(`
+service: Greeter
+method: SayHello
+input:
+  equals:
+    name: Bob
+output:
+  data:
+    message: Hello Bob
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "goodbye.json"), []byte(`[{
+		"service": "Greeter",
+		"method": "SayGoodbye",
+		"input": {"equals": {"name": "Bob"}}
+	}]`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Stubs"), 0o600))
+
+	s := stuber.New()
+
+	ids, err := s.LoadFromFile(dir)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600))
+
+	_, err = stuber.New().LoadFromFile(dir)
+	require.ErrorIs(t, err, stuber.ErrInvalidImport)
+
+	_, err = stuber.New().LoadFromFile(filepath.Join(dir, "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBudgerigar_SaveToFile_Numbers(t *testing.T) {
+	// 2^53 + 1 has no float64 representation.
+	const large = int64(1<<53 + 1)
+
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Output:  stuber.Output{Data: map[string]interface{}{"id": large, "ratio": 0.5}},
+	})
+
+	path := filepath.Join(t.TempDir(), "stubs.yaml")
+	require.NoError(t, s.SaveToFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "id: 9007199254740993\n")
+	require.Contains(t, string(data), "ratio: 0.5\n")
+}
+
+func TestBudgerigar_SaveToFile_Mode(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"})
+
+	path := filepath.Join(t.TempDir(), "stubs.json")
+	require.NoError(t, s.SaveToFile(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	// The mode of the replaced file is kept.
+	require.NoError(t, os.Chmod(path, 0o640))
+	require.NoError(t, s.SaveToFile(path))
+
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
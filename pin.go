@@ -0,0 +1,121 @@
+package stuber
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// errPinUsedUp is returned by claimPinned when a concurrent search used up
+// the pin of the found Stub value first.
+var errPinUsedUp = errors.New("pin used up")
+
+// pin forces a stub to answer the next calls of its method it matches.
+type pin struct {
+	stub      uuid.UUID
+	remaining int
+}
+
+// pinKey returns the key of the pins of the given service and method.
+func pinKey(service, method string) string {
+	return service + "/" + method
+}
+
+// pinsOf returns the order of the pinned stubs of the given service and
+// method, the first pinned first, or nil if there are none.
+func (s *searcher) pinsOf(service, method string) map[uuid.UUID]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pins := s.pins[pinKey(service, method)]
+	if len(pins) == 0 {
+		return nil
+	}
+
+	order := make(map[uuid.UUID]int, len(pins))
+	for i, pin := range pins {
+		order[pin.stub] = i
+	}
+
+	return order
+}
+
+// pinIndex returns the index of the pin of the given stub among the pins of
+// its service and method, or -1 if the stub is not pinned.
+//
+// The mutex must be held.
+func (s *searcher) pinIndex(stub *Stub) int {
+	for i, pin := range s.pins[pinKey(stub.Service, stub.Method)] {
+		if pin.stub == stub.ID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// usePin counts a call answered by the stub pinned at the given index among
+// the pins of the given key.
+//
+// The mutex must be held.
+func (s *searcher) usePin(key string, i int) {
+	if s.pins[key][i].remaining--; s.pins[key][i].remaining <= 0 {
+		s.unpin(key, i)
+	}
+}
+
+// unpin removes the pin at the given index.
+//
+// The mutex must be held.
+func (s *searcher) unpin(key string, i int) {
+	s.pins[key] = append(s.pins[key][:i], s.pins[key][i+1:]...)
+
+	if len(s.pins[key]) == 0 {
+		delete(s.pins, key)
+	}
+}
+
+// PinNext forces the Stub value with the given ID to answer the next calls
+// of its service and method it matches, regardless of the priority and the
+// rank of the other Stub values, such as to inject a one-off failure.
+//
+// The pins of a method are honored in the order they were set. Internal
+// queries are answered by the pinned Stub values without counting as calls.
+//
+// Parameters:
+// - service: The service of the Stub value.
+// - method: The method of the Stub value.
+// - id: The ID of the Stub value.
+// - n: The number of calls to answer, or zero to unpin the Stub value.
+//
+// Returns:
+// - error: ErrStubNotFound if the Stub value is not a Stub value of the
+// service and method.
+func (b *Budgerigar) PinNext(service, method string, id uuid.UUID, n int) error {
+	stub := b.searcher.findByID(id)
+	if stub == nil || stub.Service != service || stub.Method != method {
+		return fmt.Errorf("%w: %s in %s/%s", ErrStubNotFound, id, service, method)
+	}
+
+	s := b.searcher
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pinKey(service, method)
+
+	for i, pin := range s.pins[key] {
+		if pin.stub == id {
+			s.unpin(key, i)
+
+			break
+		}
+	}
+
+	if n > 0 {
+		s.pins[key] = append(s.pins[key], pin{stub: id, remaining: n})
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package stuber_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_PinNext(t *testing.T) {
+	s := stuber.New()
+
+	success := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Payments",
+		Method:   "Charge",
+		Priority: 10,
+		Input:    stuber.InputData{Equals: map[string]interface{}{"amount": 5}},
+		Output:   stuber.Output{Data: map[string]interface{}{"status": "charged"}},
+	}
+	failure := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Payments",
+		Method:  "Charge",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"amount": 5}},
+		Output:  stuber.Output{Error: "declined"},
+	}
+	other := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Payments",
+		Method:  "Charge",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"amount": 7}},
+	}
+	s.PutMany(success, failure, other)
+
+	query := stuber.Query{Service: "Payments", Method: "Charge", Data: map[string]interface{}{"amount": 5}}
+
+	find := func(query stuber.Query) uuid.UUID {
+		result, err := s.FindByQuery(query)
+		require.NoError(t, err)
+
+		return result.Found().ID
+	}
+
+	require.Equal(t, success.ID, find(query))
+
+	require.NoError(t, s.PinNext("Payments", "Charge", failure.ID, 2))
+
+	// Internal queries don't use the pin up.
+	explanation, err := s.ExplainQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, failure.ID, *explanation.Found)
+
+	// The pinned stub only answers the calls it matches.
+	require.Equal(t, other.ID, find(stuber.Query{Service: "Payments", Method: "Charge", Data: map[string]interface{}{"amount": 7}}))
+
+	require.Equal(t, failure.ID, find(query))
+	require.Equal(t, failure.ID, find(query))
+	require.Equal(t, success.ID, find(query))
+
+	// A zero count unpins the stub.
+	require.NoError(t, s.PinNext("Payments", "Charge", failure.ID, 3))
+	require.NoError(t, s.PinNext("Payments", "Charge", failure.ID, 0))
+	require.Equal(t, success.ID, find(query))
+
+	require.ErrorIs(t, s.PinNext("Payments", "Refund", failure.ID, 1), stuber.ErrStubNotFound)
+	require.ErrorIs(t, s.PinNext("Payments", "Charge", uuid.New(), 1), stuber.ErrStubNotFound)
+}
+
+func TestBudgerigar_PinNext_Concurrent(t *testing.T) {
+	const searches = 8
+
+	failure := &stuber.Stub{
+		ID:            uuid.New(),
+		Service:       "Payments",
+		Method:        "Charge",
+		Scenario:      "payment",
+		RequiredState: stuber.ScenarioStarted,
+		NewState:      "declined",
+		Output:        stuber.Output{Error: "declined"},
+	}
+	success := &stuber.Stub{ID: uuid.New(), Service: "Payments", Method: "Charge", Priority: 10}
+
+	// The searches wait for each other once the pinned failure is found
+	// ready, so they all claim it at once and only the first one uses it.
+	var (
+		ranked  sync.WaitGroup
+		waiting atomic.Int32
+	)
+
+	ranked.Add(searches)
+
+	s := stuber.New(stuber.WithRanker(func(query stuber.Query, stub *stuber.Stub) float64 {
+		if stub.ID == success.ID && waiting.Add(1) <= searches {
+			ranked.Done()
+			ranked.Wait()
+		}
+
+		return stuber.DefaultRank(query, stub) + 1
+	}), stuber.WithSlowLog(0, 2*searches))
+	s.PutMany(failure, success)
+	require.NoError(t, s.PinNext("Payments", "Charge", failure.ID, searches))
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		found = make(map[uuid.UUID]int)
+	)
+
+	for range searches {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result, err := s.FindByQuery(stuber.Query{Service: "Payments", Method: "Charge"})
+			if err != nil || result.Found() == nil {
+				return
+			}
+
+			mu.Lock()
+			found[result.Found().ID]++
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, map[uuid.UUID]int{failure.ID: 1, success.ID: searches - 1}, found)
+
+	// The searches made again are logged once.
+	require.Len(t, s.SlowMatches(), searches)
+
+	// The searches answered by another stub did not use the pin up.
+	s.ResetScenarios()
+
+	result, err := s.FindByQuery(stuber.Query{Service: "Payments", Method: "Charge"})
+	require.NoError(t, err)
+	require.Equal(t, failure.ID, result.Found().ID)
+}
@@ -0,0 +1,71 @@
+package stuber
+
+import (
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers are not returned to
+// their pool, so a single huge bucket doesn't pin memory forever.
+const maxPooledBuffer = 1 << 16
+
+// stubBuffers pools the buffers holding the candidates of a search.
+var stubBuffers = sync.Pool{ //nolint:gochecknoglobals
+	New: func() any {
+		buf := make([]*Stub, 0, 64) //nolint:mnd
+
+		return &buf
+	},
+}
+
+// candidateBuffers pools the buffers holding the evaluations of a parallel search.
+var candidateBuffers = sync.Pool{ //nolint:gochecknoglobals
+	New: func() any {
+		buf := make([]candidate, 0, defaultParallelThreshold)
+
+		return &buf
+	},
+}
+
+// getStubs returns an empty buffer of Stub values from the pool.
+func getStubs() *[]*Stub {
+	buf, _ := stubBuffers.Get().(*[]*Stub)
+
+	return buf
+}
+
+// putStubs clears the given buffer and returns it to the pool.
+func putStubs(buf *[]*Stub) {
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+
+	clear(*buf)
+	*buf = (*buf)[:0]
+
+	stubBuffers.Put(buf)
+}
+
+// getCandidates returns a buffer of n zero candidates from the pool.
+func getCandidates(n int) *[]candidate {
+	buf, _ := candidateBuffers.Get().(*[]candidate)
+
+	if cap(*buf) < n {
+		*buf = make([]candidate, n)
+	}
+
+	*buf = (*buf)[:n]
+
+	return buf
+}
+
+// putCandidates clears the given buffer and returns it to the pool.
+func putCandidates(buf *[]candidate) {
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+
+	clear(*buf)
+	*buf = (*buf)[:0]
+
+	candidateBuffers.Put(buf)
+}
@@ -0,0 +1,125 @@
+package stuber
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownPriorityBand is returned when a priority band name is unknown.
+var ErrUnknownPriorityBand = errors.New("unknown priority band")
+
+// PriorityBandWidth is the number of priorities of a priority band.
+const PriorityBandWidth = 1_000_000
+
+// PriorityBand is a named range of priorities, so teams agree on how stubs
+// override each other: any stub of a band outranks all the stubs of the
+// bands below it, whatever their offsets in their bands.
+//
+// The bands are ranges of the integer priorities: a band covers the
+// PriorityBandWidth priorities centered on its value times the width, so
+// the integer priorities used so far, close to zero, are in PriorityNormal.
+// The bottom and top bands extend to the lowest and highest priorities.
+//
+// A band is encoded in JSON by its name, such as "override".
+type PriorityBand int
+
+// Priority bands, from the lowest to the highest.
+const (
+	PriorityFallback PriorityBand = iota - 1 // Catch-all stubs answering when nothing else matches.
+	PriorityNormal                           // The stubs of the tests, the default.
+	PriorityOverride                         // Stubs overriding the normal ones, such as for a test case.
+	PriorityPinned                           // Stubs overriding everything, such as for an incident.
+)
+
+// priorityBandNames are the names of the bands, in JSON.
+var priorityBandNames = map[PriorityBand]string{ //nolint:gochecknoglobals
+	PriorityFallback: "fallback",
+	PriorityNormal:   "normal",
+	PriorityOverride: "override",
+	PriorityPinned:   "pinned",
+}
+
+// BandOf returns the band of the given priority.
+//
+// Parameters:
+// - priority: The priority of a stub.
+//
+// Returns:
+// - PriorityBand: The band containing the priority.
+func BandOf(priority int) PriorityBand {
+	const half = PriorityBandWidth / 2
+
+	switch {
+	case priority < int(PriorityNormal)*PriorityBandWidth-half:
+		return PriorityFallback
+	case priority >= int(PriorityPinned)*PriorityBandWidth-half:
+		return PriorityPinned
+	case priority >= int(PriorityOverride)*PriorityBandWidth-half:
+		return PriorityOverride
+	default:
+		return PriorityNormal
+	}
+}
+
+// Priority returns the priority at the given offset in the band, the offset
+// being clamped to the band so it never reaches another one.
+//
+// Parameters:
+// - offset: The offset in the band, ordering its stubs.
+//
+// Returns:
+// - int: The priority of the offset in the band.
+func (b PriorityBand) Priority(offset int) int {
+	const half = PriorityBandWidth / 2
+
+	return int(b)*PriorityBandWidth + min(max(offset, -half), half-1)
+}
+
+// String returns the name of the band.
+func (b PriorityBand) String() string {
+	if name, ok := priorityBandNames[b]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("PriorityBand(%d)", int(b))
+}
+
+// MarshalText encodes the band as its name.
+func (b PriorityBand) MarshalText() ([]byte, error) {
+	if _, ok := priorityBandNames[b]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownPriorityBand, int(b))
+	}
+
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText decodes the band from its name.
+func (b *PriorityBand) UnmarshalText(text []byte) error {
+	for band, name := range priorityBandNames {
+		if name == string(text) {
+			*b = band
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownPriorityBand, text)
+}
+
+// outranks checks if the given candidate is better than the found Stub value.
+//
+// The priority wins over the rank: a matching stub of a higher priority, and
+// so of a higher band, is found whatever the ranks, the rank only ordering
+// the stubs of the same priority. Without a found Stub value, the candidate
+// must have a positive rank.
+func outranks(current candidate, found *Stub, foundRank float64) bool {
+	if found == nil {
+		return current.rank > 0
+	}
+
+	if current.stub.Priority != found.Priority {
+		return current.stub.Priority > found.Priority
+	}
+
+	return current.rank > foundRank
+}
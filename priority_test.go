@@ -0,0 +1,99 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestPriorityBand(t *testing.T) {
+	bands := []stuber.PriorityBand{
+		stuber.PriorityFallback, stuber.PriorityNormal, stuber.PriorityOverride, stuber.PriorityPinned,
+	}
+
+	for i, band := range bands {
+		for _, offset := range []int{-1 << 40, -1, 0, 1, 1 << 40} {
+			priority := band.Priority(offset)
+			require.Equal(t, band, stuber.BandOf(priority))
+
+			// Any offset in a band is above any offset in the bands below it.
+			for _, lower := range bands[:i] {
+				require.Greater(t, priority, lower.Priority(1<<40))
+			}
+		}
+	}
+
+	// The integer priorities used so far are in the normal band.
+	require.Equal(t, stuber.PriorityNormal, stuber.BandOf(100))
+	require.Equal(t, 100, stuber.PriorityNormal.Priority(100))
+}
+
+func TestPriorityBand_JSON(t *testing.T) {
+	var stub stuber.Stub
+	require.NoError(t, json.Unmarshal([]byte(`{"service":"Greeter","method":"SayHello","band":"override","priority":3}`), &stub))
+	require.Nil(t, stub.Band)
+	require.Equal(t, stuber.PriorityOverride.Priority(3), stub.Priority)
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"band":"urgent"}`), &stub), stuber.ErrUnknownPriorityBand)
+
+	data, err := json.Marshal(stuber.PriorityPinned)
+	require.NoError(t, err)
+	require.JSONEq(t, `"pinned"`, string(data))
+}
+
+func TestBudgerigar_PriorityBands(t *testing.T) {
+	s := stuber.New()
+
+	band := func(band stuber.PriorityBand, priority int) *stuber.Stub {
+		return &stuber.Stub{
+			ID:       uuid.New(),
+			Service:  "Greeter",
+			Method:   "SayHello",
+			Band:     &band,
+			Priority: priority,
+		}
+	}
+
+	fallback := band(stuber.PriorityFallback, 1<<30)
+	normal := band(stuber.PriorityNormal, 1<<30)
+	override := band(stuber.PriorityOverride, -1<<30)
+	s.PutMany(fallback, normal, override)
+
+	require.Nil(t, override.Band)
+
+	result, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.Equal(t, override.ID, result.Found().ID)
+}
+
+func TestBudgerigar_Priority(t *testing.T) {
+	s := stuber.New()
+
+	low := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	high := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Greeter",
+		Method:   "SayHello",
+		Priority: 10,
+		Input:    stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+	}
+
+	s.PutMany(low, high)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, high.ID, r.Found().ID)
+}
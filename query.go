@@ -1,8 +1,18 @@
 package stuber
 
 import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
+	"unicode"
 
 	"github.com/bavix/features"
 	"github.com/google/uuid"
@@ -10,8 +20,20 @@ import (
 
 const (
 	RequestInternalFlag features.Flag = iota
+
+	// RequestExactFlag is a query flag requesting exact-match-only semantics:
+	// the stubs that don't match are not ranked and no similar stub is
+	// returned, which saves work for existence checks of administrative tools.
+	RequestExactFlag
 )
 
+// ErrInvalidQuery is returned when a query is not valid.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrUnsupportedEncoding is returned when the body of a query is compressed
+// with an unsupported Content-Encoding.
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
 type Query struct {
 	ID      *uuid.UUID             `json:"id,omitempty"`
 	Service string                 `json:"service"`
@@ -19,7 +41,23 @@ type Query struct {
 	Headers map[string]interface{} `json:"headers"`
 	Data    map[string]interface{} `json:"data"`
 
+	// Size is the size of the payload in bytes, such as the size of the
+	// protobuf message. The size of the JSON encoding of Data is used if zero.
+	Size int `json:"size,omitempty"`
+	// MessageCount is the number of messages received on the stream so far,
+	// including the current one.
+	MessageCount int `json:"messageCount,omitempty"`
+
 	toggles features.Toggles
+
+	// generation is the number of clears of the searcher when the bidi
+	// stream of the query started, if any.
+	generation *uint64
+
+	// candidates receives the number of stubs of the service and method of
+	// the query evaluated by its search, if not nil, for the spans of the
+	// search.
+	candidates *int
 }
 
 func toggles(r *http.Request) features.Toggles {
@@ -29,24 +67,201 @@ func toggles(r *http.Request) features.Toggles {
 		flags = append(flags, RequestInternalFlag)
 	}
 
+	if len(r.Header.Values("X-Gripmock-Requestexact")) > 0 {
+		flags = append(flags, RequestExactFlag)
+	}
+
 	return features.New(flags...)
 }
 
 func NewQuery(r *http.Request) (Query, error) {
+	return NewQueryFlags(r, features.New())
+}
+
+// NewQueryFlags decodes the query of the request, honoring the StrictQuery
+// feature flag of the given toggles.
+func NewQueryFlags(r *http.Request, flags features.Toggles) (Query, error) {
 	q := Query{
 		toggles: toggles(r),
 	}
 
-	decoder := json.NewDecoder(r.Body)
+	body, err := requestBody(r)
+	if err != nil {
+		return q, err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
 	decoder.UseNumber()
 
+	if flags.Has(StrictQuery) {
+		decoder.DisallowUnknownFields()
+	}
+
 	if err := decoder.Decode(&q); err != nil {
 		return q, err
 	}
 
-	return q, nil
+	return q, q.Validate()
+}
+
+// NewQueries decodes the queries of the request, whose body is either a
+// single query or an array of queries, in order.
+func NewQueries(r *http.Request) ([]Query, error) {
+	return NewQueriesFlags(r, features.New())
+}
+
+// NewQueriesFlags decodes the queries of the request, whose body is either a
+// single query or an array of queries, honoring the StrictQuery feature flag
+// of the given toggles.
+func NewQueriesFlags(r *http.Request, flags features.Toggles) ([]Query, error) {
+	queries, _, err := decodeQueries(r, flags)
+
+	return queries, err
+}
+
+// decodeQueries is NewQueriesFlags, also reporting whether the body is an
+// array of queries rather than a single query.
+func decodeQueries(r *http.Request, flags features.Toggles) ([]Query, bool, error) {
+	body, err := requestBody(r)
+	if err != nil {
+		return nil, false, err
+	}
+	defer body.Close()
+
+	reader := bufio.NewReader(body)
+
+	// Peek the first significant byte to tell an array from a single query.
+	var first byte
+
+	for {
+		if first, err = reader.ReadByte(); err != nil {
+			return nil, false, err
+		}
+
+		if !unicode.IsSpace(rune(first)) {
+			break
+		}
+	}
+
+	if err := reader.UnreadByte(); err != nil {
+		return nil, false, err
+	}
+
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	if flags.Has(StrictQuery) {
+		decoder.DisallowUnknownFields()
+	}
+
+	var (
+		queries []Query
+		batch   = first == '['
+	)
+
+	if batch {
+		err = decoder.Decode(&queries)
+	} else {
+		queries = make([]Query, 1)
+		err = decoder.Decode(&queries[0])
+	}
+
+	if err != nil {
+		return nil, batch, err
+	}
+
+	errs := make([]error, 0, len(queries))
+
+	for i := range queries {
+		queries[i].toggles = toggles(r)
+
+		if err := queries[i].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("query %d: %w", i, err))
+		}
+	}
+
+	return queries, batch, errors.Join(errs...)
+}
+
+// requestBody returns the body of the request, decompressed according to its
+// Content-Encoding header.
+func requestBody(r *http.Request) (io.ReadCloser, error) {
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return io.NopCloser(r.Body), nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r.Body)
+	case "deflate":
+		return zlib.NewReader(r.Body)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+	}
+}
+
+// Validate checks that the query names a service and a method, that it has
+// input data unless it belongs to a bidi stream, whose data comes with each
+// message, and that its headers are a flat map, returning all the problems
+// found at once.
+//
+// The errors match ErrInvalidQuery.
+func (q Query) Validate() error {
+	var errs []error
+
+	if q.Service == "" {
+		errs = append(errs, fmt.Errorf("%w: service is required", ErrInvalidQuery))
+	}
+
+	if q.Method == "" {
+		errs = append(errs, fmt.Errorf("%w: method is required", ErrInvalidQuery))
+	}
+
+	if q.Data == nil && q.generation == nil {
+		errs = append(errs, fmt.Errorf("%w: data is required", ErrInvalidQuery))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(q.Headers)) {
+		switch q.Headers[name].(type) {
+		case map[string]interface{}, []interface{}:
+			errs = append(errs, fmt.Errorf("%w: header %q is not a scalar value", ErrInvalidQuery, name))
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 func (q Query) RequestInternal() bool {
 	return q.toggles.Has(RequestInternalFlag)
 }
+
+// ExactOnly reports whether the query requests exact-match-only semantics,
+// with the RequestExactFlag set by the X-Gripmock-Requestexact header or by
+// WithExactOnly.
+func (q Query) ExactOnly() bool {
+	return q.toggles.Has(RequestExactFlag)
+}
+
+// WithExactOnly returns a copy of the query requesting exact-match-only
+// semantics or not, as the X-Gripmock-Requestexact header does, for the
+// queries built without a request.
+//
+// Parameters:
+// - exact: Whether the query requests exact-match-only semantics.
+//
+// Returns:
+// - Query: A copy of the query with the RequestExactFlag set accordingly.
+func (q Query) WithExactOnly(exact bool) Query {
+	var flags []features.Flag
+
+	if q.RequestInternal() {
+		flags = append(flags, RequestInternalFlag)
+	}
+
+	if exact {
+		flags = append(flags, RequestExactFlag)
+	}
+
+	q.toggles = features.New(flags...)
+
+	return q
+}
@@ -2,11 +2,14 @@ package stuber_test
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gripmock/stuber"
@@ -40,3 +43,154 @@ func TestQuery_NewExternal(t *testing.T) {
 	require.Equal(t, "Mundo", q.Data["Hola"])
 	require.False(t, q.RequestInternal())
 }
+
+func TestQuery_NewStrict(t *testing.T) {
+	payload := `{"service":"Testing","method":"TestMethod","dta":{"Hola":"Mundo"}}`
+
+	// Without the data, the lenient query is decoded but not valid.
+	q, err := stuber.New().NewQuery(httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(payload)))
+	require.ErrorIs(t, err, stuber.ErrInvalidQuery)
+	require.Nil(t, q.Data)
+
+	s := stuber.New(stuber.WithFlags(stuber.StrictQuery))
+
+	_, err = s.NewQuery(httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(payload)))
+	require.ErrorContains(t, err, `unknown field "dta"`)
+}
+
+func TestQuery_Validate(t *testing.T) {
+	require.NoError(t, stuber.Query{Service: "Testing", Method: "TestMethod", Data: map[string]interface{}{}}.Validate())
+
+	err := stuber.Query{
+		Headers: map[string]interface{}{
+			"x-id":   "1",
+			"x-list": []interface{}{"a"},
+			"x-map":  map[string]interface{}{"a": "b"},
+		},
+	}.Validate()
+	require.ErrorIs(t, err, stuber.ErrInvalidQuery)
+	require.EqualError(t, err, `invalid query: service is required
+invalid query: method is required
+invalid query: data is required
+invalid query: header "x-list" is not a scalar value
+invalid query: header "x-map" is not a scalar value`)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(`{"service":"Testing"}`))
+
+	_, err = stuber.NewQuery(req)
+	require.ErrorIs(t, err, stuber.ErrInvalidQuery)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(`{"service":"Testing","method":"TestMethod"}`))
+
+	_, err = stuber.NewQuery(req)
+	require.EqualError(t, err, "invalid query: data is required")
+}
+
+func TestQuery_NewCompressed(t *testing.T) {
+	payload := `{"service":"Testing","method":"TestMethod","data":{"Hola":"Mundo"}}`
+
+	var gzipped bytes.Buffer
+
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var deflated bytes.Buffer
+
+	zw := zlib.NewWriter(&deflated)
+	_, err = zw.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	for encoding, body := range map[string][]byte{"gzip": gzipped.Bytes(), "deflate": deflated.Bytes()} {
+		req := httptest.NewRequest(http.MethodPost, "/api/stubs/search", bytes.NewReader(body))
+		req.Header.Set("Content-Encoding", encoding)
+
+		q, err := stuber.NewQuery(req)
+		require.NoError(t, err)
+		require.Equal(t, "Mundo", q.Data["Hola"])
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(payload))
+	req.Header.Set("Content-Encoding", "br")
+
+	_, err = stuber.NewQuery(req)
+	require.ErrorIs(t, err, stuber.ErrUnsupportedEncoding)
+}
+
+func TestQuery_NewExact(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob", "age": 42}},
+	})
+
+	query := func(data string, exact bool) stuber.Query {
+		payload := `{"service":"Greeter","method":"SayHello","data":` + data + `}`
+
+		req := httptest.NewRequest(http.MethodPost, "/api/stubs/search", strings.NewReader(payload))
+		req.Header.Add("X-GripMock-RequestInternal", "ok")
+
+		if exact {
+			req.Header.Add("X-GripMock-RequestExact", "ok")
+		}
+
+		q, err := stuber.NewQuery(req)
+		require.NoError(t, err)
+		require.Equal(t, exact, q.ExactOnly())
+
+		return q
+	}
+
+	r, err := s.FindByQuery(query(`{"name":"Bob","age":41}`, false))
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+	require.NotNil(t, r.Similar())
+
+	_, err = s.FindByQuery(query(`{"name":"Bob","age":41}`, true))
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+
+	r, err = s.FindByQuery(query(`{"name":"Bob","age":42}`, true))
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.Nil(t, r.Similar())
+}
+
+func TestQuery_WithExactOnly(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob", "age": 42}},
+	})
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob", "age": 41},
+	}
+
+	exact := query.WithExactOnly(true)
+	require.True(t, exact.ExactOnly())
+	require.False(t, query.ExactOnly())
+	require.False(t, exact.WithExactOnly(false).ExactOnly())
+
+	r, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+	require.NotNil(t, r.Similar())
+
+	_, err = s.FindByQuery(exact)
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+
+	exact.Data = map[string]interface{}{"name": "Bob", "age": 42}
+
+	r, err = s.FindByQuery(exact)
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.Nil(t, r.Similar())
+}
@@ -0,0 +1,65 @@
+package stuber
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ScoredStub is a Stub value along with its rank for a query.
+type ScoredStub struct {
+	Stub    *Stub   `json:"stub"`    // The Stub value merged with its base stubs.
+	Score   float64 `json:"score"`   // The rank of the Stub value for the query.
+	Matched bool    `json:"matched"` // Whether the matchers of the Stub value match the query.
+}
+
+// ranked returns the Stub values of the service and the method of the query
+// with their rank, by decreasing rank and then in their canonical order.
+func (s *searcher) ranked(query Query, limit int) ([]ScoredStub, error) {
+	stubs, err := s.findBy(query.Service, query.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	SortStubs(stubs)
+
+	query = normalizeQuery(query)
+
+	scored := make([]ScoredStub, 0, len(stubs))
+
+	for _, stub := range stubs {
+		if current := s.evaluate(query, stub); current.valid {
+			scored = append(scored, ScoredStub{Stub: current.stub, Score: current.rank, Matched: current.matched})
+		}
+	}
+
+	slices.SortStableFunc(scored, func(a, b ScoredStub) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	return scored, nil
+}
+
+// FindAllByQueryV2 returns the Stub values of the service and the method of
+// the query with their rank for it, the closest first, such as to display
+// the top closest matches of a query in tooling.
+//
+// Unlike FindByQuery, the priority and the usage of the Stub values are
+// ignored, as are their ordered groups, dependencies and scenarios, and the
+// Stub values are not marked as used. Abstract Stub values are left out.
+//
+// Parameters:
+// - query: The Query to rank the Stub values for.
+// - limit: The maximum number of Stub values returned, all if not positive.
+//
+// Returns:
+// - []ScoredStub: The Stub values merged with their base stubs and their
+// rank, by decreasing rank and then in their canonical order.
+// - error: ErrServiceNotFound or ErrMethodNotFound if there are no Stub
+// values for the service or the method of the query.
+func (b *Budgerigar) FindAllByQueryV2(query Query, limit int) ([]ScoredStub, error) {
+	return b.searcher.ranked(b.canonicalQuery(query), limit)
+}
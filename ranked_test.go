@@ -0,0 +1,56 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_FindAllByQueryV2(t *testing.T) {
+	s := stuber.New()
+
+	exact := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob", "lang": "en"}},
+	}
+	partial := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob", "lang": "fr"}},
+	}
+	unrelated := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"id": 42}},
+	}
+	abstract := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", Abstract: true}
+	s.PutMany(unrelated, partial, exact, abstract)
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob", "lang": "en"}}
+
+	scored, err := s.FindAllByQueryV2(query, 0)
+	require.NoError(t, err)
+	require.Len(t, scored, 3)
+	require.Equal(t, exact.ID, scored[0].Stub.ID)
+	require.True(t, scored[0].Matched)
+	require.Equal(t, partial.ID, scored[1].Stub.ID)
+	require.False(t, scored[1].Matched)
+	require.Greater(t, scored[0].Score, scored[1].Score)
+	require.Greater(t, scored[1].Score, scored[2].Score)
+
+	scored, err = s.FindAllByQueryV2(query, 2)
+	require.NoError(t, err)
+	require.Len(t, scored, 2)
+
+	require.Empty(t, s.Used())
+
+	_, err = s.FindAllByQueryV2(stuber.Query{Service: "Greeter", Method: "SayGoodbye"}, 1)
+	require.ErrorIs(t, err, stuber.ErrMethodNotFound)
+}
@@ -0,0 +1,222 @@
+package stuber
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// RetryAfterHeader is the response header carrying the number of seconds
+// after which a rate limited call may be retried.
+const RetryAfterHeader = "retry-after"
+
+// defaultRateLimitMessage is the error message used when a RateLimit does not
+// define an output.
+const defaultRateLimitMessage = "rate limit exceeded"
+
+// rateWindowPrune is the interval between two prunings of the expired rate
+// limit windows.
+const rateWindowPrune = time.Minute
+
+// ErrInvalidRateLimit is returned when a decoded RateLimit has no positive
+// window.
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// RateLimit describes the number of matches allowed per time window.
+//
+// Once the limit is reached, the Output is returned instead of the matched
+// output until the window is over.
+//
+// A RateLimit without a positive Window is rejected when decoded, and not
+// applied otherwise.
+type RateLimit struct {
+	Limit  int      `json:"limit"`            // The number of matches allowed per window.
+	Window Duration `json:"window"`           // The duration of the window.
+	Output *Output  `json:"output,omitempty"` // The output returned once the limit is reached.
+}
+
+// UnmarshalJSON decodes the rate limit, rejecting a window that is not
+// positive.
+func (r *RateLimit) UnmarshalJSON(data []byte) error {
+	type rateLimit RateLimit
+
+	if err := json.Unmarshal(data, (*rateLimit)(r)); err != nil {
+		return err
+	}
+
+	if r.Window <= 0 {
+		return fmt.Errorf("%w: window %s is not positive", ErrInvalidRateLimit, r.Window.Std())
+	}
+
+	return nil
+}
+
+// rateWindow is the state of a fixed rate limit window.
+type rateWindow struct {
+	start time.Time
+	end   time.Time // When the window expires, zero until its next hit if restored.
+	count int
+}
+
+// rateLimiter tracks the rate limit windows of stubs and services.
+//
+// The expired windows are pruned periodically, so the windows of the deleted
+// stubs and the idle ones are not kept forever.
+type rateLimiter struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	pruned   time.Time // When the expired windows were last pruned.
+	services map[string]*RateLimit
+	windows  map[string]*rateWindow
+}
+
+// newRateLimiter creates a new rateLimiter.
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		now:      time.Now,
+		services: make(map[string]*RateLimit),
+		windows:  make(map[string]*rateWindow),
+	}
+}
+
+// setService sets the rate limit of the given service.
+//
+// Passing nil removes the limit.
+func (l *rateLimiter) setService(service string, limit *RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit == nil {
+		delete(l.services, service)
+	} else {
+		l.services[service] = limit
+	}
+
+	delete(l.windows, "service:"+service)
+}
+
+// reset forgets all the rate limit windows.
+func (l *rateLimiter) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.windows = make(map[string]*rateWindow)
+}
+
+// apply counts a match of the given stub against the limits of the stub and
+// of its service.
+//
+// It returns the stub unchanged if the match is allowed, otherwise a copy of
+// the stub with the exhausted output. The match is counted by both limits
+// only if both allow it, so a call rejected by one limit doesn't use up the
+// other.
+func (l *rateLimiter) apply(stub *Stub) *Stub {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune()
+
+	var (
+		exceeded   *RateLimit
+		retryAfter time.Duration
+		allowed    []*rateWindow
+	)
+
+	if limit := l.services[stub.Service]; limit != nil {
+		if window, wait, ok := l.allow("service:"+stub.Service, limit); !ok {
+			exceeded, retryAfter = limit, wait
+		} else if window != nil {
+			allowed = append(allowed, window)
+		}
+	}
+
+	if stub.RateLimit != nil {
+		if window, wait, ok := l.allow("stub:"+stub.ID.String(), stub.RateLimit); !ok {
+			if wait > retryAfter {
+				exceeded, retryAfter = stub.RateLimit, wait
+			}
+		} else if window != nil {
+			allowed = append(allowed, window)
+		}
+	}
+
+	if exceeded == nil {
+		for _, window := range allowed {
+			window.count++
+		}
+
+		return stub
+	}
+
+	return stub.withOutput(exceeded.output(retryAfter))
+}
+
+// allow checks a hit against the window with the given key, starting a new
+// window if the current one is over, without counting the hit.
+//
+// It returns the window, the time left in it and whether the hit is allowed.
+// Limits without a positive window allow every hit, without a window.
+func (l *rateLimiter) allow(key string, limit *RateLimit) (*rateWindow, time.Duration, bool) {
+	if limit.Window <= 0 {
+		return nil, 0, true
+	}
+
+	now := l.now()
+
+	window, ok := l.windows[key]
+	if !ok || now.Sub(window.start) >= limit.Window.Std() {
+		window = &rateWindow{start: now}
+		l.windows[key] = window
+	}
+
+	window.end = window.start.Add(limit.Window.Std())
+
+	return window, window.end.Sub(now), window.count < limit.Limit
+}
+
+// prune drops the expired windows, at most once per rateWindowPrune.
+//
+// The mutex must be held.
+func (l *rateLimiter) prune() {
+	now := l.now()
+	if now.Sub(l.pruned) < rateWindowPrune {
+		return
+	}
+
+	l.pruned = now
+
+	for key, window := range l.windows {
+		if !window.end.IsZero() && !now.Before(window.end) {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// output returns the output used once the limit is reached, with the
+// Retry-After header set.
+func (r *RateLimit) output(retryAfter time.Duration) Output {
+	var output Output
+
+	if r.Output != nil {
+		output = *r.Output
+	} else {
+		code := codes.ResourceExhausted
+		output = Output{Error: defaultRateLimitMessage, Code: &code}
+	}
+
+	headers := make(map[string]string, len(output.Headers)+1)
+	for k, v := range output.Headers {
+		headers[k] = v
+	}
+
+	headers[RetryAfterHeader] = strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	output.Headers = headers
+
+	return output
+}
@@ -0,0 +1,180 @@
+package stuber //nolint:testpackage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRateLimit_Stub(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewBudgerigar(features.New())
+	s.limiter.now = func() time.Time { return now }
+
+	s.PutMany(&Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		Output:    Output{Data: map[string]interface{}{"message": "hello"}},
+		RateLimit: &RateLimit{Limit: 2, Window: Duration(10 * time.Second)},
+	})
+
+	query := Query{Service: "Greeter", Method: "SayHello"}
+
+	for range 2 {
+		r, err := s.FindByQuery(query)
+		require.NoError(t, err)
+		require.Empty(t, r.Found().Output.Error)
+	}
+
+	now = now.Add(3 * time.Second)
+
+	r, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, codes.ResourceExhausted, *r.Found().Output.Code)
+	require.Equal(t, "7", r.Found().Output.Headers[RetryAfterHeader])
+
+	now = now.Add(7 * time.Second)
+
+	r, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+}
+
+func TestRateLimit_Service(t *testing.T) {
+	s := NewBudgerigar(features.New())
+
+	s.PutMany(
+		&Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"},
+		&Stub{ID: uuid.New(), Service: "Greeter", Method: "SayBye"},
+	)
+
+	code := codes.Unavailable
+	s.SetRateLimit("Greeter", &RateLimit{
+		Limit:  1,
+		Window: Duration(time.Minute),
+		Output: &Output{Error: "busy", Code: &code},
+	})
+
+	r, err := s.FindByQuery(Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+
+	r, err = s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+	require.NoError(t, err)
+	require.Equal(t, "busy", r.Found().Output.Error)
+	require.Equal(t, "60", r.Found().Output.Headers[RetryAfterHeader])
+
+	s.SetRateLimit("Greeter", nil)
+
+	r, err = s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+}
+
+func TestRateLimit_StubAndService(t *testing.T) {
+	s := NewBudgerigar(features.New())
+
+	limited := &Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		RateLimit: &RateLimit{Limit: 1, Window: Duration(time.Minute)},
+	}
+	s.PutMany(limited, &Stub{ID: uuid.New(), Service: "Greeter", Method: "SayBye"})
+	s.SetRateLimit("Greeter", &RateLimit{Limit: 2, Window: Duration(time.Minute)})
+
+	r, err := s.FindByQuery(Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+
+	// The calls rejected by the limit of the stub don't count for the service.
+	for range 3 {
+		r, err = s.FindByQuery(Query{Service: "Greeter", Method: "SayHello"})
+		require.NoError(t, err)
+		require.Equal(t, defaultRateLimitMessage, r.Found().Output.Error)
+	}
+
+	r, err = s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+
+	r, err = s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+	require.NoError(t, err)
+	require.Equal(t, defaultRateLimitMessage, r.Found().Output.Error)
+}
+
+func TestRateLimit_Prune(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := New()
+	s.limiter.now = func() time.Time { return now }
+
+	hello := &Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		RateLimit: &RateLimit{Limit: 1, Window: Duration(time.Minute)},
+	}
+	bye := &Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayBye",
+		RateLimit: &RateLimit{Limit: 1, Window: Duration(time.Hour)},
+	}
+
+	s.PutMany(hello, bye)
+
+	for _, method := range []string{"SayHello", "SayBye"} {
+		_, err := s.FindByQuery(Query{Service: "Greeter", Method: method})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, s.limiter.windows, 2)
+
+	// The expired window of the deleted stub is dropped, the others are kept.
+	s.DeleteByID(hello.ID)
+
+	now = now.Add(2 * time.Minute)
+
+	r, err := s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+	require.NoError(t, err)
+	require.NotEmpty(t, r.Found().Output.Error)
+	require.Len(t, s.limiter.windows, 1)
+	require.Contains(t, s.limiter.windows, "stub:"+bye.ID.String())
+}
+
+func TestRateLimit_InvalidWindow(t *testing.T) {
+	var stub Stub
+
+	err := json.Unmarshal([]byte(`{
+		"service": "Greeter",
+		"method": "SayHello",
+		"rateLimit": {"limit": 1, "window": "0s"}
+	}`), &stub)
+	require.ErrorIs(t, err, ErrInvalidRateLimit)
+
+	s := New()
+
+	_, err = s.Import([]byte(`[{"service": "Greeter", "method": "SayHello", "rateLimit": {"limit": 1}}]`))
+	require.ErrorIs(t, err, ErrInvalidRateLimit)
+	require.Empty(t, s.All())
+
+	// The limits built without a positive window are not applied.
+	s.PutMany(&Stub{
+		ID:        uuid.New(),
+		Service:   "Greeter",
+		Method:    "SayHello",
+		RateLimit: &RateLimit{Limit: 0},
+	})
+
+	r, err := s.FindByQuery(Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.Empty(t, r.Found().Output.Error)
+}
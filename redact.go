@@ -0,0 +1,114 @@
+package stuber
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultRedactionMask is the value replacing the redacted fields of a
+// RedactionRule without a mask.
+const DefaultRedactionMask = "***"
+
+// RedactionRule masks a field of the exported documents, such as a
+// PII-like value of a recorded stub, before they are shared.
+//
+// The path is the dot-separated path of the field in the JSON document:
+// a Stub for Export, such as "input.equals.email" or "output.data.user.ssn",
+// and a TrafficEntry for ExportTraffic, such as "data.email". A "*" segment
+// matches any field or item, and a "**" segment any number of them, so
+// "**.email" masks the email fields at any depth.
+type RedactionRule struct {
+	Path string `json:"path"`           // The path of the masked fields.
+	Mask string `json:"mask,omitempty"` // The value replacing the fields, DefaultRedactionMask if empty.
+}
+
+// redact returns the JSON encoding of the value with the fields of the rules
+// masked, the keys of its objects being sorted.
+//
+// Only the free-form fields can be masked, such as the matchers, the headers
+// and the data: masking a typed field, such as a priority, fails.
+func redact[T any](rules []RedactionRule, value T) (json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the numbers as json.Number, so they are encoded back verbatim:
+	// the redacted document is returned as encoded, as decoding it into the
+	// value would turn the large integers of its free-form fields into
+	// imprecise floats.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if rule.Path == "" {
+			continue
+		}
+
+		tree = redactNode(tree, strings.Split(rule.Path, "."), cmp.Or(rule.Mask, DefaultRedactionMask))
+	}
+
+	if data, err = json.Marshal(tree); err != nil {
+		return nil, err
+	}
+
+	// The masks of the typed fields don't decode.
+	if err := json.Unmarshal(data, new(T)); err != nil {
+		return nil, fmt.Errorf("redact: %w", err)
+	}
+
+	return data, nil
+}
+
+// redactNode replaces the descendants of the node at the path with the mask
+// and returns the node.
+func redactNode(node any, path []string, mask string) any {
+	if len(path) == 0 {
+		return mask
+	}
+
+	segment := path[0]
+
+	if segment == "**" {
+		// Match zero segments, then one or more.
+		node = redactNode(node, path[1:], mask)
+
+		return redactChildren(node, func(string) bool { return true }, func(child any) any {
+			return redactNode(child, path, mask)
+		})
+	}
+
+	return redactChildren(node, func(key string) bool { return segment == "*" || segment == key }, func(child any) any {
+		return redactNode(child, path[1:], mask)
+	})
+}
+
+// redactChildren replaces the children of the node whose key or index is
+// accepted by the predicate with their redaction, and returns the node.
+func redactChildren(node any, accept func(string) bool, redactChild func(any) any) any {
+	switch node := node.(type) {
+	case map[string]any:
+		for key, child := range node {
+			if accept(key) {
+				node[key] = redactChild(child)
+			}
+		}
+	case []any:
+		for i, child := range node {
+			if accept(strconv.Itoa(i)) {
+				node[i] = redactChild(child)
+			}
+		}
+	}
+
+	return node
+}
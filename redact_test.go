@@ -0,0 +1,112 @@
+package stuber_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Export_Redaction(t *testing.T) {
+	s := stuber.New(
+		stuber.WithTrafficLog(10),
+		stuber.WithRedaction(
+			stuber.RedactionRule{Path: "**.email"},
+			stuber.RedactionRule{Path: "output.data.cards.*.number", Mask: "XXXX"},
+			stuber.RedactionRule{Path: "data.ssn"},
+		),
+	)
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Users",
+		Method:  "GetUser",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"email": "bob@example.com"}},
+		Output: stuber.Output{Data: map[string]interface{}{
+			"user":  map[string]interface{}{"email": "bob@example.com", "age": 42},
+			"cards": []interface{}{map[string]interface{}{"number": "4111111111111111"}},
+		}},
+	}
+	s.PutMany(stub)
+
+	data, err := s.Export()
+	require.NoError(t, err)
+
+	var exported []*stuber.Stub
+	require.NoError(t, json.Unmarshal(data, &exported))
+	require.Len(t, exported, 1)
+	require.Equal(t, "***", exported[0].Input.Equals["email"])
+	require.Equal(t, map[string]interface{}{"email": "***", "age": float64(42)}, exported[0].Output.Data["user"])
+	require.Equal(t, []interface{}{map[string]interface{}{"number": "XXXX"}}, exported[0].Output.Data["cards"])
+
+	// The stored stub is left untouched.
+	require.Equal(t, "bob@example.com", s.FindByID(stub.ID).Input.Equals["email"])
+
+	_, err = s.FindByQuery(stuber.Query{
+		Service: "Users",
+		Method:  "GetUser",
+		Data:    map[string]interface{}{"email": "bob@example.com", "ssn": "078-05-1120"},
+	})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	require.NoError(t, s.ExportTraffic(&archive))
+	require.NotContains(t, archive.String(), "bob@example.com")
+	require.NotContains(t, archive.String(), "078-05-1120")
+
+	// The saved stubs are loaded back as they are.
+	path := filepath.Join(t.TempDir(), "stubs.json")
+	require.NoError(t, s.SaveToFile(path))
+
+	restored := stuber.New()
+	_, err = restored.LoadFromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "bob@example.com", restored.FindByID(stub.ID).Input.Equals["email"])
+}
+
+func TestBudgerigar_Export_RedactionTypedField(t *testing.T) {
+	s := stuber.New(stuber.WithRedaction(stuber.RedactionRule{Path: "priority"}))
+	s.PutMany(&stuber.Stub{ID: uuid.New(), Service: "Users", Method: "GetUser", Priority: 1})
+
+	_, err := s.Export()
+	require.Error(t, err)
+}
+
+func TestBudgerigar_Export_RedactionLargeInteger(t *testing.T) {
+	s := stuber.New(
+		stuber.WithTrafficLog(10),
+		stuber.WithRedaction(stuber.RedactionRule{Path: "**.email"}),
+	)
+
+	// 2^53 + 1 has no float64 representation.
+	const large = int64(1<<53 + 1)
+
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Users",
+		Method:  "GetUser",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"id": large, "email": "bob@example.com"}},
+	})
+
+	data, err := s.Export()
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"id": 9007199254740993`)
+	require.NotContains(t, string(data), "bob@example.com")
+
+	_, err = s.FindByQuery(stuber.Query{
+		Service: "Users",
+		Method:  "GetUser",
+		Data:    map[string]interface{}{"name": "Bob", "id": large},
+	})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	require.NoError(t, s.ExportTraffic(&archive))
+	require.Contains(t, archive.String(), `"id":9007199254740993`)
+}
@@ -0,0 +1,333 @@
+package stuber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidation is returned when an invalidation message of a RedisBackend
+// cannot be decoded.
+var ErrInvalidation = errors.New("invalid invalidation message")
+
+// Key suffixes of a RedisBackend.
+const (
+	redisStubsSuffix   = ":stubs"  // The hash of the stubs, by ID.
+	redisChannelSuffix = ":events" // The channel of the invalidation messages.
+)
+
+// RedisClient is the subset of the Redis commands used by a RedisBackend.
+//
+// The package doesn't depend on a Redis client: the application adapts the
+// client it uses, such as go-redis, with a method per command. The methods
+// are called concurrently.
+type RedisClient interface {
+	// HGetAll returns the fields and values of the hash at key (HGETALL).
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// HMGet returns the values of the fields of the hash at key, in their
+	// order, with an empty string for the missing ones (HMGET).
+	HMGet(ctx context.Context, key string, fields ...string) ([]string, error)
+	// HSet sets the fields of the hash at key, atomically (HSET).
+	HSet(ctx context.Context, key string, values map[string]string) error
+	// HDel deletes the fields of the hash at key, atomically (HDEL).
+	HDel(ctx context.Context, key string, fields ...string) error
+	// Del deletes the key (DEL).
+	Del(ctx context.Context, key string) error
+	// Publish publishes the message to the channel (PUBLISH).
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe subscribes to the channel (SUBSCRIBE) and returns its
+	// messages until the context is done, when the channel is closed. The
+	// subscription is active when Subscribe returns.
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
+}
+
+// invalidation is a message of a RedisBackend notifying the other instances
+// of a change.
+type invalidation struct {
+	Origin uuid.UUID   `json:"origin"`        // The instance which made the change.
+	Op     string      `json:"op"`            // logPut, logDelete or logClear.
+	IDs    []uuid.UUID `json:"ids,omitempty"` // The IDs of the changed stubs.
+}
+
+// RedisBackend is a Backend keeping the stubs in a Redis hash, shared by the
+// mock instances of a farm.
+//
+// Each change is published on a channel, so that the instances watching it
+// with Watch apply the changes of the others: the messages carry the IDs of
+// the changed stubs only, which the instances read back from the hash.
+type RedisBackend struct {
+	client  RedisClient
+	key     string
+	channel string
+	origin  uuid.UUID
+}
+
+// NewRedisBackend creates a RedisBackend storing the stubs under the given
+// prefix, in the hash "<prefix>:stubs" and publishing on the channel
+// "<prefix>:events".
+//
+// Parameters:
+// - client: The Redis client.
+// - prefix: The prefix of the keys, shared by the instances of a farm.
+//
+// Returns:
+// - *RedisBackend: The new backend.
+func NewRedisBackend(client RedisClient, prefix string) *RedisBackend {
+	return &RedisBackend{
+		client:  client,
+		key:     prefix + redisStubsSuffix,
+		channel: prefix + redisChannelSuffix,
+		origin:  uuid.New(),
+	}
+}
+
+// Load returns the stubs of the hash.
+//
+// Returns:
+// - []*Stub: The stubs, in no particular order.
+// - error: An error if the hash cannot be read or a stub decoded.
+func (r *RedisBackend) Load() ([]*Stub, error) {
+	values, err := r.client.HGetAll(context.Background(), r.key)
+	if err != nil {
+		return nil, err
+	}
+
+	stubs := make([]*Stub, 0, len(values))
+
+	for id, value := range values {
+		stub, err := decodeRedisStub(id, value)
+		if err != nil {
+			return nil, err
+		}
+
+		stubs = append(stubs, stub)
+	}
+
+	return stubs, nil
+}
+
+// Put writes the stubs to the hash and notifies the other instances.
+//
+// Parameters:
+// - stubs: The stubs to persist.
+//
+// Returns:
+// - error: An error if the stubs cannot be written or the change published.
+func (r *RedisBackend) Put(stubs []*Stub) error {
+	if len(stubs) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(stubs))
+
+	for _, stub := range stubs {
+		// Stamp the stubs with the current version, so loading them doesn't upgrade them.
+		clone := *stub
+		clone.SchemaVersion = SchemaVersion
+
+		value, err := json.Marshal(&clone)
+		if err != nil {
+			return err
+		}
+
+		values[stub.ID.String()] = string(value)
+	}
+
+	ctx := context.Background()
+
+	if err := r.client.HSet(ctx, r.key, values); err != nil {
+		return err
+	}
+
+	return r.publish(ctx, logPut, stubIDs(stubs))
+}
+
+// Delete deletes the stubs from the hash and notifies the other instances.
+//
+// Parameters:
+// - ids: The IDs of the stubs to delete.
+//
+// Returns:
+// - error: An error if the stubs cannot be deleted or the change published.
+func (r *RedisBackend) Delete(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = id.String()
+	}
+
+	ctx := context.Background()
+
+	if err := r.client.HDel(ctx, r.key, fields...); err != nil {
+		return err
+	}
+
+	return r.publish(ctx, logDelete, ids)
+}
+
+// Clear deletes the hash and notifies the other instances.
+//
+// Returns:
+// - error: An error if the hash cannot be deleted or the change published.
+func (r *RedisBackend) Clear() error {
+	ctx := context.Background()
+
+	if err := r.client.Del(ctx, r.key); err != nil {
+		return err
+	}
+
+	return r.publish(ctx, logClear, nil)
+}
+
+// publish notifies the other instances of a change.
+func (r *RedisBackend) publish(ctx context.Context, op string, ids []uuid.UUID) error {
+	message, err := json.Marshal(invalidation{Origin: r.origin, Op: op, IDs: ids})
+	if err != nil {
+		return err
+	}
+
+	return r.client.Publish(ctx, r.channel, string(message))
+}
+
+// Watch subscribes to the changes of the other instances and applies them
+// to the Budgerigar, which is expected to be attached to the backend with
+// AttachBackend, until the context is done.
+//
+// The changes are applied without being written back to the backend. A
+// message which cannot be applied is logged with the logger of WithLogger,
+// and the Budgerigar may be behind until the stubs change again.
+//
+// Parameters:
+// - ctx: The context of the subscription.
+// - b: The Budgerigar to apply the changes to.
+//
+// Returns:
+// - error: An error if the channel cannot be subscribed to.
+func (r *RedisBackend) Watch(ctx context.Context, b *Budgerigar) error {
+	messages, err := r.client.Subscribe(ctx, r.channel)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for message := range messages {
+			if err := r.apply(ctx, b, message); err != nil {
+				b.searcher.logger.Error("stuber: invalidation failed", "channel", r.channel, "error", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// apply applies the change of the invalidation message to the Budgerigar,
+// unless the change is its own.
+func (r *RedisBackend) apply(ctx context.Context, b *Budgerigar, message string) error {
+	var change invalidation
+	if err := json.Unmarshal([]byte(message), &change); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidation, err)
+	}
+
+	if change.Origin == r.origin {
+		return nil
+	}
+
+	switch change.Op {
+	case logPut:
+		return r.reload(ctx, b, change.IDs)
+	case logDelete:
+		b.replicate(nil, change.IDs)
+	case logClear:
+		b.replicate(nil, stubIDs(b.searcher.all()))
+	default:
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidation, change.Op)
+	}
+
+	return nil
+}
+
+// reload reads the stubs with the given IDs back from the hash and applies
+// them to the Budgerigar. The ones deleted since are deleted.
+func (r *RedisBackend) reload(ctx context.Context, b *Budgerigar, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = id.String()
+	}
+
+	values, err := r.client.HMGet(ctx, r.key, fields...)
+	if err != nil {
+		return err
+	}
+
+	var (
+		put     []*Stub
+		deleted []uuid.UUID
+	)
+
+	for i, value := range values {
+		if value == "" {
+			deleted = append(deleted, ids[i])
+
+			continue
+		}
+
+		stub, err := decodeRedisStub(fields[i], value)
+		if err != nil {
+			return err
+		}
+
+		put = append(put, stub)
+	}
+
+	b.replicate(put, deleted)
+
+	return nil
+}
+
+// decodeRedisStub decodes the stub stored in the given field of the hash.
+func decodeRedisStub(field, value string) (*Stub, error) {
+	var stub Stub
+	if err := json.Unmarshal([]byte(value), &stub); err != nil {
+		return nil, fmt.Errorf("stub %s: %w", field, err)
+	}
+
+	return &stub, nil
+}
+
+// replicate applies the changes made by another instance sharing the
+// backend, without writing them back to it.
+func (b *Budgerigar) replicate(put []*Stub, deleted []uuid.UUID) {
+	defer b.templateCache.forget(append(stubIDs(put), deleted...)...)
+
+	b.searcher.replicate(put, deleted)
+}
+
+// replicate applies the changes made by another instance sharing the
+// backend, without writing them back to it.
+func (s *searcher) replicate(put []*Stub, deleted []uuid.UUID) {
+	defer s.cache.invalidate()
+
+	var removed []*Stub
+	if s.events.active() {
+		removed = s.castToStub(s.storage.findByIDs(deleted...))
+	}
+
+	s.persistence.Lock()
+	s.revisions.record(put...)
+	s.storage.del(deleted...)
+	s.storage.upsert(s.castToValue(put)...)
+	s.persistence.Unlock()
+
+	s.events.publish(EventDelete, removed...)
+	s.events.publish(EventPut, put...)
+}
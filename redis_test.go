@@ -0,0 +1,185 @@
+package stuber_test
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+// fakeRedis is an in-memory RedisClient.
+type fakeRedis struct {
+	mu     sync.Mutex
+	hashes map[string]map[string]string
+	subs   map[string][]chan string
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{hashes: make(map[string]map[string]string), subs: make(map[string][]chan string)}
+}
+
+func (f *fakeRedis) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return maps.Clone(f.hashes[key]), nil
+}
+
+func (f *fakeRedis) HMGet(_ context.Context, key string, fields ...string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = f.hashes[key][field]
+	}
+
+	return values, nil
+}
+
+func (f *fakeRedis) HSet(_ context.Context, key string, values map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.hashes[key] == nil {
+		f.hashes[key] = make(map[string]string)
+	}
+
+	maps.Copy(f.hashes[key], values)
+
+	return nil
+}
+
+func (f *fakeRedis) HDel(_ context.Context, key string, fields ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, field := range fields {
+		delete(f.hashes[key], field)
+	}
+
+	return nil
+}
+
+func (f *fakeRedis) Del(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.hashes, key)
+
+	return nil
+}
+
+func (f *fakeRedis) Publish(_ context.Context, channel, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, sub := range f.subs[channel] {
+		sub <- message
+	}
+
+	return nil
+}
+
+func (f *fakeRedis) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sub := make(chan string, 64)
+	f.subs[channel] = append(f.subs[channel], sub)
+
+	go func() {
+		<-ctx.Done()
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		for i, other := range f.subs[channel] {
+			if other == sub {
+				f.subs[channel] = append(f.subs[channel][:i], f.subs[channel][i+1:]...)
+
+				break
+			}
+		}
+
+		close(sub)
+	}()
+
+	return sub, nil
+}
+
+func TestRedisBackend_SharedStubs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	client := newFakeRedis()
+
+	first, second := stuber.New(), stuber.New()
+
+	hello := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello"}
+	first.PutMany(hello)
+
+	for _, b := range []*stuber.Budgerigar{first, second} {
+		backend := stuber.NewRedisBackend(client, "farm")
+
+		_, err := b.AttachBackend(backend)
+		require.NoError(t, err)
+		require.NoError(t, backend.Watch(ctx, b))
+	}
+
+	// The stubs stored before attaching are shared.
+	require.NotNil(t, second.FindByID(hello.ID))
+
+	bye := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayGoodbye"}
+	second.PutMany(bye)
+
+	require.Eventually(t, func() bool {
+		return first.FindByID(bye.ID) != nil
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, first.PatchByID(bye.ID, func(stub *stuber.Stub) error {
+		stub.Priority = 7
+
+		return nil
+	}))
+
+	require.Eventually(t, func() bool {
+		return second.FindByID(bye.ID).Priority == 7
+	}, time.Second, time.Millisecond)
+
+	first.DeleteByID(hello.ID)
+
+	require.Eventually(t, func() bool {
+		return second.FindByID(hello.ID) == nil
+	}, time.Second, time.Millisecond)
+
+	second.Clear()
+
+	require.Eventually(t, func() bool {
+		return len(first.All()) == 0
+	}, time.Second, time.Millisecond)
+
+	values, err := client.HGetAll(ctx, "farm:stubs")
+	require.NoError(t, err)
+	require.Empty(t, values)
+}
+
+func TestRedisBackend_Load(t *testing.T) {
+	client := newFakeRedis()
+
+	stub := &stuber.Stub{ID: uuid.New(), Service: "Greeter", Method: "SayHello", Priority: 3}
+	require.NoError(t, stuber.NewRedisBackend(client, "farm").Put([]*stuber.Stub{stub}))
+
+	s := stuber.New()
+
+	ids, err := s.AttachBackend(stuber.NewRedisBackend(client, "farm"))
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{stub.ID}, ids)
+	require.Equal(t, 3, s.FindByID(stub.ID).Priority)
+}
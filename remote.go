@@ -0,0 +1,258 @@
+package stuber
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RemoteSource is a source of stubs consulted when a service or a method is
+// not found locally, such as a central stub registry.
+type RemoteSource interface {
+	// Fetch returns the stubs of the given service and method.
+	Fetch(service, method string) ([]*Stub, error)
+}
+
+// remoteEntry is a set of stubs fetched from the remote source.
+type remoteEntry struct {
+	at  time.Time
+	ids []uuid.UUID
+}
+
+// remoteCall is a fetch in flight, which the concurrent misses of the same
+// service and method wait for instead of calling the source again.
+type remoteCall struct {
+	done   chan struct{} // Closed once the fetched stubs are inserted.
+	loaded bool          // Whether stubs were fetched, set before done is closed.
+}
+
+// remote caches the stubs fetched from a RemoteSource.
+type remote struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	source   RemoteSource
+	ttl      time.Duration
+	entries  map[string]remoteEntry
+	inflight map[string]*remoteCall
+}
+
+// newRemote creates a new remote without source.
+func newRemote() *remote {
+	return &remote{
+		now:      time.Now,
+		entries:  make(map[string]remoteEntry),
+		inflight: make(map[string]*remoteCall),
+	}
+}
+
+// set replaces the remote source and forgets the fetched entries.
+//
+// The stubs fetched from the previous source are kept until they are deleted.
+func (r *remote) set(source RemoteSource, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.source = source
+	r.ttl = ttl
+	r.entries = make(map[string]remoteEntry)
+	r.inflight = make(map[string]*remoteCall)
+}
+
+// reset forgets the fetched entries.
+func (r *remote) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = make(map[string]remoteEntry)
+	r.inflight = make(map[string]*remoteCall)
+}
+
+// expire deletes the stubs fetched for the given service and method if they
+// are older than the TTL.
+//
+// The stubs are deleted without holding the lock, as their events are
+// delivered to listeners which may search.
+//
+// It returns true if the stubs were deleted and have to be fetched again.
+func (r *remote) expire(s *searcher, service, method string) bool {
+	key := service + "/" + method
+
+	r.mu.Lock()
+
+	entry, ok := r.entries[key]
+	if !ok || r.source == nil || r.ttl <= 0 || r.now().Sub(entry.at) < r.ttl {
+		r.mu.Unlock()
+
+		return false
+	}
+
+	delete(r.entries, key)
+	r.mu.Unlock()
+
+	s.remove(EventExpire, entry.ids)
+
+	return true
+}
+
+// load fetches the stubs of the given service and method and inserts them.
+//
+// The source is called without holding the lock, so a slow source only
+// delays the misses of its service and method, which share a single fetch.
+// The fetched stubs are dropped if the source is replaced or the entries are
+// reset during the fetch. They are inserted without holding the lock, as
+// their events are delivered to listeners which may search.
+//
+// It returns false if there is no source, if the stubs were already fetched
+// or if the source failed.
+func (r *remote) load(b *Budgerigar, service, method string) bool {
+	key := service + "/" + method
+
+	r.mu.Lock()
+
+	if r.source == nil {
+		r.mu.Unlock()
+
+		return false
+	}
+
+	if _, ok := r.entries[key]; ok {
+		r.mu.Unlock()
+
+		return false
+	}
+
+	// Wait for the fetch in flight, if any.
+	if call, ok := r.inflight[key]; ok {
+		r.mu.Unlock()
+		<-call.done
+
+		return call.loaded
+	}
+
+	call := &remoteCall{done: make(chan struct{})}
+	r.inflight[key] = call
+	source := r.source
+
+	r.mu.Unlock()
+
+	// The waiting misses search once the fetched stubs are inserted.
+	defer close(call.done)
+
+	stubs, err := source.Fetch(service, method)
+	if err == nil {
+		stubs = localize(stubs)
+	}
+
+	r.mu.Lock()
+
+	// The map of the calls in flight is replaced when the source is replaced
+	// or the entries are reset.
+	if r.inflight[key] != call {
+		r.mu.Unlock()
+
+		return false
+	}
+
+	delete(r.inflight, key)
+
+	if err != nil {
+		r.mu.Unlock()
+		b.logger.Warn("stuber: remote source failed", "service", service, "method", method, "error", err)
+
+		return false
+	}
+
+	// Empty responses are cached too, so the source is not called on every miss.
+	r.entries[key] = remoteEntry{at: r.now(), ids: stubIDs(stubs)}
+	call.loaded = len(stubs) > 0
+
+	r.mu.Unlock()
+
+	b.PutMany(stubs...)
+
+	return call.loaded
+}
+
+// localize returns copies of the given fetched stubs with new IDs, so a
+// remote source can neither replace the local stubs nor the stubs of another
+// fetch. The references of the fetched stubs to each other, by DependsOn and
+// Base, are rewritten to the new IDs. Nil stubs are dropped.
+func localize(stubs []*Stub) []*Stub {
+	ids := make(map[uuid.UUID]uuid.UUID, len(stubs))
+	result := make([]*Stub, 0, len(stubs))
+
+	for _, stub := range stubs {
+		if stub == nil {
+			continue
+		}
+
+		clone := *stub
+		clone.ID = uuid.New()
+
+		if stub.ID != uuid.Nil {
+			ids[stub.ID] = clone.ID
+		}
+
+		result = append(result, &clone)
+	}
+
+	rewrite := func(id uuid.UUID) uuid.UUID {
+		if local, ok := ids[id]; ok {
+			return local
+		}
+
+		return id
+	}
+
+	for _, stub := range result {
+		if stub.Base != nil {
+			base := rewrite(*stub.Base)
+			stub.Base = &base
+		}
+
+		if len(stub.DependsOn) > 0 {
+			dependsOn := make([]uuid.UUID, len(stub.DependsOn))
+			for i, id := range stub.DependsOn {
+				dependsOn[i] = rewrite(id)
+			}
+
+			stub.DependsOn = dependsOn
+		}
+	}
+
+	return result
+}
+
+// find searches the query, consulting the remote source when the service or
+// the method of the query is not found or when the fetched stubs expired.
+func (b *Budgerigar) find(query Query) (*Result, error) {
+	expired := b.remote.expire(b.searcher, query.Service, query.Method)
+
+	result, err := b.searcher.find(query)
+
+	if expired || errors.Is(err, ErrServiceNotFound) || errors.Is(err, ErrMethodNotFound) {
+		if b.remote.load(b, query.Service, query.Method) {
+			return b.searcher.find(query)
+		}
+	}
+
+	return result, err
+}
+
+// SetRemoteSource sets the source consulted when a service or a method is
+// not found. The fetched stubs are cached locally and fetched again once
+// they are older than the TTL; a TTL of zero caches them forever.
+//
+// The fetched stubs are inserted with new IDs, so they never replace the
+// local stubs; their DependsOn and Base references to each other follow.
+//
+// Passing a nil source disables the remote lookup.
+//
+// Parameters:
+// - source: The RemoteSource to consult, or nil.
+// - ttl: The duration the fetched stubs are cached for.
+func (b *Budgerigar) SetRemoteSource(source RemoteSource, ttl time.Duration) {
+	b.remote.set(source, ttl)
+}
@@ -0,0 +1,177 @@
+package stuber //nolint:testpackage
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type testSource struct {
+	calls int
+	stubs []*Stub
+}
+
+func (s *testSource) Fetch(service, method string) ([]*Stub, error) {
+	s.calls++
+
+	result := make([]*Stub, 0, len(s.stubs))
+
+	for _, stub := range s.stubs {
+		if stub.Service == service && stub.Method == method {
+			clone := *stub
+			clone.ID = uuid.New()
+			result = append(result, &clone)
+		}
+	}
+
+	return result, nil
+}
+
+func TestBudgerigar_RemoteSource(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	source := &testSource{stubs: []*Stub{{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output:  Output{Data: map[string]interface{}{"message": "remote"}},
+	}}}
+
+	s := NewBudgerigar(features.New())
+	s.remote.now = func() time.Time { return now }
+	s.SetRemoteSource(source, time.Minute)
+
+	query := Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	r, err := s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, "remote", r.Found().Output.Data["message"])
+	require.Equal(t, 1, source.calls)
+
+	_, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.Equal(t, 1, source.calls)
+
+	_, err = s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+	require.ErrorIs(t, err, ErrMethodNotFound)
+	require.Equal(t, 2, source.calls)
+
+	now = now.Add(time.Minute)
+
+	r, err = s.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.Equal(t, 3, source.calls)
+	require.Len(t, s.All(), 1)
+}
+
+// blockingSource is a RemoteSource whose fetches of SayHello wait until
+// released.
+type blockingSource struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *blockingSource) Fetch(service, method string) ([]*Stub, error) {
+	s.calls.Add(1)
+
+	if method == "SayHello" {
+		<-s.release
+	}
+
+	return []*Stub{{ID: uuid.New(), Service: service, Method: method}}, nil
+}
+
+func TestBudgerigar_RemoteSource_Concurrent(t *testing.T) {
+	source := &blockingSource{release: make(chan struct{})}
+
+	s := New()
+	s.SetRemoteSource(source, 0)
+
+	const n = 8
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, n)
+
+	for range n {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := s.FindByQuery(Query{Service: "Greeter", Method: "SayHello"})
+			errs <- err
+		}()
+	}
+
+	require.Eventually(t, func() bool { return source.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// The misses of the other methods are not blocked by the slow fetch.
+	_, err := s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), source.calls.Load())
+
+	close(source.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(2), source.calls.Load())
+	require.Len(t, s.All(), 2)
+}
+
+// collidingSource is a RemoteSource answering stubs with the given ID.
+type collidingSource struct {
+	id uuid.UUID
+}
+
+func (s collidingSource) Fetch(service, method string) ([]*Stub, error) {
+	return []*Stub{{ID: s.id, Service: service, Method: method}}, nil
+}
+
+func TestBudgerigar_RemoteSource_Listener(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	local := &Stub{ID: uuid.New(), Service: "Greeter", Method: "SayBye"}
+
+	s := New()
+	s.remote.now = func() time.Time { return now }
+	s.PutMany(local)
+
+	// The source answers with the ID of the local stub, which must be kept.
+	s.SetRemoteSource(collidingSource{id: local.ID}, time.Minute)
+
+	// The listener searches while the fetched stubs are inserted and expired.
+	var events atomic.Int32
+
+	s.Listen(EventListenerFunc(func(event StubEvent) {
+		switch event.(type) {
+		case StubAdded, StubExpired:
+			events.Add(1)
+
+			_, _ = s.FindByQuery(Query{Service: "Greeter", Method: "SayBye"})
+		}
+	}))
+
+	r, err := s.FindByQuery(Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.NotEqual(t, local.ID, r.Found().ID)
+	require.Equal(t, "SayBye", s.FindByID(local.ID).Method)
+
+	now = now.Add(time.Minute)
+
+	r, err = s.FindByQuery(Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.NotNil(t, r.Found())
+	require.Equal(t, int32(3), events.Load())
+	require.Len(t, s.All(), 2)
+}
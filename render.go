@@ -0,0 +1,371 @@
+package stuber
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/google/uuid"
+)
+
+// TemplateData is the root object of the output templates, such as
+// {{ .Request.name }}, {{ .Headers.authorization }}, {{ .Stub.ID }} or
+// {{ .MessageIndex }}.
+//
+// Template functions needing the context of the rendering receive it as an
+// argument, such as {{ myFunc . }} or {{ myFunc .Stub }}.
+type TemplateData struct {
+	Request      map[string]interface{}   // The data of the request, the last received message of a client stream.
+	Requests     []map[string]interface{} // The received messages of a client stream, in order.
+	Headers      map[string]interface{}   // The headers of the request.
+	Query        Query                    // The full query, such as .Query.Service.
+	Stub         *Stub                    // The matched stub being rendered.
+	MessageIndex int                      // The index of the last received message of a client stream, 0 otherwise.
+}
+
+// TemplateCacheStats are the statistics of the cache of parsed templates.
+type TemplateCacheStats struct {
+	Entries       int    `json:"entries"`       // The number of cached templates.
+	Hits          uint64 `json:"hits"`          // The number of templates found in the cache.
+	Misses        uint64 `json:"misses"`        // The number of templates parsed.
+	Invalidations uint64 `json:"invalidations"` // The number of times the templates of a stub were dropped because it changed.
+}
+
+// templateKey identifies a template by its stub, its location in the output
+// of the stub and the hash of its text, as the outputs sharing a stub, such
+// as its alternative outputs, have different texts at the same locations.
+type templateKey struct {
+	id       uuid.UUID
+	location string
+	sum      [sha256.Size]byte
+}
+
+// templateCache caches the parsed templates of the stubs.
+//
+// The templates of a stub are dropped when it changes.
+type templateCache struct {
+	mu    sync.Mutex
+	items map[templateKey]*template.Template
+	stats TemplateCacheStats
+	limit int // The maximum number of cached templates, or -1 for no limit.
+}
+
+// newTemplateCache creates an empty templateCache without limit.
+func newTemplateCache() *templateCache {
+	return &templateCache{items: make(map[templateKey]*template.Template), limit: -1}
+}
+
+// get returns the template of the given text at the given location of the
+// output of the given stub, parsing the text with the given function if it
+// is not cached.
+//
+// The text is parsed without holding the mutex, so the concurrent misses
+// may parse it more than once, the first parsed template being kept.
+func (c *templateCache) get(
+	id uuid.UUID,
+	location, text string,
+	parse func(name, text string) (*template.Template, error),
+) (*template.Template, error) {
+	key := templateKey{id: id, location: location, sum: sha256.Sum256([]byte(text))}
+
+	c.mu.Lock()
+
+	if tmpl, ok := c.items[key]; ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+
+		return tmpl, nil
+	}
+
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	tmpl, err := parse(location, text)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.items[key]; ok {
+		return cached, nil
+	}
+
+	if c.limit == 0 {
+		return tmpl, nil
+	}
+
+	// Evict an arbitrary template once the cache is full.
+	if c.limit > 0 && len(c.items) >= c.limit {
+		for evicted := range c.items {
+			delete(c.items, evicted)
+
+			break
+		}
+	}
+
+	c.items[key] = tmpl
+
+	return tmpl, nil
+}
+
+// invalidate removes the templates of the given changed stubs.
+func (c *templateCache) invalidate(ids ...uuid.UUID) {
+	removed := c.remove(ids)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Invalidations += uint64(removed) //nolint:gosec
+}
+
+// forget removes the templates of the given stubs.
+func (c *templateCache) forget(ids ...uuid.UUID) {
+	c.remove(ids)
+}
+
+// remove removes the templates of the given stubs, and returns the number
+// of stubs whose templates were removed.
+func (c *templateCache) remove(ids []uuid.UUID) int {
+	if len(ids) == 0 {
+		return 0
+	}
+
+	forgotten := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		forgotten[id] = struct{}{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := make(map[uuid.UUID]struct{})
+
+	for key := range c.items {
+		if _, ok := forgotten[key.id]; ok {
+			delete(c.items, key)
+			removed[key.id] = struct{}{}
+		}
+	}
+
+	return len(removed)
+}
+
+// retain removes the templates of the stubs not accepted by the predicate.
+func (c *templateCache) retain(keep func(uuid.UUID) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.items {
+		if !keep(key.id) {
+			delete(c.items, key)
+		}
+	}
+}
+
+// reset removes all the templates.
+func (c *templateCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[templateKey]*template.Template)
+}
+
+// snapshot returns the statistics of the cache.
+func (c *templateCache) snapshot() TemplateCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.items)
+
+	return stats
+}
+
+// RenderOutput returns the output of the stub with its templates executed
+// against the query. The parsed templates are cached by stub until the stub
+// is updated.
+//
+// Parameters:
+// - stub: The Stub value whose output is rendered, usually a found one.
+// - query: The Query answered by the stub.
+//
+// Returns:
+// - Output: The rendered output.
+// - error: An error matching ErrInvalidTemplate if a template cannot be
+// parsed, or the error of its execution.
+func (b *Budgerigar) RenderOutput(stub *Stub, query Query) (Output, error) {
+	return b.render(stub, TemplateData{
+		Request: query.Data,
+		Headers: query.Headers,
+		Query:   query,
+		Stub:    stub,
+	})
+}
+
+// RenderStreamOutput returns the output of the stub with its templates
+// executed against the received messages of a client stream, available as
+// .Requests, the last one being .Request and the data of .Query.
+//
+// Parameters:
+// - stub: The Stub value whose output is rendered, usually a found one.
+// - headers: The headers of the request.
+// - messages: The received messages, in order.
+//
+// Returns:
+// - Output: The rendered output.
+// - error: An error matching ErrInvalidTemplate if a template cannot be
+// parsed, or the error of its execution.
+func (b *Budgerigar) RenderStreamOutput(
+	stub *Stub,
+	headers map[string]interface{},
+	messages []map[string]interface{},
+) (Output, error) {
+	data := TemplateData{
+		Requests: messages,
+		Headers:  headers,
+		Query:    Query{Service: stub.Service, Method: stub.Method, Headers: headers},
+		Stub:     stub,
+	}
+
+	if len(messages) > 0 {
+		data.Request = messages[len(messages)-1]
+		data.Query.Data = data.Request
+		data.MessageIndex = len(messages) - 1
+	}
+
+	return b.render(stub, data)
+}
+
+// render returns the output of the stub with its templates executed against
+// the given data.
+//
+// With a seed, of the output or of the Budgerigar, the random template
+// functions draw from a generator seeded by it and the hash of the query, so
+// the same request renders the same output, its values and headers being
+// rendered in order.
+func (b *Budgerigar) render(stub *Stub, data TemplateData) (Output, error) {
+	r := renderer{b: b, id: stub.ID, data: data}
+
+	seed := stub.Output.Seed
+	if seed == nil {
+		seed = b.templates.currentSeed()
+	}
+
+	if seed != nil {
+		hash := sha256.Sum256([]byte(QueryHash(data.Query)))
+		r.random = b.templates.restricted(seededFunctions(
+			rand.New(rand.NewPCG(*seed, binary.BigEndian.Uint64(hash[:]))), //nolint:gosec
+		))
+	}
+
+	output := stub.Output
+
+	rendered, err := r.value("output.data", stub.Output.Data)
+	if err != nil {
+		return stub.Output, err
+	}
+
+	output.Data, _ = rendered.(map[string]interface{})
+
+	if output.Error, err = r.text("output.error", stub.Output.Error); err != nil {
+		return stub.Output, err
+	}
+
+	if stub.Output.Headers != nil {
+		output.Headers = make(map[string]string, len(stub.Output.Headers))
+
+		for _, name := range slices.Sorted(maps.Keys(stub.Output.Headers)) {
+			if output.Headers[name], err = r.text("output.headers."+name, stub.Output.Headers[name]); err != nil {
+				return stub.Output, err
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// renderer executes the templates of the output of a stub.
+type renderer struct {
+	b      *Budgerigar
+	id     uuid.UUID
+	data   TemplateData
+	random template.FuncMap // The seeded random functions, if any.
+}
+
+// value returns a copy of the given value with its strings rendered.
+func (r renderer) value(location string, value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return r.text(location, v)
+	case map[string]interface{}:
+		if v == nil {
+			return v, nil
+		}
+
+		result := make(map[string]interface{}, len(v))
+
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			rendered, err := r.value(location+"."+key, v[key])
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = rendered
+		}
+
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+
+		for i, item := range v {
+			rendered, err := r.value(location+"["+strconv.Itoa(i)+"]", item)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = rendered
+		}
+
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// text renders the given text, if it is a template.
+func (r renderer) text(location, text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := r.b.templateCache.get(r.id, location, text, r.b.templates.parse)
+	if err != nil {
+		return "", err
+	}
+
+	// The cached template is shared, so the seeded functions replace the
+	// unseeded ones in a clone.
+	if len(r.random) > 0 {
+		if tmpl, err = tmpl.Clone(); err != nil {
+			return "", err
+		}
+
+		tmpl.Funcs(r.random)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r.data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
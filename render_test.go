@@ -0,0 +1,311 @@
+package stuber_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_RenderOutput(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		Output: stuber.Output{
+			Data: map[string]interface{}{
+				"message": "Hello {{ .Request.name }}",
+				"tags":    []interface{}{"{{ upper .Request.name }}", 42},
+			},
+			Headers: map[string]string{"x-user": "{{ .Headers.user }}"},
+		},
+	}
+	s.PutMany(stub)
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: map[string]interface{}{"user": "bob"},
+		Data:    map[string]interface{}{"name": "Bob"},
+	}
+
+	for range 3 {
+		output, err := s.RenderOutput(stub, query)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"message": "Hello Bob",
+			"tags":    []interface{}{"BOB", 42},
+		}, output.Data)
+		require.Equal(t, map[string]string{"x-user": "bob"}, output.Headers)
+	}
+
+	require.Equal(t, "Hello {{ .Request.name }}", stub.Output.Data["message"])
+	require.Equal(t, stuber.TemplateCacheStats{Entries: 3, Hits: 6, Misses: 3}, s.Info().Templates)
+
+	updated := *stub
+	updated.Output.Data = map[string]interface{}{"message": "Bye {{ .Request.name }}"}
+	updated.Output.Headers = nil
+	s.UpdateMany(&updated)
+
+	output, err := s.RenderOutput(&updated, query)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"message": "Bye Bob"}, output.Data)
+	require.Equal(t, uint64(1), s.Info().Templates.Invalidations)
+
+	s.DeleteByID(stub.ID)
+	require.Equal(t, stuber.Info{
+		Templates: stuber.TemplateCacheStats{Hits: 6, Misses: 4, Invalidations: 1},
+	}, s.Info())
+}
+
+func TestBudgerigar_RenderOutput_Concurrent(t *testing.T) {
+	s := stuber.New()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "Hello {{ title .Request.n }}"}},
+	}
+	s.PutMany(stub)
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"n": "bob smith"}}
+
+	var (
+		wg       sync.WaitGroup
+		messages = make([]interface{}, 8)
+		errs     = make([]error, 8)
+	)
+
+	for i := range messages {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 50 {
+				output, err := s.RenderOutput(stub, query)
+				if err != nil {
+					errs[i] = err
+
+					return
+				}
+
+				messages[i] = output.Data["message"]
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for i := range messages {
+		require.NoError(t, errs[i])
+		require.Equal(t, "Hello Bob Smith", messages[i])
+	}
+}
+
+func TestBudgerigar_RenderOutput_SharedID(t *testing.T) {
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	// The alternative outputs of a stub, as well as the outputs of the not
+	// found stubs, share an ID.
+	for _, id := range []uuid.UUID{uuid.New(), uuid.Nil} {
+		s := stuber.New()
+
+		hello := &stuber.Stub{ID: id, Output: stuber.Output{Data: map[string]interface{}{"message": "Hello {{ .Request.name }}"}}}
+		hi := &stuber.Stub{ID: id, Output: stuber.Output{Data: map[string]interface{}{"message": "Hi {{ .Request.name }}"}}}
+
+		for range 3 {
+			output, err := s.RenderOutput(hello, query)
+			require.NoError(t, err)
+			require.Equal(t, "Hello Bob", output.Data["message"])
+
+			output, err = s.RenderOutput(hi, query)
+			require.NoError(t, err)
+			require.Equal(t, "Hi Bob", output.Data["message"])
+		}
+
+		require.Equal(t, stuber.TemplateCacheStats{Entries: 2, Hits: 4, Misses: 2}, s.Info().Templates)
+	}
+}
+
+func TestBudgerigar_RenderOutput_Invalid(t *testing.T) {
+	stub := &stuber.Stub{
+		ID:     uuid.New(),
+		Output: stuber.Output{Error: "{{ .Request.name"},
+	}
+
+	output, err := stuber.New().RenderOutput(stub, stuber.Query{})
+	require.ErrorIs(t, err, stuber.ErrInvalidTemplate)
+	require.Equal(t, stub.Output, output)
+}
+
+func TestBudgerigar_RenderOutput_Context(t *testing.T) {
+	id := uuid.MustParse("1c5fc1b6-6f3a-4c1d-9d4e-3b0f8b4f5a51")
+
+	stub := &stuber.Stub{
+		ID:      id,
+		Service: "Greeter",
+		Method:  "SayHello",
+		Output: stuber.Output{
+			Data: map[string]interface{}{
+				"stub":    "{{ .Stub.ID }}",
+				"service": "{{ .Query.Service }}/{{ .Query.Method }}",
+				"name":    "{{ .Query.Data.name }}",
+				"user":    "{{ .Headers.user }}",
+				"index":   "{{ .MessageIndex }}",
+			},
+		},
+	}
+
+	output, err := stuber.New().RenderOutput(stub, stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Headers: map[string]interface{}{"user": "bob"},
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"stub":    id.String(),
+		"service": "Greeter/SayHello",
+		"name":    "Bob",
+		"user":    "bob",
+		"index":   "0",
+	}, output.Data)
+
+	output, err = stuber.New().RenderStreamOutput(stub, nil, []map[string]interface{}{
+		{"name": "Alice"},
+		{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Bob", output.Data["name"])
+	require.Equal(t, "1", output.Data["index"])
+	require.Equal(t, "Greeter/SayHello", output.Data["service"])
+
+	usage, err := stuber.AnalyzeTemplates(stub)
+	require.NoError(t, err)
+	require.Equal(t, []string{"name"}, usage.Fields)
+	require.Equal(t, []string{"user"}, usage.Headers)
+}
+
+func TestNew_WithTemplateSeed(t *testing.T) {
+	stub := &stuber.Stub{
+		ID: uuid.New(),
+		Output: stuber.Output{
+			Data: map[string]interface{}{
+				"id":     "{{ uuid }}",
+				"amount": "{{ random 0 1000000 }}",
+			},
+		},
+	}
+
+	query := func(name string) stuber.Query {
+		return stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": name}}
+	}
+
+	render := func(s *stuber.Budgerigar, stub *stuber.Stub, name string) map[string]interface{} {
+		output, err := s.RenderOutput(stub, query(name))
+		require.NoError(t, err)
+
+		return output.Data
+	}
+
+	first := stuber.New(stuber.WithTemplateSeed(42))
+	second := stuber.New(stuber.WithTemplateSeed(42))
+
+	require.Equal(t, render(first, stub, "Bob"), render(second, stub, "Bob"))
+	require.Equal(t, render(first, stub, "Bob"), render(first, stub, "Bob"))
+	require.NotEqual(t, render(first, stub, "Bob"), render(first, stub, "Alice"))
+	require.NotEqual(t, render(first, stub, "Bob"), render(stuber.New(stuber.WithTemplateSeed(7)), stub, "Bob"))
+
+	seed := uint64(42)
+	require.Equal(t, &seed, first.Info().TemplateSeed)
+	require.Nil(t, stuber.New().Info().TemplateSeed)
+
+	seeded := *stub
+	seeded.Output.Seed = &seed
+
+	require.Equal(t, render(first, stub, "Bob"), render(stuber.New(), &seeded, "Bob"))
+	require.Equal(t, render(first, stub, "Bob"), render(stuber.New(stuber.WithTemplateSeed(7)), &seeded, "Bob"))
+
+	unseeded := stuber.New()
+	require.NotEqual(t, render(unseeded, stub, "Bob"), render(unseeded, stub, "Bob"))
+
+	_, err := stuber.New(
+		stuber.WithTemplateSeed(42),
+		stuber.WithoutTemplateFunctions("uuid"),
+	).RenderOutput(stub, query("Bob"))
+	require.ErrorIs(t, err, stuber.ErrInvalidTemplate)
+}
+
+func TestBudgerigar_RenderOutput_BaseSeed(t *testing.T) {
+	seed := uint64(42)
+
+	base := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Greeter",
+		Method:   "SayHello",
+		Abstract: true,
+		Output:   stuber.Output{Data: map[string]interface{}{"source": "base"}},
+	}
+
+	// The derived stub renders from its own seed, whatever the instance.
+	render := func() map[string]interface{} {
+		s := stuber.New()
+		s.PutMany(base, &stuber.Stub{
+			ID:     uuid.New(),
+			Base:   &base.ID,
+			Output: stuber.Output{Seed: &seed, Data: map[string]interface{}{"id": "{{ uuid }}"}},
+		})
+
+		query := stuber.Query{Service: "Greeter", Method: "SayHello"}
+
+		result, err := s.FindByQuery(query)
+		require.NoError(t, err)
+		require.Equal(t, &seed, result.Found().Output.Seed)
+
+		output, err := s.RenderOutput(result.Found(), query)
+		require.NoError(t, err)
+
+		return output.Data
+	}
+
+	first := render()
+	require.Equal(t, "base", first["source"])
+	require.Equal(t, first, render())
+}
+
+func TestNew_WithTemplateCache(t *testing.T) {
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Output: stuber.Output{
+			Data: map[string]interface{}{"message": "Hello {{ .Request.name }}", "name": "{{ .Request.name }}"},
+		},
+	}
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	render := func(s *stuber.Budgerigar) stuber.TemplateCacheStats {
+		s.PutMany(stub)
+
+		for range 2 {
+			output, err := s.RenderOutput(stub, query)
+			require.NoError(t, err)
+			require.Equal(t, "Hello Bob", output.Data["message"])
+		}
+
+		return s.Info().Templates
+	}
+
+	require.Equal(t, 1, render(stuber.New(stuber.WithTemplateCache(1))).Entries)
+	require.Equal(t, stuber.TemplateCacheStats{Misses: 4}, render(stuber.New(stuber.WithTemplateCache(0))))
+	require.Equal(t, stuber.TemplateCacheStats{Entries: 2, Hits: 2, Misses: 2}, render(stuber.New()))
+}
@@ -0,0 +1,164 @@
+package stuber
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrRevisionNotFound is returned when a revision of a stub is not kept.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is a past or current version of a stub.
+type Revision struct {
+	Number int   `json:"number"` // The number of the revision, starting at 1.
+	Stub   *Stub `json:"stub"`   // A copy of the stub at this revision.
+}
+
+// revisionLog is the kept revisions of a stub.
+type revisionLog struct {
+	next  int
+	items []Revision
+}
+
+// revisions keeps the last revisions of each stub.
+type revisions struct {
+	mu    sync.RWMutex
+	limit int
+	logs  map[uuid.UUID]*revisionLog
+}
+
+// newRevisions creates a new revisions instance keeping no revisions.
+func newRevisions() *revisions {
+	return &revisions{logs: make(map[uuid.UUID]*revisionLog)}
+}
+
+// setLimit sets the number of revisions kept for each stub.
+func (r *revisions) setLimit(limit int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.limit = limit
+}
+
+// record keeps a deep copy of the given stubs as their new revision, so the
+// later changes of the stubs do not rewrite it.
+func (r *revisions) record(stubs ...*Stub) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.limit <= 0 {
+		return
+	}
+
+	for _, stub := range stubs {
+		log, ok := r.logs[stub.ID]
+		if !ok {
+			log = &revisionLog{}
+			r.logs[stub.ID] = log
+		}
+
+		log.next++
+		log.items = append(log.items, Revision{Number: log.next, Stub: stub.Clone()})
+
+		if len(log.items) > r.limit {
+			log.items = slices.Delete(log.items, 0, len(log.items)-r.limit)
+		}
+	}
+}
+
+// list returns deep copies of the kept revisions of the stub with the given
+// ID, oldest first.
+func (r *revisions) list(id uuid.UUID) []Revision {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	log, ok := r.logs[id]
+	if !ok {
+		return nil
+	}
+
+	items := make([]Revision, 0, len(log.items))
+	for _, revision := range log.items {
+		items = append(items, Revision{Number: revision.Number, Stub: revision.Stub.Clone()})
+	}
+
+	return items
+}
+
+// get returns a deep copy of the stub with the given ID at the given revision.
+func (r *revisions) get(id uuid.UUID, number int) (*Stub, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	log, ok := r.logs[id]
+	if !ok {
+		return nil, false
+	}
+
+	for _, revision := range log.items {
+		if revision.Number == number {
+			return revision.Stub.Clone(), true
+		}
+	}
+
+	return nil, false
+}
+
+// reset forgets all the revisions.
+func (r *revisions) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs = make(map[uuid.UUID]*revisionLog)
+}
+
+// Revisions returns the kept revisions of the Stub value with the given ID,
+// oldest first. The last one is the current version of the Stub value.
+//
+// Revisions are only kept when enabled with WithRevisions. They are kept
+// after the Stub value is deleted, so it can be restored, until Clear.
+//
+// Parameters:
+// - id: The UUID of the Stub value.
+//
+// Returns:
+// - []Revision: The kept revisions, or nil.
+func (b *Budgerigar) Revisions(id uuid.UUID) []Revision {
+	return b.searcher.revisions.list(id)
+}
+
+// RollbackByID restores the Stub value with the given ID to the given
+// revision, which is recorded as a new revision. A deleted Stub value is
+// inserted again.
+//
+// The Stub value is restored as PatchByID and PutMany modify it, normalized
+// and with its cached templates dropped.
+//
+// Parameters:
+// - id: The UUID of the Stub value.
+// - revision: The number of the revision to restore.
+//
+// Returns:
+// - error: ErrRevisionNotFound if the revision is not kept.
+func (b *Budgerigar) RollbackByID(id uuid.UUID, revision int) error {
+	stub, ok := b.searcher.revisions.get(id, revision)
+	if !ok {
+		return ErrRevisionNotFound
+	}
+
+	err := b.PatchByID(id, func(current *Stub) error {
+		*current = *stub
+
+		return nil
+	})
+	if errors.Is(err, ErrStubNotFound) {
+		b.PutMany(stub)
+
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,120 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_RollbackByID(t *testing.T) {
+	s := stuber.New(stuber.WithRevisions(2))
+
+	id := uuid.New()
+
+	s.PutMany(&stuber.Stub{
+		ID:      id,
+		Service: "Greeter",
+		Method:  "SayHello",
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "v1"}},
+	})
+
+	for _, message := range []string{"v2", "v3"} {
+		require.NoError(t, s.PatchByID(id, func(stub *stuber.Stub) error {
+			stub.Output = stuber.Output{Data: map[string]interface{}{"message": message}}
+
+			return nil
+		}))
+	}
+
+	revisions := s.Revisions(id)
+	require.Len(t, revisions, 2)
+	require.Equal(t, 2, revisions[0].Number)
+	require.Equal(t, 3, revisions[1].Number)
+
+	require.ErrorIs(t, s.RollbackByID(id, 1), stuber.ErrRevisionNotFound)
+	require.ErrorIs(t, s.RollbackByID(uuid.New(), 1), stuber.ErrRevisionNotFound)
+
+	require.NoError(t, s.RollbackByID(id, 2))
+	require.Equal(t, "v2", s.FindByID(id).Output.Data["message"])
+	require.Equal(t, 4, s.Revisions(id)[1].Number)
+
+	require.Equal(t, 1, s.DeleteByID(id))
+	require.NoError(t, s.RollbackByID(id, 4))
+	require.Equal(t, "v2", s.FindByID(id).Output.Data["message"])
+	require.Len(t, s.All(), 1)
+
+	s.Clear()
+	require.Empty(t, s.Revisions(id))
+}
+
+func TestBudgerigar_RollbackByID_InPlace(t *testing.T) {
+	s := stuber.New(stuber.WithRevisions(3))
+
+	id := uuid.New()
+
+	s.PutMany(&stuber.Stub{
+		ID:      id,
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "Hello {{ .Request.name }}"}},
+	})
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	output, err := s.RenderOutput(s.FindByID(id), query)
+	require.NoError(t, err)
+	require.Equal(t, "Hello Bob", output.Data["message"])
+
+	// The patches modifying the maps in place do not rewrite the revisions.
+	require.NoError(t, s.PatchByID(id, func(stub *stuber.Stub) error {
+		stub.Input.Equals["name"] = "Alice"
+		stub.Output.Data["message"] = "Bye {{ .Request.name }}"
+
+		return nil
+	}))
+
+	revisions := s.Revisions(id)
+	require.Len(t, revisions, 2)
+	require.Equal(t, "Bob", revisions[0].Stub.Input.Equals["name"])
+	require.Equal(t, "Alice", revisions[1].Stub.Input.Equals["name"])
+
+	// The returned revisions are copies as well.
+	revisions[0].Stub.Input.Equals["name"] = "Eve"
+
+	output, err = s.RenderOutput(s.FindByID(id), query)
+	require.NoError(t, err)
+	require.Equal(t, "Bye Bob", output.Data["message"])
+
+	invalidations := s.Info().Templates.Invalidations
+
+	require.NoError(t, s.RollbackByID(id, 1))
+
+	stub := s.FindByID(id)
+	require.Equal(t, "Bob", stub.Input.Equals["name"])
+
+	// The rollback drops the cached templates of the stub.
+	require.Equal(t, invalidations+1, s.Info().Templates.Invalidations)
+
+	output, err = s.RenderOutput(stub, query)
+	require.NoError(t, err)
+	require.Equal(t, "Hello Bob", output.Data["message"])
+
+	// A deleted stub is inserted again.
+	require.Equal(t, 1, s.DeleteByID(id))
+	require.NoError(t, s.RollbackByID(id, 2))
+	require.Equal(t, "Alice", s.FindByID(id).Input.Equals["name"])
+}
+
+func TestBudgerigar_RevisionsDisabled(t *testing.T) {
+	s := stuber.New()
+
+	id := uuid.New()
+	s.PutMany(&stuber.Stub{ID: id, Service: "Greeter", Method: "SayHello"})
+
+	require.Empty(t, s.Revisions(id))
+	require.ErrorIs(t, s.RollbackByID(id, 1), stuber.ErrRevisionNotFound)
+}
@@ -0,0 +1,92 @@
+package stuber
+
+import (
+	"maps"
+)
+
+// ScenarioStarted is the state of a scenario until a match transitions it.
+const ScenarioStarted = "Started"
+
+// scenarioState returns the current state of the given scenario.
+//
+// The mutex of the searcher must be held.
+func (s *searcher) scenarioState(scenario string) string {
+	if state, ok := s.scenarios[scenario]; ok {
+		return state
+	}
+
+	return ScenarioStarted
+}
+
+// inState checks if the scenario of the given Stub value is in the state the
+// Stub value requires, if any.
+//
+// The mutex of the searcher must be held.
+func (s *searcher) inState(stub *Stub) bool {
+	return stub.RequiredState == "" || s.scenarioState(stub.Scenario) == stub.RequiredState
+}
+
+// transition moves the scenario of the given matched Stub value to its new
+// state, if any.
+//
+// The mutex of the searcher must be held.
+func (s *searcher) transition(stub *Stub) {
+	if stub.NewState != "" {
+		s.scenarios[stub.Scenario] = stub.NewState
+	}
+}
+
+// scenarioStates returns the current states of the scenarios of the Stub
+// values.
+func (s *searcher) scenarioStates() map[string]string {
+	states := make(map[string]string)
+
+	for stub := range s.stubs(s.storage.iterValues(), everything) {
+		if stub.Scenario != "" {
+			states[stub.Scenario] = ""
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	maps.Copy(states, s.scenarios)
+
+	for scenario := range states {
+		states[scenario] = s.scenarioState(scenario)
+	}
+
+	return states
+}
+
+// exportScenarios returns a copy of the states of the scenarios that moved
+// from ScenarioStarted.
+func (s *searcher) exportScenarios() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Clone(s.scenarios)
+}
+
+// resetScenarios moves all the scenarios back to ScenarioStarted.
+func (s *searcher) resetScenarios() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios = make(map[string]string)
+}
+
+// ScenarioStates returns the current state of each scenario of the Stub
+// values, ScenarioStarted until a match transitions it.
+//
+// Returns:
+// - map[string]string: The states, by scenario.
+func (b *Budgerigar) ScenarioStates() map[string]string {
+	return b.searcher.scenarioStates()
+}
+
+// ResetScenarios moves all the scenarios back to ScenarioStarted, so a
+// multi-step flow can be replayed.
+func (b *Budgerigar) ResetScenarios() {
+	b.searcher.resetScenarios()
+}
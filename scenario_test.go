@@ -0,0 +1,122 @@
+package stuber_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Scenarios(t *testing.T) {
+	s := stuber.New()
+
+	order := func(status, required, next string) *stuber.Stub {
+		return &stuber.Stub{
+			ID:            uuid.New(),
+			Service:       "Orders",
+			Method:        "GetOrder",
+			Scenario:      "checkout",
+			RequiredState: required,
+			NewState:      next,
+			Input:         stuber.InputData{Equals: map[string]interface{}{"id": "42"}},
+			Output:        stuber.Output{Data: map[string]interface{}{"status": status}},
+		}
+	}
+
+	s.PutMany(
+		order("pending", stuber.ScenarioStarted, "paid"),
+		order("paid", "paid", "shipped"),
+		order("shipped", "shipped", ""),
+	)
+
+	query := stuber.Query{Service: "Orders", Method: "GetOrder", Data: map[string]interface{}{"id": "42"}}
+
+	status := func() string {
+		result, err := s.FindByQuery(query)
+		require.NoError(t, err)
+		require.NotNil(t, result.Found())
+
+		return result.Found().Output.Data["status"].(string)
+	}
+
+	require.Equal(t, map[string]string{"checkout": stuber.ScenarioStarted}, s.ScenarioStates())
+
+	require.Equal(t, "pending", status())
+	require.Equal(t, "paid", status())
+	require.Equal(t, "shipped", status())
+	require.Equal(t, "shipped", status())
+	require.Equal(t, map[string]string{"checkout": "shipped"}, s.ScenarioStates())
+
+	state := s.ExportState()
+
+	s.ResetScenarios()
+	require.Equal(t, "pending", status())
+
+	s.ImportState(state)
+	require.Equal(t, map[string]string{"checkout": "shipped"}, s.ScenarioStates())
+
+	s.Clear()
+	require.Empty(t, s.ScenarioStates())
+}
+
+func TestBudgerigar_Scenarios_Concurrent(t *testing.T) {
+	const searches = 8
+
+	login := &stuber.Stub{
+		ID:            uuid.New(),
+		Service:       "Auth",
+		Method:        "Login",
+		Priority:      10,
+		Scenario:      "session",
+		RequiredState: stuber.ScenarioStarted,
+		NewState:      "logged-in",
+	}
+	again := &stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Login"}
+
+	// The first searches wait for each other once the login is found ready,
+	// ranking the other stub after it, so they all mark it at once.
+	var (
+		ranked  sync.WaitGroup
+		waiting atomic.Int32
+	)
+
+	ranked.Add(searches)
+
+	s := stuber.New(stuber.WithRanker(func(query stuber.Query, stub *stuber.Stub) float64 {
+		if stub.ID == again.ID && waiting.Add(1) <= searches {
+			ranked.Done()
+			ranked.Wait()
+		}
+
+		return stuber.DefaultRank(query, stub) + 1
+	}))
+	s.PutMany(login, again)
+
+	var (
+		wg     sync.WaitGroup
+		logins atomic.Int32
+	)
+
+	for range searches {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result, err := s.FindByQuery(stuber.Query{Service: "Auth", Method: "Login"})
+			if err == nil && result.Found() != nil && result.Found().ID == login.ID {
+				logins.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// The transition fires once.
+	require.Equal(t, int32(1), logins.Load())
+	require.Equal(t, "logged-in", s.ScenarioStates()["session"])
+}
@@ -0,0 +1,113 @@
+package stuber
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// SchemaVersion is the current version of the JSON format of stubs.
+//
+// Stubs without a "schemaVersion" field are assumed to be of version 1, the
+// first versioned format.
+const SchemaVersion = 2
+
+// ErrUnsupportedSchema is returned when a stub is of a newer, unknown version.
+var ErrUnsupportedSchema = errors.New("unsupported stub schema version")
+
+// schemaMigration upgrades the JSON document of a stub to the next version.
+type schemaMigration func(doc map[string]any)
+
+// schemaMigrations are the upgrade shims of the JSON format of stubs, by the
+// version they upgrade from.
+var schemaMigrations = map[int]schemaMigration{ //nolint:gochecknoglobals
+	// Version 2 renamed the "stream" messages of client streaming stubs to "inputs".
+	1: func(doc map[string]any) {
+		if stream, ok := doc["stream"]; ok {
+			if _, ok := doc["inputs"]; !ok {
+				doc["inputs"] = stream
+			}
+
+			delete(doc, "stream")
+		}
+	},
+}
+
+// stubJSON is the Stub type without its UnmarshalJSON method.
+type stubJSON Stub
+
+// UnmarshalJSON decodes the JSON document of a stub, upgrading it from older
+// versions of the format. Numbers are decoded as float64, as the stubs were
+// decoded before the format was versioned.
+//
+// A JSON null leaves the stub unchanged, as for the other Go values.
+func (s *Stub) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var version struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+
+	if err := json.Unmarshal(data, &version); err != nil {
+		return err
+	}
+
+	if version.SchemaVersion == 0 {
+		version.SchemaVersion = 1
+	}
+
+	if version.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSchema, version.SchemaVersion)
+	}
+
+	if version.SchemaVersion < SchemaVersion {
+		upgraded, err := upgradeStub(data, version.SchemaVersion)
+		if err != nil {
+			return err
+		}
+
+		data = upgraded
+	}
+
+	if err := json.Unmarshal(data, (*stubJSON)(s)); err != nil {
+		return err
+	}
+
+	s.SchemaVersion = SchemaVersion
+	s.canonicalize()
+
+	return nil
+}
+
+// upgradeStub applies the migrations of the JSON document of a stub from
+// the given version up to the current one.
+func upgradeStub(data []byte, version int) ([]byte, error) {
+	// Keep the numbers as written through the migrations, so the upgraded
+	// document decodes to the same float64 values as the original one.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	// Only an object is a stub, which a null decodes to a nil map.
+	if doc == nil {
+		return nil, &json.UnmarshalTypeError{Value: "null", Type: reflect.TypeFor[Stub]()}
+	}
+
+	for ; version < SchemaVersion; version++ {
+		if migrate, ok := schemaMigrations[version]; ok {
+			migrate(doc)
+		}
+	}
+
+	doc["schemaVersion"] = SchemaVersion
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,83 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestStub_UnmarshalJSON(t *testing.T) {
+	var stub stuber.Stub
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"service": "Greeter",
+		"method": "SayHello",
+		"input": {"equals": {"age": 42}}
+	}`), &stub))
+	require.Equal(t, stuber.SchemaVersion, stub.SchemaVersion)
+	require.Equal(t, "Greeter", stub.Service)
+	require.InDelta(t, 42.0, stub.Input.Equals["age"], 0)
+
+	// The numbers of the current format are decoded as float64 as well.
+	var current stuber.Stub
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schemaVersion": 2,
+		"service": "Greeter",
+		"method": "SayHello",
+		"input": {"equals": {"age": 42}}
+	}`), &current))
+	require.IsType(t, float64(0), current.Input.Equals["age"])
+
+	err := json.Unmarshal([]byte(`{"schemaVersion": 99, "service": "Greeter"}`), &stub)
+	require.ErrorIs(t, err, stuber.ErrUnsupportedSchema)
+
+	_, err = stuber.New().Import([]byte(`[{"schemaVersion": 99, "service": "Greeter", "method": "SayHello"}]`))
+	require.ErrorIs(t, err, stuber.ErrUnsupportedSchema)
+
+	// A null stub is left unchanged instead of being upgraded.
+	var stubs []stuber.Stub
+
+	require.NoError(t, json.Unmarshal([]byte(`[null]`), &stubs))
+	require.Len(t, stubs, 1)
+	require.Empty(t, stubs[0].Service)
+}
+
+func TestStub_Inputs(t *testing.T) {
+	var legacy stuber.Stub
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"service": "Greeter",
+		"method": "SayHello",
+		"stream": [{"equals": {"name": "Bob"}}, {"equals": {"name": "Alice"}}]
+	}`), &legacy))
+	require.Len(t, legacy.Inputs, 2)
+	require.Empty(t, legacy.Stream) //nolint:staticcheck
+
+	var current stuber.Stub
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schemaVersion": 2,
+		"service": "Greeter",
+		"method": "SayHello",
+		"stream": [{"equals": {"name": "Bob"}}]
+	}`), &current))
+	require.Len(t, current.Inputs, 1)
+
+	s := stuber.New()
+
+	ids := s.PutMany(&stuber.Stub{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Stream:  []stuber.InputData{{Equals: map[string]interface{}{"name": "Bob"}}}, //nolint:staticcheck
+	})
+	require.Len(t, s.FindByID(ids[0]).Inputs, 1)
+
+	data, err := json.Marshal(s.FindByID(ids[0]))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"inputs"`)
+	require.NotContains(t, string(data), `"stream"`)
+}
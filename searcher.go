@@ -2,8 +2,11 @@ package stuber
 
 import (
 	"errors"
+	"log/slog"
 	"sync"
+	"time"
 
+	"github.com/bavix/features"
 	"github.com/google/uuid"
 	"golang.org/x/exp/maps"
 )
@@ -17,14 +20,45 @@ var ErrMethodNotFound = errors.New("method not found")
 // ErrStubNotFound is returned when the stub is not found.
 var ErrStubNotFound = errors.New("stub not found")
 
+// errStubUsedUp is returned by claim when a concurrent search used up the
+// found Stub value first.
+var errStubUsedUp = errors.New("stub used up")
+
+// maxSearchAttempts is the number of times a search evaluates the Stub values
+// when concurrent searches use up the found ones first.
+const maxSearchAttempts = 8
+
 // searcher is a struct that manages the storage of search results.
 //
 // It contains a mutex for concurrent access, a map to store and retrieve
 // used stubs by their UUID, and a pointer to the storage struct.
 type searcher struct {
 	mu       sync.RWMutex // mutex for concurrent access
-	stubUsed map[uuid.UUID]struct{}
-	// map to store and retrieve used stubs by their UUID
+	stubUsed map[uuid.UUID]stubUsage
+	// map to store and retrieve the usage of the used stubs by their UUID
+
+	violations []OrderViolation  // order violations of ordered groups
+	scenarios  map[string]string // states of the scenarios, by name
+	pins       map[string][]pin  // stubs answering the next calls, by service and method
+	generation uint64            // number of clears, which invalidate the bidi streams
+
+	rank    RankFunc         // ranking strategy of the candidates
+	toggles features.Toggles // global feature flags, which stubs may override
+	now     func() time.Time // clock of the stub timestamps
+
+	cache        preparedCache // stubs prepared for comparisons
+	parallel     parallelism   // parallel evaluation of large buckets
+	shortCircuit bool          // whether the search stops at a perfect match
+	customRank   bool          // whether the rank is not the default one
+	similars     int           // number of similar stubs tracked by a search
+	revisions    *revisions    // last revisions of the stubs
+	events       *eventBus     // subscriptions to the changes and matches
+	persistence  persistence   // backend the changes are written through to
+	slowLog      *slowLog      // last searches slower than a threshold
+	scanLimit    int           // number of stubs evaluated by FindAnywhere, or 0
+
+	recoverPanics bool         // whether panics of stub evaluations are recovered
+	logger        *slog.Logger // logger of the recovered panics
 
 	storage *storage // pointer to the storage struct
 }
@@ -36,8 +70,21 @@ type searcher struct {
 // Returns a pointer to the newly created searcher struct.
 func newSearcher() *searcher {
 	return &searcher{
-		storage:  newStorage(),
-		stubUsed: make(map[uuid.UUID]struct{}),
+		storage:   newStorage(),
+		stubUsed:  make(map[uuid.UUID]stubUsage),
+		scenarios: make(map[string]string),
+		pins:      make(map[string][]pin),
+		rank:      rankMatch,
+		parallel:  defaultParallelism(),
+		similars:  defaultSimilarLimit,
+		revisions: newRevisions(),
+		events:    newEventBus(),
+		slowLog:   newSlowLog(),
+		scanLimit: DefaultScanLimit,
+		now:       time.Now,
+
+		recoverPanics: true,
+		logger:        discardLogger(),
 	}
 }
 
@@ -47,8 +94,30 @@ func newSearcher() *searcher {
 // match found in the search, while similar represents the most similar match
 // found.
 type Result struct {
-	found   *Stub // The exact match found in the search
-	similar *Stub // The most similar match found
+	found    *Stub   // The exact match found in the search
+	similar  *Stub   // The most similar match found
+	similars []*Stub // The most similar matches found, in descending rank
+	rank     float64 // The rank of the exact match
+	release  func()  // Releases the concurrency slots taken by the match, if any
+}
+
+// NewResult creates a Result with the given exact match and most similar
+// matches, such as the result of a search made elsewhere.
+//
+// Parameters:
+// - found: The exact match, or nil.
+// - similars: The most similar matches, in descending rank.
+//
+// Returns:
+// - *Result: A new Result.
+func NewResult(found *Stub, similars ...*Stub) *Result {
+	result := &Result{found: found, similars: similars}
+
+	if len(similars) > 0 {
+		result.similar = similars[0]
+	}
+
+	return result
 }
 
 // Found returns the exact match found in the search.
@@ -58,6 +127,18 @@ func (r *Result) Found() *Stub {
 	return r.found
 }
 
+// Done reports that the call answered with the exact match is over, which
+// releases the concurrency slots it holds. It is safe to call more than once
+// and on results holding no slot.
+//
+// The embedding server should call it once each call is answered, see
+// ConcurrencyLimit and WithCallTracking.
+func (r *Result) Done() {
+	if r != nil && r.release != nil {
+		r.release()
+	}
+}
+
 // Similar returns the most similar match found in the search.
 //
 // Returns a pointer to the Stub struct representing the similar match.
@@ -65,20 +146,179 @@ func (r *Result) Similar() *Stub {
 	return r.similar
 }
 
+// Similars returns the most similar matches found in the search, in
+// descending rank. Their number is limited by WithSimilarLimit.
+//
+// The search may stop at a perfect match before ranking all the stubs when
+// the rank short-circuit is enabled, in which case only the ranked stubs are
+// returned.
+//
+// Returns a slice of pointers to the Stub structs of the similar matches.
+func (r *Result) Similars() []*Stub {
+	return r.similars
+}
+
 // upsert inserts the given stub values into the searcher. If a stub value
 // already exists with the same key, it is updated.
 //
 // The function returns a slice of UUIDs representing the keys of the
 // inserted or updated values.
 func (s *searcher) upsert(values ...*Stub) []uuid.UUID {
+	defer s.cache.invalidate()
+
+	s.persistence.Lock()
+
+	ids := s.insert(values)
+
+	s.write("put", func(backend Backend) error { return backend.Put(values) })
+	s.persistence.Unlock()
+
+	s.events.publish(EventPut, values...)
+
+	return ids
+}
+
+// insert stores the given stub values with their timestamps and revisions,
+// without writing them to the backend nor publishing their events.
+//
+// The persistence mutex must be held.
+func (s *searcher) insert(values []*Stub) []uuid.UUID {
+	s.touch(values)
+	s.revisions.record(values...)
+
 	return s.storage.upsert(s.castToValue(values)...)
 }
 
+// touch sets the timestamps of the given stub values before their insertion.
+//
+// Stub values replacing a stored one keep its creation time, and new ones
+// keep the creation time they were given, if any.
+func (s *searcher) touch(values []*Stub) {
+	now := s.now()
+
+	for _, value := range values {
+		// Each stub value has its own timestamps, which may be modified.
+		created, updated := now, now
+
+		if stored := s.findByID(value.ID); stored != nil && stored.CreatedAt != nil {
+			created = *stored.CreatedAt
+			value.CreatedAt = &created
+		} else if value.CreatedAt == nil {
+			value.CreatedAt = &created
+		}
+
+		value.UpdatedAt = &updated
+	}
+}
+
 // del deletes the stub values with the given UUIDs from the searcher.
 //
 // Returns the number of stub values that were successfully deleted.
 func (s *searcher) del(ids ...uuid.UUID) int {
-	return s.storage.del(ids...)
+	return s.remove(EventDelete, ids)
+}
+
+// remove deletes the stub values with the given UUIDs from the searcher,
+// publishing events of the given type.
+//
+// Returns the number of stub values that were successfully deleted.
+func (s *searcher) remove(typ EventType, ids []uuid.UUID) int {
+	defer s.cache.invalidate()
+
+	// Look the stub values up for their events only if they are published.
+	var deleted []*Stub
+	if s.events.active() {
+		deleted = s.castToStub(s.storage.findByIDs(ids...))
+	}
+
+	s.persistence.Lock()
+	n := s.storage.del(ids...)
+	s.write("delete", func(backend Backend) error { return backend.Delete(ids) })
+	s.persistence.Unlock()
+
+	s.events.publish(typ, deleted...)
+
+	return n
+}
+
+// deleteWhere deletes the stub values accepted by the given predicate from
+// the searcher.
+//
+// Returns the number of stub values that were deleted.
+func (s *searcher) deleteWhere(pred func(*Stub) bool) int {
+	defer s.cache.invalidate()
+
+	var deleted []*Stub
+
+	s.persistence.Lock()
+
+	n := s.storage.delWhere(func(v Value) bool {
+		stub, ok := v.(*Stub)
+		if ok && pred(stub) {
+			deleted = append(deleted, stub)
+
+			return true
+		}
+
+		return false
+	})
+
+	if len(deleted) > 0 {
+		s.write("delete", func(backend Backend) error { return backend.Delete(stubIDs(deleted)) })
+	}
+
+	s.persistence.Unlock()
+
+	s.events.publish(EventDelete, deleted...)
+
+	return n
+}
+
+// apply inserts the given stub values and deletes the stub values with the
+// given UUIDs under a single persistence lock, inserting first so that the
+// searches never see an empty store in between.
+func (s *searcher) apply(values []*Stub, ids []uuid.UUID) {
+	defer s.cache.invalidate()
+
+	// Look the stub values up for their events only if they are published.
+	var deleted []*Stub
+	if s.events.active() {
+		deleted = s.castToStub(s.storage.findByIDs(ids...))
+	}
+
+	s.persistence.Lock()
+
+	if len(values) > 0 {
+		s.touch(values)
+		s.revisions.record(values...)
+		s.storage.upsert(s.castToValue(values)...)
+		s.write("put", func(backend Backend) error { return backend.Put(values) })
+	}
+
+	if len(ids) > 0 {
+		s.storage.del(ids...)
+		s.write("delete", func(backend Backend) error { return backend.Delete(ids) })
+	}
+
+	s.persistence.Unlock()
+
+	if len(values) > 0 {
+		s.events.publish(EventPut, values...)
+	}
+
+	if len(deleted) > 0 {
+		s.events.publish(EventDelete, deleted...)
+	}
+}
+
+// reindex rebuilds the positions of the stored stub values, dropping the
+// stale and duplicate entries.
+//
+// Returns the number of dropped entries.
+func (s *searcher) reindex() int {
+	defer s.cache.invalidate()
+
+	return s.storage.reindex()
 }
 
 // findByID retrieves the stub value associated with the given ID from the
@@ -123,10 +363,31 @@ func (s *searcher) clear() {
 	defer s.mu.Unlock()
 
 	// Clear the stubUsed map.
-	s.stubUsed = make(map[uuid.UUID]struct{})
+	s.stubUsed = make(map[uuid.UUID]stubUsage)
+
+	// Clear the order violations.
+	s.violations = nil
+
+	// Reset the scenarios.
+	s.scenarios = make(map[string]string)
+
+	// Forget the pins.
+	s.pins = make(map[string][]pin)
 
-	// Clear the storage.
+	// Invalidate the bidi streams.
+	s.generation++
+
+	// Clear the storage and the backend.
+	s.persistence.Lock()
 	s.storage.clear()
+	s.write("clear", Backend.Clear)
+	s.persistence.Unlock()
+
+	// Clear the prepared stubs.
+	s.cache.invalidate()
+
+	// Clear the revisions.
+	s.revisions.reset()
 }
 
 // all returns all Stub values stored in the searcher.
@@ -213,10 +474,12 @@ func (s *searcher) searchByID(service, method string, query Query) (*Result, err
 	// Search for the Stub value with the given ID.
 	if found := s.findByID(*query.ID); found != nil {
 		// Mark the Stub value as used.
-		s.mark(query, *query.ID)
+		if err := s.mark(query, found); err != nil {
+			return nil, err
+		}
 
-		// Return the found Stub value.
-		return &Result{found: found}, nil
+		// Return the found Stub value merged with its base stubs.
+		return &Result{found: s.resolve(found)}, nil
 	}
 
 	// Return an error if the Stub value is not found.
@@ -232,72 +495,399 @@ func (s *searcher) searchByID(service, method string, query Query) (*Result, err
 // - *Result: The Result containing the found Stub value (if any), or nil.
 // - error: An error if the search fails.
 func (s *searcher) search(query Query) (*Result, error) {
-	// Find all Stub values with the given service and method.
-	stubs, err := s.findBy(query.Service, query.Method)
+	// Find all Stub values with the given service and method, in a pooled buffer.
+	values, err := s.storage.findAll(query.Service, query.Method)
 	if err != nil {
 		return nil, s.wrap(err)
 	}
 
-	// Initialize variables to store the found and similar Stub values.
+	buf := getStubs()
+	defer putStubs(buf)
+
+	*buf = s.appendStubs(*buf, values)
+	stubs := *buf
+
+	if query.candidates != nil {
+		*query.candidates = len(stubs)
+	}
+
+	// Record the search in the slow log if it takes too long.
 	var (
-		found       *Stub
-		foundRank   float64
-		similar     *Stub
-		similarRank float64
+		start     = time.Now()
+		evaluated int
+		result    *Result
 	)
 
-	// Iterate over the found Stub values.
-	for _, stub := range stubs {
-		// Calculate the rank of the current Stub value.
-		current := rankMatch(query, stub)
+	defer func() {
+		if !s.slowLog.enabled() {
+			return
+		}
+
+		slow := SlowMatch{
+			Service:    query.Service,
+			Method:     query.Method,
+			Duration:   time.Since(start),
+			BucketSize: len(stubs),
+			Candidates: evaluated,
+		}
+
+		if result != nil && result.found != nil {
+			slow.StubID = &result.found.ID
+		}
+
+		s.slowLog.record(slow)
+	}()
+
+	// Normalize the query once for all the comparisons.
+	query = normalizeQuery(query)
+
+	pins := s.pinsOf(query.Service, query.Method)
+
+	// Find the rank of a perfect match at the top priority of the bucket, for
+	// the rank short-circuit, which would miss the pinned stubs.
+	top, perfect, shortCircuit := s.perfectRank(query, stubs)
+	shortCircuit = shortCircuit && len(pins) == 0
+
+	// Evaluate the found Stub values, in parallel for large buckets.
+	evaluate, release := s.candidates(query, stubs)
+	defer release()
+
+	for attempt := 1; ; attempt++ {
+		c := s.choose(query, stubs, evaluate, pins, top, perfect, shortCircuit)
+		evaluated += c.evaluated
+
+		var (
+			found *Stub
+			rank  float64
+			err   error
+		)
 
-		// Update the similar Stub value if the current rank is higher.
-		if current > similarRank {
-			similar = stub
-			similarRank = current
+		// A pinned Stub value answers in place of the found one, unless its
+		// pin was used up by a concurrent search.
+		if c.pinned != nil {
+			found, rank = c.pinned, c.pinnedRank
+			err = s.claimPinned(query, c.pinned)
 		}
 
-		// Update the found Stub value if the current Stub value matches the query and has a higher rank.
-		if match(query, stub) && current > foundRank {
-			found = stub
-			foundRank = current
+		if c.pinned == nil || errors.Is(err, errPinUsedUp) {
+			found, rank, err = c.found, c.rank, nil
+
+			if found != nil {
+				err = s.claim(query, found, c.sequenced)
+			}
+		}
+
+		// If a found Stub value is found, return it once marked as used.
+		if found != nil {
+			// Search again, the Stub value not being ready anymore, unless a
+			// concurrent search keeps using up the found Stub values.
+			if errors.Is(err, errStubUsedUp) && attempt < maxSearchAttempts {
+				continue
+			}
+
+			if errors.Is(err, errStubUsedUp) {
+				return nil, ErrStubNotFound
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			result = &Result{found: found, rank: rank, similars: c.similar.stubs()}
+
+			return result, nil
+		}
+
+		// If the query only matches a stub of an ordered group out of order, record the violation.
+		if c.outOfOrder != nil {
+			s.violate(query, c.outOfOrder, c.heads[c.outOfOrder.OrderedGroup])
+		}
+
+		// If no found Stub value is found, return the similar Stub value.
+		if c.similar.best() == nil {
+			return nil, ErrStubNotFound
+		}
+
+		return &Result{found: nil, similar: c.similar.best(), similars: c.similar.stubs()}, nil
+	}
+}
+
+// choice is the outcome of an evaluation of the Stub values of a search.
+type choice struct {
+	found      *Stub                // The best ready match, if any.
+	rank       float64              // The rank of the found Stub value.
+	sequenced  bool                 // Whether the found Stub value is the next of its ordered group.
+	similar    topSimilar           // The most similar Stub values.
+	outOfOrder *Stub                // The first match of an ordered group out of order, if any.
+	heads      map[string]uuid.UUID // The next Stub values of the ordered groups met.
+	pinned     *Stub                // The first pinned ready match, if any.
+	pinnedRank float64              // The rank of the pinned Stub value.
+	evaluated  int                  // The number of evaluated Stub values.
+}
+
+// choose picks the best ready match among the evaluated Stub values, along
+// with the pinned match and the most similar Stub values.
+//
+// The search stops at a match ranking at least the perfect rank at the top
+// priority if the short-circuit is enabled.
+func (s *searcher) choose(
+	query Query,
+	stubs []*Stub,
+	evaluate func(int) candidate,
+	pins map[uuid.UUID]int,
+	top int,
+	perfect float64,
+	shortCircuit bool,
+) choice {
+	var (
+		c           = choice{similar: newTopSimilar(s.similars), heads: make(map[string]uuid.UUID)}
+		pinnedOrder int
+	)
+
+	// Iterate over the evaluated Stub values in order.
+	for i := range stubs {
+		current := evaluate(i)
+		c.evaluated++
+
+		if !current.valid {
+			continue
+		}
+
+		stub := current.stub
+
+		// Track the Stub value if it is among the most similar ones,
+		// unless the query only accepts exact matches.
+		if !query.ExactOnly() {
+			c.similar.add(stub, current.rank)
+		}
+
+		// Pinned stubs win regardless of priority and rank, the first pinned first.
+		if order, ok := pins[stub.ID]; ok && current.matched && (c.pinned == nil || order < pinnedOrder) && s.ready(stub) {
+			c.pinned, c.pinnedRank, pinnedOrder = stub, current.rank, order
+		}
+
+		// Stubs of an ordered group are only found when they are next in their group,
+		// in which case they win regardless of priority and rank.
+		if stub.OrderedGroup != "" {
+			head, ok := c.heads[stub.OrderedGroup]
+			if !ok {
+				head = s.groupHead(stub.OrderedGroup)
+				c.heads[stub.OrderedGroup] = head
+			}
+
+			if current.matched && s.ready(stub) {
+				if stub.ID == head {
+					c.found = stub
+					c.rank = current.rank
+					c.sequenced = true
+				} else if c.outOfOrder == nil {
+					c.outOfOrder = stub
+				}
+			}
+
+			continue
+		}
+
+		// Update the found Stub value if the current Stub value matches the query and
+		// has a higher priority, or the same priority and a higher rank.
+		// Stubs whose dependencies have not been used yet cannot be found.
+		if !c.sequenced && current.matched && outranks(current, c.found, c.rank) && s.ready(stub) {
+			c.found = stub
+			c.rank = current.rank
+
+			// Stop at a perfect match, which no remaining stub can outrank.
+			if shortCircuit && stub.Priority == top && c.rank >= perfect {
+				break
+			}
 		}
 	}
 
-	// If a found Stub value is found, mark it as used and return it.
-	if found != nil {
-		s.mark(query, found.ID)
+	return c
+}
+
+// match checks if the given query matches the given Stub value, honoring the
+// feature flags of the searcher and the overrides of the Stub value.
+//
+// Parameters:
+// - query: The query to check.
+// - stub: The Stub value to check.
+//
+// Returns:
+// - bool: True if the query matches the Stub value, otherwise false.
+func (s *searcher) match(query Query, stub *Stub) bool {
+	if stub.enabled(StrictFields, s.toggles) && !strictFields(query, stub) {
+		return false
+	}
+
+	return match(query, stub)
+}
+
+// ready checks if all the stubs the given Stub value depends on have been used,
+// if its scenario is in the state it requires, and if it has calls left.
+//
+// Parameters:
+// - stub: The Stub value to check.
+//
+// Returns:
+// - bool: True if the Stub value has no unused dependencies, its scenario
+// is in the required state and it was matched fewer times than its MaxCalls,
+// otherwise false.
+func (s *searcher) ready(stub *Stub) bool {
+	if len(stub.DependsOn) == 0 && stub.RequiredState == "" && stub.MaxCalls <= 0 {
+		return true
+	}
 
-		return &Result{found: found}, nil
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.inState(stub) || s.exhausted(stub) {
+		return false
 	}
 
-	// If no found Stub value is found, return the similar Stub value.
-	if similar == nil {
-		return nil, ErrStubNotFound
+	for _, id := range stub.DependsOn {
+		if _, ok := s.stubUsed[id]; !ok {
+			return false
+		}
 	}
 
-	return &Result{found: nil, similar: similar}, nil
+	return true
 }
 
-// mark marks the given Stub value as used in the searcher.
+// exhausted checks if the given Stub value was matched as many times as its
+// MaxCalls, if any.
+//
+// The mutex of the searcher must be held.
+func (s *searcher) exhausted(stub *Stub) bool {
+	return stub.MaxCalls > 0 && s.stubUsed[stub.ID].hits >= uint64(stub.MaxCalls)
+}
+
+// mark marks the given Stub value as used in the searcher, counting the hit
+// and its time, and moves its scenario to its new state, if any.
+//
+// If the query's RequestInternal flag is set, the mark is skipped. If the
+// query is of a bidi stream whose stubs were cleared since it started,
+// nothing is marked: the check is made under the mutex, so a clear happens
+// either before it or after the mark.
+//
+// Parameters:
+// - query: The query used to mark the Stub value.
+// - stub: The Stub value to mark.
 //
-// If the query's RequestInternal flag is set, the mark is skipped.
+// Returns:
+// - error: ErrBidiInvalidated if the stubs of the query were cleared.
+func (s *searcher) mark(query Query, stub *Stub) error {
+	return s.markIf(query, stub, false, nil, false)
+}
+
+// claim is mark for a Stub value found by a search, checking again that it
+// is ready: ready only holds the read lock, so concurrent searches may find
+// a Stub value with a single call left, the same transition of a scenario,
+// or the same next stub of an ordered group, and only the first one to claim
+// it uses it.
 //
 // Parameters:
 // - query: The query used to mark the Stub value.
-// - id: The UUID of the Stub value to mark.
-func (s *searcher) mark(query Query, id uuid.UUID) {
+// - stub: The Stub value to mark.
+// - sequenced: Whether the Stub value was found as the next of its ordered group.
+//
+// Returns:
+// - error: ErrBidiInvalidated if the stubs of the query were cleared, or
+// errStubUsedUp if the Stub value is not ready anymore.
+func (s *searcher) claim(query Query, stub *Stub, sequenced bool) error {
+	var group []*Stub
+	if sequenced {
+		group = s.groups()[stub.OrderedGroup]
+	}
+
+	return s.markIf(query, stub, true, group, false)
+}
+
+// claimPinned is claim for a pinned Stub value, which also counts the call
+// against its pin: the pin is only used if the Stub value is claimed.
+//
+// Returns:
+// - error: ErrBidiInvalidated if the stubs of the query were cleared,
+// errPinUsedUp if the Stub value is not pinned anymore, or errStubUsedUp if
+// it is not ready anymore.
+func (s *searcher) claimPinned(query Query, stub *Stub) error {
+	return s.markIf(query, stub, true, nil, true)
+}
+
+// markIf marks the given Stub value as used, checking first that it is still
+// ready if asked to, still the next of the given stubs of its ordered group,
+// if any, and still pinned if asked to, in which case its pin is used.
+func (s *searcher) markIf(query Query, stub *Stub, ready bool, group []*Stub, pinned bool) error {
+	now := s.now()
+
+	// Lock the mutex to ensure concurrent access.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stale(query) {
+		return ErrBidiInvalidated
+	}
+
+	pin := -1
+	if pinned {
+		if pin = s.pinIndex(stub); pin < 0 {
+			return errPinUsedUp
+		}
+	}
+
 	// If the query's RequestInternal flag is set, skip the mark.
 	if query.RequestInternal() {
-		return
+		return nil
 	}
 
-	// Lock the mutex to ensure concurrent access.
+	if ready && (!s.inState(stub) || s.exhausted(stub)) {
+		return errStubUsedUp
+	}
+
+	if group != nil && s.headOf(group) != stub.ID {
+		return errStubUsedUp
+	}
+
+	if pin >= 0 {
+		s.usePin(pinKey(stub.Service, stub.Method), pin)
+	}
+
+	// Mark the Stub value as used by counting the hit in the stubUsed map.
+	usage := s.stubUsed[stub.ID]
+	usage.hits++
+	usage.lastHit = now
+	s.stubUsed[stub.ID] = usage
+
+	s.transition(stub)
+
+	return nil
+}
+
+// markUsed marks the Stub values with the given IDs as used, such as by
+// another instance. The Stub values not used yet count a single hit, whose
+// time is unknown.
+//
+// Parameters:
+// - ids: The UUIDs of the Stub values to mark.
+func (s *searcher) markUsed(ids ...uuid.UUID) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Mark the Stub value as used by adding it to the stubUsed map.
-	s.stubUsed[id] = struct{}{}
+	for _, id := range ids {
+		if _, ok := s.stubUsed[id]; !ok {
+			s.stubUsed[id] = stubUsage{hits: 1}
+		}
+	}
+}
+
+// usedIDs returns the IDs of the Stub values that have been used.
+//
+// Returns:
+// - []uuid.UUID: The UUIDs of the used Stub values.
+func (s *searcher) usedIDs() []uuid.UUID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Keys(s.stubUsed)
 }
 
 // castToValue converts a slice of *Stub values to a slice of Value interface{}.
@@ -324,15 +914,26 @@ func (s *searcher) castToValue(values []*Stub) []Value {
 // Returns:
 // - A slice of *Stub containing the converted values.
 func (s *searcher) castToStub(values []Value) []*Stub {
-	ret := make([]*Stub, 0, len(values))
+	return s.appendStubs(make([]*Stub, 0, len(values)), values)
+}
 
+// appendStubs appends the *Stub values of a slice of Value interface{} to
+// the given slice of *Stub.
+//
+// Parameters:
+// - dst: The slice to append to.
+// - values: A slice of Value interface{} to convert.
+//
+// Returns:
+// - The extended slice of *Stub.
+func (s *searcher) appendStubs(dst []*Stub, values []Value) []*Stub {
 	for _, v := range values {
 		if s, ok := v.(*Stub); ok {
-			ret = append(ret, s)
+			dst = append(dst, s)
 		}
 	}
 
-	return ret
+	return dst
 }
 
 // wrap wraps an error with specific error types.
@@ -0,0 +1,83 @@
+package stuber
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+)
+
+// SelfTestFailure describes an example of a stub that does not match it.
+type SelfTestFailure struct {
+	Stub    uuid.UUID `json:"stub"`            // The stub declaring the example.
+	Example int       `json:"example"`         // The index of the example in the stub.
+	Matched uuid.UUID `json:"matched"`         // The stub matched instead, or uuid.Nil.
+	Error   string    `json:"error,omitempty"` // The error of the search, if any.
+}
+
+// SelfTest searches the examples of every stored Stub value and reports the
+// ones which don't match their own Stub value, such as after a refactoring
+// of the stubs made a broader stub win.
+//
+// Stub values without examples are checked against the request generated
+// by GenerateExample, if they have matchers.
+//
+// The searches are internal: they don't mark Stub values as used and don't
+// trigger rate limits, chaos, hooks or the remote source. Stubs whose
+// dependencies or ordered group are not satisfied yet are reported too.
+//
+// Returns:
+// - []SelfTestFailure: The failing examples, or nil.
+func (b *Budgerigar) SelfTest() []SelfTestFailure {
+	var failures []SelfTestFailure
+
+	for _, stub := range b.searcher.all() {
+		if stub.Abstract {
+			continue
+		}
+
+		examples := stub.Examples
+		if len(examples) == 0 {
+			if resolved := b.searcher.resolve(stub); hasMatchers(resolved) {
+				examples = []Example{GenerateExample(resolved)}
+			}
+		}
+
+		for i, example := range examples {
+			result, err := b.searcher.find(Query{
+				Service: stub.Service,
+				Method:  stub.Method,
+				Headers: example.Headers,
+				Data:    example.Data,
+				toggles: features.New(RequestInternalFlag),
+			})
+
+			switch {
+			case err != nil:
+				failures = append(failures, SelfTestFailure{Stub: stub.ID, Example: i, Error: err.Error()})
+			case result.found == nil:
+				failures = append(failures, SelfTestFailure{Stub: stub.ID, Example: i})
+			case result.found.ID != stub.ID:
+				failures = append(failures, SelfTestFailure{Stub: stub.ID, Example: i, Matched: result.found.ID})
+			}
+		}
+	}
+
+	slices.SortFunc(failures, func(a, b SelfTestFailure) int {
+		return cmp.Or(cmp.Compare(a.Stub.String(), b.Stub.String()), cmp.Compare(a.Example, b.Example))
+	})
+
+	return failures
+}
+
+// hasMatchers checks if the stub has any input or header matcher.
+func hasMatchers(stub *Stub) bool {
+	for _, m := range []inputMatchers{stub.Input, stub.Headers} {
+		if len(m.GetEquals())+len(m.GetContains())+len(m.GetMatches()) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_SelfTest(t *testing.T) {
+	s := stuber.New()
+
+	specific := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Greeter",
+		Method:   "SayHello",
+		Input:    stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Examples: []stuber.Example{{Data: map[string]interface{}{"name": "Bob"}}},
+	}
+	broad := &stuber.Stub{
+		ID:       uuid.New(),
+		Service:  "Greeter",
+		Method:   "SayHello",
+		Priority: 1,
+		Input:    stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		Examples: []stuber.Example{
+			{Data: map[string]interface{}{"name": "Bob", "age": 1}},
+			{Data: map[string]interface{}{"name": "Alice"}},
+		},
+	}
+
+	s.PutMany(specific, broad)
+
+	failures := s.SelfTest()
+	require.Len(t, failures, 2)
+
+	for _, failure := range failures {
+		switch failure.Stub {
+		case specific.ID:
+			require.Equal(t, broad.ID, failure.Matched)
+		case broad.ID:
+			require.Equal(t, 1, failure.Example)
+			require.Equal(t, uuid.Nil, failure.Matched)
+		}
+	}
+
+	require.Empty(t, s.Used())
+}
@@ -0,0 +1,172 @@
+package stuber
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// OrderViolation describes a call that matched a stub of an ordered group
+// before the stubs preceding it in the group were used.
+type OrderViolation struct {
+	Group    string    `json:"group"`    // The name of the ordered group.
+	Service  string    `json:"service"`  // The service of the call.
+	Method   string    `json:"method"`   // The method of the call.
+	Expected uuid.UUID `json:"expected"` // The stub expected to be called next, or uuid.Nil if the group is exhausted.
+	Actual   uuid.UUID `json:"actual"`   // The stub matched out of order.
+}
+
+// maxOrderViolations is the number of order violations kept, the oldest
+// ones being dropped first.
+const maxOrderViolations = 1000
+
+// groupHead returns the ID of the first unused stub of the given ordered group.
+//
+// Stubs are ordered by GroupOrder, then by ID. uuid.Nil is returned when every
+// stub of the group has been used.
+func (s *searcher) groupHead(group string) uuid.UUID {
+	stubs := s.groups()[group]
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.headOf(stubs)
+}
+
+// headOf returns the ID of the first unused stub of the given stubs of an
+// ordered group, in their order, or uuid.Nil if they have all been used.
+//
+// The mutex of the searcher must be held.
+func (s *searcher) headOf(stubs []*Stub) uuid.UUID {
+	for _, stub := range stubs {
+		if _, ok := s.stubUsed[stub.ID]; !ok {
+			return stub.ID
+		}
+	}
+
+	return uuid.Nil
+}
+
+// compareGroupOrder compares the stubs by their order in their group.
+func compareGroupOrder(a, b *Stub) int {
+	return cmp.Or(cmp.Compare(a.GroupOrder, b.GroupOrder), cmp.Compare(a.ID.String(), b.ID.String()))
+}
+
+// groups returns the stubs of the ordered groups, by group, in their order:
+// by GroupOrder, then by ID.
+//
+// The groups are indexed once per change of the stubs, and shared by the
+// searches: they must not be modified.
+func (s *searcher) groups() map[string][]*Stub {
+	s.cache.mu.Lock()
+	groups, gen := s.cache.groups, s.cache.gen
+	s.cache.mu.Unlock()
+
+	if groups != nil {
+		return groups
+	}
+
+	groups = make(map[string][]*Stub)
+
+	for _, stub := range s.all() {
+		if stub.OrderedGroup != "" {
+			groups[stub.OrderedGroup] = append(groups[stub.OrderedGroup], stub)
+		}
+	}
+
+	for _, stubs := range groups {
+		slices.SortFunc(stubs, compareGroupOrder)
+	}
+
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	// Skip the cache if a write happened while the groups were indexed.
+	if s.cache.gen == gen {
+		s.cache.groups = groups
+	}
+
+	return groups
+}
+
+// groupPositions returns the positions of the ordered groups, by group: the
+// number of stubs of the group before its first unused one.
+func (s *searcher) groupPositions() map[string]int {
+	positions := make(map[string]int)
+
+	for group, stubs := range s.groups() {
+		position := slices.IndexFunc(stubs, func(stub *Stub) bool { return !s.isUsed(stub.ID) })
+		if position < 0 {
+			position = len(stubs)
+		}
+
+		positions[group] = position
+	}
+
+	return positions
+}
+
+// seekGroups marks the stubs of the ordered groups before the given
+// positions as used, so the groups go on from the positions. The stubs not
+// used yet count a single hit, whose time is unknown.
+func (s *searcher) seekGroups(positions map[string]int) {
+	for group, stubs := range s.groups() {
+		position := min(max(positions[group], 0), len(stubs))
+
+		ids := make([]uuid.UUID, position)
+		for i, stub := range stubs[:position] {
+			ids[i] = stub.ID
+		}
+
+		s.markUsed(ids...)
+	}
+}
+
+// isUsed checks if the stub with the given ID has been used.
+func (s *searcher) isUsed(id uuid.UUID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.stubUsed[id]
+
+	return ok
+}
+
+// violate records an order violation for the given query.
+//
+// Internal queries and the queries of invalidated bidi streams never record
+// violations.
+func (s *searcher) violate(query Query, stub *Stub, expected uuid.UUID) {
+	if query.RequestInternal() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stale(query) {
+		return
+	}
+
+	// Drop the oldest violation once the kept ones reach the limit.
+	if len(s.violations) >= maxOrderViolations {
+		s.violations = slices.Delete(s.violations, 0, len(s.violations)-maxOrderViolations+1)
+	}
+
+	s.violations = append(s.violations, OrderViolation{
+		Group:    stub.OrderedGroup,
+		Service:  query.Service,
+		Method:   query.Method,
+		Expected: expected,
+		Actual:   stub.ID,
+	})
+}
+
+// orderViolations returns a copy of the recorded order violations.
+func (s *searcher) orderViolations() []OrderViolation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.violations)
+}
@@ -0,0 +1,131 @@
+package stuber_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_OrderedGroup(t *testing.T) {
+	s := stuber.NewBudgerigar(features.New())
+
+	login := &stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Login", OrderedGroup: "session", GroupOrder: 1}
+	fetch := &stuber.Stub{ID: uuid.New(), Service: "Data", Method: "Fetch", OrderedGroup: "session", GroupOrder: 2}
+	logout := &stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Logout", OrderedGroup: "session", GroupOrder: 3}
+
+	// A better ranked stub outside of the group must not win over the group.
+	other := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Auth",
+		Method:  "Login",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"user": "admin"}},
+	}
+
+	s.PutMany(logout, fetch, login, other)
+
+	r, err := s.FindByQuery(stuber.Query{Service: "Data", Method: "Fetch"})
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+
+	violations := s.OrderViolations()
+	require.Len(t, violations, 1)
+	require.Equal(t, "session", violations[0].Group)
+	require.Equal(t, login.ID, violations[0].Expected)
+	require.Equal(t, fetch.ID, violations[0].Actual)
+
+	for _, expected := range []*stuber.Stub{login, fetch, logout} {
+		r, err = s.FindByQuery(stuber.Query{
+			Service: expected.Service,
+			Method:  expected.Method,
+			Data:    map[string]interface{}{"user": "admin"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected.ID, r.Found().ID)
+	}
+
+	require.Len(t, s.OrderViolations(), 1)
+
+	s.Clear()
+	require.Empty(t, s.OrderViolations())
+}
+
+func TestBudgerigar_OrderedGroup_Concurrent(t *testing.T) {
+	const searches = 4
+
+	stubs := make([]*stuber.Stub, searches)
+	for i := range stubs {
+		stubs[i] = &stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Login", OrderedGroup: "session", GroupOrder: i}
+	}
+
+	// The searches wait for each other once the last stub is ranked, after
+	// the head of the group is known, so they all find the same head at once.
+	var (
+		ranked  sync.WaitGroup
+		waiting atomic.Int32
+	)
+
+	ranked.Add(searches)
+
+	s := stuber.New(stuber.WithRanker(func(query stuber.Query, stub *stuber.Stub) float64 {
+		if stub.ID == stubs[searches-1].ID && waiting.Add(1) <= searches {
+			ranked.Done()
+			ranked.Wait()
+		}
+
+		return stuber.DefaultRank(query, stub) + 1
+	}))
+	s.PutMany(stubs...)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		found = make(map[uuid.UUID]int)
+	)
+
+	for range searches {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result, err := s.FindByQuery(stuber.Query{Service: "Auth", Method: "Login"})
+			if err != nil || result.Found() == nil {
+				return
+			}
+
+			mu.Lock()
+			found[result.Found().ID]++
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	// Each search consumes the next stub of the group.
+	require.Len(t, found, searches)
+
+	for _, stub := range stubs {
+		require.Equal(t, 1, found[stub.ID])
+	}
+}
+
+func TestBudgerigar_OrderViolations_Limit(t *testing.T) {
+	s := stuber.New()
+
+	s.PutMany(
+		&stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Login", OrderedGroup: "session", GroupOrder: 1},
+		&stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Logout", OrderedGroup: "session", GroupOrder: 2},
+	)
+
+	for range 1100 {
+		_, _ = s.FindByQuery(stuber.Query{Service: "Auth", Method: "Logout"})
+	}
+
+	require.Len(t, s.OrderViolations(), 1000)
+}
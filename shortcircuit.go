@@ -0,0 +1,177 @@
+package stuber
+
+import (
+	"math"
+	"reflect"
+)
+
+// rankBounds are the upper bounds of the default ranks of the stubs of a
+// bucket at its top priority.
+type rankBounds struct {
+	top    int           // The top priority of the stubs of the bucket.
+	bounds [2][2]float64 // The highest bound, by whether the data then the headers of the query are empty.
+	usable bool          // Whether the short-circuit may be used for the bucket.
+}
+
+// perfectRank returns the top priority of the given Stub values of the
+// service and method of the query, and the rank from which a match at this
+// priority is perfect: no other Stub value can rank higher, so the search may
+// stop without changing the found Stub value.
+//
+// It returns false if the short-circuit is not enabled, if the rank is not
+// the default one, whose bounds are known, or if the bucket contains ordered
+// groups, whose next stub wins regardless of rank.
+func (s *searcher) perfectRank(query Query, stubs []*Stub) (int, float64, bool) {
+	if !s.shortCircuit || s.customRank {
+		return 0, 0, false
+	}
+
+	bounds := s.bucketBounds(query.Service, query.Method, stubs)
+	if !bounds.usable {
+		return 0, 0, false
+	}
+
+	return bounds.top, bounds.bounds[boolIndex(len(query.Data) == 0)][boolIndex(len(query.Headers) == 0)], true
+}
+
+// bucketBounds returns the rank bounds of the given Stub values of the
+// service and method, computed once per change of the stubs.
+func (s *searcher) bucketBounds(service, method string, stubs []*Stub) rankBounds {
+	key := service + "/" + method
+
+	s.cache.mu.Lock()
+	bounds, ok := s.cache.bounds[key]
+	gen := s.cache.gen
+	s.cache.mu.Unlock()
+
+	if ok {
+		return bounds
+	}
+
+	bounds = rankBounds{top: math.MinInt, usable: true}
+
+	for _, stub := range stubs {
+		if stub.OrderedGroup != "" {
+			bounds.usable = false
+
+			break
+		}
+
+		if stub.Abstract {
+			continue
+		}
+
+		// The stubs are ranked merged with their base stubs and normalized.
+		resolved, matcher := s.prepare(stub)
+
+		if resolved.Priority > bounds.top {
+			bounds.top = resolved.Priority
+			bounds.bounds = [2][2]float64{}
+		}
+
+		if resolved.Priority == bounds.top {
+			for data := range 2 {
+				for headers := range 2 {
+					bounds.bounds[data][headers] = max(bounds.bounds[data][headers], stubRankBound(matcher, data == 1, headers == 1))
+				}
+			}
+		}
+	}
+
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	// Skip the cache if a write happened while the bounds were computed.
+	if s.cache.gen == gen {
+		if s.cache.bounds == nil {
+			s.cache.bounds = make(map[string]rankBounds)
+		}
+
+		s.cache.bounds[key] = bounds
+	}
+
+	return bounds
+}
+
+// stubRankBound returns an upper bound of the rank of the given Stub value
+// by rankMatch, for queries whose data and headers are empty or not.
+func stubRankBound(stub *Stub, emptyData, emptyHeaders bool) float64 {
+	bound := matcherRankBound(stub.Input.Equals, emptyData) +
+		matcherRankBound(stub.Input.Contains, emptyData) +
+		matcherRankBound(stub.Input.Matches, emptyData)
+
+	if stub.Headers.Len() > 0 {
+		bound += matcherRankBound(stub.Headers.Equals, emptyHeaders) +
+			matcherRankBound(stub.Headers.Contains, emptyHeaders) +
+			matcherRankBound(stub.Headers.Matches, emptyHeaders)
+	}
+
+	// Each comparison of the size and the message count ranks one.
+	return bound + float64(len(stub.Size)+len(stub.MessageCount))
+}
+
+// matcherRankBound returns an upper bound of the rank of a matcher against
+// the data or the headers of a query, empty or not.
+//
+// An empty matcher ranks zero against non-empty values, and at most two
+// against empty ones.
+func matcherRankBound(matcher map[string]any, emptyActual bool) float64 {
+	if len(matcher) > 0 {
+		return rankBound(matcher)
+	}
+
+	if emptyActual {
+		return 2 //nolint:mnd
+	}
+
+	return 0
+}
+
+// rankBound returns an upper bound of the rank by deeply.RankMatch of any
+// value against the given expected value, from the structure of the latter:
+//
+//   - a nil value ranks at most three, one by each of the comparisons of
+//     deeply.RankMatch;
+//   - any other scalar ranks at most one;
+//   - a map ranks at most one when equal, plus twice the bound of its values,
+//     as each key is compared from both maps, averaged over the keys;
+//   - a slice ranks at most one when equal, plus the bound of its items,
+//     averaged over the items.
+//
+// Empty maps and slices rank at most one more when both values are empty.
+func rankBound(expected any) float64 {
+	if expected == nil {
+		return 3 //nolint:mnd
+	}
+
+	var (
+		value = reflect.ValueOf(expected)
+		child float64
+	)
+
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Map:
+		for iter := value.MapRange(); iter.Next(); {
+			child = max(child, rankBound(iter.Value().Interface()))
+		}
+
+		return 1 + max(1, 2*child) //nolint:mnd
+	case reflect.Slice:
+		for i := range value.Len() {
+			child = max(child, rankBound(value.Index(i).Interface()))
+		}
+
+		return 1 + max(1, child)
+	default:
+		return 1
+	}
+}
+
+// boolIndex returns 1 if the given value is true, otherwise 0.
+func boolIndex(value bool) int {
+	if value {
+		return 1
+	}
+
+	return 0
+}
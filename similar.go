@@ -0,0 +1,71 @@
+package stuber
+
+// defaultSimilarLimit is the default number of similar stubs tracked by a search.
+const defaultSimilarLimit = 1
+
+// rankedStub is a Stub value with its rank.
+type rankedStub struct {
+	stub *Stub
+	rank float64
+}
+
+// topSimilar tracks the best ranked Stub values of a search, in descending rank.
+//
+// Among Stub values of the same rank, the first added one comes first.
+type topSimilar struct {
+	limit int
+	items []rankedStub
+}
+
+// newTopSimilar creates a new topSimilar tracking at most limit Stub values.
+func newTopSimilar(limit int) topSimilar {
+	return topSimilar{limit: max(limit, 1)}
+}
+
+// add tracks the given Stub value if its rank is positive and it is among
+// the best ranked ones.
+func (t *topSimilar) add(stub *Stub, rank float64) {
+	if rank <= 0 {
+		return
+	}
+
+	if len(t.items) == t.limit && rank <= t.items[len(t.items)-1].rank {
+		return
+	}
+
+	// Find the position of the Stub value, after the ones of the same rank.
+	i := len(t.items)
+	for i > 0 && t.items[i-1].rank < rank {
+		i--
+	}
+
+	if len(t.items) < t.limit {
+		t.items = append(t.items, rankedStub{})
+	}
+
+	copy(t.items[i+1:], t.items[i:])
+	t.items[i] = rankedStub{stub: stub, rank: rank}
+}
+
+// best returns the best ranked Stub value, or nil.
+func (t *topSimilar) best() *Stub {
+	if len(t.items) == 0 {
+		return nil
+	}
+
+	return t.items[0].stub
+}
+
+// stubs returns the tracked Stub values, in descending rank.
+func (t *topSimilar) stubs() []*Stub {
+	if len(t.items) == 0 {
+		return nil
+	}
+
+	stubs := make([]*Stub, len(t.items))
+	for i, item := range t.items {
+		stubs[i] = item.stub
+	}
+
+	return stubs
+}
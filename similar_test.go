@@ -0,0 +1,65 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestResult_Similars(t *testing.T) {
+	s := stuber.New(stuber.WithSimilarLimit(2))
+
+	newStub := func(equals map[string]interface{}) *stuber.Stub {
+		return &stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: equals},
+		}
+	}
+
+	one := newStub(map[string]interface{}{"name": "Bob", "age": 1.0, "city": "Paris"})
+	two := newStub(map[string]interface{}{"name": "Bob", "age": 2.0, "city": "Paris"})
+	three := newStub(map[string]interface{}{"name": "Alice", "age": 3.0, "city": "Rome"})
+
+	s.PutMany(three, one, two)
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob", "age": 1.0, "city": "Rome"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+	require.Len(t, r.Similars(), 2)
+	require.Equal(t, r.Similar(), r.Similars()[0])
+}
+
+func TestResult_SimilarsDefault(t *testing.T) {
+	s := stuber.New()
+
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}, &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bobby"}},
+	})
+
+	r, err := s.FindByQuery(stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bo"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+	require.Len(t, r.Similars(), 1)
+	require.Equal(t, r.Similar(), r.Similars()[0])
+}
@@ -0,0 +1,94 @@
+package stuber
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SlowMatch is a search that took longer than the slow log threshold.
+type SlowMatch struct {
+	Time       time.Time     `json:"time"`             // When the search ended.
+	Service    string        `json:"service"`          // The service of the query.
+	Method     string        `json:"method"`           // The method of the query.
+	Duration   time.Duration `json:"duration"`         // How long the search took.
+	BucketSize int           `json:"bucketSize"`       // The number of stubs of the service method.
+	Candidates int           `json:"candidates"`       // The number of stubs evaluated against the query.
+	StubID     *uuid.UUID    `json:"stubId,omitempty"` // The found stub, if any.
+}
+
+// slowLog keeps the last slow searches in a ring buffer.
+type slowLog struct {
+	mu        sync.RWMutex
+	now       func() time.Time
+	threshold time.Duration
+	entries   []SlowMatch
+	next      int
+	count     int
+}
+
+// newSlowLog creates a new slowLog keeping no searches.
+func newSlowLog() *slowLog {
+	return &slowLog{now: time.Now}
+}
+
+// configure sets the threshold above which searches are kept, and the number
+// of kept searches, forgetting the kept ones.
+func (l *slowLog) configure(threshold time.Duration, size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.threshold = threshold
+	l.entries = make([]SlowMatch, max(size, 0))
+	l.next = 0
+	l.count = 0
+}
+
+// enabled checks if slow searches are kept.
+func (l *slowLog) enabled() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.entries) > 0
+}
+
+// record keeps the search if it took longer than the threshold.
+func (l *slowLog) record(match SlowMatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.entries) == 0 || match.Duration < l.threshold {
+		return
+	}
+
+	match.Time = l.now()
+
+	l.entries[l.next] = match
+	l.next = (l.next + 1) % len(l.entries)
+	l.count = min(l.count+1, len(l.entries))
+}
+
+// list returns the kept searches, from the oldest to the newest.
+func (l *slowLog) list() []SlowMatch {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make([]SlowMatch, 0, l.count)
+
+	for i := range l.count {
+		result = append(result, l.entries[(l.next-l.count+i+len(l.entries))%len(l.entries)])
+	}
+
+	return result
+}
+
+// SlowMatches returns the last searches that took longer than the threshold
+// set with WithSlowLog, from the oldest to the newest, to find the stub
+// buckets degrading the mock server.
+//
+// Returns:
+// - []SlowMatch: The slow searches.
+func (b *Budgerigar) SlowMatches() []SlowMatch {
+	return b.searcher.slowLog.list()
+}
@@ -0,0 +1,72 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestNew_WithSlowLog(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(
+		stuber.WithClock(func() time.Time { return now }),
+		stuber.WithSlowLog(time.Millisecond, 2),
+		stuber.WithRanker(func(query stuber.Query, stub *stuber.Stub) float64 {
+			if query.Data["slow"] == true {
+				time.Sleep(time.Millisecond)
+			}
+
+			return stuber.DefaultRank(query, stub)
+		}),
+	)
+
+	id := uuid.New()
+
+	s.PutMany(
+		&stuber.Stub{
+			ID:      id,
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Contains: map[string]interface{}{"name": "Bob"}},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+		},
+	)
+
+	find := func(data map[string]interface{}) {
+		_, _ = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: data})
+	}
+
+	find(map[string]interface{}{"name": "Bob"})
+	require.Empty(t, s.SlowMatches())
+
+	find(map[string]interface{}{"name": "Bob", "slow": true})
+
+	slow := s.SlowMatches()
+	require.Len(t, slow, 1)
+	require.Equal(t, now, slow[0].Time)
+	require.Equal(t, "SayHello", slow[0].Method)
+	require.GreaterOrEqual(t, slow[0].Duration, time.Millisecond)
+	require.Equal(t, 2, slow[0].BucketSize)
+	require.Equal(t, 2, slow[0].Candidates)
+	require.Equal(t, &id, slow[0].StubID)
+
+	find(map[string]interface{}{"name": "Nobody", "slow": true})
+	find(map[string]interface{}{"name": "Nobody", "slow": true, "last": true})
+
+	slow = s.SlowMatches()
+	require.Len(t, slow, 2)
+	require.Nil(t, slow[0].StubID)
+	require.Nil(t, slow[1].StubID)
+
+	require.Empty(t, stuber.New().SlowMatches())
+}
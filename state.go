@@ -0,0 +1,139 @@
+package stuber
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// State is the usage state of a Budgerigar.
+//
+// It can be exported before a restart and imported afterwards, so the
+// verification of the usage can span several runs of a mock server.
+type State struct {
+	Used       []uuid.UUID            `json:"used"`                 // The IDs of the used stubs.
+	Hits       map[uuid.UUID]StubHits `json:"hits,omitempty"`       // The usage of the used stubs, by ID.
+	RateLimits map[string]RateWindow  `json:"rateLimits,omitempty"` // The rate limit windows, by key.
+	Violations []OrderViolation       `json:"violations,omitempty"` // The order violations of ordered groups.
+	Scenarios  map[string]string      `json:"scenarios,omitempty"`  // The states of the scenarios, by name.
+	Sequences  map[string]int         `json:"sequences,omitempty"`  // The positions of the ordered groups, by name.
+}
+
+// StubHits is the usage of a used stub in a State.
+type StubHits struct {
+	Hits    uint64     `json:"hits"`              // The number of matches of the stub.
+	LastHit *time.Time `json:"lastHit,omitempty"` // The time of its last match, if known.
+}
+
+// RateWindow is the state of a rate limit window.
+type RateWindow struct {
+	Start time.Time `json:"start"` // The start of the window.
+	Count int       `json:"count"` // The number of matches in the window.
+}
+
+// ExportState returns the usage state of the Budgerigar.
+//
+// The positions of the ordered groups are exported along with the used
+// stubs, so they resume at the same stub once imported.
+//
+// Returns:
+// - State: The usage state.
+func (b *Budgerigar) ExportState() State {
+	return State{
+		Used:       b.searcher.usedIDs(),
+		Hits:       b.searcher.exportHits(),
+		RateLimits: b.limiter.export(),
+		Violations: b.searcher.orderViolations(),
+		Scenarios:  b.searcher.exportScenarios(),
+		Sequences:  b.searcher.groupPositions(),
+	}
+}
+
+// ImportState replaces the usage state of the Budgerigar.
+//
+// The used stubs without hits, such as in the states exported before the
+// hits were, count a single hit whose time is unknown, as do the stubs of
+// the ordered groups before their positions.
+//
+// Parameters:
+// - state: The usage state to import.
+func (b *Budgerigar) ImportState(state State) {
+	b.searcher.importState(state)
+	b.searcher.seekGroups(state.Sequences)
+	b.limiter.restore(state.RateLimits)
+}
+
+// exportHits returns the usage of the used stubs.
+func (s *searcher) exportHits() map[uuid.UUID]StubHits {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hits := make(map[uuid.UUID]StubHits, len(s.stubUsed))
+
+	for id, usage := range s.stubUsed {
+		entry := StubHits{Hits: usage.hits}
+		if !usage.lastHit.IsZero() {
+			entry.LastHit = &usage.lastHit
+		}
+
+		hits[id] = entry
+	}
+
+	return hits
+}
+
+// importState replaces the used stubs and their hits, the order violations
+// and the states of the scenarios.
+func (s *searcher) importState(state State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stubUsed = make(map[uuid.UUID]stubUsage, len(state.Used))
+	for _, id := range state.Used {
+		s.stubUsed[id] = stubUsage{hits: 1}
+	}
+
+	for id, entry := range state.Hits {
+		if entry.Hits == 0 {
+			continue
+		}
+
+		usage := stubUsage{hits: entry.Hits}
+		if entry.LastHit != nil {
+			usage.lastHit = *entry.LastHit
+		}
+
+		s.stubUsed[id] = usage
+	}
+
+	s.violations = slices.Clone(state.Violations)
+
+	s.scenarios = make(map[string]string, len(state.Scenarios))
+	maps.Copy(s.scenarios, state.Scenarios)
+}
+
+// export returns a copy of the rate limit windows.
+func (l *rateLimiter) export() map[string]RateWindow {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make(map[string]RateWindow, len(l.windows))
+	for key, window := range l.windows {
+		result[key] = RateWindow{Start: window.start, Count: window.count}
+	}
+
+	return result
+}
+
+// restore replaces the rate limit windows.
+func (l *rateLimiter) restore(windows map[string]RateWindow) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.windows = make(map[string]*rateWindow, len(windows))
+	for key, window := range windows {
+		l.windows[key] = &rateWindow{start: window.Start, count: window.Count}
+	}
+}
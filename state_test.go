@@ -0,0 +1,145 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_ExportImportState(t *testing.T) {
+	stubs := []*stuber.Stub{
+		{
+			ID:        uuid.New(),
+			Service:   "Greeter",
+			Method:    "SayHello",
+			Input:     stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			RateLimit: &stuber.RateLimit{Limit: 1, Window: stuber.Duration(time.Hour)},
+		},
+		{ID: uuid.New(), Service: "Greeter", Method: "SayBye"},
+	}
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	s := stuber.NewBudgerigar(features.New())
+	s.PutMany(stubs...)
+
+	_, err := s.FindByQuery(query)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(s.ExportState())
+	require.NoError(t, err)
+
+	// Simulate a restart.
+	restarted := stuber.NewBudgerigar(features.New())
+	restarted.PutMany(stubs...)
+
+	var state stuber.State
+	require.NoError(t, json.Unmarshal(data, &state))
+
+	restarted.ImportState(state)
+
+	require.Len(t, restarted.Used(), 1)
+	require.Len(t, restarted.Unused(), 1)
+
+	// The rate limit window survives the restart.
+	r, err := restarted.FindByQuery(query)
+	require.NoError(t, err)
+	require.NotEmpty(t, r.Found().Output.Error)
+}
+
+func TestBudgerigar_ExportImportState_Hits(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := 3
+
+	stubs := []*stuber.Stub{
+		{ID: uuid.New(), Service: "Greeter", Method: "SayHello", MaxCalls: 3},
+		{ID: uuid.New(), Service: "Greeter", Method: "SayBye", Expectations: &stuber.Expectations{Times: &times}},
+	}
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+	s.PutMany(stubs...)
+
+	for _, method := range []string{"SayHello", "SayHello", "SayBye", "SayBye"} {
+		_, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: method})
+		require.NoError(t, err)
+	}
+
+	data, err := json.Marshal(s.ExportState())
+	require.NoError(t, err)
+
+	// Simulate a restart.
+	restarted := stuber.New()
+	restarted.PutMany(stubs...)
+
+	var state stuber.State
+	require.NoError(t, json.Unmarshal(data, &state))
+
+	restarted.ImportState(state)
+
+	require.Equal(t, uint64(2), restarted.HitsByID(stubs[0].ID))
+	require.Equal(t, uint64(2), restarted.HitsByID(stubs[1].ID))
+	require.Equal(t, now, *restarted.Stats().Stubs[0].LastHit)
+
+	// The counts go on from the imported hits.
+	_, err = restarted.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+
+	r, err := restarted.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello"})
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+
+	require.Len(t, restarted.Verify().Failures, 1)
+
+	_, err = restarted.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayBye"})
+	require.NoError(t, err)
+	require.Empty(t, restarted.Verify().Failures)
+}
+
+func TestBudgerigar_ExportImportState_Sequences(t *testing.T) {
+	login := &stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Login", OrderedGroup: "session", GroupOrder: 1}
+	fetch := &stuber.Stub{ID: uuid.New(), Service: "Data", Method: "Fetch", OrderedGroup: "session", GroupOrder: 2}
+	logout := &stuber.Stub{ID: uuid.New(), Service: "Auth", Method: "Logout", OrderedGroup: "session", GroupOrder: 3}
+
+	s := stuber.New()
+	s.PutMany(login, fetch, logout)
+
+	for _, stub := range []*stuber.Stub{login, fetch} {
+		_, err := s.FindByQuery(stuber.Query{Service: stub.Service, Method: stub.Method})
+		require.NoError(t, err)
+	}
+
+	data, err := json.Marshal(s.ExportState())
+	require.NoError(t, err)
+
+	var state stuber.State
+	require.NoError(t, json.Unmarshal(data, &state))
+	require.Equal(t, map[string]int{"session": 2}, state.Sequences)
+
+	// Simulate a restart: the group resumes at the logout.
+	restarted := stuber.New()
+	restarted.PutMany(login, fetch, logout)
+	restarted.ImportState(state)
+
+	r, err := restarted.FindByQuery(stuber.Query{Service: "Data", Method: "Fetch"})
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+
+	r, err = restarted.FindByQuery(stuber.Query{Service: "Auth", Method: "Logout"})
+	require.NoError(t, err)
+	require.Equal(t, logout.ID, r.Found().ID)
+
+	// The positions alone resume the group as well.
+	restarted = stuber.New()
+	restarted.PutMany(login, fetch, logout)
+	restarted.ImportState(stuber.State{Sequences: map[string]int{"session": 1}})
+
+	r, err = restarted.FindByQuery(stuber.Query{Service: "Data", Method: "Fetch"})
+	require.NoError(t, err)
+	require.Equal(t, fetch.ID, r.Found().ID)
+}
@@ -0,0 +1,112 @@
+package stuber
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stubUsage is the usage of a used stub.
+type stubUsage struct {
+	hits    uint64    // The number of matches of the stub.
+	lastHit time.Time // The time of its last match, zero if unknown.
+}
+
+// StubStats is the usage of a stub.
+type StubStats struct {
+	ID      uuid.UUID  `json:"id"`                // The ID of the stub.
+	Service string     `json:"service"`           // The service of the stub.
+	Method  string     `json:"method"`            // The method of the stub.
+	Hits    uint64     `json:"hits"`              // The number of matches of the stub.
+	LastHit *time.Time `json:"lastHit,omitempty"` // The time of its last match, if known.
+}
+
+// ServiceStats is the number of matches of the stubs of a service.
+type ServiceStats struct {
+	Service string `json:"service"` // The service.
+	Hits    uint64 `json:"hits"`    // The number of matches of its stubs.
+}
+
+// MethodStats is the number of matches of the stubs of a method.
+type MethodStats struct {
+	Service string `json:"service"` // The service of the method.
+	Method  string `json:"method"`  // The method.
+	Hits    uint64 `json:"hits"`    // The number of matches of its stubs.
+}
+
+// Stats is the usage of the stored stubs.
+type Stats struct {
+	Stubs    []StubStats    `json:"stubs"`    // The usage of each stub, in their canonical order.
+	Services []ServiceStats `json:"services"` // The totals by service, sorted by service.
+	Methods  []MethodStats  `json:"methods"`  // The totals by method, sorted by service and method.
+}
+
+// usage returns the usage of the Stub value with the given ID.
+func (s *searcher) usage(id uuid.UUID) stubUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.stubUsed[id]
+}
+
+// Stats returns the usage of the stored Stub values: the number of matches
+// of each one and the time of its last match, along with the totals by
+// service and by method.
+//
+// Internal queries are not counted. Stub values marked as used by another
+// instance of a cluster, or by ImportState without their hits, count a
+// single hit whose time is unknown.
+//
+// Returns:
+// - Stats: The usage of the stored Stub values.
+func (b *Budgerigar) Stats() Stats {
+	stubs := SortStubs(b.searcher.all())
+
+	stats := Stats{
+		Stubs:    make([]StubStats, len(stubs)),
+		Services: []ServiceStats{},
+		Methods:  []MethodStats{},
+	}
+
+	for i, stub := range stubs {
+		usage := b.searcher.usage(stub.ID)
+
+		stats.Stubs[i] = StubStats{
+			ID:      stub.ID,
+			Service: stub.Service,
+			Method:  stub.Method,
+			Hits:    usage.hits,
+		}
+
+		if !usage.lastHit.IsZero() {
+			stats.Stubs[i].LastHit = &usage.lastHit
+		}
+
+		// The stubs are sorted by service and method, so their totals are contiguous.
+		if n := len(stats.Services); n == 0 || stats.Services[n-1].Service != stub.Service {
+			stats.Services = append(stats.Services, ServiceStats{Service: stub.Service})
+		}
+
+		if n := len(stats.Methods); n == 0 || stats.Methods[n-1].Service != stub.Service ||
+			stats.Methods[n-1].Method != stub.Method {
+			stats.Methods = append(stats.Methods, MethodStats{Service: stub.Service, Method: stub.Method})
+		}
+
+		stats.Services[len(stats.Services)-1].Hits += usage.hits
+		stats.Methods[len(stats.Methods)-1].Hits += usage.hits
+	}
+
+	return stats
+}
+
+// HitsByID returns the number of matches of the Stub value with the given
+// ID, such as to verify how many times a test exercised it.
+//
+// Parameters:
+// - id: The UUID of the Stub value.
+//
+// Returns:
+// - uint64: The number of matches, 0 if the Stub value was never matched.
+func (b *Budgerigar) HitsByID(id uuid.UUID) uint64 {
+	return b.searcher.usage(id).hits
+}
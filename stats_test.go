@@ -0,0 +1,71 @@
+package stuber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Stats(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	hello := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	bye := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		Service: "Greeter",
+		Method:  "SayGoodbye",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	order := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+		Service: "Orders",
+		Method:  "GetOrder",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"id": "42"}},
+	}
+	s.PutMany(hello, bye, order)
+
+	for range 3 {
+		_, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}})
+		require.NoError(t, err)
+	}
+
+	now = now.Add(time.Minute)
+
+	_, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayGoodbye", Data: map[string]interface{}{"name": "Bob"}})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(3), s.HitsByID(hello.ID))
+	require.Equal(t, uint64(0), s.HitsByID(order.ID))
+
+	stats := s.Stats()
+
+	first, last := now.Add(-time.Minute), now
+
+	require.Equal(t, []stuber.StubStats{
+		{ID: bye.ID, Service: "Greeter", Method: "SayGoodbye", Hits: 1, LastHit: &last},
+		{ID: hello.ID, Service: "Greeter", Method: "SayHello", Hits: 3, LastHit: &first},
+		{ID: order.ID, Service: "Orders", Method: "GetOrder"},
+	}, stats.Stubs)
+	require.Equal(t, []stuber.ServiceStats{
+		{Service: "Greeter", Hits: 4},
+		{Service: "Orders", Hits: 0},
+	}, stats.Services)
+	require.Equal(t, []stuber.MethodStats{
+		{Service: "Greeter", Method: "SayGoodbye", Hits: 1},
+		{Service: "Greeter", Method: "SayHello", Hits: 3},
+		{Service: "Orders", Method: "GetOrder", Hits: 0},
+	}, stats.Methods)
+
+	s.Clear()
+	require.Equal(t, uint64(0), s.HitsByID(hello.ID))
+}
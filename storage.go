@@ -89,6 +89,9 @@ func (s *storage) values() []Value {
 	//
 	// This function returns a slice of Value objects containing all the values
 	// stored in the storage. The values are returned in an arbitrary order.
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return maps.Values(s.itemsByID)
 }
 
@@ -107,6 +110,10 @@ func (s *storage) values() []Value {
 //     and right values.
 //   - error: A nil error if the values are found, otherwise an error indicating
 //     that the values were not found.
+//
+// The returned slice is shared with the storage and must not be modified. The
+// writes of the storage copy the slices of the positions instead of modifying
+// them in place, so it is safe to read after the storage is unlocked.
 func (s *storage) findAll(left, right string) ([]Value, error) {
 	// Find the position of the given left and right values.
 	pos, err := s.posByN(left, right)
@@ -145,6 +152,13 @@ func (s *storage) findByID(key uuid.UUID) Value { //nolint:ireturn
 	return nil
 }
 
+// findByIDLocked retrieves the value associated with the given ID, or nil.
+//
+// The caller must hold the lock of the storage.
+func (s *storage) findByIDLocked(key uuid.UUID) Value { //nolint:ireturn
+	return s.itemsByID[key]
+}
+
 // findByIDs retrieves the values associated with the given IDs.
 //
 // This function takes a slice of keys as a parameter and returns the values
@@ -179,40 +193,159 @@ func (s *storage) findByIDs(keys ...uuid.UUID) []Value {
 
 func (s *storage) upsert(values ...Value) []uuid.UUID {
 	// upsert inserts the given values into the storage. If a value already exists
-	// with the same key, it is updated.
+	// with the same key, it is replaced, and moved to the position of its new left
+	// and right values if they changed, so that it is never indexed twice.
 	//
 	// The function returns a slice of UUIDs representing the keys of the inserted
 	// or updated values.
 	results := make([]uuid.UUID, len(values))
 
+	// Lock the storage for writing, once for all the values.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, v := range values {
-		// Get the ID of the left value. If it does not exist, create a new ID.
-		leftID := s.leftIDOrNew(v.Left())
+		results[i] = v.Key()
 
-		// Get the ID of the right value. If it does not exist, create a new ID.
-		rightID := s.rightIDOrNew(v.Right())
+		// Replace the value stored with the same key, if any.
+		if old, ok := s.itemsByID[v.Key()]; ok {
+			s.replaceLocked(old, v)
+
+			continue
+		}
+
+		// Get the IDs of the left and right values, creating them if they do not exist.
+		leftID := idOrNewLocked(s.lefts, &s.leftTotal, v.Left())
+		rightID := idOrNewLocked(s.rights, &s.rightTotal, v.Right())
 
 		// Calculate the index of the value based on the left and right IDs.
 		ind := s.pos(leftID, rightID)
 
-		// Lock the storage for writing.
-		s.mu.Lock()
-
 		// Store the key and value in the storage.
-		results[i] = v.Key()
+		if !slices.Contains(s.leftRights[leftID], rightID) {
+			s.leftRights[leftID] = append(s.leftRights[leftID], rightID)
+		}
 
-		s.leftRights[leftID] = append(s.leftRights[leftID], rightID)
 		s.items[ind] = append(s.items[ind], v)
 		s.itemsByID[v.Key()] = v
-
-		// Unlock the storage.
-		s.mu.Unlock()
 	}
 
 	// Return the keys of the inserted or updated values.
 	return results
 }
 
+// replaceLocked replaces the old value by the new one, which has the same key,
+// moving it to the position of its left and right values if they changed.
+//
+// The caller must hold the write lock of the storage.
+func (s *storage) replaceLocked(old, v Value) {
+	oldPos := s.pos(s.lefts[old.Left()], s.rights[old.Right()])
+
+	leftID := idOrNewLocked(s.lefts, &s.leftTotal, v.Left())
+	rightID := idOrNewLocked(s.rights, &s.rightTotal, v.Right())
+	pos := s.pos(leftID, rightID)
+
+	// The slices of the positions are copied, as they may be read by a search.
+	if pos == oldPos {
+		// Replace the value at its index to keep its insertion order.
+		i := slices.IndexFunc(s.items[pos], func(value Value) bool {
+			return value.Key() == old.Key()
+		})
+		if i >= 0 {
+			s.items[pos] = slices.Clone(s.items[pos])
+			s.items[pos][i] = v
+		}
+	} else {
+		s.items[oldPos] = slices.DeleteFunc(slices.Clone(s.items[oldPos]), func(value Value) bool {
+			return value.Key() == old.Key()
+		})
+
+		if !slices.Contains(s.leftRights[leftID], rightID) {
+			s.leftRights[leftID] = append(s.leftRights[leftID], rightID)
+		}
+
+		s.items[pos] = append(s.items[pos], v)
+	}
+
+	s.itemsByID[v.Key()] = v
+}
+
+// idOrNewLocked returns the ID associated with the given name, creating it if
+// it does not exist.
+//
+// The caller must hold the write lock of the storage.
+func idOrNewLocked(ids map[string]uint64, total *atomic.Uint64, name string) uint64 {
+	if id, ok := ids[name]; ok {
+		return id
+	}
+
+	ids[name] = total.Add(1)
+
+	return ids[name]
+}
+
+// reindex rebuilds the positions of the values from the values stored by
+// key, dropping the stale and duplicate entries and keeping the insertion
+// order of the others.
+//
+// The function returns the number of dropped entries.
+func (s *storage) reindex() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		dropped int
+		items   = make(map[uuid.UUID][]Value, len(s.items))
+		placed  = make(map[uuid.UUID]struct{}, len(s.itemsByID))
+	)
+
+	// Keep the entries that are the stored value of their key, at its position.
+	for pos, values := range s.items {
+		for _, v := range values {
+			_, seen := placed[v.Key()]
+			current, ok := s.itemsByID[v.Key()]
+
+			if seen || !ok || current != v || s.pos(s.lefts[v.Left()], s.rights[v.Right()]) != pos {
+				dropped++
+
+				continue
+			}
+
+			placed[v.Key()] = struct{}{}
+			items[pos] = append(items[pos], v)
+		}
+	}
+
+	// Place the stored values that had no valid entry.
+	for key, v := range s.itemsByID {
+		if _, ok := placed[key]; !ok {
+			leftID := idOrNewLocked(s.lefts, &s.leftTotal, v.Left())
+			rightID := idOrNewLocked(s.rights, &s.rightTotal, v.Right())
+			pos := s.pos(leftID, rightID)
+
+			items[pos] = append(items[pos], v)
+		}
+	}
+
+	// Drop the duplicate right values associated with each left value, and
+	// add the missing ones.
+	for leftID, rightIDs := range s.leftRights {
+		slices.Sort(rightIDs)
+		s.leftRights[leftID] = slices.Compact(rightIDs)
+	}
+
+	for _, v := range s.itemsByID {
+		leftID, rightID := s.lefts[v.Left()], s.rights[v.Right()]
+		if !slices.Contains(s.leftRights[leftID], rightID) {
+			s.leftRights[leftID] = append(s.leftRights[leftID], rightID)
+		}
+	}
+
+	s.items = items
+
+	return dropped
+}
+
 // del deletes the values with the given keys from the storage.
 //
 // The function returns the number of values that were successfully deleted.
@@ -248,7 +381,8 @@ func (s *storage) del(keys ...uuid.UUID) int {
 
 	// Delete the values with the keys from the storage.
 	for pos, v := range deleteIDs {
-		s.items[pos] = slices.DeleteFunc(s.items[pos], func(value Value) bool {
+		// Copy the slice of the position, which may be read by a search.
+		s.items[pos] = slices.DeleteFunc(slices.Clone(s.items[pos]), func(value Value) bool {
 			// Check if the key of the value is in the list of keys to be deleted.
 			return slices.Contains(v, value.Key())
 		})
@@ -263,6 +397,97 @@ func (s *storage) del(keys ...uuid.UUID) int {
 	return result
 }
 
+// delWhere deletes the values accepted by the given predicate from the storage.
+//
+// The storage is locked for writing once for the whole deletion, so the
+// predicate must not access the storage.
+//
+// The function returns the number of values that were deleted.
+func (s *storage) delWhere(pred func(Value) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Delete the accepted values from the itemsByID map.
+	deleted := make(map[uuid.UUID]struct{})
+
+	for key, v := range s.itemsByID {
+		if pred(v) {
+			deleted[key] = struct{}{}
+
+			delete(s.itemsByID, key)
+		}
+	}
+
+	if len(deleted) == 0 {
+		return 0
+	}
+
+	// Delete the accepted values from their positions.
+	isDeleted := func(value Value) bool {
+		_, ok := deleted[value.Key()]
+
+		return ok
+	}
+
+	for pos, values := range s.items {
+		// Copy the slice of the position, which may be read by a search.
+		if slices.ContainsFunc(values, isDeleted) {
+			s.items[pos] = slices.DeleteFunc(slices.Clone(values), isDeleted)
+		}
+	}
+
+	// Return the number of values that were deleted.
+	return len(deleted)
+}
+
+// modifyByID replaces the value with the given key by the value returned by
+// the given function, under a single write lock.
+//
+// It returns ErrStubNotFound if there is no value with the given key, and
+// the error of the function, in which case the value is kept.
+func (s *storage) modifyByID(key uuid.UUID, fn func(Value) (Value, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.itemsByID[key]
+	if !ok {
+		return ErrStubNotFound
+	}
+
+	v, err := fn(old)
+	if err != nil {
+		return err
+	}
+
+	s.replaceLocked(old, v)
+
+	return nil
+}
+
+// modifyWhere replaces the values accepted by the given predicate by the
+// values returned by the given function, under a single write lock.
+//
+// It returns the number of replaced values.
+func (s *storage) modifyWhere(pred func(Value) bool, fn func(Value) Value) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Collect the values first, as replacing them modifies the map.
+	var olds []Value
+
+	for _, v := range s.itemsByID {
+		if pred(v) {
+			olds = append(olds, v)
+		}
+	}
+
+	for _, old := range olds {
+		s.replaceLocked(old, fn(old))
+	}
+
+	return len(olds)
+}
+
 func (s *storage) leftID(name string) (uint64, error) {
 	// leftId returns the ID associated with the given left name.
 	//
@@ -115,6 +115,76 @@ func TestUpdate(t *testing.T) {
 	val, ok = v.(*testItem)
 	require.True(t, ok)
 	require.Equal(t, 42, val.value)
+
+	values, err := s.findAll("Greeter", "SayHello")
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+}
+
+func TestUpdateMoved(t *testing.T) {
+	id := uuid.New()
+
+	s := newStorage()
+	s.upsert(&testItem{id: id, left: "Greeter", right: "SayHello"})
+	s.upsert(&testItem{id: id, left: "Greeter", right: "SayGoodbye", value: 42})
+
+	values, err := s.findAll("Greeter", "SayHello")
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	values, err = s.findAll("Greeter", "SayGoodbye")
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	require.Len(t, s.leftRights[s.lefts["Greeter"]], 2)
+}
+
+func TestReindex(t *testing.T) {
+	id := uuid.New()
+	stale := &testItem{id: id, left: "Greeter", right: "SayHello"}
+	current := &testItem{id: id, left: "Greeter", right: "SayGoodbye", value: 42}
+
+	s := newStorage()
+	s.upsert(stale, &testItem{id: uuid.New(), left: "Greeter", right: "SayHello"})
+	s.upsert(current)
+
+	// Simulate the duplicates left by older versions.
+	hello := s.pos(s.lefts["Greeter"], s.rights["SayHello"])
+	goodbye := s.pos(s.lefts["Greeter"], s.rights["SayGoodbye"])
+	s.items[hello] = append(s.items[hello], stale)
+	s.items[goodbye] = append(s.items[goodbye], current)
+	s.leftRights[s.lefts["Greeter"]] = append(s.leftRights[s.lefts["Greeter"]], s.rights["SayHello"])
+
+	require.Equal(t, 2, s.reindex())
+	require.Equal(t, 0, s.reindex())
+
+	require.Len(t, s.items[hello], 1)
+	require.Equal(t, []Value{current}, s.items[goodbye])
+	require.Len(t, s.leftRights[s.lefts["Greeter"]], 2)
+}
+
+func TestCompact(t *testing.T) {
+	kept := &testItem{id: uuid.New(), left: "Greeter", right: "SayHello"}
+	removed := &testItem{id: uuid.New(), left: "Greeter", right: "SayGoodbye"}
+
+	s := newStorage()
+	s.upsert(kept, removed, &testItem{id: uuid.New(), left: "Greeter", right: "SayHello"})
+	s.del(removed.id)
+
+	// Simulate a stale entry left by older versions.
+	hello := s.pos(s.lefts["Greeter"], s.rights["SayHello"])
+	s.items[hello] = append(s.items[hello], kept)
+
+	before := s.stats()
+	require.Equal(t, StorageStats{Stubs: 2, Buckets: 2, Entries: 3, Capacity: before.Capacity}, before)
+
+	require.Equal(t, 1, s.compact())
+
+	require.Equal(t, StorageStats{Stubs: 2, Buckets: 1, Entries: 2, Capacity: 2}, s.stats())
+	require.NotNil(t, s.findByID(kept.id))
+	require.Nil(t, s.findByID(removed.id))
+
+	_, err := s.findAll("Greeter", "SayGoodbye")
+	require.NoError(t, err)
 }
 
 func TestFindByID(t *testing.T) {
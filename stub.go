@@ -1,18 +1,97 @@
 package stuber
 
 import (
+	"time"
+
+	"github.com/bavix/features"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 )
 
 // Stub represents a gRPC service method and its associated data.
 type Stub struct {
-	ID      uuid.UUID   `json:"id"`      // The unique identifier of the stub.
-	Service string      `json:"service"` // The name of the service.
-	Method  string      `json:"method"`  // The name of the method.
-	Headers InputHeader `json:"headers"` // The headers of the request.
-	Input   InputData   `json:"input"`   // The input data of the request.
-	Output  Output      `json:"output"`  // The output data of the response.
+	SchemaVersion int `json:"schemaVersion,omitempty"` // The version of the JSON format of the stub.
+
+	ID        uuid.UUID   `json:"id"`                  // The unique identifier of the stub.
+	Service   string      `json:"service"`             // The name of the service.
+	Method    string      `json:"method"`              // The name of the method.
+	Headers   InputHeader `json:"headers"`             // The headers of the request.
+	Input     InputData   `json:"input"`               // The input data of the request.
+	Output    Output      `json:"output"`              // The output data of the response.
+	Priority  int         `json:"priority,omitempty"`  // The priority of the stub over other matching stubs.
+	RateLimit *RateLimit  `json:"rateLimit,omitempty"` // The rate limit of the stub.
+	DependsOn []uuid.UUID `json:"dependsOn,omitempty"` // The stubs that must be used before this stub matches.
+
+	// Band is the band of the priority, the Priority being the offset in the
+	// band. It is moved into the Priority on unmarshal and insertion.
+	Band *PriorityBand `json:"band,omitempty"`
+
+	// Inputs are the messages of a client streaming request, in order.
+	Inputs []InputData `json:"inputs,omitempty"`
+	// Stream is the former name of Inputs, moved into Inputs on unmarshal and insertion.
+	//
+	// Deprecated: Use Inputs instead.
+	Stream []InputData `json:"stream,omitempty"`
+
+	Size         Comparison `json:"size,omitempty"`         // The bounds of the payload size of the request, in bytes.
+	MessageCount Comparison `json:"messageCount,omitempty"` // The bounds of the number of messages received on the stream.
+
+	OrderedGroup string `json:"orderedGroup,omitempty"` // The ordered group the stub belongs to.
+	GroupOrder   int    `json:"groupOrder,omitempty"`   // The position of the stub in its ordered group.
+
+	Scenario      string `json:"scenario,omitempty"`      // The scenario the stub belongs to.
+	RequiredState string `json:"requiredState,omitempty"` // The state of the scenario required to match, any if empty.
+	NewState      string `json:"newState,omitempty"`      // The state the scenario moves to once the stub matches, if any.
+
+	Expectations *Expectations `json:"expectations,omitempty"` // The number of times the stub is expected to be matched, checked by Verify.
+	MaxCalls     int           `json:"maxCalls,omitempty"`     // The number of matches after which the stub stops matching, unlimited if zero.
+
+	MaxConcurrent *ConcurrencyLimit `json:"maxConcurrent,omitempty"` // The number of simultaneous calls the stub answers, see ConcurrencyLimit.
+	Faults        *FaultConfig      `json:"faults,omitempty"`        // The faults injected into the matches of the stub.
+	Pagination    *Pagination       `json:"pagination,omitempty"`    // The dataset served page by page by the stub.
+
+	Webhook string `json:"webhook,omitempty"` // The URL notified after the stub is matched.
+
+	Base     *uuid.UUID `json:"base,omitempty"`     // The stub whose matchers and output are inherited.
+	Abstract bool       `json:"abstract,omitempty"` // Whether the stub is only used as a base and never matches.
+
+	Description string `json:"description,omitempty"` // A human readable description of the stub, ignored by matching.
+	Owner       string `json:"owner,omitempty"`       // The team or person owning the stub, ignored by matching.
+
+	// Meta holds arbitrary labels, such as ticket IDs or environments, ignored
+	// by matching but carried by the events and the webhooks of the stub.
+	Meta map[string]string `json:"meta,omitempty"`
+
+	CreatedAt *time.Time `json:"createdAt,omitempty"` // When the stub was first inserted, set automatically.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"` // When the stub was last inserted or patched, set automatically.
+
+	Flags map[string]bool `json:"flags,omitempty"` // The feature flags overridden for the stub, by name.
+
+	Examples []Example `json:"examples,omitempty"` // The requests the stub is expected to match.
+}
+
+// Example is a request a stub is expected to match, checked by SelfTest.
+// Examples can be generated from the matchers of a stub with GenerateExample.
+type Example struct {
+	Headers map[string]interface{} `json:"headers,omitempty"` // The headers of the request.
+	Data    map[string]interface{} `json:"data"`              // The data of the request.
+}
+
+// canonicalize moves the deprecated fields of the stub into their canonical
+// replacements, and its priority band into its priority.
+func (s *Stub) canonicalize() {
+	if len(s.Stream) > 0 {
+		if len(s.Inputs) == 0 {
+			s.Inputs = s.Stream
+		}
+
+		s.Stream = nil
+	}
+
+	if s.Band != nil {
+		s.Priority = s.Band.Priority(s.Priority)
+		s.Band = nil
+	}
 }
 
 // Key returns the unique identifier of the stub.
@@ -20,6 +99,28 @@ func (s Stub) Key() uuid.UUID {
 	return s.ID
 }
 
+// enabled checks if the given feature flag is enabled for the stub.
+//
+// The Flags of the stub take precedence over the given global toggles.
+func (s Stub) enabled(flag features.Flag, toggles features.Toggles) bool {
+	if value, ok := s.Flags[FlagName(flag)]; ok {
+		return value
+	}
+
+	return toggles.Has(flag)
+}
+
+// MetaEquals checks if the Meta of the stub has all the given labels.
+func (s Stub) MetaEquals(meta map[string]string) bool {
+	for key, value := range meta {
+		if actual, ok := s.Meta[key]; !ok || actual != value {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Left returns the service name of the stub.
 func (s Stub) Left() string {
 	return s.Service
@@ -82,8 +183,30 @@ func (i InputHeader) Len() int {
 
 // Output represents the output data of a gRPC response.
 type Output struct {
-	Headers map[string]string      `json:"headers"`        // The headers of the response.
-	Data    map[string]interface{} `json:"data"`           // The data of the response.
-	Error   string                 `json:"error"`          // The error message of the response.
-	Code    *codes.Code            `json:"code,omitempty"` // The status code of the response.
+	Headers map[string]string      `json:"headers"`         // The headers of the response.
+	Data    map[string]interface{} `json:"data"`            // The data of the response.
+	Error   string                 `json:"error"`           // The error message of the response.
+	Code    *codes.Code            `json:"code,omitempty"`  // The status code of the response.
+	Delay   Duration               `json:"delay,omitempty"` // The delay before the response is sent.
+
+	// Seed seeds the random template functions, so the same request renders
+	// the same output, and the selection of the alternatives, so the same
+	// sequence of matches selects the same outputs. It overrides the seed of
+	// the Budgerigar.
+	Seed *uint64 `json:"seed,omitempty"`
+
+	// Alternatives are the outputs returned instead of this one, one of them
+	// being selected at random for each match in proportion to its weight.
+	Alternatives []WeightedOutput `json:"alternatives,omitempty"`
+}
+
+// withOutput returns a shallow copy of the stub with the given output.
+//
+// It is used to alter the response of a matched stub without modifying
+// the stored value.
+func (s *Stub) withOutput(output Output) *Stub {
+	clone := *s
+	clone.Output = output
+
+	return &clone
 }
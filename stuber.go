@@ -1,35 +1,152 @@
 package stuber
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
 	"github.com/bavix/features"
 	"github.com/google/uuid"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
-// MethodTitle is a feature flag for using title casing in the method field
-// of a Query struct.
-const MethodTitle features.Flag = iota
+const (
+	// MethodTitle is a feature flag for using title casing in the method field
+	// of a Query struct.
+	MethodTitle features.Flag = iota
+
+	// ImportEnv is a feature flag for expanding ${ENV_VAR} placeholders with
+	// environment variables in imported documents.
+	ImportEnv
+
+	// StrictFields is a feature flag for rejecting queries whose data contains
+	// top-level fields that no input matcher of the stub mentions.
+	StrictFields
+
+	// LowerHeaders is a feature flag for lowercasing the header names of
+	// queries and stubs, as gRPC metadata keys are lowercase.
+	LowerHeaders
+
+	// StrictQuery is a feature flag for rejecting decoded queries with unknown
+	// top-level fields, such as a "dta" typo.
+	StrictQuery
+)
 
 // Budgerigar is the main struct for the stuber package. It contains a
 // searcher and toggles.
 type Budgerigar struct {
 	searcher *searcher
 	toggles  features.Toggles
+	limiter  *rateLimiter
+	inflight *concurrencyLimiter
+	picker   *outputPicker
+	faults   *FaultInjector
+	pages    *paginator
+	changes  *changeLog
+	hooks    *hooks
+	importer *importer
+	remote   *remote
+	logger   *slog.Logger
+	metrics  Metrics
+	tracer   Tracer // The tracer of the searches, if any.
+	chaos    atomic.Pointer[ChaosProfile]
+	metadata *serviceMetadata
+	history  *matchHistory
+	notFound *notFound
+	misses   *missLog
+	traffic  *trafficLog
+	keys     KeyProvider // The keys encrypting the saved documents, if any.
+
+	redaction []RedactionRule // The rules masking the fields of the exports.
+
+	templates     *templates
+	templateCache *templateCache
+
+	backend Backend  // The backend attached by New, if any.
+	health  []string // The services whose health stubs are inserted by New, the server being the empty one.
+
+	sorted     bool // Whether the listings are sorted canonically.
+	timestamps bool // Whether the exports include the timestamps of the stubs.
+}
+
+// New creates a new Budgerigar configured with the given options.
+//
+// Parameters:
+// - opts: The options to apply.
+//
+// Returns:
+// - A new Budgerigar.
+func New(opts ...Option) *Budgerigar {
+	b := &Budgerigar{
+		searcher: newSearcher(),
+		toggles:  features.New(),
+		limiter:  newRateLimiter(),
+		inflight: newConcurrencyLimiter(),
+		picker:   newOutputPicker(),
+		faults:   &FaultInjector{},
+		pages:    newPaginator(),
+		changes:  newChangeLog(),
+		hooks:    newHooks(),
+		importer: newImporter(),
+		remote:   newRemote(),
+		logger:   discardLogger(),
+		metrics:  nopMetrics{},
+		metadata: newServiceMetadata(),
+		history:  newMatchHistory(),
+		notFound: newNotFound(),
+		misses:   newMissLog(),
+		traffic:  newTrafficLog(),
+
+		templates:     newTemplates(),
+		templateCache: newTemplateCache(),
+	}
+
+	b.hooks.logger = b.logger
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.searcher.toggles = b.toggles
+
+	// Record the changes only if they are kept, so the events are skipped otherwise.
+	if b.changes.enabled() {
+		b.searcher.events.listen(b.changes)
+	}
+
+	// Attach the backend once all the options are applied, so the stubs are
+	// loaded as configured, whatever the order of the options.
+	if b.backend != nil {
+		if _, err := b.AttachBackend(b.backend); err != nil {
+			b.logger.Error("stuber: backend not attached", "error", err)
+		}
+	}
+
+	// Insert the health stubs once all the options are applied, so they are
+	// inserted as configured, whatever the order of the options.
+	for _, service := range b.health {
+		b.SetHealthStatus(service, HealthServing)
+	}
+
+	return b
 }
 
 // NewBudgerigar creates a new Budgerigar with the given features.Toggles.
 //
+// Deprecated: Use New with WithToggles instead.
+//
 // Parameters:
 // - toggles: The features.Toggles to use.
 //
 // Returns:
 // - A new Budgerigar.
 func NewBudgerigar(toggles features.Toggles) *Budgerigar {
-	return &Budgerigar{
-		searcher: newSearcher(),
-		toggles:  toggles,
-	}
+	return New(WithToggles(toggles))
 }
 
 // PutMany inserts the given Stub values into the Budgerigar. If a Stub value
@@ -41,16 +158,39 @@ func NewBudgerigar(toggles features.Toggles) *Budgerigar {
 // Returns:
 // - []uuid.UUID: The keys of the inserted Stub values.
 func (b *Budgerigar) PutMany(values ...*Stub) []uuid.UUID {
+	b.prepare(values)
+
+	// Insert the Stub values into the Budgerigar's searcher.
+	ids := b.searcher.upsert(values...)
+
+	// Drop the cached templates of the replaced Stub values.
+	b.templateCache.invalidate(ids...)
+
+	return ids
+}
+
+// prepare readies the given Stub values for their insertion: it generates
+// the missing keys, moves the deprecated fields, inherits the targets and
+// lowercases the header names as configured.
+func (b *Budgerigar) prepare(values []*Stub) {
 	// Iterate over each Stub value.
 	for _, value := range values {
 		// If the Stub value does not have a key, generate a new UUID for its key.
 		if value.Key() == uuid.Nil {
 			value.ID = uuid.New()
 		}
+
+		// Move the deprecated fields and the priority band of the Stub value into their replacements.
+		value.canonicalize()
 	}
 
-	// Insert the Stub values into the Budgerigar's searcher.
-	return b.searcher.upsert(values...)
+	// Inherit the service and method of the Stub values from their base.
+	b.searcher.inheritTargets(values)
+
+	// Lowercase the header names of the Stub values if the LowerHeaders feature flag is enabled.
+	if b.toggles.Has(LowerHeaders) {
+		lowerStubHeaders(values)
+	}
 }
 
 func (b *Budgerigar) UpdateMany(values ...*Stub) []uuid.UUID {
@@ -61,10 +201,19 @@ func (b *Budgerigar) UpdateMany(values ...*Stub) []uuid.UUID {
 	for _, value := range values {
 		// Only update the value if it has a non-nil key.
 		if value.Key() != uuid.Nil {
+			value.canonicalize()
 			updates = append(updates, value)
 		}
 	}
 
+	// Inherit the service and method of the updates from their base.
+	b.searcher.inheritTargets(updates)
+
+	// Lowercase the header names of the updates if the LowerHeaders feature flag is enabled.
+	if b.toggles.Has(LowerHeaders) {
+		lowerStubHeaders(updates)
+	}
+
 	// Insert the updates into the searcher.
 	// Returns the keys of the inserted or updated values.
 	//
@@ -73,7 +222,12 @@ func (b *Budgerigar) UpdateMany(values ...*Stub) []uuid.UUID {
 	//
 	// Returns:
 	// - []uuid.UUID: The keys of the inserted or updated values.
-	return b.searcher.upsert(updates...)
+	ids := b.searcher.upsert(updates...)
+
+	// Drop the cached templates of the updated Stub values.
+	b.templateCache.invalidate(ids...)
+
+	return ids
 }
 
 // DeleteByID deletes the Stub values with the given IDs from the Budgerigar's searcher.
@@ -92,9 +246,48 @@ func (b *Budgerigar) DeleteByID(ids ...uuid.UUID) int {
 	//
 	// Returns:
 	// - int: The number of Stub values that were successfully deleted.
+	defer b.templateCache.forget(ids...)
+
 	return b.searcher.del(ids...)
 }
 
+// DeleteWhere deletes the Stub values accepted by the given predicate from
+// the Budgerigar's searcher, in a single pass under a single lock.
+//
+// The predicate must not access the Budgerigar.
+//
+// Parameters:
+// - pred: The predicate selecting the Stub values to delete.
+//
+// Returns:
+// - int: The number of Stub values that were deleted.
+func (b *Budgerigar) DeleteWhere(pred func(*Stub) bool) int {
+	var ids []uuid.UUID
+
+	defer func() { b.templateCache.forget(ids...) }()
+
+	return b.searcher.deleteWhere(func(stub *Stub) bool {
+		if pred(stub) {
+			ids = append(ids, stub.ID)
+
+			return true
+		}
+
+		return false
+	})
+}
+
+// Reindex rebuilds the index of the Stub values by service and method,
+// dropping the stale and duplicate entries. It is a maintenance operation
+// for stores populated by older versions, which left a Stub value indexed
+// under its old service and method when an update changed them.
+//
+// Returns:
+// - int: The number of dropped entries.
+func (b *Budgerigar) Reindex() int {
+	return b.searcher.reindex()
+}
+
 // FindByID retrieves the Stub value associated with the given ID from the Budgerigar's searcher.
 //
 // Parameters:
@@ -113,6 +306,24 @@ func (b *Budgerigar) FindByID(id uuid.UUID) *Stub {
 	return b.searcher.findByID(id)
 }
 
+// canonicalQuery returns the query adjusted according to the feature flags
+// of the Budgerigar.
+func (b *Budgerigar) canonicalQuery(query Query) Query {
+	// Backward compatibility: convert the method field to title case if the MethodTitle feature flag is enabled.
+	if b.toggles.Has(MethodTitle) {
+		query.Method = cases.
+			Title(language.English, cases.NoLower).
+			String(query.Method)
+	}
+
+	// Lowercase the header names of the query if the LowerHeaders feature flag is enabled.
+	if b.toggles.Has(LowerHeaders) {
+		query.Headers = lowerKeys(query.Headers)
+	}
+
+	return query
+}
+
 // FindByQuery retrieves the Stub value associated with the given Query from the Budgerigar's searcher.
 //
 // Parameters:
@@ -122,22 +333,119 @@ func (b *Budgerigar) FindByID(id uuid.UUID) *Stub {
 // - *Result: The Result containing the found Stub value (if any), or nil.
 // - error: An error if the search fails.
 func (b *Budgerigar) FindByQuery(query Query) (*Result, error) {
-	// Backward compatibility: convert the method field to title case if the MethodTitle feature flag is enabled.
-	if b.toggles.Has(MethodTitle) {
-		query.Method = cases.
-			Title(language.English, cases.NoLower).
-			String(query.Method)
-	}
+	return b.FindByQueryContext(context.Background(), query)
+}
+
+// findByQuery searches the Stub value answering the given Query, tracing the
+// matching within the span of the given context.
+func (b *Budgerigar) findByQuery(ctx context.Context, query Query) (*Result, error) {
+	// Keep the query as received, which identifies it in the match history.
+	received := query
+
+	query = b.canonicalQuery(query)
 
 	// Find the Stub value associated with the given Query from the Budgerigar's searcher.
-	//
-	// Parameters:
-	// - query: The Query used to search for a Stub value.
-	//
-	// Returns:
-	// - *Result: The Result containing the found Stub value (if any), or nil.
-	// - error: An error if the search fails.
-	return b.searcher.find(query)
+	start := time.Now()
+	result, err := b.match(ctx, query)
+	latency := time.Since(start)
+
+	b.metrics.ObserveSearch(query.Service, query.Method, err == nil && result.found != nil, latency)
+
+	// The searches of the invalidated bidi streams have no effects: their
+	// matches are checked before the stubs are marked as used, and their
+	// misses before they are recorded.
+	if errors.Is(err, ErrBidiInvalidated) || ((err != nil || result.found == nil) && b.searcher.invalidated(query)) {
+		return nil, ErrBidiInvalidated
+	}
+
+	// Internal queries are not misses of the clients.
+	if !query.RequestInternal() {
+		b.misses.record(query, result, err)
+
+		if err != nil || result.found == nil {
+			b.searcher.events.missed(query, result)
+		}
+	}
+
+	// Answer the misses as configured for the service and the method, which
+	// internal queries ignore.
+	if (err != nil || result.found == nil) && !query.RequestInternal() {
+		return b.notFound.apply(query, result, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Internal queries are not affected by rate limits and chaos.
+	if result.found == nil || query.RequestInternal() {
+		return result, nil
+	}
+
+	// Record the match before the overlays replace the Stub value.
+	b.history.record(received, result.found, result.rank)
+
+	// Select one of the alternative outputs of the Stub value, if any.
+	result.found = b.picker.pick(result.found, cmp.Or(result.found.Output.Seed, b.templates.currentSeed()))
+
+	// Answer with the page of the dataset of the Stub value, if any.
+	result.found = b.pages.apply(query, result.found)
+
+	// Count the match against the rate limits of the Stub value and its service.
+	result.found = b.limiter.apply(result.found)
+
+	// Inject the faults of the Stub value.
+	result.found = b.faults.Inject(result.found)
+
+	// Apply the chaos profile to the found Stub value.
+	if profile := b.chaos.Load(); profile != nil {
+		result.found = profile.apply(result.found)
+	}
+
+	// Take a slot of the Stub value and of the global concurrency limit,
+	// until Done is called or for the delay of the output including the
+	// latency of the faults and chaos.
+	result.found, result.release = b.inflight.apply(result.found)
+
+	// Keep the match with the response of the Stub value as answered.
+	b.recordTraffic(received, result.found, latency)
+
+	// Notify the registered hooks, the subscriptions and the listeners about the match.
+	b.hooks.matched(result.found, query)
+	b.searcher.events.matched(result.found, query)
+
+	return result, nil
+}
+
+// OnMatch registers a hook invoked asynchronously after each match.
+//
+// The invocations are queued and run by a bounded number of workers, see
+// WithHookQueue; they are dropped and logged when the queue is full.
+//
+// Internal queries do not trigger hooks.
+//
+// Parameters:
+// - hook: The MatchHook to invoke.
+func (b *Budgerigar) OnMatch(hook MatchHook) {
+	b.hooks.add(hook)
+}
+
+// SetChaos sets the chaos profile applied to every match.
+//
+// Passing nil disables the chaos profile.
+//
+// Parameters:
+// - profile: The ChaosProfile to apply, or nil.
+func (b *Budgerigar) SetChaos(profile *ChaosProfile) {
+	b.chaos.Store(profile)
+}
+
+// Chaos returns the current chaos profile.
+//
+// Returns:
+// - *ChaosProfile: The active ChaosProfile, or nil if none is set.
+func (b *Budgerigar) Chaos() *ChaosProfile {
+	return b.chaos.Load()
 }
 
 // FindBy retrieves all Stub values that match the given service and method
@@ -156,29 +464,95 @@ func (b *Budgerigar) FindBy(service, method string) ([]*Stub, error) {
 
 // All returns all Stub values from the Budgerigar's searcher.
 //
+// The Stub values are in an arbitrary order, unless WithSortedListings is set.
+//
 // Returns:
 // - []*Stub: All Stub values.
 func (b *Budgerigar) All() []*Stub {
-	return b.searcher.all()
+	return b.listing(b.searcher.all())
 }
 
 // Used returns all Stub values that have been used from the Budgerigar's searcher.
 //
+// The Stub values are in an arbitrary order, unless WithSortedListings is set.
+//
 // Returns:
 // - []*Stub: All used Stub values.
 func (b *Budgerigar) Used() []*Stub {
-	return b.searcher.used()
+	return b.listing(b.searcher.used())
 }
 
 // Unused returns all Stub values that have not been used from the Budgerigar's searcher.
 //
+// The Stub values are in an arbitrary order, unless WithSortedListings is set.
+//
 // Returns:
 // - []*Stub: All unused Stub values.
 func (b *Budgerigar) Unused() []*Stub {
-	return b.searcher.unused()
+	return b.listing(b.searcher.unused())
+}
+
+// SetRateLimit sets the rate limit shared by all the stubs of the given service.
+//
+// Passing nil removes the rate limit of the service.
+//
+// Parameters:
+// - service: The name of the service.
+// - limit: The RateLimit to apply, or nil.
+func (b *Budgerigar) SetRateLimit(service string, limit *RateLimit) {
+	b.limiter.setService(service, limit)
+}
+
+// SetMaxConcurrent sets the concurrency limit shared by all the stubs, to
+// simulate a server answering a bounded number of simultaneous calls.
+//
+// A call holds its slot until Result.Done is called. Unless the calls are
+// tracked with WithCallTracking, a call is also over once its delay elapses,
+// so the calls answered without delay are never limited. See
+// ConcurrencyLimit.
+//
+// Passing nil removes the limit.
+//
+// Parameters:
+// - limit: The ConcurrencyLimit to apply, or nil.
+func (b *Budgerigar) SetMaxConcurrent(limit *ConcurrencyLimit) {
+	b.inflight.setGlobal(limit)
+}
+
+// OrderViolations returns the calls that matched a stub of an ordered group
+// out of order. The last 1000 violations are kept.
+//
+// Returns:
+// - []OrderViolation: The recorded order violations.
+func (b *Budgerigar) OrderViolations() []OrderViolation {
+	return b.searcher.orderViolations()
 }
 
 // Clear clears all Stub values from the Budgerigar's searcher.
+//
+// The bidi streams started before the clear are invalidated, and their next
+// messages fail with ErrBidiInvalidated.
 func (b *Budgerigar) Clear() {
+	// Clearing the searcher invalidates the bidi streams.
 	b.searcher.clear()
+	b.templateCache.reset()
+	b.limiter.reset()
+	b.inflight.reset()
+	b.picker.reset()
+	b.pages.reset()
+	b.remote.reset()
+	b.searcher.events.publish(EventClear, nil)
+}
+
+// NewQuery decodes the query of the request, honoring the StrictQuery
+// feature flag of the Budgerigar.
+//
+// Parameters:
+// - r: The HTTP request whose body is the query.
+//
+// Returns:
+// - Query: The decoded query.
+// - error: An error if the body cannot be decoded.
+func (b *Budgerigar) NewQuery(r *http.Request) (Query, error) {
+	return NewQueryFlags(r, b.toggles)
 }
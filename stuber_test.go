@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/bavix/features"
 	"github.com/google/uuid"
@@ -90,6 +92,29 @@ func TestUpdateMany(t *testing.T) {
 	require.Len(t, s.All(), 1)
 }
 
+func TestUpdateMany_Moved(t *testing.T) {
+	s := stuber.NewBudgerigar(features.New())
+
+	id := uuid.New()
+
+	s.PutMany(&stuber.Stub{ID: id, Service: "Greeter1", Method: "SayHello1"})
+	s.UpdateMany(&stuber.Stub{ID: id, Service: "Greeter2", Method: "SayHello2"})
+	s.PutMany(&stuber.Stub{ID: id, Service: "Greeter2", Method: "SayHello2"})
+
+	require.Len(t, s.All(), 1)
+
+	stubs, err := s.FindBy("Greeter1", "SayHello1")
+	require.NoError(t, err)
+	require.Empty(t, stubs)
+
+	stubs, err = s.FindBy("Greeter2", "SayHello2")
+	require.NoError(t, err)
+	require.Len(t, stubs, 1)
+
+	require.Equal(t, 0, s.Reindex())
+	require.Len(t, s.All(), 1)
+}
+
 func TestRelationship(t *testing.T) {
 	s := stuber.NewBudgerigar(features.New())
 
@@ -385,6 +410,35 @@ func TestDelete(t *testing.T) {
 	require.Empty(t, all)
 }
 
+func TestDeleteWhere(t *testing.T) {
+	id1, id2, id3 := uuid.New(), uuid.New(), uuid.New()
+
+	s := stuber.New()
+
+	s.PutMany(
+		&stuber.Stub{ID: id1, Service: "Greeter", Method: "SayHello"},
+		&stuber.Stub{ID: id2, Service: "Greeter", Method: "SayHello", Webhook: "tmp"},
+		&stuber.Stub{ID: id3, Service: "Greeter", Method: "SayGoodbye", Webhook: "tmp"},
+	)
+
+	require.Equal(t, 0, s.DeleteWhere(func(*stuber.Stub) bool { return false }))
+	require.Len(t, s.All(), 3)
+
+	require.Equal(t, 2, s.DeleteWhere(func(stub *stuber.Stub) bool { return stub.Webhook == "tmp" }))
+	require.Len(t, s.All(), 1)
+	require.NotNil(t, s.FindByID(id1))
+	require.Nil(t, s.FindByID(id2))
+	require.Nil(t, s.FindByID(id3))
+
+	all, err := s.FindBy("Greeter", "SayHello")
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+
+	all, err = s.FindBy("Greeter", "SayGoodbye")
+	require.NoError(t, err)
+	require.Empty(t, all)
+}
+
 func TestBudgerigar_Clear(t *testing.T) {
 	s := stuber.NewBudgerigar(features.New(stuber.MethodTitle))
 
@@ -411,3 +465,101 @@ func TestBudgerigar_Clear(t *testing.T) {
 
 	require.Empty(t, s.All())
 }
+
+func TestBudgerigar_DependsOn(t *testing.T) {
+	s := stuber.NewBudgerigar(features.New())
+
+	create := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Orders",
+		Method:  "Create",
+		Output:  stuber.Output{Data: map[string]interface{}{"id": "42"}},
+	}
+	get := &stuber.Stub{
+		ID:        uuid.New(),
+		Service:   "Orders",
+		Method:    "Get",
+		Input:     stuber.InputData{Equals: map[string]interface{}{"id": "42"}},
+		Output:    stuber.Output{Data: map[string]interface{}{"status": "created"}},
+		DependsOn: []uuid.UUID{create.ID},
+	}
+
+	s.PutMany(create, get)
+
+	getQuery := stuber.Query{Service: "Orders", Method: "Get", Data: map[string]interface{}{"id": "42"}}
+
+	r, err := s.FindByQuery(getQuery)
+	require.NoError(t, err)
+	require.Nil(t, r.Found())
+	require.Equal(t, get.ID, r.Similar().ID)
+
+	r, err = s.FindByQuery(stuber.Query{Service: "Orders", Method: "Create"})
+	require.NoError(t, err)
+	require.Equal(t, create.ID, r.Found().ID)
+
+	r, err = s.FindByQuery(getQuery)
+	require.NoError(t, err)
+	require.Equal(t, get.ID, r.Found().ID)
+}
+
+func BenchmarkBudgerigar_FindByQuery(b *testing.B) {
+	s := stuber.New()
+
+	for i := range 100 {
+		s.PutMany(&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input: stuber.InputData{Equals: map[string]interface{}{
+				"name": "user" + strconv.Itoa(i),
+			}},
+		})
+	}
+
+	query := stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "user42"},
+	}
+
+	b.ReportAllocs()
+
+	for range b.N {
+		if _, err := s.FindByQuery(query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBudgerigar_Timestamps(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	id := uuid.New()
+	s.PutMany(&stuber.Stub{ID: id, Service: "Greeter", Method: "SayHello"})
+
+	created := now
+	require.Equal(t, created, *s.FindByID(id).CreatedAt)
+	require.Equal(t, created, *s.FindByID(id).UpdatedAt)
+
+	now = now.Add(time.Hour)
+	require.NoError(t, s.PatchByID(id, func(stub *stuber.Stub) error {
+		stub.Priority = 1
+
+		return nil
+	}))
+	require.Equal(t, created, *s.FindByID(id).CreatedAt)
+	require.Equal(t, now, *s.FindByID(id).UpdatedAt)
+
+	now = now.Add(time.Hour)
+	s.UpdateMany(&stuber.Stub{ID: id, Service: "Greeter", Method: "SayHello"})
+	require.Equal(t, created, *s.FindByID(id).CreatedAt)
+	require.Equal(t, now, *s.FindByID(id).UpdatedAt)
+
+	// Cleanup policies can rely on the timestamps.
+	now = now.Add(90 * 24 * time.Hour)
+	require.Equal(t, 1, s.DeleteWhere(func(stub *stuber.Stub) bool {
+		return now.Sub(*stub.UpdatedAt) >= 90*24*time.Hour
+	}))
+}
@@ -0,0 +1,342 @@
+// Package stuberclient provides a client of the admin API served by
+// stuber.NewAdminHandler, with the method names of stuber.Budgerigar, so test
+// code can target an in-process or a remote mock interchangeably.
+package stuberclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/gripmock/stuber"
+)
+
+// ErrRequestFailed is returned when the admin API cannot be reached or
+// answers an unexpected status.
+var ErrRequestFailed = errors.New("admin request failed")
+
+// Stuber is the set of methods shared by stuber.Budgerigar and Client.
+type Stuber interface {
+	PutMany(values ...*stuber.Stub) []uuid.UUID
+	DeleteByID(ids ...uuid.UUID) int
+	FindByID(id uuid.UUID) *stuber.Stub
+	FindByQuery(query stuber.Query) (*stuber.Result, error)
+	All() []*stuber.Stub
+	Used() []*stuber.Stub
+	Unused() []*stuber.Stub
+	Clear()
+}
+
+var (
+	_ Stuber = (*stuber.Budgerigar)(nil)
+	_ Stuber = (*Client)(nil)
+)
+
+// remoteErrors are the errors of the admin API, by their code.
+var remoteErrors = map[string]error{ //nolint:gochecknoglobals
+	stuber.ErrorCodeServiceNotFound: stuber.ErrServiceNotFound,
+	stuber.ErrorCodeMethodNotFound:  stuber.ErrMethodNotFound,
+	stuber.ErrorCodeStubNotFound:    stuber.ErrStubNotFound,
+	stuber.ErrorCodeInvalidQuery:    stuber.ErrInvalidQuery,
+	stuber.ErrorCodeInvalidImport:   stuber.ErrInvalidImport,
+}
+
+// Client is a client of a remote Budgerigar.
+//
+// The methods of Budgerigar that cannot fail locally don't return an error;
+// their first failure is kept and returned by Err instead.
+type Client struct {
+	base   string
+	client *http.Client
+
+	mu  sync.Mutex
+	err error
+}
+
+// New creates a new Client of the admin API served at the given URL.
+//
+// Parameters:
+// - baseURL: The URL the admin handler is mounted at, such as http://localhost:4771.
+// - client: The HTTP client to use, or nil for http.DefaultClient.
+//
+// Returns:
+// - *Client: A new Client.
+func New(baseURL string, client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Client{base: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// Err returns the first failure of the methods not returning an error, such
+// as PutMany, and forgets it.
+//
+// Returns:
+// - error: The first failure since the last call, or nil.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.err
+	c.err = nil
+
+	return err
+}
+
+// PutMany inserts the given Stub values into the remote Budgerigar.
+//
+// Parameters:
+// - values: The Stub values to insert.
+//
+// Returns:
+// - []uuid.UUID: The keys of the inserted Stub values, or nil on failure.
+func (c *Client) PutMany(values ...*stuber.Stub) []uuid.UUID {
+	var ids []uuid.UUID
+
+	c.keep(c.do(http.MethodPost, "/api/stubs", nil, values, &ids))
+
+	return ids
+}
+
+// DeleteByID deletes the Stub values with the given IDs from the remote
+// Budgerigar.
+//
+// Parameters:
+// - ids: The UUIDs of the Stub values to delete.
+//
+// Returns:
+// - int: The number of deleted Stub values, 0 on failure.
+func (c *Client) DeleteByID(ids ...uuid.UUID) int {
+	var deleted int
+
+	c.keep(c.do(http.MethodPost, "/api/stubs/batchDelete", nil, ids, &deleted))
+
+	return deleted
+}
+
+// FindByID returns the Stub value with the given ID from the remote
+// Budgerigar.
+//
+// Parameters:
+// - id: The UUID of the Stub value.
+//
+// Returns:
+// - *stuber.Stub: The Stub value, or nil if it is not found or on failure.
+func (c *Client) FindByID(id uuid.UUID) *stuber.Stub {
+	var stub *stuber.Stub
+
+	if err := c.do(http.MethodGet, "/api/stubs/"+id.String(), nil, nil, &stub); !errors.Is(err, stuber.ErrStubNotFound) {
+		c.keep(err)
+	}
+
+	return stub
+}
+
+// FindByQuery searches the remote Budgerigar for the Stub value answering
+// the query. Internal and exact-only queries keep their flags.
+//
+// Parameters:
+// - query: The Query used to search for a Stub value.
+//
+// Returns:
+// - *stuber.Result: The Result of the search, without its rank.
+// - error: The error of the search, matching the same errors as a local
+// search, or ErrRequestFailed.
+func (c *Client) FindByQuery(query stuber.Query) (*stuber.Result, error) {
+	header := make(http.Header)
+
+	if query.RequestInternal() {
+		header.Set("X-Gripmock-Requestinternal", "true")
+	}
+
+	if query.ExactOnly() {
+		header.Set("X-Gripmock-Requestexact", "true")
+	}
+
+	var response stuber.SearchResponse
+	if err := c.do(http.MethodPost, "/api/stubs/search", header, query, &response); err != nil {
+		return nil, err
+	}
+
+	return stuber.NewResult(response.Found, response.Similars...), nil
+}
+
+// FindByQueries searches the given queries in order in the remote
+// Budgerigar, in a single request, as FindByQuery does. The internal and
+// exact-only flags of the queries are not sent.
+//
+// Parameters:
+// - queries: The queries to search.
+//
+// Returns:
+// - []stuber.BatchResult: The outcome of each query, in the order of the
+// queries.
+// - error: ErrRequestFailed, or the error of the request, such as one
+// matching stuber.ErrInvalidQuery.
+func (c *Client) FindByQueries(queries ...stuber.Query) ([]stuber.BatchResult, error) {
+	var responses []stuber.BatchSearchResponse
+	if err := c.do(http.MethodPost, "/api/stubs/search", nil, queries, &responses); err != nil {
+		return nil, err
+	}
+
+	if len(responses) != len(queries) {
+		return nil, fmt.Errorf("%w: %d results for %d queries", ErrRequestFailed, len(responses), len(queries))
+	}
+
+	results := make([]stuber.BatchResult, len(responses))
+
+	for i, response := range responses {
+		if response.Error != nil {
+			results[i].Err = bodyError(*response.Error)
+		} else {
+			results[i].Result = stuber.NewResult(response.Found, response.Similars...)
+		}
+	}
+
+	return results, nil
+}
+
+// All returns all the Stub values of the remote Budgerigar.
+//
+// Returns:
+// - []*stuber.Stub: All Stub values, or nil on failure.
+func (c *Client) All() []*stuber.Stub {
+	return c.list("/api/stubs")
+}
+
+// Used returns the used Stub values of the remote Budgerigar.
+//
+// Returns:
+// - []*stuber.Stub: The used Stub values, or nil on failure.
+func (c *Client) Used() []*stuber.Stub {
+	return c.list("/api/stubs/used")
+}
+
+// Unused returns the unused Stub values of the remote Budgerigar.
+//
+// Returns:
+// - []*stuber.Stub: The unused Stub values, or nil on failure.
+func (c *Client) Unused() []*stuber.Stub {
+	return c.list("/api/stubs/unused")
+}
+
+// Clear clears all the Stub values of the remote Budgerigar.
+func (c *Client) Clear() {
+	c.keep(c.do(http.MethodDelete, "/api/stubs", nil, nil, nil))
+}
+
+// list returns the Stub values listed at the given path.
+func (c *Client) list(path string) []*stuber.Stub {
+	var stubs []*stuber.Stub
+
+	c.keep(c.do(http.MethodGet, path, nil, nil, &stubs))
+
+	return stubs
+}
+
+// keep keeps the given error if it is the first one.
+func (c *Client) keep(err error) {
+	if err == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// do sends a request with the JSON encoding of the body, if any, and decodes
+// the response into the result, if any.
+func (c *Client) do(method, path string, header http.Header, body, result any) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, c.base+path, reader)
+	if err != nil {
+		return errors.Join(ErrRequestFailed, err)
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Join(ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp)
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	if err := decoder.Decode(result); err != nil {
+		return errors.Join(ErrRequestFailed, err)
+	}
+
+	return nil
+}
+
+// remoteError is an error answered by the admin API.
+type remoteError struct {
+	message string
+	kind    error
+}
+
+func (e *remoteError) Error() string {
+	return e.message
+}
+
+func (e *remoteError) Unwrap() error {
+	return e.kind
+}
+
+// responseError returns the error of a failed response, matching the error of
+// the remote Budgerigar when its code is recognized.
+func responseError(resp *http.Response) error {
+	var body stuber.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		return fmt.Errorf("%w: status %d", ErrRequestFailed, resp.StatusCode)
+	}
+
+	return bodyError(body)
+}
+
+// bodyError returns the error of the given ErrorResponse, matching the error
+// of the remote Budgerigar when its code is recognized.
+func bodyError(body stuber.ErrorResponse) error {
+	if kind, ok := remoteErrors[body.Code]; ok {
+		return &remoteError{message: body.Error, kind: kind}
+	}
+
+	return &remoteError{message: body.Error, kind: ErrRequestFailed}
+}
@@ -0,0 +1,96 @@
+package stuberclient_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+	"github.com/gripmock/stuber/stuberclient"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(stuber.NewAdminHandler(stuber.New()))
+	defer server.Close()
+
+	local := stuber.New()
+	remote := stuberclient.New(server.URL+"/", nil)
+
+	for _, s := range []stuberclient.Stuber{local, remote} {
+		stub := &stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Greeter",
+			Method:  "SayHello",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			Output:  stuber.Output{Data: map[string]interface{}{"message": "Hello Bob"}},
+		}
+
+		require.Equal(t, []uuid.UUID{stub.ID}, s.PutMany(stub))
+		require.Len(t, s.All(), 1)
+		require.Equal(t, stub.Service, s.FindByID(stub.ID).Service)
+		require.Nil(t, s.FindByID(uuid.New()))
+
+		result, err := s.FindByQuery(stuber.Query{
+			Service: "Greeter",
+			Method:  "SayHello",
+			Data:    map[string]interface{}{"name": "Bob"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, stub.ID, result.Found().ID)
+		require.Equal(t, "Hello Bob", result.Found().Output.Data["message"])
+
+		_, err = s.FindByQuery(stuber.Query{Service: "Unknown", Method: "SayHello", Data: map[string]interface{}{}})
+		require.ErrorIs(t, err, stuber.ErrServiceNotFound)
+
+		require.Len(t, s.Used(), 1)
+		require.Empty(t, s.Unused())
+
+		require.Equal(t, 1, s.DeleteByID(stub.ID))
+		require.Empty(t, s.All())
+
+		s.PutMany(stub)
+		s.Clear()
+		require.Empty(t, s.All())
+	}
+
+	require.NoError(t, remote.Err())
+}
+
+func TestClient_FindByQueries(t *testing.T) {
+	s := stuber.New()
+	server := httptest.NewServer(stuber.NewAdminHandler(s))
+	defer server.Close()
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	s.PutMany(stub)
+
+	remote := stuberclient.New(server.URL, nil)
+
+	results, err := remote.FindByQueries(
+		stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}},
+		stuber.Query{Service: "Unknown", Method: "SayHello", Data: map[string]interface{}{}},
+	)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, stub.ID, results[0].Result.Found().ID)
+	require.NoError(t, results[0].Err)
+	require.ErrorIs(t, results[1].Err, stuber.ErrServiceNotFound)
+
+	_, err = remote.FindByQueries(stuber.Query{Service: "Greeter"})
+	require.ErrorIs(t, err, stuber.ErrInvalidQuery)
+}
+
+func TestClient_Err(t *testing.T) {
+	remote := stuberclient.New("http://127.0.0.1:0", nil)
+
+	require.Nil(t, remote.PutMany(&stuber.Stub{Service: "Greeter", Method: "SayHello"}))
+	require.ErrorIs(t, remote.Err(), stuberclient.ErrRequestFailed)
+	require.NoError(t, remote.Err())
+}
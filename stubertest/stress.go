@@ -0,0 +1,156 @@
+// Package stubertest provides helpers to test code built on the stuber package.
+package stubertest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/gripmock/stuber"
+)
+
+// Default settings of Stress.
+const (
+	defaultWorkers    = 8
+	defaultIterations = 200
+	defaultServices   = 4
+)
+
+// StressOptions configures Stress.
+type StressOptions struct {
+	// Workers is the number of concurrent goroutines, 8 by default.
+	Workers int
+	// Iterations is the number of put, search and delete cycles of each worker, 200 by default.
+	Iterations int
+	// Services is the number of services the stubs are spread over, 4 by default.
+	Services int
+}
+
+// withDefaults returns the options with the zero values replaced by the defaults.
+func (o StressOptions) withDefaults() StressOptions {
+	if o.Workers <= 0 {
+		o.Workers = defaultWorkers
+	}
+
+	if o.Iterations <= 0 {
+		o.Iterations = defaultIterations
+	}
+
+	if o.Services <= 0 {
+		o.Services = defaultServices
+	}
+
+	return o
+}
+
+// Stress hammers the Budgerigar with concurrent insertions, searches,
+// patches and deletions, checking invariants along the way. It is meant to
+// be run with the race detector.
+//
+// Each worker owns its stubs, so it checks that:
+//   - its stubs can be found by ID after their insertion, and not after their deletion;
+//   - the queries it sends match a stub of the queried service and method;
+//   - the queries built from its stubs' unique input match exactly these stubs.
+//
+// The Budgerigar should be empty, and is left empty when no invariant fails.
+//
+// Parameters:
+// - t: The test reporting the failed invariants.
+// - b: The Budgerigar to stress.
+// - opts: The number of workers, iterations and services.
+func Stress(t testing.TB, b *stuber.Budgerigar, opts StressOptions) {
+	t.Helper()
+
+	opts = opts.withDefaults()
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, opts.Workers)
+
+	for worker := range opts.Workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := stressWorker(b, worker, opts); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// stressWorker runs the cycles of one worker, returning the first failed invariant.
+func stressWorker(b *stuber.Budgerigar, worker int, opts StressOptions) error {
+	for i := range opts.Iterations {
+		service := fmt.Sprintf("Service%d", (worker+i)%opts.Services)
+		key := fmt.Sprintf("%d-%d", worker, i)
+
+		stub := &stuber.Stub{
+			ID:      uuid.New(),
+			Service: service,
+			Method:  "Call",
+			Input:   stuber.InputData{Equals: map[string]interface{}{"key": key}},
+			Output:  stuber.Output{Data: map[string]interface{}{"key": key}},
+		}
+
+		b.PutMany(stub)
+
+		if b.FindByID(stub.ID) == nil {
+			return fmt.Errorf("worker %d: stub %s not found after insertion", worker, stub.ID)
+		}
+
+		result, err := b.FindByQuery(stuber.Query{
+			Service: service,
+			Method:  "Call",
+			Data:    map[string]interface{}{"key": key},
+		})
+		if err != nil {
+			return fmt.Errorf("worker %d: search of %s: %w", worker, key, err)
+		}
+
+		if found := result.Found(); found == nil || found.ID != stub.ID {
+			return fmt.Errorf("worker %d: search of %s did not match stub %s", worker, key, stub.ID)
+		}
+
+		if err := b.PatchByID(stub.ID, func(stub *stuber.Stub) error {
+			stub.Priority++
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("worker %d: patch of %s: %w", worker, stub.ID, err)
+		}
+
+		// Searches of other services run concurrently with the changes of the other workers.
+		other := fmt.Sprintf("Service%d", (worker+i+1)%opts.Services)
+		if result, err := b.FindByQuery(stuber.Query{
+			Service: other,
+			Method:  "Call",
+			Data:    map[string]interface{}{"key": key},
+		}); err == nil && result.Found() != nil && result.Found().Service != other {
+			return fmt.Errorf("worker %d: search of %s matched a stub of %s", worker, other, result.Found().Service)
+		}
+
+		_ = b.All()
+		_ = b.Used()
+
+		if n := b.DeleteByID(stub.ID); n != 1 {
+			return fmt.Errorf("worker %d: deleted %d stubs instead of %s", worker, n, stub.ID)
+		}
+
+		if b.FindByID(stub.ID) != nil {
+			return fmt.Errorf("worker %d: stub %s found after deletion", worker, stub.ID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package stubertest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+	"github.com/gripmock/stuber/stubertest"
+)
+
+func TestStress(t *testing.T) {
+	b := stuber.New(stuber.WithParallelRanking(2, 2))
+
+	stubertest.Stress(t, b, stubertest.StressOptions{Workers: 8, Iterations: 50})
+
+	require.Empty(t, b.All())
+}
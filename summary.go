@@ -0,0 +1,79 @@
+package stuber
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+)
+
+// BucketSummary are the statistics of the stubs of a service method.
+type BucketSummary struct {
+	Service           string              `json:"service"`           // The name of the service.
+	Method            string              `json:"method"`            // The name of the method.
+	Stubs             int                 `json:"stubs"`             // The number of stubs.
+	MinPriority       int                 `json:"minPriority"`       // The lowest priority of the stubs.
+	MaxPriority       int                 `json:"maxPriority"`       // The highest priority of the stubs.
+	Matchers          map[MatcherKind]int `json:"matchers"`          // The number of stubs using each matcher kind, on the input or the headers.
+	AverageOutputSize float64             `json:"averageOutputSize"` // The average size of the JSON encoding of the outputs, in bytes.
+}
+
+// Summary returns the statistics of the stubs of each service method, for
+// capacity planning and spotting suspicious buckets, such as thousands of
+// stubs on a single method.
+//
+// Returns:
+// - []BucketSummary: The statistics, sorted by service and method.
+func (b *Budgerigar) Summary() []BucketSummary {
+	type bucket struct{ service, method string }
+
+	var (
+		buckets     = make(map[bucket]*BucketSummary)
+		outputSizes = make(map[bucket]int)
+	)
+
+	for _, stub := range b.searcher.all() {
+		key := bucket{stub.Service, stub.Method}
+
+		summary, ok := buckets[key]
+		if !ok {
+			summary = &BucketSummary{
+				Service:     stub.Service,
+				Method:      stub.Method,
+				MinPriority: stub.Priority,
+				MaxPriority: stub.Priority,
+				Matchers:    make(map[MatcherKind]int),
+			}
+			buckets[key] = summary
+		}
+
+		summary.Stubs++
+		summary.MinPriority = min(summary.MinPriority, stub.Priority)
+		summary.MaxPriority = max(summary.MaxPriority, stub.Priority)
+
+		inputs := stub.Input.Matchers()
+		headers := stub.Headers.Matchers()
+
+		for _, kind := range MatcherKinds() {
+			if len(inputs[kind]) > 0 || len(headers[kind]) > 0 {
+				summary.Matchers[kind]++
+			}
+		}
+
+		if data, err := json.Marshal(stub.Output); err == nil {
+			outputSizes[key] += len(data)
+		}
+	}
+
+	summaries := make([]BucketSummary, 0, len(buckets))
+
+	for key, summary := range buckets {
+		summary.AverageOutputSize = float64(outputSizes[key]) / float64(summary.Stubs)
+		summaries = append(summaries, *summary)
+	}
+
+	slices.SortFunc(summaries, func(a, b BucketSummary) int {
+		return cmp.Or(cmp.Compare(a.Service, b.Service), cmp.Compare(a.Method, b.Method))
+	})
+
+	return summaries
+}
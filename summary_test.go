@@ -0,0 +1,75 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Summary(t *testing.T) {
+	s := stuber.New()
+
+	require.Empty(t, s.Summary())
+
+	s.PutMany(
+		&stuber.Stub{
+			ID:       uuid.New(),
+			Service:  "Greeter",
+			Method:   "SayHello",
+			Priority: -1,
+			Input:    stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+			Headers:  stuber.InputHeader{Matches: map[string]interface{}{"x-user": ".+"}},
+			Output:   stuber.Output{Data: map[string]interface{}{"message": "Hello Bob"}},
+		},
+		&stuber.Stub{
+			ID:       uuid.New(),
+			Service:  "Greeter",
+			Method:   "SayHello",
+			Priority: 3,
+			Input:    stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+			Output:   stuber.Output{Data: map[string]interface{}{"message": "Hello Alice, welcome back"}},
+		},
+		&stuber.Stub{
+			ID:      uuid.New(),
+			Service: "Admin",
+			Method:  "Reset",
+			Input:   stuber.InputData{Contains: map[string]interface{}{"force": true}},
+		},
+	)
+
+	summary := s.Summary()
+	require.Len(t, summary, 2)
+
+	require.Equal(t, "Admin", summary[0].Service)
+	require.Equal(t, 1, summary[0].Stubs)
+	require.Equal(t, map[stuber.MatcherKind]int{stuber.MatcherContains: 1}, summary[0].Matchers)
+
+	greeter := summary[1]
+	require.Equal(t, "SayHello", greeter.Method)
+	require.Equal(t, 2, greeter.Stubs)
+	require.Equal(t, -1, greeter.MinPriority)
+	require.Equal(t, 3, greeter.MaxPriority)
+	require.Equal(t, map[stuber.MatcherKind]int{
+		stuber.MatcherEquals:  2,
+		stuber.MatcherMatches: 1,
+	}, greeter.Matchers)
+
+	var sizes int
+
+	for stub := range s.IterBy("Greeter", "SayHello") {
+		data, err := json.Marshal(stub.Output)
+		require.NoError(t, err)
+
+		sizes += len(data)
+	}
+
+	require.InDelta(t, float64(sizes)/2, greeter.AverageOutputSize, 0.001)
+
+	data, err := json.Marshal(greeter)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"matchers":{"equals":2,"matches":1}`)
+}
@@ -0,0 +1,407 @@
+package stuber
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// ErrInvalidTemplate is returned when a template of an output cannot be parsed.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// TemplateUsage describes what the templates of the output of a stub use.
+//
+// Request fields are the chains of .Request or .Query.Data, such as
+// "user.name" for {{.Request.user.name}}, and header fields the chains of
+// .Headers or .Query.Headers.
+type TemplateUsage struct {
+	Functions    []string `json:"functions,omitempty"`    // The template functions called.
+	Fields       []string `json:"fields,omitempty"`       // The request fields referenced.
+	Headers      []string `json:"headers,omitempty"`      // The header fields referenced.
+	Unguaranteed []string `json:"unguaranteed,omitempty"` // The request fields the input matchers don't guarantee to exist.
+}
+
+// AnalyzeTemplates returns the template functions and the request fields
+// referenced by the output of the stub, in its data, error and headers.
+//
+// The referenced request fields that are not mentioned by the input
+// matchers of the stub are Unguaranteed: a matching request may lack them.
+//
+// Parameters:
+// - stub: The Stub value to analyze.
+//
+// Returns:
+// - TemplateUsage: The sorted functions and fields used.
+// - error: An error matching ErrInvalidTemplate if a template cannot be parsed.
+func AnalyzeTemplates(stub *Stub) (TemplateUsage, error) {
+	a := templateAnalyzer{
+		functions: map[string]struct{}{},
+		fields:    map[string]struct{}{},
+		headers:   map[string]struct{}{},
+	}
+
+	var errs []error
+
+	outputStrings(stub.Output, func(location, text string) {
+		errs = append(errs, a.analyze(location, text))
+	})
+
+	usage := TemplateUsage{
+		Functions: slices.Sorted(maps.Keys(a.functions)),
+		Fields:    slices.Sorted(maps.Keys(a.fields)),
+		Headers:   slices.Sorted(maps.Keys(a.headers)),
+	}
+
+	for _, field := range usage.Fields {
+		if !guaranteed(stub.Input, strings.Split(field, ".")) {
+			usage.Unguaranteed = append(usage.Unguaranteed, field)
+		}
+	}
+
+	return usage, errors.Join(errs...)
+}
+
+// templateAnalyzer collects the functions and the fields used by templates.
+type templateAnalyzer struct {
+	functions map[string]struct{}
+	fields    map[string]struct{}
+	headers   map[string]struct{}
+}
+
+// analyze parses the given text as a template, found at the given location,
+// and collects what it uses.
+func (a *templateAnalyzer) analyze(location, text string) error {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+
+	tree := parse.New(location)
+	tree.Mode = parse.SkipFuncCheck
+
+	if _, err := tree.Parse(text, "", "", map[string]*parse.Tree{}); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, location, err)
+	}
+
+	a.node(tree.Root, true)
+
+	return nil
+}
+
+// node collects what the given node uses. Fields are only resolved while
+// the dot is the root of the template data, as with and range change it.
+//
+//nolint:cyclop
+func (a *templateAnalyzer) node(node parse.Node, root bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			a.node(child, root)
+		}
+	case *parse.ActionNode:
+		a.node(n.Pipe, root)
+	case *parse.IfNode:
+		a.node(n.Pipe, root)
+		a.node(n.List, root)
+		a.node(n.ElseList, root)
+	case *parse.RangeNode:
+		a.node(n.Pipe, root)
+		a.node(n.List, false)
+		a.node(n.ElseList, root)
+	case *parse.WithNode:
+		a.node(n.Pipe, root)
+		a.node(n.List, false)
+		a.node(n.ElseList, root)
+	case *parse.TemplateNode:
+		a.node(n.Pipe, root)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, cmd := range n.Cmds {
+			a.node(cmd, root)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			a.node(arg, root)
+		}
+	case *parse.IdentifierNode:
+		a.functions[n.Ident] = struct{}{}
+	case *parse.FieldNode:
+		if root {
+			a.chain(n.Ident)
+		}
+	case *parse.VariableNode:
+		// $ is the root of the template data, whatever the dot is.
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			a.chain(n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		a.node(n.Node, root)
+	}
+}
+
+// chain collects the field referenced by the given chain of identifiers.
+func (a *templateAnalyzer) chain(idents []string) {
+	if len(idents) < 2 { //nolint:mnd
+		return
+	}
+
+	// The data and headers of the full query are the same as the shortcuts.
+	if idents[0] == "Query" && len(idents) > 2 {
+		switch idents[1] {
+		case "Data":
+			idents = append([]string{"Request"}, idents[2:]...)
+		case "Headers":
+			idents = idents[1:]
+		}
+	}
+
+	switch idents[0] {
+	case "Request":
+		a.fields[strings.Join(idents[1:], ".")] = struct{}{}
+	case "Headers":
+		a.headers[strings.Join(idents[1:], ".")] = struct{}{}
+	}
+}
+
+// outputStrings calls the given function with the strings of the output,
+// which may be templates, and their locations.
+func outputStrings(output Output, fn func(location, text string)) {
+	walkStrings("output.data", output.Data, fn)
+
+	fn("output.error", output.Error)
+
+	for _, name := range slices.Sorted(maps.Keys(output.Headers)) {
+		fn("output.headers."+name, output.Headers[name])
+	}
+}
+
+// walkStrings calls the given function with the string values nested in the
+// given value and their locations.
+func walkStrings(location string, value any, fn func(location, text string)) {
+	switch v := value.(type) {
+	case string:
+		fn(location, v)
+	case map[string]interface{}:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			walkStrings(location+"."+key, v[key], fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkStrings(location+"["+strconv.Itoa(i)+"]", item, fn)
+		}
+	}
+}
+
+// guaranteed checks if a request matching the given input data has the field
+// at the given path, because one of the matchers mentions it.
+func guaranteed(input InputData, path []string) bool {
+	for _, fields := range input.Matchers() {
+		if hasPath(fields, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasPath checks if the given value has a value at the given path of keys.
+func hasPath(value any, path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	next, ok := fields[path[0]]
+	if !ok {
+		return false
+	}
+
+	return hasPath(next, path[1:])
+}
+
+// templates holds the template functions available to a Budgerigar.
+type templates struct {
+	mu    sync.RWMutex
+	now   func() time.Time
+	allow map[string]struct{} // The only allowed functions, all if nil.
+	deny  map[string]struct{} // The denied functions.
+	funcs template.FuncMap
+
+	strictMath bool    // Whether div and mod return ErrDivisionByZero.
+	seed       *uint64 // The seed of the random functions, if any.
+}
+
+// newTemplates creates templates with all the template functions.
+func newTemplates() *templates {
+	t := &templates{now: time.Now}
+	t.build()
+
+	return t
+}
+
+// restrict sets the allowed and denied functions, nil keeping the current
+// ones, and rebuilds the available functions.
+func (t *templates) restrict(allow, deny []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if allow != nil {
+		t.allow = nameSet(allow)
+	}
+
+	if deny != nil {
+		t.deny = nameSet(deny)
+	}
+
+	t.buildLocked()
+}
+
+// setClock sets the clock of the time functions.
+func (t *templates) setClock(now func() time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.now = now
+	t.buildLocked()
+}
+
+// setStrictMath sets whether div and mod return ErrDivisionByZero.
+func (t *templates) setStrictMath(strict bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.strictMath = strict
+	t.buildLocked()
+}
+
+// build builds the available functions.
+func (t *templates) build() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buildLocked()
+}
+
+// buildLocked builds the available functions from the allowed and denied ones.
+//
+// The caller must hold the write lock.
+func (t *templates) buildLocked() {
+	t.funcs = templateFunctions(t.now, t.strictMath)
+
+	for name := range t.funcs {
+		_, allowed := t.allow[name]
+		_, denied := t.deny[name]
+
+		if (t.allow != nil && !allowed) || denied {
+			delete(t.funcs, name)
+		}
+	}
+}
+
+// setSeed sets the seed of the random functions.
+func (t *templates) setSeed(seed uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seed = &seed
+}
+
+// currentSeed returns the seed of the random functions, if any.
+func (t *templates) currentSeed() *uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.seed
+}
+
+// restricted returns the given functions that are available.
+func (t *templates) restricted(funcs template.FuncMap) template.FuncMap {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for name := range funcs {
+		if _, ok := t.funcs[name]; !ok {
+			delete(funcs, name)
+		}
+	}
+
+	return funcs
+}
+
+// parse parses the given text as a template with the available functions.
+func (t *templates) parse(name, text string) (*template.Template, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tmpl, err := template.New(name).Funcs(t.funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	return tmpl, nil
+}
+
+// nameSet returns the set of the given names.
+func nameSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+
+	return set
+}
+
+// ParseTemplate parses the given text as an output template, with the
+// template functions available to the Budgerigar.
+//
+// Parameters:
+// - name: The name of the template, reported by its errors.
+// - text: The text of the template.
+//
+// Returns:
+// - *template.Template: The parsed template.
+// - error: An error matching ErrInvalidTemplate, such as for a function that
+// is not available.
+func (b *Budgerigar) ParseTemplate(name, text string) (*template.Template, error) {
+	return b.templates.parse(name, text)
+}
+
+// ValidateTemplates parses the templates of the output of the stub, with the
+// template functions available to the Budgerigar.
+//
+// Parameters:
+// - stub: The Stub value whose output is validated.
+//
+// Returns:
+// - error: The errors of the templates that cannot be parsed, matching
+// ErrInvalidTemplate, or nil.
+func (b *Budgerigar) ValidateTemplates(stub *Stub) error {
+	var errs []error
+
+	outputStrings(stub.Output, func(location, text string) {
+		if strings.Contains(text, "{{") {
+			if _, err := b.templates.parse(location, text); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package stuber_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestAnalyzeTemplates(t *testing.T) {
+	usage, err := stuber.AnalyzeTemplates(&stuber.Stub{
+		Input: stuber.InputData{
+			Equals:   map[string]interface{}{"user": map[string]interface{}{"name": "Bob"}},
+			Contains: map[string]interface{}{"id": "42"},
+		},
+		Output: stuber.Output{
+			Data: map[string]interface{}{
+				"message": "Hello {{ upper .Request.user.name }}",
+				"items": []interface{}{
+					"{{ .Request.id }}",
+					"{{ with .Request.user }}{{ .name }} {{ $.Request.email }}{{ end }}",
+				},
+			},
+			Error:   "{{ if .Request.fail }}failed{{ end }}",
+			Headers: map[string]string{"x-trace": "{{ .Headers.trace }}"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, stuber.TemplateUsage{
+		Functions:    []string{"upper"},
+		Fields:       []string{"email", "fail", "id", "user", "user.name"},
+		Headers:      []string{"trace"},
+		Unguaranteed: []string{"email", "fail"},
+	}, usage)
+}
+
+func TestAnalyzeTemplates_Invalid(t *testing.T) {
+	usage, err := stuber.AnalyzeTemplates(&stuber.Stub{
+		Output: stuber.Output{
+			Data: map[string]interface{}{
+				"message": "{{ .Request.name",
+				"id":      "{{ .Request.id }}",
+			},
+		},
+	})
+	require.ErrorIs(t, err, stuber.ErrInvalidTemplate)
+	require.ErrorContains(t, err, "output.data.message")
+	require.Equal(t, []string{"id"}, usage.Fields)
+}
+
+func TestBudgerigar_ParseTemplate(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := stuber.New(stuber.WithClock(func() time.Time { return now }))
+
+	tmpl, err := s.ParseTemplate("message", `{{ upper .name }} {{ add .a .b }} {{ div 1 0 }} {{ unix now }}`)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+
+	require.NoError(t, tmpl.Execute(&buf, map[string]interface{}{
+		"name": "Bob",
+		"a":    json.Number("1"),
+		"b":    2.5,
+	}))
+	require.Equal(t, "BOB 3.5 0 1704164645", buf.String())
+
+	_, err = s.ParseTemplate("message", `{{ unknown }}`)
+	require.ErrorIs(t, err, stuber.ErrInvalidTemplate)
+}
+
+func TestNew_WithTemplateFunctions(t *testing.T) {
+	stub := &stuber.Stub{
+		Output: stuber.Output{
+			Data:  map[string]interface{}{"id": "{{ uuid }}", "name": "{{ upper .Request.name }}"},
+			Error: "{{ now }}",
+		},
+	}
+
+	require.NoError(t, stuber.New().ValidateTemplates(stub))
+
+	err := stuber.New(stuber.WithoutTemplateFunctions("now", "random", "uuid")).ValidateTemplates(stub)
+	require.ErrorIs(t, err, stuber.ErrInvalidTemplate)
+	require.ErrorContains(t, err, "output.data.id")
+	require.ErrorContains(t, err, "output.error")
+	require.NotContains(t, err.Error(), "output.data.name")
+
+	err = stuber.New(stuber.WithTemplateFunctions("upper")).ValidateTemplates(stub)
+	require.ErrorContains(t, err, "output.data.id")
+	require.NotContains(t, err.Error(), "output.data.name")
+
+	_, err = stuber.New(stuber.WithTemplateFunctions("upper")).ParseTemplate("name", "{{ upper .Request.name }}")
+	require.NoError(t, err)
+}
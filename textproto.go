@@ -0,0 +1,225 @@
+package stuber
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ErrInvalidFixture is returned when a protobuf text fixture cannot be
+// converted into a stub.
+var ErrInvalidFixture = errors.New("invalid textproto fixture")
+
+// Suffixes of the protobuf text fixture files read by ReadTextprotoFixtures.
+const (
+	requestSuffix  = ".request.textproto"
+	responseSuffix = ".response.textproto"
+)
+
+// TextprotoFixture is a request and its response in the protobuf text
+// format, a golden file pair of a method.
+type TextprotoFixture struct {
+	Service  string // The service, by full name such as "helloworld.Greeter" or by name.
+	Method   string // The method.
+	Request  []byte // The request message, in the protobuf text format.
+	Response []byte // The response message, in the protobuf text format.
+}
+
+// ReadTextprotoFixtures reads the fixtures of the given directory, laid out
+// as <service>/<method>/<name>.request.textproto along with
+// <name>.response.textproto, in lexical order.
+//
+// Parameters:
+// - dir: The directory of the fixtures.
+//
+// Returns:
+// - []TextprotoFixture: The fixtures.
+// - error: An error if a file cannot be read, or one matching
+// ErrInvalidFixture if a request has no response or is misplaced.
+func ReadTextprotoFixtures(dir string) ([]TextprotoFixture, error) {
+	var fixtures []TextprotoFixture
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, requestSuffix) {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 3 { //nolint:mnd
+			return fmt.Errorf("%w: %s is not in a <service>/<method> directory", ErrInvalidFixture, path)
+		}
+
+		request, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		response, err := os.ReadFile(strings.TrimSuffix(path, requestSuffix) + responseSuffix)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %s has no response", ErrInvalidFixture, path)
+		} else if err != nil {
+			return err
+		}
+
+		fixtures = append(fixtures, TextprotoFixture{
+			Service:  parts[0],
+			Method:   parts[1],
+			Request:  request,
+			Response: response,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fixtures, nil
+}
+
+// ImportTextproto converts the fixtures into stubs matching their request
+// exactly and answering their response, and inserts them.
+//
+// The messages are decoded with the given descriptors, and their fields are
+// named like in the proto files, such as "user_id".
+//
+// Parameters:
+// - descriptors: A serialized FileDescriptorSet of the services and their
+// dependencies, such as written by protoc --descriptor_set_out --include_imports.
+// - fixtures: The fixtures to convert.
+//
+// Returns:
+// - []uuid.UUID: The keys of the inserted Stub values.
+// - error: An error matching ErrInvalidFixture if the descriptors or a
+// fixture cannot be decoded, in which case no Stub value is inserted.
+func (b *Budgerigar) ImportTextproto(descriptors []byte, fixtures ...TextprotoFixture) ([]uuid.UUID, error) {
+	files, err := parseDescriptors(descriptors)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFixture, err)
+	}
+
+	stubs := make([]*Stub, 0, len(fixtures))
+
+	for _, fixture := range fixtures {
+		stub, err := fixture.stub(files)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s/%s: %w", ErrInvalidFixture, fixture.Service, fixture.Method, err)
+		}
+
+		stubs = append(stubs, stub)
+	}
+
+	return b.PutMany(stubs...), nil
+}
+
+// stub converts the fixture into a stub, with the messages described by the
+// given files.
+func (f TextprotoFixture) stub(files *protoregistry.Files) (*Stub, error) {
+	method := findMethod(files, f.Service, f.Method)
+	if method == nil {
+		return nil, ErrMethodNotFound
+	}
+
+	request, err := textprotoData(method.Input(), f.Request)
+	if err != nil {
+		return nil, fmt.Errorf("request: %w", err)
+	}
+
+	response, err := textprotoData(method.Output(), f.Response)
+	if err != nil {
+		return nil, fmt.Errorf("response: %w", err)
+	}
+
+	return &Stub{
+		Service: f.Service,
+		Method:  f.Method,
+		Input:   InputData{Equals: request},
+		Output:  Output{Data: response},
+	}, nil
+}
+
+// parseDescriptors decodes a serialized FileDescriptorSet into the files it
+// describes.
+func parseDescriptors(descriptors []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(descriptors, &set); err != nil {
+		return nil, err
+	}
+
+	return protodesc.NewFiles(&set)
+}
+
+// findMethod returns the descriptor of the method of the service with the
+// given full name or name, or nil.
+func findMethod(files *protoregistry.Files, service, method string) protoreflect.MethodDescriptor {
+	var found protoreflect.MethodDescriptor
+
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		services := file.Services()
+
+		for i := range services.Len() {
+			desc := services.Get(i)
+			if string(desc.FullName()) != service && string(desc.Name()) != service {
+				continue
+			}
+
+			if found = desc.Methods().ByName(protoreflect.Name(method)); found != nil {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// textprotoData decodes the message in the protobuf text format into its
+// JSON data, with numbers as json.Number.
+func textprotoData(desc protoreflect.MessageDescriptor, text []byte) (map[string]interface{}, error) {
+	message := dynamicpb.NewMessage(desc)
+	if err := prototext.Unmarshal(text, message); err != nil {
+		return nil, err
+	}
+
+	return messageData(message)
+}
+
+// messageData encodes the message into its JSON data, with the fields named
+// like in the proto files and numbers as json.Number.
+func messageData(message proto.Message) (map[string]interface{}, error) {
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,102 @@
+package stuber_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/gripmock/stuber"
+)
+
+// greeterDescriptors returns the FileDescriptorSet of a helloworld.Greeter
+// service with a SayHello method.
+func greeterDescriptors(t *testing.T) []byte {
+	t.Helper()
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("helloworld.proto"),
+		Package: proto.String("helloworld"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("HelloRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("user_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("age", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				},
+			},
+			{
+				Name:  proto.String("HelloReply"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("message", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("SayHello"),
+				InputType:  proto.String(".helloworld.HelloRequest"),
+				OutputType: proto.String(".helloworld.HelloReply"),
+			}},
+		}},
+	}}}
+
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestBudgerigar_ImportTextproto(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "helloworld.Greeter", "SayHello")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bob.request.textproto"), []byte(`user_name: "Bob" age: 42`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bob.response.textproto"), []byte(`message: "Hello Bob"`), 0o600))
+
+	fixtures, err := stuber.ReadTextprotoFixtures(filepath.Dir(filepath.Dir(dir)))
+	require.NoError(t, err)
+	require.Len(t, fixtures, 1)
+	require.Equal(t, "helloworld.Greeter", fixtures[0].Service)
+
+	s := stuber.New()
+
+	ids, err := s.ImportTextproto(greeterDescriptors(t), fixtures...)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	result, err := s.FindByQuery(stuber.Query{
+		Service: "helloworld.Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"user_name": "Bob", "age": 42},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Hello Bob", result.Found().Output.Data["message"])
+
+	_, err = s.ImportTextproto(greeterDescriptors(t), stuber.TextprotoFixture{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Request: []byte(`unknown_field: 1`),
+	})
+	require.ErrorIs(t, err, stuber.ErrInvalidFixture)
+
+	_, err = s.ImportTextproto(greeterDescriptors(t), stuber.TextprotoFixture{Service: "Greeter", Method: "SayGoodbye"})
+	require.ErrorIs(t, err, stuber.ErrInvalidFixture)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "bob.response.textproto")))
+
+	_, err = stuber.ReadTextprotoFixtures(filepath.Dir(filepath.Dir(dir)))
+	require.ErrorIs(t, err, stuber.ErrInvalidFixture)
+}
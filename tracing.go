@@ -0,0 +1,117 @@
+package stuber
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Names of the spans of a search.
+const (
+	SpanFind  = "stuber.find"  // The whole search, from the query to the answer.
+	SpanMatch = "stuber.match" // The matching of the query against the stubs.
+
+	SpanExplain = "stuber.explain" // The explanation of the search of a query.
+)
+
+// Attribute keys of the spans of a search.
+const (
+	AttrService    = attribute.Key("stuber.service")    // The service of the query.
+	AttrMethod     = attribute.Key("stuber.method")     // The method of the query.
+	AttrCandidates = attribute.Key("stuber.candidates") // The number of stubs of the service and method.
+	AttrFound      = attribute.Key("stuber.found")      // Whether a stub answers the query.
+	AttrStubID     = attribute.Key("stuber.stub_id")    // The ID of the stub answering the query.
+	AttrRank       = attribute.Key("stuber.rank")       // The rank of the stub answering the query.
+)
+
+// Tracer is the OpenTelemetry tracer starting the spans of the searches,
+// such as to see the matcher latency inside a distributed trace.
+type Tracer = trace.Tracer
+
+// queryAttributes returns the attributes describing the given query.
+func queryAttributes(query Query) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttrService.String(query.Service),
+		AttrMethod.String(query.Method),
+	}
+}
+
+// resultAttributes returns the attributes describing the given result.
+func resultAttributes(result *Result) []attribute.KeyValue {
+	if result == nil || result.found == nil {
+		return []attribute.KeyValue{AttrFound.Bool(false)}
+	}
+
+	return []attribute.KeyValue{
+		AttrFound.Bool(true),
+		AttrStubID.String(result.found.ID.String()),
+		AttrRank.Float64(result.rank),
+	}
+}
+
+// endSpan describes the span with the given attributes and the error failing
+// its operation, if any, and ends it.
+func endSpan(span trace.Span, attrs []attribute.KeyValue, err error) {
+	span.SetAttributes(attrs...)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// FindByQueryContext is FindByQuery tracing the search, within the span of
+// the given context, with the Tracer set by WithTracer.
+//
+// The search emits a SpanFind span, with a SpanMatch child span for the
+// matching, described by the attributes such as AttrCandidates and AttrRank.
+//
+// Parameters:
+// - ctx: The context of the search, holding the parent span, if any.
+// - query: The Query used to search for a Stub value.
+//
+// Returns:
+// - *Result: The Result containing the found Stub value (if any), or nil.
+// - error: An error if the search fails.
+func (b *Budgerigar) FindByQueryContext(ctx context.Context, query Query) (*Result, error) {
+	if b.tracer == nil {
+		return b.findByQuery(ctx, query)
+	}
+
+	ctx, span := b.tracer.Start(ctx, SpanFind)
+
+	result, err := b.findByQuery(ctx, query)
+
+	endSpan(span, append(queryAttributes(query), resultAttributes(result)...), err)
+
+	return result, err
+}
+
+// match searches the canonical query in the stubs, tracing the matching.
+func (b *Budgerigar) match(ctx context.Context, query Query) (*Result, error) {
+	if b.tracer == nil {
+		return b.find(query)
+	}
+
+	_, span := b.tracer.Start(ctx, SpanMatch)
+
+	// The search counts the stubs it evaluates, including the ones of the
+	// remote source.
+	candidates := -1
+	query.candidates = &candidates
+
+	result, err := b.find(query)
+
+	attrs := queryAttributes(query)
+	if candidates >= 0 {
+		attrs = append(attrs, AttrCandidates.Int(candidates))
+	}
+
+	endSpan(span, append(attrs, resultAttributes(result)...), err)
+
+	return result, err
+}
@@ -0,0 +1,121 @@
+package stuber_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gripmock/stuber"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+
+	return attrs
+}
+
+func TestBudgerigar_FindByQueryContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	s := stuber.New(stuber.WithTracer(provider.Tracer("stuber")))
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "Hello Bob"}},
+	}
+	s.PutMany(stub, &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Alice"}},
+	})
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	result, err := s.FindByQueryContext(context.Background(), query)
+	require.NoError(t, err)
+	require.Equal(t, stub.ID, result.Found().ID)
+
+	// The spans are recorded once ended, the child first.
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	match, find := spans[0], spans[1]
+
+	require.Equal(t, stuber.SpanFind, find.Name())
+	require.Equal(t, "Greeter", spanAttributes(find)[stuber.AttrService].AsString())
+	require.Equal(t, stub.ID.String(), spanAttributes(find)[stuber.AttrStubID].AsString())
+
+	require.Equal(t, stuber.SpanMatch, match.Name())
+	require.Equal(t, find.SpanContext().SpanID(), match.Parent().SpanID())
+	require.Equal(t, "SayHello", spanAttributes(match)[stuber.AttrMethod].AsString())
+	require.Equal(t, int64(2), spanAttributes(match)[stuber.AttrCandidates].AsInt64())
+	require.True(t, spanAttributes(match)[stuber.AttrFound].AsBool())
+	require.Equal(t, stub.ID.String(), spanAttributes(match)[stuber.AttrStubID].AsString())
+	require.Greater(t, spanAttributes(match)[stuber.AttrRank].AsFloat64(), 0.0)
+
+	// The misses are traced with the error of the search, and count the
+	// stubs evaluated.
+	_, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"age": 42}})
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 4)
+	require.Equal(t, int64(2), spanAttributes(spans[2])[stuber.AttrCandidates].AsInt64())
+	require.False(t, spanAttributes(spans[3])[stuber.AttrFound].AsBool())
+	require.Equal(t, codes.Error, spans[3].Status().Code)
+
+	_, err = s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayGoodbye"})
+	require.ErrorIs(t, err, stuber.ErrMethodNotFound)
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 6)
+	require.Equal(t, codes.Error, spans[4].Status().Code)
+	require.Len(t, spans[4].Events(), 1)
+	require.NotContains(t, spanAttributes(spans[4]), stuber.AttrCandidates)
+}
+
+func TestBudgerigar_ExplainQueryContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	s := stuber.New(stuber.WithTracer(provider.Tracer("stuber")))
+	s.PutMany(&stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	})
+
+	query := stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}}
+
+	explanation, err := s.ExplainQueryContext(context.Background(), query)
+	require.NoError(t, err)
+	require.NotNil(t, explanation.Found)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, stuber.SpanExplain, spans[0].Name())
+	require.Equal(t, int64(1), spanAttributes(spans[0])[stuber.AttrCandidates].AsInt64())
+	require.True(t, spanAttributes(spans[0])[stuber.AttrFound].AsBool())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = s.ExplainQueryContext(ctx, query)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, codes.Error, recorder.Ended()[1].Status().Code)
+}
@@ -0,0 +1,157 @@
+package stuber
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrafficEntry is a matched query along with the response rendered by its
+// stub, one line of a traffic archive.
+type TrafficEntry struct {
+	Time     time.Time              `json:"time"`              // When the query was searched.
+	Latency  Duration               `json:"latency"`           // How long the search took.
+	Hash     string                 `json:"hash"`              // The QueryHash of the query.
+	Service  string                 `json:"service"`           // The service of the query.
+	Method   string                 `json:"method"`            // The method of the query.
+	Headers  map[string]interface{} `json:"headers,omitempty"` // The headers of the query.
+	Data     map[string]interface{} `json:"data,omitempty"`    // The data of the query.
+	StubID   uuid.UUID              `json:"stubId"`            // The stub that answered the query.
+	Response Output                 `json:"response"`          // The output of the stub, with its templates rendered.
+}
+
+// trafficLog keeps the last matched queries in a ring buffer.
+type trafficLog struct {
+	mu      sync.RWMutex
+	now     func() time.Time
+	entries []TrafficEntry
+	next    int
+	count   int
+}
+
+// newTrafficLog creates a new trafficLog keeping no queries.
+func newTrafficLog() *trafficLog {
+	return &trafficLog{now: time.Now}
+}
+
+// resize sets the number of kept queries and forgets the kept ones.
+func (l *trafficLog) resize(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = make([]TrafficEntry, max(size, 0))
+	l.next = 0
+	l.count = 0
+}
+
+// enabled checks if queries are kept.
+func (l *trafficLog) enabled() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.entries) > 0
+}
+
+// record keeps the given entry, timestamped with the clock of the log.
+func (l *trafficLog) record(entry TrafficEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.entries) == 0 {
+		return
+	}
+
+	entry.Time = l.now()
+
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	l.count = min(l.count+1, len(l.entries))
+}
+
+// list returns the kept entries, from the oldest to the newest.
+func (l *trafficLog) list() []TrafficEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make([]TrafficEntry, 0, l.count)
+
+	for i := range l.count {
+		result = append(result, l.entries[(l.next-l.count+i+len(l.entries))%len(l.entries)])
+	}
+
+	return result
+}
+
+// recordTraffic keeps the match of the query by the given stub, with its
+// rendered output, if the traffic is kept.
+//
+// The output of a stub whose templates cannot be rendered is kept as is.
+func (b *Budgerigar) recordTraffic(query Query, stub *Stub, latency time.Duration) {
+	if !b.traffic.enabled() {
+		return
+	}
+
+	response, err := b.RenderOutput(stub, query)
+	if err != nil {
+		response = stub.Output
+	}
+
+	b.traffic.record(TrafficEntry{
+		Latency:  Duration(latency),
+		Hash:     QueryHash(query),
+		Service:  query.Service,
+		Method:   query.Method,
+		Headers:  query.Headers,
+		Data:     query.Data,
+		StubID:   stub.ID,
+		Response: response,
+	})
+}
+
+// Traffic returns the last matched queries, from the oldest to the newest,
+// with the responses rendered by their stubs.
+//
+// Queries are only kept when enabled with WithTrafficLog, and only the last
+// ones are kept.
+//
+// Returns:
+// - []TrafficEntry: The kept queries.
+func (b *Budgerigar) Traffic() []TrafficEntry {
+	return b.traffic.list()
+}
+
+// ExportTraffic writes the last matched queries to the given writer as a
+// traffic archive: one JSON encoded TrafficEntry per line, from the oldest
+// to the newest, which analysis tools can consume line by line. The fields
+// of the entries are masked by the rules of WithRedaction, if any.
+//
+// Parameters:
+// - w: The writer of the archive, such as a CI artifact file.
+//
+// Returns:
+// - error: An error if an entry cannot be encoded, redacted or written.
+func (b *Budgerigar) ExportTraffic(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	for _, entry := range b.traffic.list() {
+		var encoded any = entry
+
+		if len(b.redaction) > 0 {
+			redacted, err := redact(b.redaction, entry)
+			if err != nil {
+				return err
+			}
+
+			encoded = redacted
+		}
+
+		if err := enc.Encode(encoded); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
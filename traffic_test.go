@@ -0,0 +1,85 @@
+package stuber_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Traffic(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := stuber.New(stuber.WithTrafficLog(2), stuber.WithClock(func() time.Time { return now }))
+
+	stub := &stuber.Stub{
+		ID:      uuid.New(),
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Contains: map[string]interface{}{"lang": "en"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "Hello {{ .Request.name }}"}},
+	}
+	s.PutMany(stub)
+
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		result, err := s.FindByQuery(stuber.Query{
+			Service: "Greeter",
+			Method:  "SayHello",
+			Data:    map[string]interface{}{"name": name, "lang": "en"},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.Found())
+	}
+
+	_, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"lang": "fr"}})
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+
+	traffic := s.Traffic()
+	require.Len(t, traffic, 2)
+	require.Equal(t, "Hello Bob", traffic[0].Response.Data["message"])
+	require.Equal(t, "Hello Carol", traffic[1].Response.Data["message"])
+	require.Equal(t, stub.ID, traffic[1].StubID)
+	require.Equal(t, now, traffic[1].Time)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.ExportTraffic(&buf))
+
+	var lines []stuber.TrafficEntry
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var entry stuber.TrafficEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+
+		lines = append(lines, entry)
+	}
+
+	require.Len(t, lines, 2)
+	require.Equal(t, "Greeter", lines[0].Service)
+	require.Equal(t, "SayHello", lines[0].Method)
+	require.Equal(t, "Bob", lines[0].Data["name"])
+	require.Equal(t, traffic[0].Latency, lines[0].Latency)
+}
+
+func TestBudgerigar_TrafficDisabled(t *testing.T) {
+	s := stuber.New()
+	s.PutMany(&stuber.Stub{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	})
+
+	_, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: "SayHello", Data: map[string]interface{}{"name": "Bob"}})
+	require.NoError(t, err)
+
+	require.Empty(t, s.Traffic())
+
+	var buf bytes.Buffer
+	require.NoError(t, s.ExportTraffic(&buf))
+	require.Zero(t, buf.Len())
+}
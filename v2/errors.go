@@ -0,0 +1,42 @@
+package stuber
+
+import (
+	"github.com/gripmock/stuber"
+)
+
+// The sentinel errors wrapped by Error, to be compared with errors.Is.
+var (
+	ErrServiceNotFound  = stuber.ErrServiceNotFound
+	ErrMethodNotFound   = stuber.ErrMethodNotFound
+	ErrStubNotFound     = stuber.ErrStubNotFound
+	ErrInvalidQuery     = stuber.ErrInvalidQuery
+	ErrInvalidPatch     = stuber.ErrInvalidPatch
+	ErrRevisionNotFound = stuber.ErrRevisionNotFound
+)
+
+// Error is the error returned by the Budgerigar. It records the operation
+// that failed and wraps its cause, either a sentinel error of the package or
+// the error of the context.
+type Error struct {
+	Op  string // The failed operation, such as "find".
+	Err error  // The cause of the failure.
+}
+
+// Error returns the message of the error.
+func (e *Error) Error() string {
+	return "stuber: " + e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrap returns the given error as an Error of the operation, or nil.
+func wrap(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Op: op, Err: err}
+}
@@ -0,0 +1,224 @@
+// Package stuber is the second major version of the stub storage and
+// matching of gripmock.
+//
+// Its methods consistently take a context.Context, are configured with
+// option structs and return *Error values. The types of the stubs and the
+// queries are shared with the first version, which is still maintained for
+// gripmock compatibility and reachable with Budgerigar.V1.
+package stuber
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bavix/features"
+	"github.com/google/uuid"
+
+	"github.com/gripmock/stuber"
+)
+
+// The types shared with the first version.
+type (
+	Stub        = stuber.Stub
+	InputData   = stuber.InputData
+	InputHeader = stuber.InputHeader
+	Output      = stuber.Output
+	Query       = stuber.Query
+	Result      = stuber.Result
+	Explanation = stuber.Explanation
+	RankFunc    = stuber.RankFunc
+	Metrics     = stuber.Metrics
+	Tracer      = stuber.Tracer
+)
+
+// Options configures a Budgerigar created with New. The zero value is the
+// default configuration.
+type Options struct {
+	Toggles features.Toggles // The feature flags, none by default.
+	Logger  *slog.Logger     // The logger of the failures not affecting matching, discarding by default.
+	Clock   func() time.Time // The clock of the time based features, time.Now by default.
+	Rank    RankFunc         // The ranking strategy, stuber.DefaultRank by default.
+	Metrics Metrics          // The receiver of the measurements, none by default.
+	Tracer  Tracer           // The tracer of the searches, none by default.
+}
+
+// ListOptions filters the stubs returned by Budgerigar.List. The zero value
+// lists all the stubs.
+type ListOptions struct {
+	Service string // The service of the stubs, any if empty.
+	Method  string // The method of the stubs, any if empty.
+
+	MetaEquals map[string]string // The labels the Meta of the stubs must have.
+}
+
+// Budgerigar stores stubs and finds the stub answering a query.
+type Budgerigar struct {
+	v1 *stuber.Budgerigar
+}
+
+// New creates a new Budgerigar configured with the given options.
+func New(opts Options) *Budgerigar {
+	v1 := []stuber.Option{stuber.WithToggles(opts.Toggles)}
+
+	if opts.Logger != nil {
+		v1 = append(v1, stuber.WithLogger(opts.Logger))
+	}
+
+	if opts.Clock != nil {
+		v1 = append(v1, stuber.WithClock(opts.Clock))
+	}
+
+	if opts.Rank != nil {
+		v1 = append(v1, stuber.WithRanker(opts.Rank))
+	}
+
+	if opts.Metrics != nil {
+		v1 = append(v1, stuber.WithMetrics(opts.Metrics))
+	}
+
+	if opts.Tracer != nil {
+		v1 = append(v1, stuber.WithTracer(opts.Tracer))
+	}
+
+	return &Budgerigar{v1: stuber.New(v1...)}
+}
+
+// V1 returns the underlying Budgerigar of the first version, for the
+// features not exposed by the second one yet.
+func (b *Budgerigar) V1() *stuber.Budgerigar {
+	return b.v1
+}
+
+// Put inserts the given stubs, generating the IDs of the stubs without
+// one, and returns their IDs.
+func (b *Budgerigar) Put(ctx context.Context, stubs ...*Stub) ([]uuid.UUID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("put", err)
+	}
+
+	return b.v1.PutMany(stubs...), nil
+}
+
+// Update replaces the given stubs, inserting the unknown ones, and returns
+// their IDs.
+func (b *Budgerigar) Update(ctx context.Context, stubs ...*Stub) ([]uuid.UUID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("update", err)
+	}
+
+	return b.v1.UpdateMany(stubs...), nil
+}
+
+// Patch applies the given mutator to a copy of the stub with the given ID
+// and stores the result.
+func (b *Budgerigar) Patch(ctx context.Context, id uuid.UUID, mutate func(*Stub) error) error {
+	if err := ctx.Err(); err != nil {
+		return wrap("patch", err)
+	}
+
+	return wrap("patch", b.v1.PatchByID(id, mutate))
+}
+
+// Delete deletes the stubs with the given IDs and returns how many were
+// deleted.
+func (b *Budgerigar) Delete(ctx context.Context, ids ...uuid.UUID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, wrap("delete", err)
+	}
+
+	return b.v1.DeleteByID(ids...), nil
+}
+
+// Get returns the stub with the given ID, or an error wrapping
+// ErrStubNotFound.
+func (b *Budgerigar) Get(ctx context.Context, id uuid.UUID) (*Stub, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("get", err)
+	}
+
+	stub := b.v1.FindByID(id)
+	if stub == nil {
+		return nil, wrap("get", ErrStubNotFound)
+	}
+
+	return stub, nil
+}
+
+// List returns the stubs selected by the given options.
+//
+// Listing an unknown service or method returns no stubs, not an error.
+func (b *Budgerigar) List(ctx context.Context, opts ListOptions) ([]*Stub, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("list", err)
+	}
+
+	stubs := make([]*Stub, 0)
+
+	for stub := range b.v1.Iter() {
+		if (opts.Service == "" || stub.Service == opts.Service) &&
+			(opts.Method == "" || stub.Method == opts.Method) &&
+			stub.MetaEquals(opts.MetaEquals) {
+			stubs = append(stubs, stub)
+		}
+	}
+
+	return stubs, nil
+}
+
+// Find validates the query and returns the result of its search, traced
+// within the span of the context by the Tracer of the options.
+//
+// The errors wrap ErrInvalidQuery, ErrServiceNotFound, ErrMethodNotFound
+// or the error of the context.
+func (b *Budgerigar) Find(ctx context.Context, query Query) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("find", err)
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, wrap("find", err)
+	}
+
+	result, err := b.v1.FindByQueryContext(ctx, query)
+	if err != nil {
+		return nil, wrap("find", err)
+	}
+
+	return result, nil
+}
+
+// Explain validates the query and explains, stub by stub, why the stubs of
+// its service and method match it or not, traced within the span of the
+// context by the Tracer of the options. The search is internal and doesn't
+// mark the stubs as used.
+//
+// The errors wrap ErrInvalidQuery, ErrServiceNotFound, ErrMethodNotFound
+// or the error of the context.
+func (b *Budgerigar) Explain(ctx context.Context, query Query) (*Explanation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("explain", err)
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, wrap("explain", err)
+	}
+
+	explanation, err := b.v1.ExplainQueryContext(ctx, query)
+	if err != nil {
+		return nil, wrap("explain", err)
+	}
+
+	return explanation, nil
+}
+
+// Clear deletes all the stubs.
+func (b *Budgerigar) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return wrap("clear", err)
+	}
+
+	b.v1.Clear()
+
+	return nil
+}
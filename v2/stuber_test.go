@@ -0,0 +1,112 @@
+package stuber_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber/v2"
+)
+
+func TestBudgerigar(t *testing.T) {
+	ctx := context.Background()
+	s := stuber.New(stuber.Options{})
+
+	id := uuid.New()
+
+	ids, err := s.Put(ctx, &stuber.Stub{
+		ID:      id,
+		Service: "Greeter",
+		Method:  "SayHello",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Output:  stuber.Output{Data: map[string]interface{}{"message": "Hello Bob"}},
+		Meta:    map[string]string{"owner": "payments"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{id}, ids)
+
+	stubs, err := s.List(ctx, stuber.ListOptions{Service: "Greeter"})
+	require.NoError(t, err)
+	require.Len(t, stubs, 1)
+
+	stubs, err = s.List(ctx, stuber.ListOptions{Method: "SayGoodbye"})
+	require.NoError(t, err)
+	require.Empty(t, stubs)
+
+	stubs, err = s.List(ctx, stuber.ListOptions{MetaEquals: map[string]string{"owner": "payments"}})
+	require.NoError(t, err)
+	require.Len(t, stubs, 1)
+
+	stubs, err = s.List(ctx, stuber.ListOptions{MetaEquals: map[string]string{"owner": "search"}})
+	require.NoError(t, err)
+	require.Empty(t, stubs)
+
+	r, err := s.Find(ctx, stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Bob"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, id, r.Found().ID)
+
+	explanation, err := s.Explain(ctx, stuber.Query{
+		Service: "Greeter",
+		Method:  "SayHello",
+		Data:    map[string]interface{}{"name": "Alice"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, explanation.Found)
+	require.Len(t, explanation.Candidates, 1)
+	require.False(t, explanation.Candidates[0].Matched)
+
+	_, err = s.Explain(ctx, stuber.Query{Service: "Unknown", Method: "SayHello", Data: map[string]interface{}{}})
+	require.ErrorIs(t, err, stuber.ErrServiceNotFound)
+
+	_, err = s.Find(ctx, stuber.Query{Service: "Greeter"})
+	require.ErrorIs(t, err, stuber.ErrInvalidQuery)
+
+	_, err = s.Find(ctx, stuber.Query{Service: "Unknown", Method: "SayHello", Data: map[string]interface{}{}})
+	require.ErrorIs(t, err, stuber.ErrServiceNotFound)
+
+	var typed *stuber.Error
+
+	require.True(t, errors.As(err, &typed))
+	require.Equal(t, "find", typed.Op)
+
+	require.NoError(t, s.Patch(ctx, id, func(stub *stuber.Stub) error {
+		stub.Priority = 1
+
+		return nil
+	}))
+
+	stub, err := s.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 1, stub.Priority)
+
+	n, err := s.Delete(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, err = s.Get(ctx, id)
+	require.ErrorIs(t, err, stuber.ErrStubNotFound)
+}
+
+func TestBudgerigar_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := stuber.New(stuber.Options{})
+
+	_, err := s.Put(ctx, &stuber.Stub{Service: "Greeter", Method: "SayHello"})
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.Find(ctx, stuber.Query{Service: "Greeter", Method: "SayHello"})
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.ErrorIs(t, s.Clear(ctx), context.Canceled)
+
+	require.Empty(t, s.V1().All())
+}
@@ -0,0 +1,130 @@
+package stuber
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnmetExpectations is returned when stubs were not matched as many times
+// as they expect.
+var ErrUnmetExpectations = errors.New("unmet expectations")
+
+// Expectations are the bounds of the number of times a stub is expected to
+// be matched, checked by Verify. Unset bounds are not checked.
+type Expectations struct {
+	Times   *int `json:"times,omitempty"`   // The exact number of matches.
+	AtLeast *int `json:"atLeast,omitempty"` // The minimum number of matches.
+	AtMost  *int `json:"atMost,omitempty"`  // The maximum number of matches.
+}
+
+// Met checks if the given number of matches is within the bounds.
+func (e Expectations) Met(hits uint64) bool {
+	switch {
+	case e.Times != nil && hits != uint64(max(*e.Times, 0)):
+		return false
+	case e.AtLeast != nil && hits < uint64(max(*e.AtLeast, 0)):
+		return false
+	case e.AtMost != nil && hits > uint64(max(*e.AtMost, 0)):
+		return false
+	default:
+		return true
+	}
+}
+
+// String describes the bounds, such as "exactly 2 times".
+func (e Expectations) String() string {
+	var bounds []string
+
+	if e.Times != nil {
+		bounds = append(bounds, "exactly "+strconv.Itoa(*e.Times))
+	}
+
+	if e.AtLeast != nil {
+		bounds = append(bounds, "at least "+strconv.Itoa(*e.AtLeast))
+	}
+
+	if e.AtMost != nil {
+		bounds = append(bounds, "at most "+strconv.Itoa(*e.AtMost))
+	}
+
+	if len(bounds) == 0 {
+		return "any number of times"
+	}
+
+	return strings.Join(bounds, " and ") + " times"
+}
+
+// VerificationFailure describes a stub matched more or fewer times than it
+// expects.
+type VerificationFailure struct {
+	Stub     uuid.UUID    `json:"stub"`     // The stub with the unmet expectations.
+	Service  string       `json:"service"`  // The service of the stub.
+	Method   string       `json:"method"`   // The method of the stub.
+	Expected Expectations `json:"expected"` // The expectations of the stub.
+	Actual   uint64       `json:"actual"`   // The number of matches of the stub.
+}
+
+// VerificationReport lists the stubs whose expectations are not met.
+type VerificationReport struct {
+	Failures []VerificationFailure `json:"failures,omitempty"` // The unmet expectations, in the canonical order of their stubs.
+}
+
+// OK checks if all the expectations are met.
+func (r VerificationReport) OK() bool {
+	return len(r.Failures) == 0
+}
+
+// Err returns an error matching ErrUnmetExpectations which describes the
+// unmet expectations, or nil if all of them are met.
+func (r VerificationReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+
+	lines := make([]string, len(r.Failures))
+	for i, failure := range r.Failures {
+		lines[i] = fmt.Sprintf(
+			"stub %s (%s/%s) expected %s, called %d times",
+			failure.Stub, failure.Service, failure.Method, failure.Expected, failure.Actual,
+		)
+	}
+
+	return fmt.Errorf("%w:\n%s", ErrUnmetExpectations, strings.Join(lines, "\n"))
+}
+
+// Verify checks the number of matches of the stored Stub values against
+// their Expectations, such as at the end of a test.
+//
+// Internal queries are not counted, as by Stats.
+//
+// Returns:
+// - VerificationReport: The Stub values matched more or fewer times than
+// expected.
+func (b *Budgerigar) Verify() VerificationReport {
+	var report VerificationReport
+
+	for _, stub := range SortStubs(b.searcher.all()) {
+		if stub.Expectations == nil {
+			continue
+		}
+
+		hits := b.searcher.usage(stub.ID).hits
+		if stub.Expectations.Met(hits) {
+			continue
+		}
+
+		report.Failures = append(report.Failures, VerificationFailure{
+			Stub:     stub.ID,
+			Service:  stub.Service,
+			Method:   stub.Method,
+			Expected: *stub.Expectations,
+			Actual:   hits,
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,64 @@
+package stuber_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestBudgerigar_Verify(t *testing.T) {
+	s := stuber.New()
+
+	once, two := 1, 2
+
+	hello := &stuber.Stub{
+		ID:           uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Service:      "Greeter",
+		Method:       "SayHello",
+		Input:        stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Expectations: &stuber.Expectations{Times: &once},
+	}
+	bye := &stuber.Stub{
+		ID:           uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		Service:      "Greeter",
+		Method:       "SayGoodbye",
+		Input:        stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+		Expectations: &stuber.Expectations{AtLeast: &once, AtMost: &two},
+	}
+	unchecked := &stuber.Stub{
+		ID:      uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+		Service: "Greeter",
+		Method:  "SayHi",
+		Input:   stuber.InputData{Equals: map[string]interface{}{"name": "Bob"}},
+	}
+	s.PutMany(hello, bye, unchecked)
+
+	find := func(method string) {
+		_, err := s.FindByQuery(stuber.Query{Service: "Greeter", Method: method, Data: map[string]interface{}{"name": "Bob"}})
+		require.NoError(t, err)
+	}
+
+	report := s.Verify()
+	require.False(t, report.OK())
+	require.Equal(t, []stuber.VerificationFailure{
+		{Stub: bye.ID, Service: "Greeter", Method: "SayGoodbye", Expected: *bye.Expectations},
+		{Stub: hello.ID, Service: "Greeter", Method: "SayHello", Expected: *hello.Expectations},
+	}, report.Failures)
+
+	find("SayHello")
+	find("SayGoodbye")
+	require.NoError(t, s.Verify().Err())
+
+	find("SayHello")
+	find("SayGoodbye")
+	find("SayGoodbye")
+
+	err := s.Verify().Err()
+	require.ErrorIs(t, err, stuber.ErrUnmetExpectations)
+	require.Equal(t, "unmet expectations:\n"+
+		"stub "+bye.ID.String()+" (Greeter/SayGoodbye) expected at least 1 and at most 2 times, called 3 times\n"+
+		"stub "+hello.ID.String()+" (Greeter/SayHello) expected exactly 1 times, called 2 times", err.Error())
+}
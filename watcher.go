@@ -0,0 +1,259 @@
+package stuber
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/uuid"
+)
+
+// watchedFile is the state of a stub file loaded by a DirectoryWatcher.
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte // The hash of the content.
+	ids     []uuid.UUID
+}
+
+// DirectoryWatcher loads the .json, .yaml and .yml stub files of a directory
+// and its subdirectories, and reloads them when they change, so stub files
+// can be edited while the server is running.
+//
+// Run reloads the changed files as soon as the file system notifies their
+// changes. The directory is also scanned at an interval, as a fallback for
+// the file systems without notifications and the notifications dropped by
+// the operating system. A scan detects the changes by the modification
+// times and the sizes of the files, and misses a change keeping both, which
+// a notification catches.
+type DirectoryWatcher struct {
+	budgerigar *Budgerigar
+	dir        string
+
+	mu    sync.Mutex
+	files map[string]watchedFile
+}
+
+// NewDirectoryWatcher creates a new DirectoryWatcher of the given directory.
+// Nothing is loaded until Reload or Run is called.
+//
+// Parameters:
+// - b: The Budgerigar the stubs are loaded into.
+// - dir: The directory of the stub files.
+//
+// Returns:
+// - *DirectoryWatcher: A new DirectoryWatcher.
+func NewDirectoryWatcher(b *Budgerigar, dir string) *DirectoryWatcher {
+	return &DirectoryWatcher{budgerigar: b, dir: dir, files: make(map[string]watchedFile)}
+}
+
+// Reload loads the new and the changed stub files of the directory, replacing
+// the Stub values of the changed ones, and deletes the Stub values of the
+// deleted ones.
+//
+// The changes are detected by the modification times and the sizes of the
+// files. A file that cannot be decoded keeps its previous Stub values, and
+// its error is returned along with the others once all the files are
+// processed.
+//
+// Returns:
+// - error: The errors of the directory and of the files, if any.
+func (w *DirectoryWatcher) Reload() error {
+	return w.reload(nil)
+}
+
+// reload is Reload reading the given files even if their modification times
+// and sizes did not change, such as once notified of their changes.
+func (w *DirectoryWatcher) reload(changed map[string]struct{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+
+	seen := make(map[string]struct{}, len(w.files))
+
+	err := filepath.WalkDir(w.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !isStubFile(path) {
+			return nil
+		}
+
+		seen[path] = struct{}{}
+
+		_, force := changed[path]
+
+		if err := w.load(path, entry, force); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+
+		return nil
+	})
+	if err != nil {
+		// The files of an unreadable directory are not deleted.
+		return errors.Join(append(errs, err)...)
+	}
+
+	for path, file := range w.files {
+		if _, ok := seen[path]; !ok {
+			w.budgerigar.DeleteByID(file.ids...)
+			delete(w.files, path)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// load loads the stub file at the given path if it is new or has changed.
+//
+// Unless forced, a file keeping its modification time and its size is not
+// read. A file read with the same content is not loaded again.
+func (w *DirectoryWatcher) load(path string, entry fs.DirEntry, force bool) error {
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+
+	previous, ok := w.files[path]
+	if ok && !force && previous.modTime.Equal(info.ModTime()) && previous.size == info.Size() {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	if ok && previous.sum == sum {
+		previous.modTime, previous.size = info.ModTime(), info.Size()
+		w.files[path] = previous
+
+		return nil
+	}
+
+	stubs, err := w.budgerigar.importer.decode(data, w.budgerigar.toggles.Has(ImportEnv))
+	if err != nil {
+		return err
+	}
+
+	ids := w.budgerigar.PutMany(stubs...)
+
+	// Delete the Stub values removed from the file.
+	w.budgerigar.DeleteByID(slices.DeleteFunc(previous.ids, func(id uuid.UUID) bool {
+		return slices.Contains(ids, id)
+	})...)
+
+	w.files[path] = watchedFile{modTime: info.ModTime(), size: info.Size(), sum: sum, ids: ids}
+
+	return nil
+}
+
+// Run reloads the directory until the context is canceled: the changed
+// files as soon as the file system notifies their changes, and the whole
+// directory at the given interval. Without notifications, such as when the
+// limit of watches of the operating system is reached, the directory is
+// only reloaded at the interval. Reload errors, and the errors of the
+// notifications, are passed to onError, which may be nil.
+//
+// Parameters:
+// - ctx: The context stopping the watch.
+// - interval: The duration between two reloads of the whole directory.
+// - onError: The callback receiving reload errors, or nil.
+func (w *DirectoryWatcher) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	report := func(err error) {
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	var (
+		events <-chan fsnotify.Event
+		errs   <-chan error
+	)
+
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		report(fmt.Errorf("watch %s: %w", w.dir, err))
+	} else {
+		defer notifier.Close()
+
+		events, errs = notifier.Events, notifier.Errors
+
+		report(w.watch(notifier, w.dir))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	report(w.Reload())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report(w.Reload())
+		case err := <-errs:
+			report(err)
+		case event := <-events:
+			changed := make(map[string]struct{})
+
+			// Reload the files of the events at once, an editor saving a
+			// file with several writes.
+			for pending := true; pending; {
+				w.notified(notifier, event, changed, report)
+
+				select {
+				case event = <-events:
+				default:
+					pending = false
+				}
+			}
+
+			report(w.reload(changed))
+		}
+	}
+}
+
+// notified records the file of the given event as changed, and watches the
+// directory of the event if it is a new one.
+func (w *DirectoryWatcher) notified(
+	notifier *fsnotify.Watcher,
+	event fsnotify.Event,
+	changed map[string]struct{},
+	report func(error),
+) {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			report(w.watch(notifier, event.Name))
+		}
+	}
+
+	changed[event.Name] = struct{}{}
+}
+
+// watch adds the given directory and its subdirectories to the notifier.
+func (w *DirectoryWatcher) watch(notifier *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return notifier.Add(path)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,106 @@
+package stuber_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gripmock/stuber"
+)
+
+func TestDirectoryWatcher_Reload(t *testing.T) {
+	dir := t.TempDir()
+	hello := filepath.Join(dir, "hello.yaml")
+	goodbye := filepath.Join(dir, "nested", "goodbye.json")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(goodbye), 0o755))
+	require.NoError(t, os.WriteFile(hello, []byte("service: Greeter\nmethod: SayHello\n"), 0o600))
+	require.NoError(t, os.WriteFile(goodbye, []byte(`{"service": "Greeter", "method": "SayGoodbye"}`), 0o600))
+
+	s := stuber.New()
+	w := stuber.NewDirectoryWatcher(s, dir)
+
+	require.NoError(t, w.Reload())
+	require.Len(t, s.All(), 2)
+
+	// Unchanged files are not loaded again.
+	before := s.All()
+	require.NoError(t, w.Reload())
+	require.ElementsMatch(t, before, s.All())
+
+	// Changed files replace their stubs.
+	require.NoError(t, os.WriteFile(hello, []byte("- service: Greeter\n  method: SayHello\n- service: Greeter\n  method: SayHi\n"), 0o600))
+	require.NoError(t, w.Reload())
+	require.Len(t, s.All(), 3)
+
+	// Broken files keep their stubs.
+	require.NoError(t, os.WriteFile(goodbye, []byte(`{`), 0o600))
+	require.ErrorIs(t, w.Reload(), stuber.ErrInvalidImport)
+	require.Len(t, s.All(), 3)
+
+	// Deleted files delete their stubs.
+	require.NoError(t, os.Remove(hello))
+	require.ErrorIs(t, w.Reload(), stuber.ErrInvalidImport)
+	require.Len(t, s.All(), 1)
+	require.Equal(t, "SayGoodbye", s.All()[0].Method)
+}
+
+func TestDirectoryWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.json"), []byte(`{"service": "Greeter", "method": "SayHello"}`), 0o600))
+
+	s := stuber.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go stuber.NewDirectoryWatcher(s, dir).Run(ctx, time.Millisecond, nil)
+
+	require.Eventually(t, func() bool { return len(s.All()) == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "hello.json")))
+	require.Eventually(t, func() bool { return len(s.All()) == 0 }, time.Second, time.Millisecond)
+}
+
+func TestDirectoryWatcher_Run_SameSize(t *testing.T) {
+	dir := t.TempDir()
+	hello := filepath.Join(dir, "hello.json")
+	require.NoError(t, os.WriteFile(hello, []byte(`{"service": "Greeter", "method": "SayHello"}`), 0o600))
+
+	info, err := os.Stat(hello)
+	require.NoError(t, err)
+
+	s := stuber.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The scans never run: the changes are notified.
+	go stuber.NewDirectoryWatcher(s, dir).Run(ctx, time.Hour, nil)
+
+	require.Eventually(t, func() bool { return len(s.All()) == 1 }, time.Second, time.Millisecond)
+
+	// Edit the file keeping its size and its modification time.
+	require.NoError(t, os.WriteFile(hello, []byte(`{"service": "Greeter", "method": "SayHallo"}`), 0o600))
+	require.NoError(t, os.Chtimes(hello, info.ModTime(), info.ModTime()))
+
+	require.Eventually(t, func() bool {
+		all := s.All()
+
+		return len(all) == 1 && all[0].Method == "SayHallo"
+	}, time.Second, time.Millisecond)
+
+	// The files of new directories are notified as well.
+	nested := filepath.Join(dir, "nested")
+	require.NoError(t, os.Mkdir(nested, 0o755))
+	require.Eventually(t, func() bool {
+		// Write again until the directory is watched.
+		_ = os.WriteFile(filepath.Join(nested, "bye.json"), []byte(`{"service": "Greeter", "method": "SayBye"}`), 0o600)
+
+		return len(s.All()) == 2
+	}, time.Second, 10*time.Millisecond)
+}